@@ -0,0 +1,141 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AgingParams select the businesses and the date of GetCustomerTransactionsAging
+type AgingParams struct {
+	// BusinessID restricts the aging to a business, all the businesses with transactions by default
+	BusinessID *BusinessID `json:"BusinessID,omitempty"`
+	// AsOf is the date the ages are computed at, now by default
+	AsOf *time.Time `json:"AsOf,omitempty"`
+}
+
+// CustomerAging is the receivable of a business by the age of its sale invoices
+type CustomerAging struct {
+	BusinessID BusinessID `json:"BusinessID"`
+	// Current is due for up to 30 days, a negative amount is a credit of the business
+	Current Money `json:"Current"`
+	// Days30 is due for 31 to 60 days
+	Days30 Money `json:"Days30"`
+	// Days60 is due for 61 to 90 days
+	Days60 Money `json:"Days60"`
+	// Days90 is due for more than 90 days
+	Days90 Money `json:"Days90"`
+	// Total is the balance of the business
+	Total Money `json:"Total"`
+}
+
+// CustomerAgingResponse is the response of the aging report endpoint
+type CustomerAgingResponse = APIResponse[CustomerAging]
+
+// GetCustomerTransactionsAging returns the receivable of the businesses in aging buckets, e.g. for a finance dashboard.
+// It calls the aging report of Arpa when GetCustomerAgingEndpoint is configured, otherwise it computes the aging
+// from the balances and the transactions, see AgeBalance.
+func (g *GoArpa) GetCustomerTransactionsAging(ctx context.Context, accessToken string, cookie []*http.Cookie, params AgingParams) ([]CustomerAging, error) {
+	const errMessage = "could not get customer aging"
+
+	if g.config().GetCustomerAgingEndpoint == "" {
+		return customerTransactionsAging(ctx, g, accessToken, cookie, params)
+	}
+
+	var response CustomerAgingResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetCustomerAgingEndpoint, params, &response, errMessage); err != nil {
+		return nil, err
+	}
+	return []CustomerAging(response.Data), nil
+}
+
+// customerTransactionsAging computes the aging of the businesses with the posted sale invoices up to the date
+func customerTransactionsAging(ctx context.Context, client GoArpaIface, accessToken string, cookie []*http.Cookie, params AgingParams) ([]CustomerAging, error) {
+	const errMessage = "could not compute customer aging"
+
+	asOf := time.Now()
+	if params.AsOf != nil {
+		asOf = *params.AsOf
+	}
+
+	invoices := make(map[BusinessID][]Transaction)
+	if params.BusinessID != nil {
+		invoices[*params.BusinessID] = nil
+	}
+	transactions := GetTransactionsParams{BusinessID: params.BusinessID, ToDate: &asOf}
+	for transaction, err := range client.IterateTransactions(ctx, accessToken, cookie, transactions) {
+		if err != nil {
+			return nil, errors.Wrap(err, errMessage)
+		}
+		if transaction.FactorTypeID != FactorTypeSale || transaction.TransStateID == TransStateDraft ||
+			transaction.TransDate == nil || transaction.TransDate.After(asOf) {
+			continue
+		}
+		invoices[transaction.BusinessID] = append(invoices[transaction.BusinessID], transaction)
+	}
+
+	agings := make([]CustomerAging, 0, len(invoices))
+	for businessID, transactions := range invoices {
+		balance, err := client.GetCustomerBalance(ctx, accessToken, cookie, businessID)
+		if err != nil {
+			return nil, errors.Wrap(err, errMessage)
+		}
+		agings = append(agings, AgeBalance(businessID, balance.Balance, transactions, asOf))
+	}
+	sort.Slice(agings, func(i, j int) bool { return agings[i].BusinessID < agings[j].BusinessID })
+	return agings, nil
+}
+
+// AgeBalance allocates the balance of the business to its sale invoices, the most recent first,
+// and buckets the allocated amounts by the age of the invoices. The payments are assumed to settle
+// the oldest invoices first. The balance left once all the invoices are allocated is older than them
+// and counted over 90 days, a negative balance is a credit counted as current.
+func AgeBalance(businessID BusinessID, balance Money, invoices []Transaction, asOf time.Time) CustomerAging {
+	aging := CustomerAging{BusinessID: businessID, Total: balance}
+	if !balance.IsPositive() {
+		aging.Current = balance
+		return aging
+	}
+
+	dated := make([]Transaction, 0, len(invoices))
+	for _, invoice := range invoices {
+		if invoice.TransDate != nil {
+			dated = append(dated, invoice)
+		}
+	}
+	invoices = dated
+	sort.SliceStable(invoices, func(i, j int) bool {
+		return invoices[i].TransDate.After(invoices[j].TransDate.Time)
+	})
+
+	remaining := balance
+	for _, invoice := range invoices {
+		if !remaining.IsPositive() {
+			break
+		}
+		amount := invoice.TotalAmount
+		if amount.GreaterThan(remaining.Decimal) {
+			amount = remaining
+		}
+		if !amount.IsPositive() {
+			continue
+		}
+		remaining = remaining.Sub(amount)
+
+		bucket := &aging.Days90
+		switch days := int(asOf.Sub(invoice.TransDate.Time).Hours() / 24); {
+		case days <= 30:
+			bucket = &aging.Current
+		case days <= 60:
+			bucket = &aging.Days30
+		case days <= 90:
+			bucket = &aging.Days60
+		}
+		*bucket = bucket.Add(amount)
+	}
+	aging.Days90 = aging.Days90.Add(remaining)
+	return aging
+}
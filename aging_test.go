@@ -0,0 +1,59 @@
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AgeBalance(t *testing.T) {
+	t.Parallel()
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	invoice := func(daysAgo int, amount int64) goarpa.Transaction {
+		return goarpa.Transaction{
+			TransDate:   &goarpa.CustomTime{Time: asOf.AddDate(0, 0, -daysAgo)},
+			TotalAmount: goarpa.NewMoney(amount),
+		}
+	}
+	invoices := []goarpa.Transaction{invoice(100, 500), invoice(45, 300), invoice(10, 200), invoice(70, 400)}
+
+	// the payments settled the oldest invoices, the 700 left are the most recent
+	aging := goarpa.AgeBalance(7, goarpa.NewMoney(700), invoices, asOf)
+	assert.Equal(t, "200", aging.Current.String())
+	assert.Equal(t, "300", aging.Days30.String())
+	assert.Equal(t, "200", aging.Days60.String())
+	assert.Equal(t, "0", aging.Days90.String())
+	assert.Equal(t, "700", aging.Total.String())
+
+	// an opening balance older than the invoices
+	aging = goarpa.AgeBalance(7, goarpa.NewMoney(1500), invoices, asOf)
+	assert.Equal(t, "600", aging.Days90.String())
+
+	aging = goarpa.AgeBalance(7, goarpa.NewMoney(-50), invoices, asOf)
+	assert.Equal(t, "-50", aging.Current.String())
+	assert.Equal(t, "0", aging.Days30.String())
+}
+
+func Test_GetCustomerTransactionsAgingEndpoint(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/aging", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"BusinessID":"7","Current":"100","Days30":"0","Days60":"0","Days90":"50","Total":"150"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetCustomerAgingEndpoint = "aging"
+	agings, err := client.GetCustomerTransactionsAging(context.Background(), "token", nil, goarpa.AgingParams{})
+	require.NoError(t, err)
+	require.Len(t, agings, 1)
+	assert.Equal(t, goarpa.BusinessID(7), agings[0].BusinessID)
+	assert.Equal(t, "50", agings[0].Days90.String())
+}
@@ -0,0 +1,227 @@
+// Package arpasync incrementally mirrors the customers, items and transactions of Arpa into a local store.
+//
+// Every entity keeps a watermark, the latest Modification_Date seen, and the next run only fetches
+// the records modified since then.
+package arpasync
+
+import (
+	"context"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+)
+
+// Entity is a kind of record mirrored by the syncer
+type Entity string
+
+const (
+	// EntityCustomers are the businesses
+	EntityCustomers Entity = "customers"
+	// EntityItems are the items and services
+	EntityItems Entity = "items"
+	// EntityTransactions are the transaction headers
+	EntityTransactions Entity = "transactions"
+)
+
+// Store is the local copy of Arpa. The upserts must be idempotent since the records modified
+// around a watermark are fetched again on the next run.
+type Store interface {
+	// Watermark returns the watermark of the entity, the zero time if it has never been synced
+	Watermark(ctx context.Context, entity Entity) (time.Time, error)
+	// SetWatermark saves the watermark of the entity
+	SetWatermark(ctx context.Context, entity Entity, watermark time.Time) error
+
+	UpsertCustomer(ctx context.Context, customer goarpa.Customer) error
+	UpsertItem(ctx context.Context, item goarpa.GetServiceResponse) error
+	UpsertTransaction(ctx context.Context, transaction goarpa.Transaction) error
+
+	// Deactivate marks a record as deactivated in Arpa
+	Deactivate(ctx context.Context, entity Entity, id int64) error
+}
+
+// Hooks are called for every synced record, after the store has been updated.
+// An error returned by a hook stops the sync of the entity.
+type Hooks struct {
+	Customer    func(ctx context.Context, customer goarpa.Customer, deactivated bool) error
+	Item        func(ctx context.Context, item goarpa.GetServiceResponse, deactivated bool) error
+	Transaction func(ctx context.Context, transaction goarpa.Transaction) error
+}
+
+// Options configure a Syncer
+type Options struct {
+	// Entities are the entities to sync, all of them by default
+	Entities []Entity
+	// Overlap is subtracted from the watermark when fetching, to catch the records committed late
+	// or modified within the same second. One minute by default.
+	Overlap time.Duration
+	// PageSize is the page size of the list requests
+	PageSize int
+	Hooks    Hooks
+}
+
+// Result is the outcome of the sync of an entity
+type Result struct {
+	Entity      Entity
+	Upserted    int
+	Deactivated int
+	Watermark   time.Time
+}
+
+// Syncer mirrors Arpa into a Store
+type Syncer struct {
+	client  *goarpa.GoArpa
+	store   Store
+	token   goarpa.TokenSource
+	options Options
+}
+
+// New returns a syncer mirroring Arpa into the store
+func New(client *goarpa.GoArpa, store Store, token goarpa.TokenSource, options Options) *Syncer {
+	if len(options.Entities) == 0 {
+		options.Entities = []Entity{EntityCustomers, EntityItems, EntityTransactions}
+	}
+	if options.Overlap <= 0 {
+		options.Overlap = time.Minute
+	}
+	return &Syncer{client: client, store: store, token: token, options: options}
+}
+
+// Sync mirrors the records modified since the last run of every configured entity
+func (s *Syncer) Sync(ctx context.Context) ([]Result, error) {
+	results := make([]Result, 0, len(s.options.Entities))
+	for _, entity := range s.options.Entities {
+		result, err := s.SyncEntity(ctx, entity)
+		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// SyncEntity mirrors the records of the entity modified since its watermark.
+// The watermark is only moved forward once all the records have been stored.
+func (s *Syncer) SyncEntity(ctx context.Context, entity Entity) (Result, error) {
+	result := Result{Entity: entity}
+
+	watermark, err := s.store.Watermark(ctx, entity)
+	if err != nil {
+		return result, err
+	}
+	result.Watermark = watermark
+
+	token, err := s.token(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	var since *time.Time
+	if !watermark.IsZero() {
+		since = goarpa.ToPtr(watermark.Add(-s.options.Overlap))
+	}
+	paging := goarpa.ListParams{PageSize: s.options.PageSize}
+
+	switch entity {
+	case EntityCustomers:
+		// the deactivated customers are listed whatever the filter of the client, to be deactivated in the store
+		params := goarpa.GetCustomersParams{ListParams: paging, ModifiedSince: since, Inactive: goarpa.IncludeInactive}
+		err = s.syncCustomers(ctx, token, params, &result)
+	case EntityItems:
+		err = s.syncItems(ctx, token, goarpa.GetItemsParams{ListParams: paging, ModifiedSince: since}, &result)
+	case EntityTransactions:
+		err = s.syncTransactions(ctx, token, goarpa.GetTransactionsParams{ListParams: paging, ModifiedSince: since}, &result)
+	default:
+		return result, goarpa.ErrNotSupported
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if result.Watermark.After(watermark) {
+		if err := s.store.SetWatermark(ctx, entity, result.Watermark); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (s *Syncer) syncCustomers(ctx context.Context, token string, params goarpa.GetCustomersParams, result *Result) error {
+	for customer, err := range s.client.IterateCustomers(ctx, token, nil, params) {
+		if err != nil {
+			return err
+		}
+		if err := s.store.UpsertCustomer(ctx, customer); err != nil {
+			return err
+		}
+		result.Upserted++
+		if customer.Inactive {
+			if err := s.store.Deactivate(ctx, EntityCustomers, customer.ID.Int64()); err != nil {
+				return err
+			}
+			result.Deactivated++
+		}
+		if s.options.Hooks.Customer != nil {
+			if err := s.options.Hooks.Customer(ctx, customer, customer.Inactive); err != nil {
+				return err
+			}
+		}
+		result.advance(customer.ModifiedAt)
+	}
+	return nil
+}
+
+func (s *Syncer) syncItems(ctx context.Context, token string, params goarpa.GetItemsParams, result *Result) error {
+	for item, err := range s.client.IterateItems(ctx, token, nil, params) {
+		if err != nil {
+			return err
+		}
+		if err := s.store.UpsertItem(ctx, item); err != nil {
+			return err
+		}
+		result.Upserted++
+		deactivated := !item.Active()
+		if deactivated {
+			if err := s.store.Deactivate(ctx, EntityItems, item.ItemID.Int64()); err != nil {
+				return err
+			}
+			result.Deactivated++
+		}
+		if s.options.Hooks.Item != nil {
+			if err := s.options.Hooks.Item(ctx, item, deactivated); err != nil {
+				return err
+			}
+		}
+		if item.ModificationDate != nil {
+			result.advance(item.ModificationDate.Time)
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) syncTransactions(ctx context.Context, token string, params goarpa.GetTransactionsParams, result *Result) error {
+	for transaction, err := range s.client.IterateTransactions(ctx, token, nil, params) {
+		if err != nil {
+			return err
+		}
+		if err := s.store.UpsertTransaction(ctx, transaction); err != nil {
+			return err
+		}
+		result.Upserted++
+		if s.options.Hooks.Transaction != nil {
+			if err := s.options.Hooks.Transaction(ctx, transaction); err != nil {
+				return err
+			}
+		}
+		if transaction.ModificationDate != nil {
+			result.advance(transaction.ModificationDate.Time)
+		}
+	}
+	return nil
+}
+
+// advance moves the watermark to the modification date if it is later
+func (r *Result) advance(modifiedAt time.Time) {
+	if modifiedAt.After(r.Watermark) {
+		r.Watermark = modifiedAt
+	}
+}
@@ -0,0 +1,128 @@
+package arpasync_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/arpasync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	watermarks  map[arpasync.Entity]time.Time
+	customers   map[goarpa.BusinessID]goarpa.Customer
+	deactivated map[int64]bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		watermarks:  make(map[arpasync.Entity]time.Time),
+		customers:   make(map[goarpa.BusinessID]goarpa.Customer),
+		deactivated: make(map[int64]bool),
+	}
+}
+
+func (m *memoryStore) Watermark(_ context.Context, entity arpasync.Entity) (time.Time, error) {
+	return m.watermarks[entity], nil
+}
+
+func (m *memoryStore) SetWatermark(_ context.Context, entity arpasync.Entity, watermark time.Time) error {
+	m.watermarks[entity] = watermark
+	return nil
+}
+
+func (m *memoryStore) UpsertCustomer(_ context.Context, customer goarpa.Customer) error {
+	m.customers[customer.ID] = customer
+	return nil
+}
+
+func (m *memoryStore) UpsertItem(context.Context, goarpa.GetServiceResponse) error {
+	return nil
+}
+
+func (m *memoryStore) UpsertTransaction(context.Context, goarpa.Transaction) error {
+	return nil
+}
+
+func (m *memoryStore) Deactivate(_ context.Context, _ arpasync.Entity, id int64) error {
+	m.deactivated[id] = true
+	return nil
+}
+
+func Test_SyncCustomers(t *testing.T) {
+	t.Parallel()
+	var modifiedSince []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		modifiedSince = append(modifiedSince, r.URL.Query().Get("ModifiedSince"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[
+			{"BusinessID":"1","Modification_Date":"2024-03-01T10:00:00"},
+			{"BusinessID":"2","InActive":"1","Modification_Date":"2024-03-02T10:00:00"}
+		],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetCustomersEndpoint = "customers"
+	store := newMemoryStore()
+
+	var hooked []goarpa.BusinessID
+	syncer := arpasync.New(client, store, func(context.Context) (string, error) {
+		return "token", nil
+	}, arpasync.Options{
+		Entities: []arpasync.Entity{arpasync.EntityCustomers},
+		Hooks: arpasync.Hooks{
+			Customer: func(_ context.Context, customer goarpa.Customer, _ bool) error {
+				hooked = append(hooked, customer.ID)
+				return nil
+			},
+		},
+	})
+
+	results, err := syncer.Sync(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Upserted)
+	assert.Equal(t, 1, results[0].Deactivated)
+	assert.Len(t, store.customers, 2)
+	assert.True(t, store.deactivated[2])
+	assert.Equal(t, []goarpa.BusinessID{1, 2}, hooked)
+
+	watermark := store.watermarks[arpasync.EntityCustomers]
+	assert.Equal(t, 2, watermark.Day())
+
+	// the next run only asks for the records modified since the watermark
+	_, err = syncer.Sync(context.Background())
+	require.NoError(t, err)
+	require.Len(t, modifiedSince, 2)
+	assert.Empty(t, modifiedSince[0])
+	assert.NotEmpty(t, modifiedSince[1])
+}
+
+func Test_SyncCustomersExcludingInactive(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"BusinessID":"2","InActive":"1","Modification_Date":"2024-03-02T10:00:00"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	// the filter of the client does not hide the deactivations from the mirror
+	client := goarpa.NewClient(server.URL, goarpa.WithInactiveFilter(goarpa.ExcludeInactive))
+	client.Config.GetCustomersEndpoint = "customers"
+	store := newMemoryStore()
+	syncer := arpasync.New(client, store, func(context.Context) (string, error) {
+		return "token", nil
+	}, arpasync.Options{Entities: []arpasync.Entity{arpasync.EntityCustomers}})
+
+	results, err := syncer.Sync(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 1, results[0].Deactivated)
+	assert.True(t, store.deactivated[2])
+}
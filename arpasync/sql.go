@@ -0,0 +1,110 @@
+package arpasync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+)
+
+// SQLStore is the reference Store, keeping every record as JSON in a table per entity.
+// The queries use $N placeholders and ON CONFLICT, which are understood by both PostgreSQL and SQLite.
+type SQLStore struct {
+	db     *sql.DB
+	prefix string
+}
+
+// NewSQLStore returns a store using the tables named after the prefix, which are created if they do not exist
+func NewSQLStore(ctx context.Context, db *sql.DB, prefix string) (*SQLStore, error) {
+	store := &SQLStore{db: db, prefix: prefix}
+
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %swatermarks (
+	entity TEXT PRIMARY KEY,
+	watermark BIGINT NOT NULL
+)`, prefix),
+	}
+	for _, entity := range []Entity{EntityCustomers, EntityItems, EntityTransactions} {
+		statements = append(statements, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id BIGINT PRIMARY KEY,
+	data TEXT NOT NULL,
+	inactive BOOLEAN NOT NULL DEFAULT FALSE,
+	modified_at BIGINT NOT NULL
+)`, store.table(entity)))
+	}
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// Watermark returns the watermark of the entity, the zero time if it has never been synced
+func (s *SQLStore) Watermark(ctx context.Context, entity Entity) (time.Time, error) {
+	var watermark int64
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT watermark FROM %swatermarks WHERE entity = $1`, s.prefix), string(entity)).
+		Scan(&watermark)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, watermark), nil
+}
+
+// SetWatermark saves the watermark of the entity
+func (s *SQLStore) SetWatermark(ctx context.Context, entity Entity, watermark time.Time) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %swatermarks (entity, watermark) VALUES ($1, $2)
+ON CONFLICT (entity) DO UPDATE SET watermark = excluded.watermark`, s.prefix), string(entity), watermark.UnixNano())
+	return err
+}
+
+// UpsertCustomer inserts or updates the customer
+func (s *SQLStore) UpsertCustomer(ctx context.Context, customer goarpa.Customer) error {
+	return s.upsert(ctx, EntityCustomers, customer.ID.Int64(), customer, customer.Inactive, customer.ModifiedAt)
+}
+
+// UpsertItem inserts or updates the item
+func (s *SQLStore) UpsertItem(ctx context.Context, item goarpa.GetServiceResponse) error {
+	var modifiedAt time.Time
+	if item.ModificationDate != nil {
+		modifiedAt = item.ModificationDate.Time
+	}
+	return s.upsert(ctx, EntityItems, item.ItemID.Int64(), item, !item.Active(), modifiedAt)
+}
+
+// UpsertTransaction inserts or updates the transaction
+func (s *SQLStore) UpsertTransaction(ctx context.Context, transaction goarpa.Transaction) error {
+	var modifiedAt time.Time
+	if transaction.ModificationDate != nil {
+		modifiedAt = transaction.ModificationDate.Time
+	}
+	return s.upsert(ctx, EntityTransactions, transaction.TransactionID.Int64(), transaction, false, modifiedAt)
+}
+
+// Deactivate marks a record as deactivated
+func (s *SQLStore) Deactivate(ctx context.Context, entity Entity, id int64) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET inactive = TRUE WHERE id = $1`, s.table(entity)), id)
+	return err
+}
+
+func (s *SQLStore) upsert(ctx context.Context, entity Entity, id int64, record interface{}, inactive bool, modifiedAt time.Time) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (id, data, inactive, modified_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (id) DO UPDATE SET data = excluded.data, inactive = excluded.inactive, modified_at = excluded.modified_at`,
+		s.table(entity)), id, string(data), inactive, modifiedAt.UnixNano())
+	return err
+}
+
+func (s *SQLStore) table(entity Entity) string {
+	return s.prefix + string(entity)
+}
@@ -0,0 +1,133 @@
+package arpasync_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/arpasync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newSQLStore(t *testing.T) (*arpasync.SQLStore, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "arpasync.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	store, err := arpasync.NewSQLStore(context.Background(), db, "arpa_")
+	require.NoError(t, err)
+	return store, db
+}
+
+func Test_SQLStoreWatermark(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, _ := newSQLStore(t)
+
+	watermark, err := store.Watermark(ctx, arpasync.EntityCustomers)
+	require.NoError(t, err)
+	assert.True(t, watermark.IsZero())
+
+	expected := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SetWatermark(ctx, arpasync.EntityCustomers, expected))
+	require.NoError(t, store.SetWatermark(ctx, arpasync.EntityCustomers, expected.Add(time.Hour)))
+	watermark, err = store.Watermark(ctx, arpasync.EntityCustomers)
+	require.NoError(t, err)
+	assert.True(t, expected.Add(time.Hour).Equal(watermark))
+
+	// the watermarks are kept by entity
+	watermark, err = store.Watermark(ctx, arpasync.EntityItems)
+	require.NoError(t, err)
+	assert.True(t, watermark.IsZero())
+}
+
+func Test_SQLStoreSync(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[
+			{"BusinessID":"1","BusinessName":"Ali","Modification_Date":"2024-03-01T10:00:00"},
+			{"BusinessID":"2","InActive":"1","Modification_Date":"2024-03-02T10:00:00"}
+		],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetCustomersEndpoint = "customers"
+	store, db := newSQLStore(t)
+	syncer := arpasync.New(client, store, func(context.Context) (string, error) {
+		return "token", nil
+	}, arpasync.Options{Entities: []arpasync.Entity{arpasync.EntityCustomers}})
+
+	type row struct {
+		id         int64
+		inactive   bool
+		modifiedAt int64
+	}
+	rows := func() []row {
+		result, err := db.Query(`SELECT id, inactive, modified_at FROM arpa_customers ORDER BY id`)
+		require.NoError(t, err)
+		defer result.Close()
+		var rows []row
+		for result.Next() {
+			var r row
+			require.NoError(t, result.Scan(&r.id, &r.inactive, &r.modifiedAt))
+			rows = append(rows, r)
+		}
+		require.NoError(t, result.Err())
+		return rows
+	}
+
+	_, err := syncer.Sync(context.Background())
+	require.NoError(t, err)
+	first := rows()
+	require.Len(t, first, 2)
+	assert.False(t, first[0].inactive)
+	assert.True(t, first[1].inactive)
+
+	var data string
+	require.NoError(t, db.QueryRow(`SELECT data FROM arpa_customers WHERE id = 1`).Scan(&data))
+	assert.Contains(t, data, "Ali")
+
+	watermark, err := store.Watermark(context.Background(), arpasync.EntityCustomers)
+	require.NoError(t, err)
+	assert.Equal(t, 2, watermark.Day())
+
+	// the records within the overlap window are fetched again and upserted idempotently
+	_, err = syncer.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, first, rows())
+	again, err := store.Watermark(context.Background(), arpasync.EntityCustomers)
+	require.NoError(t, err)
+	assert.True(t, watermark.Equal(again))
+}
+
+func Test_SQLStoreDeactivate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, db := newSQLStore(t)
+
+	item := goarpa.GetServiceResponse{ItemID: 5}
+	require.NoError(t, store.UpsertItem(ctx, item))
+	require.NoError(t, store.UpsertTransaction(ctx, goarpa.Transaction{TransactionID: 5}))
+	require.NoError(t, store.Deactivate(ctx, arpasync.EntityTransactions, 5))
+
+	inactive := func(table string) bool {
+		var inactive bool
+		require.NoError(t, db.QueryRow(`SELECT inactive FROM `+table+` WHERE id = 5`).Scan(&inactive))
+		return inactive
+	}
+	assert.True(t, inactive("arpa_transactions"))
+	// the same ID of another entity is not deactivated
+	assert.Equal(t, !item.Active(), inactive("arpa_items"))
+
+	// deactivating an unknown record is not an error
+	require.NoError(t, store.Deactivate(ctx, arpasync.EntityCustomers, 42))
+}
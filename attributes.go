@@ -0,0 +1,103 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/erfandiakoo/goarpa/v2/shared/constant"
+	"github.com/pkg/errors"
+)
+
+// CustomerAttributes are the extended attributes of a business by name, e.g. the segments of a CRM
+type CustomerAttributes map[string]string
+
+// CustomerAttribute is an extended attribute of a business as Arpa sends it
+type CustomerAttribute struct {
+	Name  string `json:"AttributeName"`
+	Value string `json:"AttributeValue"`
+}
+
+// SetCustomerAttributesRequest writes extended attributes of a business
+type SetCustomerAttributesRequest struct {
+	BusinessID BusinessID          `json:"BusinessID"`
+	Attributes []CustomerAttribute `json:"Attributes"`
+}
+
+// attributeList returns the attributes sorted by name, so that the requests are deterministic
+func (a CustomerAttributes) attributeList() []CustomerAttribute {
+	list := make([]CustomerAttribute, 0, len(a))
+	for name, value := range a {
+		list = append(list, CustomerAttribute{Name: name, Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// GetCustomerAttributes returns the extended attributes of the business
+func (g *GoArpa) GetCustomerAttributes(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID) (CustomerAttributes, error) {
+	const errMessage = "could not get customer attributes"
+
+	url, err := g.endpointURL(g.config().GetCustomerAttributesEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[CustomerAttribute]
+
+	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetQueryParam(string(constant.BusinessIDQueryKey), businessID.String()).
+		SetResult(&result).
+		Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	attributes := make(CustomerAttributes, len(result.Data))
+	for _, attribute := range result.Data {
+		attributes[attribute.Name] = attribute.Value
+	}
+	return attributes, nil
+}
+
+// SetCustomerAttributes writes the extended attributes of the business, its other attributes are kept
+func (g *GoArpa) SetCustomerAttributes(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, attributes CustomerAttributes) error {
+	const errMessage = "could not set customer attributes"
+
+	url, err := g.endpointURL(g.config().SetCustomerAttributesEndpoint)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+
+	body, err := marshalBody(SetCustomerAttributesRequest{BusinessID: businessID, Attributes: attributes.attributeList()})
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+
+	var response APIResponse[CustomerAttribute]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody(body).
+		SetResult(&response)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "SetCustomerAttributes", url, body, resp, err, response.Error, func() map[string]string {
+		return map[string]string{"BusinessID": businessID.String()}
+	})
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return err
+	}
+
+	return g.checkForArpaError(resp, response.Error, errMessage)
+}
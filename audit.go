@@ -0,0 +1,229 @@
+package goarpa
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var auditActorContextKey = &contextKey{"auditActor"}
+
+// AuditRecord is the record of a mutating call sent to Arpa
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Actor is who made the call, see WithAuditActor
+	Actor string `json:"actor,omitempty"`
+	// Operation is the name of the client method, e.g. CreateTransaction
+	Operation string `json:"operation"`
+	Method    string `json:"method"`
+	Endpoint  string `json:"endpoint"`
+	// RequestHash is the hex encoded SHA-256 of the JSON request body
+	RequestHash string `json:"requestHash"`
+	// Body is the JSON request body, it is only recorded with WithAuditBodies
+	Body       json.RawMessage `json:"body,omitempty"`
+	StatusCode int             `json:"statusCode,omitempty"`
+	// ResponseIDs are the identifiers returned by Arpa, e.g. {"TransactionID": "42"}
+	ResponseIDs map[string]string `json:"responseIds,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// AuditSink receives a record for every mutating call, whether it succeeded or not
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord) error
+}
+
+// WithAuditSink records every Create/Update call in the sink.
+// The errors of the sink are passed to onError, which may be nil; they never fail the call.
+func WithAuditSink(sink AuditSink, onError func(error)) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.auditSink = sink
+		g.auditErrorHandler = onError
+	}
+}
+
+// WithAuditBodies records the request bodies in the audit records, so that the calls can be replayed,
+// see ReadAuditLog. The bodies contain the personal data of the customers. The SQLAuditSink does not store them.
+func WithAuditBodies() func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.auditBodies = true
+	}
+}
+
+// WithAuditActor returns a context whose mutating calls are audited as made by the actor,
+// e.g. the user or the service on whose behalf Arpa is called.
+// Without an actor, the "sub" claim of the access token is used when it is a JWT.
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorContextKey, actor)
+}
+
+// audit records a mutating call if the client has an audit sink.
+// The body is hashed as is when it is already encoded, see marshalBody. The ids, which may be nil,
+// are only computed when there is a sink, so that the calls without audit do not pay for them.
+func (g *GoArpa) audit(ctx context.Context, accessToken string, operation string, url string, body interface{}, resp *resty.Response, err error, arpaErr *ArpaError, ids func() map[string]string) {
+	if g.auditSink == nil {
+		return
+	}
+
+	record := AuditRecord{
+		Time:        g.Clock().Now(),
+		Operation:   operation,
+		Method:      "POST",
+		Endpoint:    strings.TrimPrefix(url, g.basePath),
+		RequestHash: hashRequest(body),
+		ResponseIDs: make(map[string]string),
+	}
+	if b := encodeBody(body); g.auditBodies && json.Valid(b) {
+		record.Body = b
+	}
+	record.Actor, _ = ctx.Value(auditActorContextKey).(string)
+	if record.Actor == "" {
+		record.Actor = tokenSubject(accessToken)
+	}
+	if resp != nil && resp.Request != nil {
+		record.Method = resp.Request.Method
+		record.StatusCode = resp.StatusCode()
+	}
+	switch {
+	case err != nil:
+		record.Error = err.Error()
+	case resp != nil && resp.IsError():
+		record.Error = resp.Status()
+	case arpaErr.NotEmpty():
+		record.Error = arpaErr.Error()
+	case ids != nil:
+		for key, value := range ids() {
+			if value != "" && value != "0" {
+				record.ResponseIDs[key] = value
+			}
+		}
+	}
+
+	if err := g.auditSink.Record(context.WithoutCancel(ctx), record); err != nil && g.auditErrorHandler != nil {
+		g.auditErrorHandler(fmt.Errorf("could not record audit: %w", err))
+	}
+}
+
+func hashRequest(body interface{}) string {
+	sum := sha256.Sum256(encodeBody(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeBody returns the body as is when it is already encoded, see marshalBody
+func encodeBody(body interface{}) []byte {
+	b, ok := body.([]byte)
+	if !ok {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			b = []byte(err.Error())
+		}
+	}
+	return b
+}
+
+// tokenSubject returns the "sub" claim of the token when it is a JWT
+func tokenSubject(token string) string {
+	claims, _ := parseJWTClaims(token)
+	return claims.Sub
+}
+
+// FileAuditSink appends the records to a file as JSON lines
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditSink opens the file for appending, creating it if needed
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditSink{file: file}, nil
+}
+
+// Record appends the record and syncs the file
+func (s *FileAuditSink) Record(_ context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := bufio.NewWriter(s.file)
+	_, _ = w.Write(line)
+	_ = w.WriteByte('\n')
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the file
+func (s *FileAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// SQLAuditSink inserts the records in a SQL table through database/sql, see SQLOutboxStore
+type SQLAuditSink struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLAuditSink returns a sink using the given table, which is created if it does not exist
+func NewSQLAuditSink(ctx context.Context, db *sql.DB, table string) (*SQLAuditSink, error) {
+	sink := &SQLAuditSink{db: db, table: table}
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	recorded_at BIGINT NOT NULL,
+	actor TEXT NOT NULL,
+	operation TEXT NOT NULL,
+	method TEXT NOT NULL,
+	endpoint TEXT NOT NULL,
+	request_hash TEXT NOT NULL,
+	status_code INTEGER NOT NULL,
+	response_ids TEXT NOT NULL,
+	error TEXT NOT NULL
+)`, table))
+	if err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// Record inserts the record
+func (s *SQLAuditSink) Record(ctx context.Context, record AuditRecord) error {
+	ids, err := json.Marshal(record.ResponseIDs)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (recorded_at, actor, operation, method, endpoint,
+	request_hash, status_code, response_ids, error) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`, s.table),
+		record.Time.UnixNano(), record.Actor, record.Operation, record.Method, record.Endpoint,
+		record.RequestHash, record.StatusCode, string(ids), record.Error)
+	return err
+}
+
+// transactionAuditIDs returns the identifiers of the created transaction
+func transactionAuditIDs(response *CreateTransactionResponse) map[string]string {
+	datum, ok := response.First()
+	if !ok {
+		return nil
+	}
+	return map[string]string{
+		"TransactionID": datum.TransactionID.String(),
+		"TransNumber":   strconv.FormatInt(datum.TransNumber, 10),
+	}
+}
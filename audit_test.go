@@ -0,0 +1,151 @@
+package goarpa_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryAuditSink struct {
+	mu      sync.Mutex
+	records []goarpa.AuditRecord
+}
+
+func (s *memoryAuditSink) Record(_ context.Context, record goarpa.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+func Test_AuditSink(t *testing.T) {
+	t.Parallel()
+	var fail atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if fail.Load() {
+			_, _ = w.Write([]byte(`{"data":[],"error":"business not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+	}))
+	defer server.Close()
+
+	sink := &memoryAuditSink{}
+	client := goarpa.NewClient(server.URL, goarpa.WithAuditSink(sink, nil))
+	ctx := goarpa.WithAuditActor(context.Background(), "cashier-1")
+	transaction := goarpa.CreateTransactionRequest{Data: goarpa.Data{BusinessID: 7, TransStateID: goarpa.TransStateDraft, FactorTypeID: goarpa.FactorTypeSale}}
+
+	_, err := client.CreateTransaction(ctx, "token", transaction)
+	require.NoError(t, err)
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.NoError(t, err)
+	fail.Store(true)
+	_, err = client.CreateTransaction(ctx, "token", transaction)
+	require.Error(t, err)
+
+	// only the mutating calls are audited
+	require.Len(t, sink.records, 2)
+	record := sink.records[0]
+	assert.Equal(t, "cashier-1", record.Actor)
+	assert.Equal(t, "CreateTransaction", record.Operation)
+	assert.Equal(t, http.MethodPost, record.Method)
+	assert.Equal(t, "/serv/api/NewTransaction", record.Endpoint)
+	assert.Len(t, record.RequestHash, 64)
+	assert.Equal(t, map[string]string{"TransactionID": "42", "TransNumber": "9"}, record.ResponseIDs)
+	assert.Empty(t, record.Error)
+
+	assert.Equal(t, record.RequestHash, sink.records[1].RequestHash)
+	assert.Equal(t, "business not found", sink.records[1].Error)
+	assert.Empty(t, sink.records[1].ResponseIDs)
+}
+
+func Test_FileAuditSink(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := goarpa.NewFileAuditSink(path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, sink.Record(ctx, goarpa.AuditRecord{Operation: "CreateCustomer"}))
+	require.NoError(t, sink.Record(ctx, goarpa.AuditRecord{Operation: "CreateTransaction"}))
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var operations []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record goarpa.AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		operations = append(operations, record.Operation)
+	}
+	assert.Equal(t, []string{"CreateCustomer", "CreateTransaction"}, operations)
+}
+
+func Test_SQLAuditSink(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	sink, err := goarpa.NewSQLAuditSink(ctx, db, "audit")
+	require.NoError(t, err)
+
+	recorded := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, sink.Record(ctx, goarpa.AuditRecord{
+		Time:        recorded,
+		Actor:       "cashier-1",
+		Operation:   "CreateTransaction",
+		Method:      http.MethodPost,
+		Endpoint:    "/api/transaction",
+		RequestHash: "abc",
+		Body:        json.RawMessage(`{"NationalCode":"0012345678"}`),
+		StatusCode:  http.StatusOK,
+		ResponseIDs: map[string]string{"TransactionID": "42"},
+	}))
+	require.NoError(t, sink.Record(ctx, goarpa.AuditRecord{Time: recorded.Add(time.Second), Operation: "CreateCustomer", Error: "rejected"}))
+
+	rows, err := db.QueryContext(ctx, `SELECT recorded_at, actor, operation, method, endpoint, request_hash, status_code,
+	response_ids, error FROM audit ORDER BY recorded_at`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var records []goarpa.AuditRecord
+	for rows.Next() {
+		var record goarpa.AuditRecord
+		var recordedAt int64
+		var ids string
+		require.NoError(t, rows.Scan(&recordedAt, &record.Actor, &record.Operation, &record.Method, &record.Endpoint,
+			&record.RequestHash, &record.StatusCode, &ids, &record.Error))
+		record.Time = time.Unix(0, recordedAt).UTC()
+		require.NoError(t, json.Unmarshal([]byte(ids), &record.ResponseIDs))
+		records = append(records, record)
+	}
+	require.NoError(t, rows.Err())
+
+	// the bodies are not stored
+	assert.Equal(t, []goarpa.AuditRecord{
+		{
+			Time:        recorded,
+			Actor:       "cashier-1",
+			Operation:   "CreateTransaction",
+			Method:      http.MethodPost,
+			Endpoint:    "/api/transaction",
+			RequestHash: "abc",
+			StatusCode:  http.StatusOK,
+			ResponseIDs: map[string]string{"TransactionID": "42"},
+		},
+		{Time: recorded.Add(time.Second), Operation: "CreateCustomer", Error: "rejected"},
+	}, records)
+}
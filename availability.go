@@ -0,0 +1,186 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ItemStockParams select the item and the warehouse of the stock endpoints
+type ItemStockParams struct {
+	ItemID ItemID `json:"ItemID"`
+	// WarehouseID restricts the stock to a warehouse, all the warehouses by default
+	WarehouseID *int64 `json:"StockID,omitempty"`
+}
+
+// WarehouseStock is the quantity of an item in a warehouse
+type WarehouseStock struct {
+	WarehouseID   StringInt64    `json:"StockID"`
+	WarehouseName EnforcedString `json:"StockName"`
+	Qty           EnforcedFloat  `json:"Qty"`
+}
+
+// ItemStockResponse is the response of the stock endpoints
+type ItemStockResponse = APIResponse[WarehouseStock]
+
+// ItemAvailability is the stock of an item by warehouse
+type ItemAvailability struct {
+	ItemID ItemID `json:"ItemID"`
+	// Warehouses are ordered by ID
+	Warehouses []WarehouseStock `json:"Warehouses"`
+}
+
+// Total returns the quantity of the item in all the warehouses
+func (a ItemAvailability) Total() float64 {
+	var total float64
+	for _, stock := range a.Warehouses {
+		total += float64(stock.Qty)
+	}
+	return total
+}
+
+// Qty returns the quantity of the item in the warehouse and false if the warehouse is unknown
+func (a ItemAvailability) Qty(warehouseID int64) (float64, bool) {
+	for _, stock := range a.Warehouses {
+		if stock.WarehouseID.Int64() == warehouseID {
+			return float64(stock.Qty), true
+		}
+	}
+	return 0, false
+}
+
+// ItemAvailabilityOptions configure GetItemAvailability
+type ItemAvailabilityOptions struct {
+	// Warehouses restricts the availability to the warehouses, all the warehouses by default
+	Warehouses []int64
+	// Concurrency is the number of warehouses queried at the same time when the stock is fetched
+	// warehouse by warehouse, 4 by default
+	Concurrency int
+}
+
+// GetItemStock returns the quantity of the item in the warehouse.
+// It returns ErrNotSupported when the installation has no stock endpoint.
+func (g *GoArpa) GetItemStock(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, warehouseID int64) (*WarehouseStock, error) {
+	const errMessage = "could not get item stock"
+
+	var response ItemStockResponse
+	params := ItemStockParams{ItemID: itemID, WarehouseID: &warehouseID}
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetItemStockEndpoint, params, &response, errMessage); err != nil {
+		return nil, err
+	}
+	stock, ok := response.First()
+	if !ok {
+		return &WarehouseStock{WarehouseID: StringInt64(warehouseID)}, nil
+	}
+	return &stock, nil
+}
+
+// GetItemAvailability returns the stock of the item in every warehouse, e.g. for an availability API.
+// It calls the availability endpoint of Arpa once when GetItemAvailabilityEndpoint is configured, otherwise
+// it fetches the stock of the warehouses concurrently with GetItemStock, the warehouses being listed with
+// GetWarehouses when the options have none.
+func (g *GoArpa) GetItemAvailability(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, options ItemAvailabilityOptions) (*ItemAvailability, error) {
+	const errMessage = "could not get item availability"
+
+	if g.config().GetItemAvailabilityEndpoint == "" {
+		return g.itemAvailabilityByWarehouse(ctx, accessToken, cookie, itemID, options)
+	}
+
+	var response ItemStockResponse
+	params := ItemStockParams{ItemID: itemID}
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetItemAvailabilityEndpoint, params, &response, errMessage); err != nil {
+		return nil, err
+	}
+	return newItemAvailability(itemID, response.Data, options.Warehouses), nil
+}
+
+// itemAvailabilityByWarehouse fetches the stock of the item in the warehouses one request per warehouse
+func (g *GoArpa) itemAvailabilityByWarehouse(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, options ItemAvailabilityOptions) (*ItemAvailability, error) {
+	const errMessage = "could not get item availability"
+
+	if _, err := g.endpointURL(g.config().GetItemStockEndpoint); err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	warehouseIDs := options.Warehouses
+	names := make(map[int64]string)
+	if len(warehouseIDs) == 0 {
+		warehouses, err := g.GetWarehouses(ctx, accessToken, cookie)
+		if err != nil {
+			return nil, errors.Wrap(err, errMessage)
+		}
+		for _, warehouse := range warehouses.Data {
+			warehouseIDs = append(warehouseIDs, warehouse.ID.Int64())
+			names[warehouse.ID.Int64()] = warehouse.Name
+		}
+	}
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 4
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stocks := make([]WarehouseStock, len(warehouseIDs))
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	semaphore := make(chan struct{}, concurrency)
+	for i, warehouseID := range warehouseIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				return
+			}
+			stock, err := g.GetItemStock(ctx, accessToken, cookie, itemID, warehouseID)
+			if err != nil {
+				once.Do(func() {
+					firstErr = errors.Wrapf(err, "%s: warehouse %d", errMessage, warehouseID)
+					cancel()
+				})
+				return
+			}
+			stock.WarehouseID = StringInt64(warehouseID)
+			if stock.WarehouseName == "" {
+				stock.WarehouseName = EnforcedString(names[warehouseID])
+			}
+			stocks[i] = *stock
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+	return newItemAvailability(itemID, stocks, nil), nil
+}
+
+// newItemAvailability returns the availability of the stocks in the warehouses, all of them when warehouses is empty
+func newItemAvailability(itemID ItemID, stocks []WarehouseStock, warehouses []int64) *ItemAvailability {
+	keep := make(map[int64]bool, len(warehouses))
+	for _, warehouseID := range warehouses {
+		keep[warehouseID] = true
+	}
+	availability := &ItemAvailability{ItemID: itemID, Warehouses: make([]WarehouseStock, 0, len(stocks))}
+	for _, stock := range stocks {
+		if len(keep) == 0 || keep[stock.WarehouseID.Int64()] {
+			availability.Warehouses = append(availability.Warehouses, stock)
+		}
+	}
+	sort.SliceStable(availability.Warehouses, func(i, j int) bool {
+		return availability.Warehouses[i].WarehouseID < availability.Warehouses[j].WarehouseID
+	})
+	return availability
+}
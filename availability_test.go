@@ -0,0 +1,67 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetItemAvailability(t *testing.T) {
+	t.Parallel()
+	var stockCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/warehouses":
+			_, _ = w.Write([]byte(`{"data":[{"StockID":"2","StockName":"Tabriz"},{"StockID":"1","StockName":"Tehran"}],"error":null}`))
+		case "/stock":
+			stockCalls.Add(1)
+			assert.Equal(t, "7", r.URL.Query().Get("ItemID"))
+			if r.URL.Query().Get("StockID") == "1" {
+				_, _ = w.Write([]byte(`{"data":[{"StockID":"1","Qty":"5"}],"error":null}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+		case "/availability":
+			assert.Equal(t, "7", r.URL.Query().Get("ItemID"))
+			assert.Empty(t, r.URL.Query().Get("StockID"))
+			_, _ = w.Write([]byte(`{"data":[{"StockID":"2","StockName":"Tabriz","Qty":3},{"StockID":"1","StockName":"Tehran","Qty":"5"}],"error":null}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	ctx := context.Background()
+	_, err := client.GetItemAvailability(ctx, "token", nil, 7, goarpa.ItemAvailabilityOptions{})
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+
+	// warehouse by warehouse
+	client.Config.GetWarehousesEndpoint = "warehouses"
+	client.Config.GetItemStockEndpoint = "stock"
+	availability, err := client.GetItemAvailability(ctx, "token", nil, 7, goarpa.ItemAvailabilityOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), stockCalls.Load())
+	require.Len(t, availability.Warehouses, 2)
+	assert.Equal(t, goarpa.StringInt64(1), availability.Warehouses[0].WarehouseID)
+	assert.Equal(t, goarpa.EnforcedString("Tehran"), availability.Warehouses[0].WarehouseName)
+	assert.Equal(t, goarpa.EnforcedString("Tabriz"), availability.Warehouses[1].WarehouseName)
+	assert.Equal(t, 5.0, availability.Total())
+	qty, ok := availability.Qty(2)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, qty)
+
+	// in one call
+	client.Config.GetItemAvailabilityEndpoint = "availability"
+	availability, err = client.GetItemAvailability(ctx, "token", nil, 7, goarpa.ItemAvailabilityOptions{Warehouses: []int64{2}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), stockCalls.Load())
+	require.Len(t, availability.Warehouses, 1)
+	assert.Equal(t, 3.0, availability.Total())
+}
@@ -0,0 +1,64 @@
+package goarpa
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RetryBackoff is the wait between the attempts of a retried request: a random duration between zero and Base
+// doubled at every retry, capped at Max. The "full jitter" spreads the retries of the clients recovering from
+// a restart of Arpa, which would otherwise retry at the same time and overload it again.
+type RetryBackoff struct {
+	// Base is the longest wait before the first retry
+	Base time.Duration
+	// Max caps the waits, unlimited when zero
+	Max time.Duration
+}
+
+// DefaultRetryBackoff is the backoff of the clients, see WithRetryBackoff
+var DefaultRetryBackoff = RetryBackoff{Base: 500 * time.Millisecond, Max: 30 * time.Second}
+
+// WithRetryBackoff sets the waits between the retries of the client, DefaultRetryBackoff by default.
+// The retries are enabled by the retry count and the retry conditions of the resty client.
+// The backoff replaces the wait times of the resty client, which are set to zero and Max with the client.
+// Resty keeps the waits within them when they are changed afterwards.
+func WithRetryBackoff(backoff RetryBackoff) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.retryBackoff = &backoff
+	}
+}
+
+// Wait returns a random wait before the retry, 1 for the first retry
+func (b RetryBackoff) Wait(retry int) time.Duration {
+	ceiling := b.Base
+	for i := 1; i < retry && (b.Max == 0 || ceiling < b.Max) && ceiling < time.Duration(1<<62); i++ {
+		ceiling *= 2
+	}
+	if b.Max > 0 && ceiling > b.Max {
+		ceiling = b.Max
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+// backoff returns the retry backoff of the client
+func (g *GoArpa) backoff() RetryBackoff {
+	if g.retryBackoff == nil {
+		return DefaultRetryBackoff
+	}
+	return *g.retryBackoff
+}
+
+// retryAfter is the RetryAfterFunc of the resty client, waiting for the backoff of the client
+func (g *GoArpa) retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	retry := 1
+	if resp != nil && resp.Request != nil {
+		retry = resp.Request.Attempt
+	}
+	// resty falls back to its own backoff on zero
+	return max(g.backoff().Wait(retry), time.Nanosecond), nil
+}
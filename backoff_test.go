@@ -0,0 +1,62 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RetryBackoffWait(t *testing.T) {
+	t.Parallel()
+	backoff := goarpa.RetryBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+	for retry, ceiling := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		80: time.Second,
+	} {
+		waits := make(map[time.Duration]bool)
+		for range 100 {
+			wait := backoff.Wait(retry)
+			assert.GreaterOrEqual(t, wait, time.Duration(0), retry)
+			assert.LessOrEqual(t, wait, ceiling, retry)
+			waits[wait] = true
+		}
+		// the waits are spread rather than fixed
+		assert.Greater(t, len(waits), 10, retry)
+	}
+
+	assert.Equal(t, time.Duration(0), goarpa.RetryBackoff{}.Wait(3))
+	assert.GreaterOrEqual(t, goarpa.RetryBackoff{Base: time.Hour}.Wait(100), time.Duration(0))
+}
+
+func Test_WithRetryBackoff(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithRetryBackoff(goarpa.RetryBackoff{Base: time.Millisecond, Max: 2 * time.Millisecond}))
+	client.RestyClient().
+		SetRetryCount(3).
+		AddRetryCondition(func(r *resty.Response, err error) bool {
+			return r != nil && r.StatusCode() == http.StatusBadGateway
+		})
+
+	_, err := client.GetCustomerByMobile(context.Background(), "token", nil, "09120000000")
+	var apiErr *goarpa.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 4, apiErr.Attempts)
+	// the default waits of resty would be of 100ms at least
+	assert.Less(t, apiErr.RetryWait, 100*time.Millisecond)
+}
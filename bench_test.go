@@ -0,0 +1,100 @@
+package goarpa_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/go-resty/resty/v2"
+)
+
+// cannedTransport answers every request with the same body, so that the benchmarks measure the client only
+type cannedTransport struct {
+	body []byte
+}
+
+func (t cannedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": []string{"application/json; charset=utf-8"}},
+		Body:          io.NopCloser(bytes.NewReader(t.body)),
+		ContentLength: int64(len(t.body)),
+		Request:       req,
+	}, nil
+}
+
+func newBenchmarkClient(body string) *goarpa.GoArpa {
+	client := goarpa.NewClient("http://arpa.local")
+	client.SetRestyClient(resty.New().SetTransport(cannedTransport{body: []byte(body)}))
+	return client
+}
+
+const benchmarkCustomerBody = `{"data":[{"BusinessID":"42","BusinessCode":"1001","BusName":"Ali","Mobile":"09121234567","NationalCode":"0012345678"}],"error":null}`
+
+var benchmarkTransaction = goarpa.CreateTransactionRequest{
+	Data: goarpa.Data{BusinessID: 42, TransStateID: goarpa.TransStateFinal, FactorTypeID: goarpa.FactorTypeSale},
+	Items: []goarpa.TransactionItem{
+		{ItemID: 1, Qty: 2, Price: goarpa.NewMoney(125000)},
+		{ItemID: 2, Qty: 1.5, Price: goarpa.NewMoney(9900), DiscountPercent: 10},
+		{ItemID: 3, Qty: 1, FreeQty: 1, TaxExempt: true},
+	},
+}
+
+func Benchmark_GetRequest(b *testing.B) {
+	client := goarpa.NewClient("http://arpa.local")
+	ctx := context.Background()
+	cookies := []*http.Cookie{{Name: "session", Value: "1"}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = client.GetRequestWithBearerAuthWithCookie(ctx, "token", cookies)
+	}
+}
+
+func Benchmark_GetCustomerByMobile(b *testing.B) {
+	client := newBenchmarkClient(benchmarkCustomerBody)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.GetCustomerByMobile(ctx, "token", nil, "09121234567"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_CreateTransaction(b *testing.B) {
+	client := newBenchmarkClient(`{"data":[{"TransactionID":"42","TransNumber":9}],"error":null}`)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.CreateTransaction(ctx, "token", benchmarkTransaction); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_MarshalTransaction(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(benchmarkTransaction); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_DecodeAPIResponse(b *testing.B) {
+	body := []byte(benchmarkCustomerBody)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := goarpa.DecodeAPIResponse[goarpa.Datum2](body); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Package bridge consumes CreateCustomer and CreateTransaction commands from a message bus,
+// executes them against Arpa and publishes their results.
+//
+// The bus is abstracted by the Consumer and Publisher interfaces so that the package does not depend
+// on a Kafka or NATS client: an adapter is a few lines around the reader or the subscription of the
+// client library in use. The transactions go through the durable submitter of goarpa, so a command is
+// acknowledged as soon as its transaction is persisted and is posted to Arpa exactly once.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+)
+
+// Message is a message of the bus
+type Message struct {
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+// Consumer delivers the messages of the command topic or subject.
+// Consume blocks until the context is done. A message must be acknowledged when the handler
+// returns nil and redelivered otherwise.
+type Consumer interface {
+	Consume(ctx context.Context, handler func(ctx context.Context, message Message) error) error
+}
+
+// Publisher publishes a message to a topic or subject
+type Publisher interface {
+	Publish(ctx context.Context, topic string, message Message) error
+}
+
+// CommandType is the type of a command
+type CommandType string
+
+const (
+	// CommandCreateCustomer creates the business unless it already exists
+	CommandCreateCustomer CommandType = "CreateCustomer"
+	// CommandCreateTransaction creates a transaction through the durable submitter
+	CommandCreateTransaction CommandType = "CreateTransaction"
+)
+
+// Command is a command read from the bus. The ID is the idempotency key of the command.
+type Command struct {
+	ID      string          `json:"id"`
+	Type    CommandType     `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// ResultStatus is the status of a command result
+type ResultStatus string
+
+const (
+	// ResultOK is published when the command has been executed
+	ResultOK ResultStatus = "ok"
+	// ResultQueued is published when a transaction has been persisted and waits to be submitted
+	ResultQueued ResultStatus = "queued"
+	// ResultError is published when the command has failed
+	ResultError ResultStatus = "error"
+	// ResultUnknown is published when the outcome of a transaction is unknown and has to be reconciled
+	ResultUnknown ResultStatus = "unknown"
+)
+
+// Result is published for every command
+type Result struct {
+	ID            string               `json:"id"`
+	Type          CommandType          `json:"type"`
+	Status        ResultStatus         `json:"status"`
+	BusinessID    goarpa.BusinessID    `json:"businessId,omitempty"`
+	BusinessCode  string               `json:"businessCode,omitempty"`
+	TransactionID goarpa.TransactionID `json:"transactionId,omitempty"`
+	TransNumber   int64                `json:"transNumber,omitempty"`
+	Error         string               `json:"error,omitempty"`
+}
+
+// Options configure a Bridge
+type Options struct {
+	// ResultTopic receives the results of the commands
+	ResultTopic string
+	// ErrorTopic receives the failed results, the ResultTopic when empty
+	ErrorTopic string
+	// SubmitInterval is the interval of the submitter, ten seconds by default
+	SubmitInterval time.Duration
+	// Submitter configures the durable submitter. Its OnSubmitted hook is called after the result is published.
+	Submitter goarpa.TransactionSubmitterOptions
+}
+
+// Bridge executes the commands of a Consumer and publishes their results
+type Bridge struct {
+	client    *goarpa.GoArpa
+	token     goarpa.TokenSource
+	consumer  Consumer
+	publisher Publisher
+	submitter *goarpa.TransactionSubmitter
+	options   Options
+}
+
+// New returns a bridge executing the commands with the client, the transactions being persisted in the store
+func New(client *goarpa.GoArpa, token goarpa.TokenSource, store goarpa.OutboxStore, consumer Consumer, publisher Publisher, options Options) *Bridge {
+	if options.ErrorTopic == "" {
+		options.ErrorTopic = options.ResultTopic
+	}
+	if options.SubmitInterval <= 0 {
+		options.SubmitInterval = 10 * time.Second
+	}
+
+	b := &Bridge{
+		client:    client,
+		token:     token,
+		consumer:  consumer,
+		publisher: publisher,
+		options:   options,
+	}
+
+	submitterOptions := options.Submitter
+	onSubmitted := submitterOptions.OnSubmitted
+	submitterOptions.OnSubmitted = func(entry goarpa.OutboxEntry) {
+		b.publishOutcome(entry)
+		if onSubmitted != nil {
+			onSubmitted(entry)
+		}
+	}
+	b.submitter = goarpa.NewTransactionSubmitter(client, store, token, submitterOptions)
+	return b
+}
+
+// Run consumes the commands and submits the transactions until the context is done
+func (b *Bridge) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	submitErr := make(chan error, 1)
+	go func() {
+		submitErr <- b.submitter.Run(ctx, b.options.SubmitInterval)
+	}()
+
+	err := b.consumer.Consume(ctx, b.Handle)
+	cancel()
+	if submitterErr := <-submitErr; err == nil && !errors.Is(submitterErr, context.Canceled) {
+		err = submitterErr
+	}
+	return err
+}
+
+// Handle executes a command message and publishes its result.
+// Malformed and rejected commands are answered with an error result and acknowledged,
+// transient failures are returned so that the message is redelivered.
+func (b *Bridge) Handle(ctx context.Context, message Message) error {
+	var command Command
+	if err := json.Unmarshal(message.Value, &command); err != nil {
+		return b.publish(ctx, Result{ID: message.Key, Status: ResultError, Error: fmt.Sprintf("invalid command: %s", err)})
+	}
+	if command.ID == "" {
+		command.ID = message.Key
+	}
+
+	result, err := b.execute(ctx, command)
+	if err != nil {
+		if isTransient(err) {
+			return err
+		}
+		result = Result{ID: command.ID, Type: command.Type, Status: ResultError, Error: err.Error()}
+	}
+	return b.publish(ctx, result)
+}
+
+func (b *Bridge) execute(ctx context.Context, command Command) (Result, error) {
+	result := Result{ID: command.ID, Type: command.Type}
+
+	switch command.Type {
+	case CommandCreateCustomer:
+		var customer goarpa.CreateCustomerRequest
+		if err := json.Unmarshal(command.Payload, &customer); err != nil {
+			return result, err
+		}
+		token, err := b.token(ctx)
+		if err != nil {
+			return result, err
+		}
+		ensured, err := b.client.EnsureCustomer(ctx, token, nil, customer)
+		if err != nil {
+			return result, err
+		}
+		result.Status = ResultOK
+		result.BusinessID = ensured.BusinessID
+		result.BusinessCode = ensured.BusinessCode
+
+	case CommandCreateTransaction:
+		var transaction goarpa.CreateTransactionRequest
+		if err := json.Unmarshal(command.Payload, &transaction); err != nil {
+			return result, err
+		}
+		entry, err := b.submitter.Enqueue(ctx, command.ID, transaction)
+		if err != nil {
+			return result, &transientError{err}
+		}
+		result = outboxResult(*entry)
+
+	default:
+		return result, fmt.Errorf("unknown command type %q", command.Type)
+	}
+	return result, nil
+}
+
+// publishOutcome publishes the result of a submitted transaction
+func (b *Bridge) publishOutcome(entry goarpa.OutboxEntry) {
+	_ = b.publish(context.Background(), outboxResult(entry))
+}
+
+func (b *Bridge) publish(ctx context.Context, result Result) error {
+	value, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	topic := b.options.ResultTopic
+	if result.Status == ResultError || result.Status == ResultUnknown {
+		topic = b.options.ErrorTopic
+	}
+	return b.publisher.Publish(ctx, topic, Message{Key: result.ID, Value: value})
+}
+
+func outboxResult(entry goarpa.OutboxEntry) Result {
+	result := Result{
+		ID:            entry.ID,
+		Type:          CommandCreateTransaction,
+		TransactionID: entry.TransactionID,
+		TransNumber:   entry.TransNumber,
+		Error:         entry.LastError,
+	}
+	switch entry.Status {
+	case goarpa.OutboxSubmitted:
+		result.Status = ResultOK
+	case goarpa.OutboxFailed:
+		result.Status = ResultError
+	case goarpa.OutboxUnknown:
+		result.Status = ResultUnknown
+	default:
+		result.Status = ResultQueued
+	}
+	return result
+}
+
+// transientError marks an error after which the command has to be redelivered
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// isTransient tells if the command failed because of Arpa or the store being unavailable
+func isTransient(err error) bool {
+	var transient *transientError
+	return errors.As(err, &transient) || goarpa.IsRetryableError(err)
+}
@@ -0,0 +1,66 @@
+package bridge_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/bridge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type channelBus struct {
+	mu        sync.Mutex
+	published map[string][]bridge.Result
+}
+
+func (c *channelBus) Consume(context.Context, func(context.Context, bridge.Message) error) error {
+	return nil
+}
+
+func (c *channelBus) Publish(_ context.Context, topic string, message bridge.Message) error {
+	var result bridge.Result
+	if err := json.Unmarshal(message.Value, &result); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.published[topic] = append(c.published[topic], result)
+	return nil
+}
+
+func Test_BridgeHandle(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Data":[{"TransactionID":"42","TransNumber":7}]}`))
+	}))
+	defer server.Close()
+
+	bus := &channelBus{published: make(map[string][]bridge.Result)}
+	store := goarpa.NewMemoryOutboxStore()
+	b := bridge.New(goarpa.NewClient(server.URL), func(context.Context) (string, error) {
+		return "token", nil
+	}, store, bus, bus, bridge.Options{ResultTopic: "results", ErrorTopic: "errors"})
+
+	ctx := context.Background()
+	require.NoError(t, b.Handle(ctx, bridge.Message{Key: "bad", Value: []byte(`not json`)}))
+	require.NoError(t, b.Handle(ctx, bridge.Message{Key: "unknown", Value: []byte(`{"type":"DeleteCustomer"}`)}))
+	require.NoError(t, b.Handle(ctx, bridge.Message{Value: []byte(`{"id":"order-1","type":"CreateTransaction","payload":{"Data":{"TransStateID":1,"FactorTypeID":1}}}`)}))
+
+	require.Len(t, bus.published["errors"], 2)
+	assert.Equal(t, "bad", bus.published["errors"][0].ID)
+	assert.Equal(t, "unknown", bus.published["errors"][1].ID)
+	require.Len(t, bus.published["results"], 1)
+	assert.Equal(t, bridge.ResultQueued, bus.published["results"][0].Status)
+
+	// the transaction waits in the outbox for the submitter
+	entry, err := store.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxPending, entry.Status)
+}
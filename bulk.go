@@ -0,0 +1,271 @@
+package goarpa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BulkOptions configure a bulk execution
+type BulkOptions struct {
+	// Concurrency is the number of calls made at the same time, 1 by default
+	Concurrency int
+	// RateLimit is the max number of calls started per second, 0 is unlimited
+	RateLimit float64
+	// Retries is the number of times a failed item is retried
+	Retries int
+	// Mutating marks the calls which create records in Arpa: a call which failed after it was sent may still
+	// have been processed, so only the throttled calls and the calls which were never sent are retried
+	Mutating bool
+	// RetryWait is the longest wait before the first retry of an item, doubled when Arpa throttles the requests.
+	// The waits are random and grow at every retry up to DefaultRetryBackoff.Max, see RetryBackoff.
+	RetryWait time.Duration
+	// Progress is called after each processed item
+	Progress func(progress BulkProgress)
+	// Checkpoint records the items which succeeded, so that an execution restarted after a crash
+	// skips them instead of creating them twice. The failed items are attempted again.
+	// An item whose call succeeded right before the crash, and was not saved yet, is repeated.
+	Checkpoint CheckpointStore
+	// Clock paces the retries and the rate limit, SystemClock by default.
+	// CreateCustomers and CreateTransactions use the clock of the client.
+	Clock Clock
+}
+
+// BulkProgress is the progress of a bulk execution
+type BulkProgress struct {
+	Total     int
+	Done      int
+	Succeeded int
+	Failed    int
+	// Skipped are the items which succeeded in a previous execution, see BulkOptions.Checkpoint.
+	// They are counted as done.
+	Skipped int
+}
+
+// BulkItemError is the error of a single item of a bulk execution
+type BulkItemError struct {
+	Index int
+	Err   error
+}
+
+// BulkError is the aggregate error report of a bulk execution
+type BulkError struct {
+	Total  int
+	Errors []BulkItemError
+}
+
+// Error stringifies the BulkError
+func (e *BulkError) Error() string {
+	var res strings.Builder
+	fmt.Fprintf(&res, "%d of %d items failed", len(e.Errors), e.Total)
+	for i, itemErr := range e.Errors {
+		if i == 3 {
+			fmt.Fprintf(&res, "; ...")
+			break
+		}
+		fmt.Fprintf(&res, "; item %d: %s", itemErr.Index, itemErr.Err)
+	}
+	return res.String()
+}
+
+// Bulk executes a call for many requests with bounded concurrency, rate limiting and per item retries
+type Bulk[TReq any, TResp any] struct {
+	do      func(ctx context.Context, request TReq) (TResp, error)
+	options BulkOptions
+}
+
+// NewBulk returns a bulk executor of the given call
+func NewBulk[TReq any, TResp any](do func(ctx context.Context, request TReq) (TResp, error), options BulkOptions) *Bulk[TReq, TResp] {
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+	if options.Clock == nil {
+		options.Clock = SystemClock
+	}
+	return &Bulk[TReq, TResp]{do: do, options: options}
+}
+
+// Execute runs the call for all the requests.
+// The responses have the order of the requests, the response of a failed or skipped item is the zero value.
+// The returned error is a *BulkError listing the failed items, the error of the context or of the checkpoint store.
+func (b *Bulk[TReq, TResp]) Execute(ctx context.Context, requests []TReq) ([]TResp, error) {
+	responses := make([]TResp, len(requests))
+	report := &BulkError{Total: len(requests)}
+	progress := BulkProgress{Total: len(requests)}
+	limiter := newStartLimiter(b.options.RateLimit, b.options.Clock)
+
+	var checkpoint *checkpointer
+	skipped := make([]bool, len(requests))
+	if b.options.Checkpoint != nil {
+		var err error
+		if checkpoint, err = loadCheckpointer(ctx, b.options.Checkpoint); err != nil {
+			return responses, fmt.Errorf("could not load checkpoint: %w", err)
+		}
+		for i := range requests {
+			if checkpoint.done(i) {
+				skipped[i] = true
+				progress.Skipped++
+			}
+		}
+		progress.Done = progress.Skipped
+	}
+
+	// the execution stops when a checkpoint cannot be saved, the next one would repeat the items
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	indexes := make(chan int)
+
+	for w := 0; w < b.options.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				response, err := b.executeItem(ctx, limiter, requests[i])
+
+				mu.Lock()
+				progress.Done++
+				if err != nil {
+					progress.Failed++
+					report.Errors = append(report.Errors, BulkItemError{Index: i, Err: err})
+				} else {
+					progress.Succeeded++
+					responses[i] = response
+					if checkpoint != nil {
+						if err := checkpoint.complete(ctx, i); err != nil {
+							cancel(fmt.Errorf("could not save checkpoint: %w", err))
+						}
+					}
+				}
+				if b.options.Progress != nil {
+					b.options.Progress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for i := range requests {
+		if skipped[i] {
+			continue
+		}
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return responses, err
+	}
+	if len(report.Errors) > 0 {
+		sort.Slice(report.Errors, func(i, j int) bool {
+			return report.Errors[i].Index < report.Errors[j].Index
+		})
+		return responses, report
+	}
+	return responses, nil
+}
+
+func (b *Bulk[TReq, TResp]) executeItem(ctx context.Context, limiter *startLimiter, request TReq) (TResp, error) {
+	backoff := RetryBackoff{Base: b.options.RetryWait, Max: DefaultRetryBackoff.Max}
+	for attempt := 0; ; attempt++ {
+		if err := limiter.wait(ctx); err != nil {
+			var zero TResp
+			return zero, err
+		}
+
+		response, err := b.do(ctx, request)
+		if err == nil || attempt >= b.options.Retries || !b.retryable(err) {
+			return response, err
+		}
+
+		if isThrottledError(err) {
+			backoff.Base *= 2
+		}
+		timer := b.options.Clock.NewTimer(backoff.Wait(attempt + 1))
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			var zero TResp
+			return zero, retryCanceledError(ctx, err, attempt+1)
+		}
+	}
+}
+
+// retryable returns true if the item which failed with the error may be attempted again
+func (b *Bulk[TReq, TResp]) retryable(err error) bool {
+	if b.options.Mutating {
+		return isThrottledError(err) || requestWasNotSent(err)
+	}
+	return IsRetryableError(err)
+}
+
+// IsRetryableError returns true for transport errors, throttling and server errors,
+// which are worth retrying as opposed to the errors reported by Arpa
+func IsRetryableError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Type != APIErrTypeArpa && (apiErr.Code == 0 || apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError)
+}
+
+func isThrottledError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests
+}
+
+// startLimiter spaces the start of the calls to respect a rate
+type startLimiter struct {
+	mu       sync.Mutex
+	clock    Clock
+	interval time.Duration
+	next     time.Time
+}
+
+func newStartLimiter(rate float64, clock Clock) *startLimiter {
+	limiter := &startLimiter{clock: clock}
+	if rate > 0 {
+		limiter.interval = time.Duration(float64(time.Second) / rate)
+	}
+	return limiter
+}
+
+func (l *startLimiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := l.clock.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := l.clock.NewTimer(start.Sub(now))
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,136 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BulkExecute(t *testing.T) {
+	t.Parallel()
+	var (
+		calls    atomic.Int32
+		progress []goarpa.BulkProgress
+	)
+	bulk := goarpa.NewBulk(func(ctx context.Context, request int) (int, error) {
+		calls.Add(1)
+		switch request {
+		case 3:
+			return 0, &goarpa.APIError{Code: http.StatusBadRequest, Message: "bad request"}
+		case 5:
+			return 0, &goarpa.APIError{Code: http.StatusBadGateway, Message: "bad gateway"}
+		}
+		return request * 10, nil
+	}, goarpa.BulkOptions{
+		Concurrency: 3,
+		Retries:     2,
+		RetryWait:   time.Millisecond,
+		Progress: func(p goarpa.BulkProgress) {
+			progress = append(progress, p)
+		},
+	})
+
+	responses, err := bulk.Execute(context.Background(), []int{1, 2, 3, 4, 5, 6})
+	require.Error(t, err)
+	assert.Equal(t, []int{10, 20, 0, 40, 0, 60}, responses)
+
+	var bulkErr *goarpa.BulkError
+	require.True(t, errors.As(err, &bulkErr))
+	require.Len(t, bulkErr.Errors, 2)
+	assert.Equal(t, 2, bulkErr.Errors[0].Index)
+	assert.Equal(t, 4, bulkErr.Errors[1].Index)
+
+	// the bad gateway is retried twice, the bad request is not
+	assert.Equal(t, int32(8), calls.Load())
+	require.Len(t, progress, 6)
+	assert.Equal(t, goarpa.BulkProgress{Total: 6, Done: 6, Succeeded: 4, Failed: 2}, progress[5])
+}
+
+func Test_BulkCheckpoint(t *testing.T) {
+	t.Parallel()
+	store := goarpa.NewFileCheckpointStore(filepath.Join(t.TempDir(), "import.checkpoint"))
+	requests := []int{1, 2, 3, 4, 5, 6}
+
+	// the first execution crashes after the fourth item
+	var created []int
+	var mu sync.Mutex
+	ctx, cancel := context.WithCancel(context.Background())
+	bulk := goarpa.NewBulk(func(ctx context.Context, request int) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if request == 2 {
+			return 0, &goarpa.APIError{Code: http.StatusBadRequest, Message: "bad request"}
+		}
+		created = append(created, request)
+		if request == 4 {
+			cancel()
+		}
+		return request * 10, nil
+	}, goarpa.BulkOptions{Checkpoint: store})
+	_, err := bulk.Execute(ctx, requests)
+	require.True(t, errors.Is(err, context.Canceled))
+
+	checkpoint, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.BulkCheckpoint{Next: 1, Succeeded: []int{2, 3}}, checkpoint)
+
+	// the restarted execution skips the items which succeeded and attempts the failed one again
+	var progress goarpa.BulkProgress
+	bulk = goarpa.NewBulk(func(ctx context.Context, request int) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, request)
+		return request * 10, nil
+	}, goarpa.BulkOptions{
+		Concurrency: 2,
+		Checkpoint:  store,
+		Progress: func(p goarpa.BulkProgress) {
+			progress = p
+		},
+	})
+	responses, err := bulk.Execute(context.Background(), requests)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 20, 0, 0, 50, 60}, responses)
+	assert.ElementsMatch(t, []int{1, 3, 4, 2, 5, 6}, created)
+	assert.Equal(t, goarpa.BulkProgress{Total: 6, Done: 6, Succeeded: 3, Skipped: 3}, progress)
+
+	checkpoint, err = store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.BulkCheckpoint{Next: 6}, checkpoint)
+}
+
+func Test_BulkMutating(t *testing.T) {
+	t.Parallel()
+	calls := make(map[int]int)
+	var mu sync.Mutex
+	bulk := goarpa.NewBulk(func(ctx context.Context, request int) (int, error) {
+		mu.Lock()
+		calls[request]++
+		mu.Unlock()
+		switch request {
+		case 1:
+			return 0, &goarpa.APIError{Code: http.StatusBadGateway, Message: "bad gateway"}
+		case 2:
+			return 0, &goarpa.APIError{Message: "timeout", LastErr: errors.New("read: connection reset")}
+		case 3:
+			return 0, &goarpa.APIError{Code: http.StatusTooManyRequests, Message: "too many requests"}
+		default:
+			return 0, &goarpa.APIError{Message: "refused", LastErr: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+		}
+	}, goarpa.BulkOptions{Retries: 2, RetryWait: time.Millisecond, Mutating: true})
+
+	_, err := bulk.Execute(context.Background(), []int{1, 2, 3, 4})
+	require.Error(t, err)
+	// the calls which may have reached Arpa are not repeated
+	assert.Equal(t, map[int]int{1: 1, 2: 1, 3: 3, 4: 3}, calls)
+}
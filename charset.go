@@ -0,0 +1,92 @@
+package goarpa
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+const jsonContentType = "application/json; charset=utf-8"
+
+// charsetTransport converts Windows-1256 response bodies to UTF-8.
+// The charset is taken from the Content-Type header. Without one, a body which is not valid UTF-8
+// is assumed to be Windows-1256, the usual encoding of Persian text on Windows servers.
+type charsetTransport struct {
+	next http.RoundTripper
+}
+
+// wrapCharsetTransport installs the charset conversion on the resty client, once
+func wrapCharsetTransport(restyClient *resty.Client) {
+	httpClient := restyClient.GetClient()
+	if _, ok := httpClient.Transport.(*charsetTransport); ok {
+		return
+	}
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	httpClient.Transport = &charsetTransport{next: next}
+}
+
+func (t *charsetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	charset := strings.ToLower(params["charset"])
+
+	switch {
+	case isWindows1256(charset):
+		// the charset is known, the body is converted while it is read
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{transform.NewReader(resp.Body, charmap.Windows1256.NewDecoder()), resp.Body}
+		params["charset"] = "utf-8"
+		resp.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		return resp, nil
+	case charset != "":
+		return resp, nil
+	case req.Context().Value(streamingContextKey) != nil:
+		// detecting the charset would require buffering the whole body
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if !utf8.Valid(body) {
+		if decoded, err := charmap.Windows1256.NewDecoder().Bytes(body); err == nil {
+			body = decoded
+			resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			resp.ContentLength = int64(len(body))
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func isWindows1256(charset string) bool {
+	switch charset {
+	case "windows-1256", "cp1256", "x-cp1256":
+		return true
+	}
+	return false
+}
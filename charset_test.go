@@ -0,0 +1,45 @@
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func Test_PersianCharsets(t *testing.T) {
+	t.Parallel()
+	const name = "فروشگاه پارس"
+	body := `{"data":[{"BusinessID":"1","BusinessName":"` + name + `"}],"error":null}`
+	encoded, err := charmap.Windows1256.NewEncoder().String(body)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		contentType string
+		body        string
+	}{
+		"utf-8":                 {"application/json; charset=utf-8", body},
+		"windows-1256":          {"application/json; charset=windows-1256", encoded},
+		"windows-1256 detected": {"application/json", encoded},
+	}
+	for testName, testCase := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json; charset=utf-8", r.Header.Get("Content-Type"), testName)
+			w.Header().Set("Content-Type", testCase.contentType)
+			_, _ = w.Write([]byte(testCase.body))
+		}))
+
+		client := goarpa.NewClient(server.URL)
+		response, err := client.GetCustomerByMobile(context.Background(), "token", []*http.Cookie{{Name: "session", Value: "1"}}, "09121234567")
+		server.Close()
+
+		require.NoError(t, err, testName)
+		require.Len(t, response.Data, 1, testName)
+		assert.Equal(t, name, response.Data[0].BusinessName, testName)
+	}
+}
@@ -0,0 +1,117 @@
+package goarpa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+)
+
+// BulkCheckpoint records the items of a bulk execution which succeeded
+type BulkCheckpoint struct {
+	// Next is the index of the first item which did not succeed, all the items before it did
+	Next int `json:"next"`
+	// Succeeded are the indexes after Next of the items which succeeded, with concurrency they complete out of order
+	Succeeded []int `json:"succeeded,omitempty"`
+}
+
+// CheckpointStore persists the checkpoint of a bulk execution, see BulkOptions.Checkpoint.
+// A store holds the checkpoint of one import, e.g. one file per imported file.
+type CheckpointStore interface {
+	// Load returns the saved checkpoint, the zero checkpoint when there is none
+	Load(ctx context.Context) (BulkCheckpoint, error)
+	// Save replaces the checkpoint
+	Save(ctx context.Context, checkpoint BulkCheckpoint) error
+}
+
+// checkpointer tracks the succeeded items of an execution and saves them after each one
+type checkpointer struct {
+	store     CheckpointStore
+	next      int
+	succeeded map[int]bool
+}
+
+func loadCheckpointer(ctx context.Context, store CheckpointStore) (*checkpointer, error) {
+	checkpoint, err := store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := &checkpointer{store: store, next: checkpoint.Next, succeeded: make(map[int]bool, len(checkpoint.Succeeded))}
+	for _, i := range checkpoint.Succeeded {
+		c.succeeded[i] = true
+	}
+	return c, nil
+}
+
+// done returns true if the item succeeded in a previous execution
+func (c *checkpointer) done(i int) bool {
+	return i < c.next || c.succeeded[i]
+}
+
+// complete records that the item succeeded and saves the checkpoint
+func (c *checkpointer) complete(ctx context.Context, i int) error {
+	c.succeeded[i] = true
+	for c.succeeded[c.next] {
+		delete(c.succeeded, c.next)
+		c.next++
+	}
+
+	checkpoint := BulkCheckpoint{Next: c.next}
+	for i := range c.succeeded {
+		checkpoint.Succeeded = append(checkpoint.Succeeded, i)
+	}
+	sort.Ints(checkpoint.Succeeded)
+	return c.store.Save(ctx, checkpoint)
+}
+
+// MemoryCheckpointStore is a CheckpointStore kept in memory, for tests
+type MemoryCheckpointStore struct {
+	mu         sync.Mutex
+	checkpoint BulkCheckpoint
+}
+
+// Load returns the saved checkpoint
+func (m *MemoryCheckpointStore) Load(_ context.Context) (BulkCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkpoint, nil
+}
+
+// Save replaces the checkpoint
+func (m *MemoryCheckpointStore) Save(_ context.Context, checkpoint BulkCheckpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoint = checkpoint
+	return nil
+}
+
+// FileCheckpointStore is a CheckpointStore persisted in a JSON file, which is atomically replaced on every change
+type FileCheckpointStore struct {
+	path string
+}
+
+// NewFileCheckpointStore returns a store persisted at path, the file is created by the first save
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+// Load returns the checkpoint of the file, the zero checkpoint when the file does not exist
+func (f *FileCheckpointStore) Load(_ context.Context) (BulkCheckpoint, error) {
+	var checkpoint BulkCheckpoint
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+	err = json.Unmarshal(data, &checkpoint)
+	return checkpoint, err
+}
+
+// Save replaces the checkpoint of the file
+func (f *FileCheckpointStore) Save(_ context.Context, checkpoint BulkCheckpoint) error {
+	return writeFileAtomic(f.path, checkpoint)
+}
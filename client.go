@@ -1,10 +1,20 @@
 package goarpa
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"iter"
+	"math"
 	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/erfandiakoo/goarpa/v2/shared/constant"
 	"github.com/go-resty/resty/v2"
@@ -12,17 +22,93 @@ import (
 	"github.com/pkg/errors"
 )
 
+// GoArpa is the client of an Arpa server. It is safe for concurrent use once configured:
+// the options are applied by NewClient, the configuration is changed with UpdateConfig and
+// the resty client with SetRestyClient while calls are in flight.
 type GoArpa struct {
-	basePath    string
+	basePath           string
+	retryHooks         []RetryHook
+	retryPolicy        RetryPolicy
+	retryBackoff       *RetryBackoff
+	idempotencyKeys    bool
+	locale             Locale
+	dryRun             bool
+	normalizePhones    bool
+	normalizeText      bool
+	coalesceReads      bool
+	profile            InstallationProfile
+	lenientEnvelope    bool
+	staticToken        string
+	schemaDriftHandler SchemaDriftHandler
+	auditSink          AuditSink
+	auditErrorHandler  func(error)
+	auditBodies        bool
+	capabilities       map[string]Capability
+	inactiveFilter     InactiveFilter
+	timeouts           Timeouts
+	responseCache      ResponseCache
+	clock              Clock
+	lookupConcurrency  int
+	requestDecorators  []func(*resty.Request)
+	throttle           *adaptiveThrottle
+	queryKeyNames      map[string]string
+	fieldKeyNames      map[string]string
+	// server is the server detected by GetServerInfo
+	server atomic.Pointer[detectedServer]
+	// geo is the dataset of the geo lookups, see GeoDataset
+	geo   geoCache
+	stats clientStats
+	// state holds the watermarks, the imported sessions and the idempotency keys, see ExportState
+	state clientState
+	// managedSessions are the token managers by the access tokens of their sessions, see TokenManager
+	managedSessions sync.Map
+
+	// mu guards Config and restyClient
+	mu          sync.RWMutex
 	restyClient *resty.Client
-	Config      struct {
-		GetServiceTokenEndpoint   string
-		CreateCustomerEndpoint    string
-		CreateTransactionEndpoint string
-		CreateServiceEndpoint     string
-		GetCustomerEndpoint       string
-		GetItemEndpoint           string
-	}
+	// Config holds the endpoints. It may be modified directly until the client is shared between goroutines,
+	// afterwards it must be changed with UpdateConfig.
+	Config ClientConfig
+}
+
+// ClientConfig holds the endpoints of the client, relative to its base path
+type ClientConfig struct {
+	GetServiceTokenEndpoint   string
+	CreateCustomerEndpoint    string
+	CreateTransactionEndpoint string
+	CreateServiceEndpoint     string
+	GetCustomerEndpoint       string
+	GetItemEndpoint           string
+
+	// The following endpoints are not available on every installation and have no default.
+	// The methods using them return ErrNotSupported until they are configured.
+	UpdateCustomerEndpoint        string
+	GetCustomerBalanceEndpoint    string
+	GetCustomersEndpoint          string
+	GetTransactionsEndpoint       string
+	GetItemsEndpoint              string
+	SubmitReportJobEndpoint       string
+	GetReportJobEndpoint          string
+	GetReportResultEndpoint       string
+	GetCustomerAttributesEndpoint string
+	SetCustomerAttributesEndpoint string
+	ReserveStockEndpoint          string
+	ReleaseReservationEndpoint    string
+	GetServerInfoEndpoint         string
+	GetCustomerAgingEndpoint      string
+	GetItemStockEndpoint          string
+	GetItemAvailabilityEndpoint   string
+	GetItemPriceHistoryEndpoint   string
+	GetReceiptsEndpoint           string
+	GetPaymentsEndpoint           string
+	GetCustomerStatementEndpoint  string
+	FinalizeTransactionEndpoint   string
+	GetRepresentorSalesEndpoint   string
+	GetProvincesEndpoint          string
+	GetCitiesEndpoint             string
+
+	// GeneratedEndpoints are the endpoints of the methods generated from endpoints.json
+	GeneratedEndpoints
 }
 
 const (
@@ -30,18 +116,80 @@ const (
 	urlSeparator  string = "/"
 )
 
+// DefaultAPIPrefix is the path the Arpa API is served under, relative to the base path, see WithAPIPrefix
+const DefaultAPIPrefix = "serv"
+
 func makeURL(path ...string) string {
 	return strings.Join(path, urlSeparator)
 }
 
+// WithAPIPrefix serves the default endpoints under the prefix instead of DefaultAPIPrefix,
+// e.g. "api/v2" for serv/api/PostBusiness to become api/v2/api/PostBusiness. The configured endpoints
+// starting with DefaultAPIPrefix are changed as well.
+func WithAPIPrefix(prefix string) func(*GoArpa) {
+	return func(g *GoArpa) {
+		prefix = strings.Trim(prefix, urlSeparator)
+		config := reflect.ValueOf(&g.Config).Elem()
+		for _, field := range reflect.VisibleFields(config.Type()) {
+			if field.Anonymous || field.Type.Kind() != reflect.String {
+				continue
+			}
+			value := config.FieldByIndex(field.Index)
+			if endpoint, ok := strings.CutPrefix(value.String(), DefaultAPIPrefix+urlSeparator); ok {
+				value.SetString(strings.TrimLeft(makeURL(prefix, endpoint), urlSeparator))
+			}
+		}
+	}
+}
+
+// ParseBasePath validates the base path of a client and returns it normalized: an absolute http or https URL,
+// which may have a sub-path, e.g. https://host/arpa for an Arpa deployed under /arpa/, without the trailing slashes.
+// The errors match ErrInvalidBasePath.
+func ParseBasePath(basePath string) (string, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidBasePath, basePath, reason)
+	}
+
+	u, err := url.Parse(strings.TrimSpace(basePath))
+	switch {
+	case err != nil:
+		return "", invalid(err.Error())
+	case u.Scheme != "http" && u.Scheme != "https":
+		return "", invalid("the scheme must be http or https")
+	case u.Host == "":
+		return "", invalid("the host is missing")
+	case u.RawQuery != "" || u.Fragment != "":
+		return "", invalid("a query or a fragment is not allowed")
+	}
+	if u.Path != "" {
+		u.Path = path.Clean(u.Path)
+	}
+	u.RawPath = ""
+	return strings.TrimRight(u.String(), urlSeparator), nil
+}
+
+// url returns the URL of an endpoint relative to the base path
+func (g *GoArpa) url(endpoint string) string {
+	return g.basePath + urlSeparator + strings.TrimLeft(endpoint, urlSeparator)
+}
+
 // GetRequest returns a request for calling endpoints.
 func (g *GoArpa) GetRequest(ctx context.Context) *resty.Request {
 	var err HTTPErrorResponse
-	return injectTracingHeaders(
-		ctx, g.restyClient.R().
-			SetContext(ctx).
-			SetError(&err),
-	)
+	req := g.RestyClient().R().
+		SetContext(withRetryState(ctx)).
+		SetError(&err)
+	if g.locale != "" {
+		req.SetHeader("Accept-Language", string(g.locale))
+		req.SetQueryParam(string(constant.CultureKey), g.locale.Culture())
+	}
+	for _, decorate := range g.requestDecorators {
+		decorate(req)
+	}
+	if branchID, ok := ctx.Value(callBranchContextKey).(int64); ok {
+		req.SetHeader(BranchHeader, strconv.FormatInt(branchID, 10))
+	}
+	return injectTracingHeaders(ctx, req)
 }
 
 func injectTracingHeaders(ctx context.Context, req *resty.Request) *resty.Request {
@@ -52,10 +200,7 @@ func injectTracingHeaders(ctx context.Context, req *resty.Request) *resty.Reques
 	}
 
 	// look for tracer in context, use global tracer if not found
-	tracer, ok := ctx.Value(tracerContextKey).(opentracing.Tracer)
-	if !ok || tracer == nil {
-		tracer = opentracing.GlobalTracer()
-	}
+	tracer := TracerFromContext(ctx)
 
 	// inject tracing header into request
 	err := tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
@@ -69,63 +214,172 @@ func injectTracingHeaders(ctx context.Context, req *resty.Request) *resty.Reques
 // GetRequestWithBearerAuthNoCache returns a JSON base request configured with an auth token and no-cache header.
 func (g *GoArpa) GetRequestWithBearerAuthNoCache(ctx context.Context, token string) *resty.Request {
 	return g.GetRequest(ctx).
-		SetAuthToken(token).
-		SetHeader("Content-Type", "application/json").
+		SetAuthToken(g.authToken(token)).
+		SetHeader("Content-Type", jsonContentType).
 		SetHeader("Cache-Control", "no-cache")
 }
 
 // GetRequestWithBearerAuth returns a JSON base request configured with an auth token.
 func (g *GoArpa) GetRequestWithBearerAuth(ctx context.Context, token string) *resty.Request {
 	return g.GetRequest(ctx).
-		SetAuthToken(token).
-		SetHeader("Content-Type", "application/json")
+		SetAuthToken(g.authToken(token)).
+		SetHeader("Content-Type", jsonContentType)
 }
 
+// GetRequestWithBearerAuthWithCookie returns a JSON base request configured with an auth token and the cookies of the session.
+// The cookies are not sent when the client uses a static token.
 func (g *GoArpa) GetRequestWithBearerAuthWithCookie(ctx context.Context, token string, cookie []*http.Cookie) *resty.Request {
-	return g.GetRequest(ctx).
-		SetAuthToken(token).
-		SetCookie(cookie[0]).
-		SetHeader("Content-Type", "application/json")
+	req := g.GetRequest(ctx).
+		SetAuthToken(g.authToken(token)).
+		SetHeader("Content-Type", jsonContentType)
+	if g.staticToken == "" {
+		req.SetCookies(cookie)
+	}
+	return req
 }
 
+// NewClient returns a client of the Arpa server at the base path, e.g. https://host/arpa.
+// The base path is not validated, see NewValidatedClient.
 func NewClient(basePath string, options ...func(*GoArpa)) *GoArpa {
 	c := GoArpa{
-		basePath:    strings.TrimRight(basePath, urlSeparator),
+		basePath:    strings.TrimRight(strings.TrimSpace(basePath), urlSeparator),
 		restyClient: resty.New(),
 	}
 
-	c.Config.GetServiceTokenEndpoint = makeURL("serv", "token", "GetServiceToken")
-	c.Config.CreateCustomerEndpoint = makeURL("serv", "api", "PostBusiness")
-	c.Config.CreateTransactionEndpoint = makeURL("serv", "api", "NewTransaction")
-	c.Config.CreateServiceEndpoint = makeURL("serv", "api", "PostService")
-	c.Config.GetCustomerEndpoint = makeURL("serv", "api", "GetBusiness")
-	c.Config.GetItemEndpoint = makeURL("serv", "api", "GetItem")
-
+	c.Config.GetServiceTokenEndpoint = makeURL(DefaultAPIPrefix, "token", "GetServiceToken")
+	c.Config.CreateCustomerEndpoint = makeURL(DefaultAPIPrefix, "api", "PostBusiness")
+	c.Config.CreateTransactionEndpoint = makeURL(DefaultAPIPrefix, "api", "NewTransaction")
+	c.Config.CreateServiceEndpoint = makeURL(DefaultAPIPrefix, "api", "PostService")
+	c.Config.GetCustomerEndpoint = makeURL(DefaultAPIPrefix, "api", "GetBusiness")
+	c.Config.GetItemEndpoint = makeURL(DefaultAPIPrefix, "api", "GetItem")
+	c.Config.GeneratedEndpoints = defaultGeneratedEndpoints()
 	for _, option := range options {
 		option(&c)
 	}
 
+	c.stats.init(c.Clock().Now())
+
+	c.attachHooks(c.restyClient)
+
 	return &c
 }
 
+// NewValidatedClient returns a client of the Arpa server at the base path once it is validated
+// and normalized by ParseBasePath
+func NewValidatedClient(basePath string, options ...func(*GoArpa)) (*GoArpa, error) {
+	basePath, err := ParseBasePath(basePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(basePath, options...), nil
+}
+
 // RestyClient returns the internal resty g.
 // This can be used to configure the g.
 func (g *GoArpa) RestyClient() *resty.Client {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	return g.restyClient
 }
 
 // SetRestyClient overwrites the internal resty g.
+// The calls in flight complete with the previous client.
 func (g *GoArpa) SetRestyClient(restyClient *resty.Client) {
+	g.attachHooks(restyClient)
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	g.restyClient = restyClient
 }
 
+// UpdateConfig changes the configuration of a client which may be in use.
+// The update is applied to a copy which replaces the configuration, the calls in flight keep the one they started with.
+func (g *GoArpa) UpdateConfig(update func(config *ClientConfig)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	config := g.Config
+	update(&config)
+	g.Config = config
+	// the endpoints of the operations may have changed
+	if server := g.server.Load(); server != nil {
+		g.server.Store(g.detectServer(server.info, config))
+	}
+}
+
+// config returns a copy of the configuration of the client
+func (g *GoArpa) config() ClientConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Config
+}
+
+// attachHooks registers the client hooks on the given resty client
+func (g *GoArpa) attachHooks(restyClient *resty.Client) {
+	wrapCharsetTransport(restyClient)
+	if g.staticToken != "" {
+		restyClient.SetCookieJar(nil)
+	}
+	if g.coalesceReads {
+		wrapCoalescingTransport(restyClient)
+	}
+	wrapConditionalTransport(restyClient, g.responseCache)
+	wrapTimeoutTransport(restyClient, g.timeouts)
+	wrapSingleAttemptTransport(restyClient)
+	maxWait := g.backoff().Max
+	if maxWait <= 0 {
+		maxWait = math.MaxInt64
+	}
+	restyClient.
+		SetRetryWaitTime(0).
+		SetRetryMaxWaitTime(maxWait).
+		SetRetryAfter(g.retryAfter).
+		OnBeforeRequest(g.beforeRequest).
+		OnBeforeRequest(g.applyManagedSession).
+		OnBeforeRequest(g.renameKeys).
+		OnBeforeRequest(g.waitThrottle).
+		OnAfterResponse(g.stopAfterResponse).
+		OnAfterResponse(g.captureManagedSession).
+		OnAfterResponse(g.observeThrottle).
+		OnAfterResponse(g.detectSchemaDrift).
+		AddRetryHook(g.onRetry).
+		OnSuccess(g.onCallSuccess).
+		OnSuccess(g.onRequestSuccess).
+		OnError(g.onCallError).
+		OnError(g.onRequestError)
+}
+
+// endpointURL returns the URL of an endpoint or ErrNotSupported if the endpoint is not configured
+func (g *GoArpa) endpointURL(endpoint string) (string, error) {
+	if endpoint == "" {
+		return "", ErrNotSupported
+	}
+	if err := g.checkCapability(endpoint); err != nil {
+		return "", err
+	}
+	return g.url(endpoint), nil
+}
+
+// marshalBody encodes the body of a request once, the same bytes are sent and audited
+func marshalBody(body interface{}) ([]byte, error) {
+	return json.Marshal(body)
+}
+
 func checkForError(resp *resty.Response, err error, errMessage string) error {
+	// checked first since the client fails when it is not allowed to follow the redirect
+	if resp != nil && isLoginRedirect(resp) {
+		return withRetryInfo(&APIError{
+			Code:    resp.StatusCode(),
+			Message: errors.Wrap(ErrSessionExpired, errMessage).Error(),
+			Type:    APIErrTypeSessionExpired,
+		}, resp)
+	}
+
 	if err != nil {
-		return &APIError{
+		return withRetryInfo(&APIError{
 			Code:    0,
 			Message: errors.Wrap(err, errMessage).Error(),
 			Type:    ParseAPIErrType(err),
-		}
+			LastErr: err,
+		}, resp)
 	}
 
 	if resp == nil {
@@ -144,102 +398,236 @@ func checkForError(resp *resty.Response, err error, errMessage string) error {
 			msg = resp.Status()
 		}
 
-		return &APIError{
+		return withRetryInfo(&APIError{
 			Code:    resp.StatusCode(),
 			Message: msg,
 			Type:    ParseAPIErrType(err),
-		}
+		}, resp)
 	}
 
 	return nil
 }
 
+// checkForArpaError converts the error field of a response envelope to an APIError,
+// unless the client is lenient, see WithLenientEnvelope
+func (g *GoArpa) checkForArpaError(resp *resty.Response, arpaErr *ArpaError, errMessage string) error {
+	if g.lenientEnvelope || !arpaErr.NotEmpty() {
+		return nil
+	}
+
+	errType := APIErrTypeArpa
+	if isSessionExpiredMessage(arpaErr) {
+		errType = APIErrTypeSessionExpired
+	}
+	if resp != nil {
+		g.stats.recordError(g.statsEndpoint(resp.Request), string(errType))
+	}
+	return &APIError{
+		Code:    resp.StatusCode(),
+		Message: fmt.Sprintf("%s: %s", errMessage, arpaErr),
+		Type:    errType,
+		Arpa:    arpaErr,
+	}
+}
+
+// GetAdminToken logs in and returns the access token with the cookies of the session.
+// The client using a static token returns it without logging in.
 func (g *GoArpa) GetAdminToken(ctx context.Context, username string, password string) (string, []*http.Cookie, error) {
 	const errMessage = "could not get token"
 
+	if g.staticToken != "" {
+		return g.staticToken, nil, nil
+	}
+
 	req := g.GetRequest(ctx)
 
 	resp, err := req.SetQueryParams(map[string]string{
 		"username": username,
 		"password": password,
 	}).
-		Get(g.basePath + "/" + g.Config.GetServiceTokenEndpoint + "?")
+		Get(g.url(g.config().GetServiceTokenEndpoint) + "?")
 
 	if err := checkForError(resp, err, errMessage); err != nil {
 		return "", nil, err
 	}
 
+	// the token is the raw body, a failed login may still be answered with an envelope on HTTP 200
+	if body := bytes.TrimSpace(resp.Body()); len(body) > 0 && body[0] == '{' {
+		var envelope APIResponse[json.RawMessage]
+		if json.Unmarshal(body, &envelope) == nil {
+			if err := g.checkForArpaError(resp, envelope.Error, errMessage); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+
 	return resp.String(), resp.Cookies(), nil
 }
 
+// CreateCustomer creates a business.
+// If Arpa reports that the business already existed, the response is returned along with a *CustomerExistsError
+// which matches ErrCustomerAlreadyExists.
 func (g *GoArpa) CreateCustomer(ctx context.Context, accessToken string, cookie []*http.Cookie, customer CreateCustomerRequest) (*RetCustomerResponse, error) {
 	const errMessage = "could not create customer"
 
+	if g.normalizeText {
+		normalizeCustomerText(&customer)
+	}
+	if g.normalizePhones {
+		if err := normalizeCustomerPhones(&customer); err != nil {
+			return nil, errors.Wrap(err, errMessage)
+		}
+	}
+
+	body, err := marshalBody(customer)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
 	var response RetCustomerResponse
 
-	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
-		SetBody(customer).
-		SetResult(&response).
-		Post(g.basePath + "/" + g.Config.CreateCustomerEndpoint)
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody(body).
+		SetResult(&response)
+	url := g.url(g.config().CreateCustomerEndpoint)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &RetCustomerResponse{}, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "CreateCustomer", url, body, resp, err, response.Error, func() map[string]string {
+		return map[string]string{
+			"BusinessID":   response.Data.BusinessID.String(),
+			"BusinessCode": string(response.Data.BusinessCode),
+		}
+	})
 
 	if err := checkForError(resp, err, errMessage); err != nil {
 		return nil, err
 	}
 
+	if err := g.checkForArpaError(resp, response.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	if response.Data.Existed {
+		return &response, &CustomerExistsError{
+			BusinessID:   response.Data.BusinessID,
+			BusinessCode: string(response.Data.BusinessCode),
+		}
+	}
+
 	return &response, nil
 }
 
 func (g *GoArpa) CreateTransaction(ctx context.Context, accessToken string, transaction CreateTransactionRequest) (*CreateTransactionResponse, error) {
 	const errMessage = "could not create transaction"
 
+	g.profile.apply(&transaction.Data)
+
+	body, err := marshalBody(transaction)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
 	var response CreateTransactionResponse
 
-	resp, err := g.GetRequestWithBearerAuth(ctx, accessToken).
-		SetBody(transaction).
-		SetResult(response).
-		Post(g.basePath + "/" + g.Config.CreateTransactionEndpoint)
+	req := g.GetRequestWithBearerAuth(ctx, accessToken).
+		SetBody(body).
+		SetResult(&response)
+	url := g.url(g.config().CreateTransactionEndpoint)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &CreateTransactionResponse{Data: []Datum{}}, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "CreateTransaction", url, body, resp, err, response.Error, func() map[string]string {
+		return transactionAuditIDs(&response)
+	})
 
 	if err := checkForError(resp, err, errMessage); err != nil {
 		return nil, err
 	}
 
+	if err := g.checkForArpaError(resp, response.Error, errMessage); err != nil {
+		return nil, err
+	}
+
 	return &response, nil
 }
 
 func (g *GoArpa) CreateService(ctx context.Context, accessToken string, service CreateServiceRequest) (*CreateServiceResponse, error) {
 	const errMessage = "could not create service"
 
+	body, err := marshalBody(service)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
 	var response CreateServiceResponse
 
-	resp, err := g.GetRequestWithBearerAuth(ctx, accessToken).
-		SetBody(service).
-		SetResult(response).
-		Post(g.basePath + "/" + g.Config.CreateServiceEndpoint)
+	req := g.GetRequestWithBearerAuth(ctx, accessToken).
+		SetBody(body).
+		SetResult(&response)
+	url := g.url(g.config().CreateServiceEndpoint)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &CreateServiceResponse{ServiceName: service.ServiceName, ItemCategoryID: service.ItemCategoryID}, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "CreateService", url, body, resp, err, response.Error, func() map[string]string {
+		return map[string]string{"ServiceCode": service.ServiceCode}
+	})
 
 	if err := checkForError(resp, err, errMessage); err != nil {
 		return nil, err
 	}
 
+	if err := g.checkForArpaError(resp, response.Error, errMessage); err != nil {
+		return nil, err
+	}
+
 	return &response, nil
 }
 
 func (g *GoArpa) GetCustomerByMobile(ctx context.Context, accessToken string, cookie []*http.Cookie, mobile string) (*GetCustomerResponse, error) {
 	const errMessage = "could not get customer info"
 
+	if g.normalizePhones {
+		normalized, err := NormalizeMobile(mobile)
+		if err != nil {
+			return nil, errors.Wrap(err, errMessage)
+		}
+		mobile = normalized
+	} else if g.normalizeText {
+		mobile = NormalizeDigits(mobile)
+	}
+
 	// Create an instance of GetCustomerResponse to hold the response
 	result := &GetCustomerResponse{}
 
 	// Make the request and set result to auto-unmarshal
 	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
-		SetQueryParam(constant.MobileKey, mobile).
+		SetQueryParam(string(constant.MobileKey), mobile).
 		SetResult(result).
-		Get(fmt.Sprintf("%s/%s", g.basePath, g.Config.GetCustomerEndpoint))
+		Get(g.url(g.config().GetCustomerEndpoint))
 
 	// Check for errors
 	if err := checkForError(resp, err, errMessage); err != nil {
 		return nil, err
 	}
 
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+	filterCustomers(result, g.inactiveFilter)
+
 	// Return the unmarshaled result
 	return result, nil
 }
@@ -247,36 +635,427 @@ func (g *GoArpa) GetCustomerByMobile(ctx context.Context, accessToken string, co
 func (g *GoArpa) GetCustomerByBusinessCode(ctx context.Context, accessToken string, cookie []*http.Cookie, businessCode string) (*GetCustomerResponse, error) {
 	const errMessage = "could not get customer info"
 
+	if g.normalizeText {
+		businessCode = NormalizeText(businessCode)
+	}
+
+	result := &GetCustomerResponse{}
+
+	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetQueryParam(string(constant.BusinessCodeKey), businessCode).
+		SetResult(result).
+		Get(g.url(g.config().GetCustomerEndpoint))
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+	filterCustomers(result, g.inactiveFilter)
+
+	return result, nil
+}
+
+// GetCustomerByID returns the business with the ID
+func (g *GoArpa) GetCustomerByID(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID) (*GetCustomerResponse, error) {
+	const errMessage = "could not get customer info"
+
+	result := &GetCustomerResponse{}
+
+	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetQueryParam(string(constant.BusinessIDQueryKey), businessID.String()).
+		SetResult(result).
+		Get(g.url(g.config().GetCustomerEndpoint))
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+	filterCustomers(result, g.inactiveFilter)
+
+	return result, nil
+}
+
+// GetCustomerByNationalCode returns the businesses with the national code
+func (g *GoArpa) GetCustomerByNationalCode(ctx context.Context, accessToken string, cookie []*http.Cookie, nationalCode string) (*GetCustomerResponse, error) {
+	const errMessage = "could not get customer info"
+
+	if g.normalizeText {
+		nationalCode = NormalizeDigits(nationalCode)
+	}
+
 	result := &GetCustomerResponse{}
 
 	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
-		SetQueryParam(constant.BusinessCodeKey, businessCode).
+		SetQueryParam(string(constant.NationalCodeKey), nationalCode).
 		SetResult(result).
-		Get(fmt.Sprintf("%s/%s", g.basePath, g.Config.GetCustomerEndpoint))
+		Get(g.url(g.config().GetCustomerEndpoint))
 
 	if err := checkForError(resp, err, errMessage); err != nil {
 		return nil, err
 	}
 
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+	filterCustomers(result, g.inactiveFilter)
+
 	return result, nil
 }
 
 func (g *GoArpa) GetServiceByItemCode(ctx context.Context, accessToken string, cookie []*http.Cookie, itemCode string) (*RetServiceResponse, error) {
 	const errMessage = "could not get service info"
 
+	if g.normalizeText {
+		itemCode = NormalizeText(itemCode)
+	}
+
 	result := &RetServiceResponse{}
 
 	// Make the request and set result to auto-unmarshal
 	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
-		SetQueryParam(constant.ItemCodeKey, itemCode).
+		SetQueryParam(string(constant.ItemCodeKey), itemCode).
 		SetResult(&result).
-		Get(fmt.Sprintf("%s/%s", g.basePath, g.Config.GetItemEndpoint))
+		Get(g.url(g.config().GetItemEndpoint))
 
 	// Check for errors
 	if err := checkForError(resp, err, errMessage); err != nil {
 		return nil, err
 	}
 
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
 	// Return the unmarshaled result
 	return result, nil
 }
+
+// UpdateCustomerPartial updates only the given changed fields of a business, see DiffCustomers
+func (g *GoArpa) UpdateCustomerPartial(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, changes CustomerChanges) (*RetCustomerResponse, error) {
+	const errMessage = "could not update customer"
+
+	url, err := g.endpointURL(g.config().UpdateCustomerEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	fields := make(map[string]json.RawMessage, len(changes)+1)
+	for key, value := range changes {
+		fields[key] = value
+	}
+	fields[string(constant.BusinessIDKey)] = json.RawMessage(businessID.String())
+
+	body, err := marshalBody(fields)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var response RetCustomerResponse
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody(body).
+		SetResult(&response)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &RetCustomerResponse{Data: CreateCustomerResponse{BusinessID: businessID}}, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "UpdateCustomerPartial", url, body, resp, err, response.Error, func() map[string]string {
+		return map[string]string{"BusinessID": businessID.String()}
+	})
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, response.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// EnsureCustomer returns the business matching the customer or creates it.
+// The business is first looked up by mobile number, then by national code when the mobile is empty or
+// not found, then created. If Arpa reports it as existing, the existing business is returned.
+func (g *GoArpa) EnsureCustomer(ctx context.Context, accessToken string, cookie []*http.Cookie, customer CreateCustomerRequest) (*EnsureCustomerResult, error) {
+	return ensureCustomer(ctx, g, accessToken, cookie, customer)
+}
+
+func ensureCustomer(ctx context.Context, client GoArpaIface, accessToken string, cookie []*http.Cookie, customer CreateCustomerRequest) (*EnsureCustomerResult, error) {
+	const errMessage = "could not ensure customer"
+
+	var lookups []func() (*GetCustomerResponse, error)
+	if !NilOrEmpty(customer.Mobile) {
+		lookups = append(lookups, func() (*GetCustomerResponse, error) {
+			return client.GetCustomerByMobile(ctx, accessToken, cookie, *customer.Mobile)
+		})
+	}
+	if customer.NationalCode != nil && *customer.NationalCode != "" {
+		lookups = append(lookups, func() (*GetCustomerResponse, error) {
+			return client.GetCustomerByNationalCode(ctx, accessToken, cookie, string(*customer.NationalCode))
+		})
+	}
+	for _, lookup := range lookups {
+		found, err := lookup()
+		if err != nil {
+			return nil, errors.Wrap(err, errMessage)
+		}
+		if datum, ok := found.First(); ok {
+			return &EnsureCustomerResult{
+				BusinessID:   datum.BusinessID,
+				BusinessCode: datum.BusinessCode,
+				Path:         CustomerFound,
+			}, nil
+		}
+	}
+
+	created, err := client.CreateCustomer(ctx, accessToken, cookie, customer)
+	var existsErr *CustomerExistsError
+	switch {
+	case errors.As(err, &existsErr):
+		return &EnsureCustomerResult{
+			BusinessID:   existsErr.BusinessID,
+			BusinessCode: existsErr.BusinessCode,
+			Path:         CustomerExisted,
+		}, nil
+	case err != nil:
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	return &EnsureCustomerResult{
+		BusinessID:   created.Data.BusinessID,
+		BusinessCode: string(created.Data.BusinessCode),
+		Path:         CustomerCreated,
+	}, nil
+}
+
+// GetCustomerBalance returns the account balance of a business
+func (g *GoArpa) GetCustomerBalance(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID) (*CustomerBalance, error) {
+	const errMessage = "could not get customer balance"
+
+	url, err := g.endpointURL(g.config().GetCustomerBalanceEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[CustomerBalance]
+
+	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetQueryParam(string(constant.BusinessIDQueryKey), businessID.String()).
+		SetResult(&result).
+		Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	balance, ok := result.First()
+	if !ok {
+		return &CustomerBalance{BusinessID: businessID}, nil
+	}
+	return &balance, nil
+}
+
+// CheckCustomerCredit decides whether an amount can be sold on credit to a business,
+// combining its credit limit and its balance
+func (g *GoArpa) CheckCustomerCredit(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, amount Money) (*CreditCheckResult, error) {
+	return checkCustomerCredit(ctx, g, accessToken, cookie, businessID, amount)
+}
+
+func checkCustomerCredit(ctx context.Context, client GoArpaIface, accessToken string, cookie []*http.Cookie, businessID BusinessID, amount Money) (*CreditCheckResult, error) {
+	const errMessage = "could not check customer credit"
+
+	customers, err := client.GetCustomerByID(ctx, accessToken, cookie, businessID)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+	customer, ok := customers.First()
+	if !ok {
+		return nil, errors.Wrap(ErrCustomerNotFound, errMessage)
+	}
+
+	balance, err := client.GetCustomerBalance(ctx, accessToken, cookie, customer.BusinessID)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	result := checkCredit(customer, balance.Balance, amount)
+	return &result, nil
+}
+
+// GetBusinessID returns the ID of the business with the code, e.g. for CreateTransactionRequest.Data.BusinessID.
+// The error matches ErrCustomerNotFound when there is no such business.
+func (g *GoArpa) GetBusinessID(ctx context.Context, accessToken string, cookie []*http.Cookie, businessCode string) (BusinessID, error) {
+	return getBusinessID(ctx, g, accessToken, cookie, businessCode)
+}
+
+func getBusinessID(ctx context.Context, client GoArpaIface, accessToken string, cookie []*http.Cookie, businessCode string) (BusinessID, error) {
+	const errMessage = "could not get business ID"
+
+	customers, err := client.GetCustomerByBusinessCode(ctx, accessToken, cookie, businessCode)
+	if err != nil {
+		return 0, errors.Wrap(err, errMessage)
+	}
+	customer, ok := customers.First()
+	if !ok || customer.BusinessID == 0 {
+		return 0, errors.Wrap(ErrCustomerNotFound, errMessage)
+	}
+	return customer.BusinessID, nil
+}
+
+// GetCustomers returns a page of businesses.
+// The page is filtered by params.Inactive and may hold less businesses than the page size.
+func (g *GoArpa) GetCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams) (*GetCustomerResponse, error) {
+	const errMessage = "could not get customers"
+
+	var result GetCustomerResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetCustomersEndpoint, params, &result, errMessage); err != nil {
+		return nil, err
+	}
+
+	filterCustomers(&result, params.Inactive.or(g.inactiveFilter))
+	return &result, nil
+}
+
+// GetTransactions returns a page of transactions
+func (g *GoArpa) GetTransactions(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams) (*GetTransactionsResponse, error) {
+	const errMessage = "could not get transactions"
+
+	var result GetTransactionsResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetTransactionsEndpoint, params, &result, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetItems returns a page of items and services
+func (g *GoArpa) GetItems(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams) (*RetServiceResponse, error) {
+	const errMessage = "could not get items"
+
+	var result RetServiceResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetItemsEndpoint, params, &result, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// IterateCustomers iterates over all the businesses, fetching the pages transparently
+func (g *GoArpa) IterateCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams) iter.Seq2[Customer, error] {
+	filter := params.Inactive.or(g.inactiveFilter)
+	params.Inactive = IncludeInactive
+	return filterSeq(iteratePages(ctx, params.ListParams, func(ctx context.Context, paging ListParams) ([]Customer, error) {
+		params := params
+		params.ListParams = paging
+		result, err := g.GetCustomers(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		customers := make([]Customer, 0, len(result.Data))
+		for _, datum := range result.Data {
+			customers = append(customers, datum.ToCustomer())
+		}
+		return customers, nil
+	}), func(customer Customer) bool {
+		return filter.keep(customer.Inactive)
+	})
+}
+
+// IterateTransactions iterates over all the transactions, fetching the pages transparently
+func (g *GoArpa) IterateTransactions(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams) iter.Seq2[Transaction, error] {
+	return iteratePages(ctx, params.ListParams, func(ctx context.Context, paging ListParams) ([]Transaction, error) {
+		params := params
+		params.ListParams = paging
+		result, err := g.GetTransactions(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+}
+
+// IterateItems iterates over all the items and services, fetching the pages transparently
+func (g *GoArpa) IterateItems(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams) iter.Seq2[GetServiceResponse, error] {
+	return iteratePages(ctx, params.ListParams, func(ctx context.Context, paging ListParams) ([]GetServiceResponse, error) {
+		params := params
+		params.ListParams = paging
+		result, err := g.GetItems(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+}
+
+// getList gets a page of a list endpoint into result
+func (g *GoArpa) getList(ctx context.Context, accessToken string, cookie []*http.Cookie, endpoint string, params interface{}, result interface{}, errMessage string) error {
+	url, err := g.endpointURL(endpoint)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+
+	queryParams, err := GetQueryParams(params)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+
+	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetQueryParams(queryParams).
+		SetResult(result).
+		Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return err
+	}
+
+	if e, ok := result.(envelope); ok {
+		return g.checkForArpaError(resp, e.envelopeError(), errMessage)
+	}
+	return nil
+}
+
+// CreateCustomers creates many businesses with a bulk executor.
+// The responses have the order of the customers, see Bulk.Execute.
+// The failed calls are retried like BulkOptions.Mutating ones, unless the client sends idempotency keys.
+func (g *GoArpa) CreateCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, customers []CreateCustomerRequest, options BulkOptions) ([]*RetCustomerResponse, error) {
+	if options.Clock == nil {
+		options.Clock = g.Clock()
+	}
+	if !g.idempotencyKeys {
+		options.Mutating = true
+	}
+	bulk := NewBulk(func(ctx context.Context, customer CreateCustomerRequest) (*RetCustomerResponse, error) {
+		return g.CreateCustomer(ctx, accessToken, cookie, customer)
+	}, options)
+	return bulk.Execute(ctx, customers)
+}
+
+// CreateTransactions creates many transactions with a bulk executor.
+// The responses have the order of the transactions, see Bulk.Execute.
+// The failed calls are retried like BulkOptions.Mutating ones, unless the client sends idempotency keys.
+func (g *GoArpa) CreateTransactions(ctx context.Context, accessToken string, transactions []CreateTransactionRequest, options BulkOptions) ([]*CreateTransactionResponse, error) {
+	if options.Clock == nil {
+		options.Clock = g.Clock()
+	}
+	if !g.idempotencyKeys {
+		options.Mutating = true
+	}
+	bulk := NewBulk(func(ctx context.Context, transaction CreateTransactionRequest) (*CreateTransactionResponse, error) {
+		return g.CreateTransaction(ctx, accessToken, transaction)
+	}, options)
+	return bulk.Execute(ctx, transactions)
+}
@@ -2,278 +2,704 @@ package goarpa_test
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
-	"io/ioutil"
+	"iter"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
-	"time"
 
 	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/shared/constant"
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/exp/rand"
 )
 
-type configAdmin struct {
-	UserName string `json:"username"`
-	Password string `json:"password"`
+// capturedRequest is a request received by a capture server
+type capturedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Query  url.Values
+	Body   map[string]interface{}
 }
 
-type configUser struct {
-	UserName string `json:"username"`
-	Password string `json:"password"`
-}
-
-type Config struct {
-	HostName string      `json:"hostname"`
-	Proxy    string      `json:"proxy,omitempty"`
-	Admin    configAdmin `json:"admin"`
-	User     configUser  `json:"user"`
-}
-
-var (
-	config     *Config
-	configOnce sync.Once
-	setupOnce  sync.Once
-	testUserID string
-)
-
-type RestyLogWriter struct {
-	io.Writer
-	t testing.TB
+// newCaptureServer returns a server answering all the requests with the status, the content type and the body,
+// and a function returning the requests it received
+func newCaptureServer(t *testing.T, status int, contentType string, body string) (*httptest.Server, func() []capturedRequest) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		requests []capturedRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured := capturedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header, Query: r.URL.Query()}
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		if len(data) > 0 {
+			assert.NoError(t, json.Unmarshal(data, &captured.Body), string(data))
+		}
+		mu.Lock()
+		requests = append(requests, captured)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []capturedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]capturedRequest(nil), requests...)
+	}
 }
 
-func (w *RestyLogWriter) Errorf(format string, v ...interface{}) {
-	w.write("[ERROR] "+format, v...)
+// clientMethodCase is a method of the client and the request it must send
+type clientMethodCase struct {
+	name   string
+	call   func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error
+	method string
+	path   string
+	query  map[string]string
+	// body holds the fields expected in the JSON body
+	body map[string]interface{}
+	// cookie is set when the method sends the cookies of the session
+	cookie bool
+	// response is a successful response of the method
+	response string
 }
 
-func (w *RestyLogWriter) Warnf(format string, v ...interface{}) {
-	w.write("[WARN] "+format, v...)
+var clientMethodCases = []clientMethodCase{
+	{
+		name: "GetAdminToken",
+		call: func(ctx context.Context, client *goarpa.GoArpa, _ []*http.Cookie) error {
+			_, _, err := client.GetAdminToken(ctx, "user", "pass")
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/serv/token/GetServiceToken",
+		query:    map[string]string{"username": "user", "password": "pass"},
+		response: "token",
+	},
+	{
+		name: "CreateCustomer",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.CreateCustomer(ctx, "token", cookie, goarpa.CreateCustomerRequest{BusName: "Ali", Mobile: goarpa.StringP("09120000000")})
+			return err
+		},
+		method:   http.MethodPost,
+		path:     "/serv/api/PostBusiness",
+		body:     map[string]interface{}{"BusName": "Ali", "Mobile": "09120000000"},
+		cookie:   true,
+		response: `{"data":{"BusinessID":"1","BusinessCode":"1001"},"error":null}`,
+	},
+	{
+		name: "CreateTransaction",
+		call: func(ctx context.Context, client *goarpa.GoArpa, _ []*http.Cookie) error {
+			_, err := client.CreateTransaction(ctx, "token", goarpa.CreateTransactionRequest{
+				Data:  goarpa.Data{BusinessID: 1, TransStateID: goarpa.TransStateFinal, FactorTypeID: goarpa.FactorTypeSale},
+				Items: []goarpa.TransactionItem{{ItemID: 7, Qty: 2}},
+			})
+			return err
+		},
+		method:   http.MethodPost,
+		path:     "/serv/api/NewTransaction",
+		body:     map[string]interface{}{"Items": []interface{}{map[string]interface{}{"ItemID": 7.0, "Qty": 2.0}}},
+		response: `{"data":[{"TransactionID":"42","TransNumber":7}],"error":null}`,
+	},
+	{
+		name: "CreateService",
+		call: func(ctx context.Context, client *goarpa.GoArpa, _ []*http.Cookie) error {
+			_, err := client.CreateService(ctx, "token", goarpa.CreateServiceRequest{ServiceName: "Repair", ServiceCode: "S1", ItemCategoryID: 3})
+			return err
+		},
+		method:   http.MethodPost,
+		path:     "/serv/api/PostService",
+		body:     map[string]interface{}{"ServiceName": "Repair", "ServiceCode": "S1", "ItemCategoryID": 3.0},
+		response: `{"ServiceName":"Repair","ItemCategoryId":3}`,
+	},
+	{
+		name: "GetCustomerByMobile",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.GetCustomerByMobile(ctx, "token", cookie, "09120000000")
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/serv/api/GetBusiness",
+		query:    map[string]string{"MobileNo": "09120000000"},
+		cookie:   true,
+		response: `{"data":[{"BusinessID":"1"}],"error":null}`,
+	},
+	{
+		name: "GetCustomerByBusinessCode",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.GetCustomerByBusinessCode(ctx, "token", cookie, "1001")
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/serv/api/GetBusiness",
+		query:    map[string]string{"BusinessCode": "1001"},
+		cookie:   true,
+		response: `{"data":[{"BusinessID":"1"}],"error":null}`,
+	},
+	{
+		name: "GetCustomerByID",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.GetCustomerByID(ctx, "token", cookie, 42)
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/serv/api/GetBusiness",
+		query:    map[string]string{"BusinessID": "42"},
+		cookie:   true,
+		response: `{"data":[{"BusinessID":"42"}],"error":null}`,
+	},
+	{
+		name: "GetCustomerByNationalCode",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.GetCustomerByNationalCode(ctx, "token", cookie, "0012345679")
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/serv/api/GetBusiness",
+		query:    map[string]string{"NationalCode": "0012345679"},
+		cookie:   true,
+		response: `{"data":[{"BusinessID":"1"}],"error":null}`,
+	},
+	{
+		name: "GetServiceByItemCode",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.GetServiceByItemCode(ctx, "token", cookie, "650304")
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/serv/api/GetItem",
+		query:    map[string]string{"ItemCode": "650304"},
+		cookie:   true,
+		response: `{"data":[{"ItemID":"7","ItemCode":"650304"}],"error":null}`,
+	},
+	{
+		name: "UpdateCustomerPartial",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			changes, err := goarpa.DiffCustomers(goarpa.Customer{Name: "Ali"}, goarpa.Customer{Name: "Ali", Email: "ali@example.com"})
+			if err != nil {
+				return err
+			}
+			_, err = client.UpdateCustomerPartial(ctx, "token", cookie, 1, changes)
+			return err
+		},
+		method:   http.MethodPost,
+		path:     "/update",
+		body:     map[string]interface{}{"BusinessId": 1.0, "Email": "ali@example.com"},
+		cookie:   true,
+		response: `{"data":{"BusinessID":"1"},"error":null}`,
+	},
+	{
+		name: "GetCustomerBalance",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.GetCustomerBalance(ctx, "token", cookie, 1)
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/balance",
+		query:    map[string]string{"BusinessID": "1"},
+		cookie:   true,
+		response: `{"data":[{"BusinessID":"1","Balance":1000}],"error":null}`,
+	},
+	{
+		name: "GetCustomers",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.GetCustomers(ctx, "token", cookie, goarpa.GetCustomersParams{ListParams: goarpa.ListParams{Page: 2, PageSize: 10}})
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/customers",
+		query:    map[string]string{"PageNumber": "2", "PageSize": "10"},
+		cookie:   true,
+		response: `{"data":[],"error":null}`,
+	},
+	{
+		name: "GetTransactions",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			businessID := goarpa.BusinessID(1)
+			_, err := client.GetTransactions(ctx, "token", cookie, goarpa.GetTransactionsParams{BusinessID: &businessID})
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/transactions",
+		query:    map[string]string{"BusinessID": "1"},
+		cookie:   true,
+		response: `{"data":[],"error":null}`,
+	},
+	{
+		name: "GetItems",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.GetItems(ctx, "token", cookie, goarpa.GetItemsParams{ListParams: goarpa.ListParams{PageSize: 50}})
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/items",
+		query:    map[string]string{"PageSize": "50"},
+		cookie:   true,
+		response: `{"data":[],"error":null}`,
+	},
+	{
+		name: "CreateCustomers",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.CreateCustomers(ctx, "token", cookie, []goarpa.CreateCustomerRequest{{BusName: "Ali", Mobile: goarpa.StringP("09120000000")}}, goarpa.BulkOptions{})
+			return bulkItemError(err)
+		},
+		method:   http.MethodPost,
+		path:     "/serv/api/PostBusiness",
+		body:     map[string]interface{}{"BusName": "Ali", "Mobile": "09120000000"},
+		cookie:   true,
+		response: `{"data":{"BusinessID":"1","BusinessCode":"1001"},"error":null}`,
+	},
+	{
+		name: "CreateTransactions",
+		call: func(ctx context.Context, client *goarpa.GoArpa, _ []*http.Cookie) error {
+			_, err := client.CreateTransactions(ctx, "token", []goarpa.CreateTransactionRequest{{
+				Data:  goarpa.Data{BusinessID: 1, TransStateID: goarpa.TransStateFinal, FactorTypeID: goarpa.FactorTypeSale},
+				Items: []goarpa.TransactionItem{{ItemID: 7, Qty: 2}},
+			}}, goarpa.BulkOptions{})
+			return bulkItemError(err)
+		},
+		method:   http.MethodPost,
+		path:     "/serv/api/NewTransaction",
+		body:     map[string]interface{}{"Items": []interface{}{map[string]interface{}{"ItemID": 7.0, "Qty": 2.0}}},
+		response: `{"data":[{"TransactionID":"42","TransNumber":7}],"error":null}`,
+	},
+	{
+		name: "ExportTransactions",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			businessID := goarpa.BusinessID(1)
+			return exportError(client.ExportTransactions(ctx, "token", cookie, goarpa.GetTransactionsParams{BusinessID: &businessID, ListParams: goarpa.ListParams{PageSize: 10}}, 1))
+		},
+		method:   http.MethodGet,
+		path:     "/transactions",
+		query:    map[string]string{"BusinessID": "1", "PageNumber": "1", "PageSize": "10"},
+		cookie:   true,
+		response: `{"data":[{"TransactionID":"42"}],"error":null}`,
+	},
+	{
+		name: "ExportItems",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			return exportError(client.ExportItems(ctx, "token", cookie, goarpa.GetItemsParams{ListParams: goarpa.ListParams{PageSize: 50}}, 1))
+		},
+		method:   http.MethodGet,
+		path:     "/items",
+		query:    map[string]string{"PageNumber": "1", "PageSize": "50"},
+		cookie:   true,
+		response: `{"data":[{"ItemID":"7","ItemCode":"650304"}],"error":null}`,
+	},
+	{
+		name: "ExportItemsCSV",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			var out strings.Builder
+			return client.ExportItemsCSV(ctx, "token", cookie, goarpa.GetItemsParams{ListParams: goarpa.ListParams{PageSize: 50}}, &out, nil)
+		},
+		method:   http.MethodGet,
+		path:     "/items",
+		query:    map[string]string{"PageNumber": "1", "PageSize": "50"},
+		cookie:   true,
+		response: `{"data":[{"ItemID":"7","ItemCode":"650304"}],"error":null}`,
+	},
+	{
+		name: "IterateTransactions",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			businessID := goarpa.BusinessID(1)
+			return iterError(client.IterateTransactions(ctx, "token", cookie, goarpa.GetTransactionsParams{BusinessID: &businessID, ListParams: goarpa.ListParams{PageSize: 10}}))
+		},
+		method:   http.MethodGet,
+		path:     "/transactions",
+		query:    map[string]string{"BusinessID": "1", "PageNumber": "1", "PageSize": "10"},
+		cookie:   true,
+		response: `{"data":[{"TransactionID":"42"}],"error":null}`,
+	},
+	{
+		name: "IterateItems",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			return iterError(client.IterateItems(ctx, "token", cookie, goarpa.GetItemsParams{ListParams: goarpa.ListParams{PageSize: 50}}))
+		},
+		method:   http.MethodGet,
+		path:     "/items",
+		query:    map[string]string{"PageNumber": "1", "PageSize": "50"},
+		cookie:   true,
+		response: `{"data":[{"ItemID":"7","ItemCode":"650304"}],"error":null}`,
+	},
+	{
+		name: "IteratePayments",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			return iterError(client.IteratePayments(ctx, "token", cookie, goarpa.GetCashMovementsParams{ListParams: goarpa.ListParams{PageSize: 20}}))
+		},
+		method:   http.MethodGet,
+		path:     "/payments",
+		query:    map[string]string{"PageNumber": "1", "PageSize": "20"},
+		cookie:   true,
+		response: `{"data":[],"error":null}`,
+	},
+	{
+		name: "GetTransactionsStream",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			businessID := goarpa.BusinessID(1)
+			return client.GetTransactionsStream(ctx, "token", cookie, goarpa.GetTransactionsParams{BusinessID: &businessID}, func(goarpa.Transaction) error {
+				return nil
+			})
+		},
+		method:   http.MethodGet,
+		path:     "/transactions",
+		query:    map[string]string{"BusinessID": "1"},
+		cookie:   true,
+		response: `{"data":[{"TransactionID":"42"}],"error":null}`,
+	},
+	{
+		name: "ImportCustomersCSV",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			result, err := client.ImportCustomersCSV(ctx, "token", cookie, strings.NewReader("نام,ایمیل\nAli,ali@example.com\n"), goarpa.CSVMapping{"Name": "نام", "Email": "ایمیل"})
+			if err != nil {
+				return err
+			}
+			if len(result.Errors) > 0 {
+				return &result.Errors[0]
+			}
+			return nil
+		},
+		method:   http.MethodPost,
+		path:     "/serv/api/PostBusiness",
+		body:     map[string]interface{}{"BusName": "Ali", "Email": "ali@example.com"},
+		cookie:   true,
+		response: `{"data":{"BusinessID":"1","BusinessCode":"1001"},"error":null}`,
+	},
+	{
+		name: "GetReportJob",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.GetReportJob(ctx, "token", cookie, "job-1")
+			return err
+		},
+		method:   http.MethodGet,
+		path:     "/report-job",
+		query:    map[string]string{"JobID": "job-1"},
+		cookie:   true,
+		response: `{"data":[{"JobID":"job-1","Status":"ready"}],"error":null}`,
+	},
+	{
+		name: "SubmitReportJob",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.SubmitReportJob(ctx, "token", cookie, goarpa.ReportJobRequest{ReportName: "stock"})
+			return err
+		},
+		method:   http.MethodPost,
+		path:     "/report",
+		body:     map[string]interface{}{"ReportName": "stock"},
+		cookie:   true,
+		response: `{"data":[{"JobID":"job-1","Status":"queued"}],"error":null}`,
+	},
+	{
+		name: "OpenShift",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.OpenShift(ctx, "token", cookie, goarpa.OpenShiftRequest{CashierID: 3})
+			return err
+		},
+		method:   http.MethodPost,
+		path:     "/shift/open",
+		body:     map[string]interface{}{"CashierID": 3.0},
+		cookie:   true,
+		response: `{"data":[{"ShiftID":7}],"error":null}`,
+	},
+	{
+		name: "CloseShift",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.CloseShift(ctx, "token", cookie, goarpa.CloseShiftRequest{ShiftID: 7})
+			return err
+		},
+		method:   http.MethodPost,
+		path:     "/shift/close",
+		body:     map[string]interface{}{"ShiftID": 7.0},
+		cookie:   true,
+		response: `{"data":[{"ShiftID":7}],"error":null}`,
+	},
+	{
+		name: "VoidTransaction",
+		call: func(ctx context.Context, client *goarpa.GoArpa, cookie []*http.Cookie) error {
+			_, err := client.VoidTransaction(ctx, "token", cookie, goarpa.VoidTransactionRequest{TransactionID: 42})
+			return err
+		},
+		method:   http.MethodPost,
+		path:     "/void",
+		cookie:   true,
+		response: `{"data":[{"TransactionID":"42"}],"error":null}`,
+	},
 }
 
-func (w *RestyLogWriter) Debugf(format string, v ...interface{}) {
-	w.write("[DEBUG] "+format, v...)
+// newMethodTestClient returns a client of the server with the optional endpoints of clientMethodCases
+func newMethodTestClient(server *httptest.Server, options ...func(*goarpa.GoArpa)) *goarpa.GoArpa {
+	client := goarpa.NewClient(server.URL, options...)
+	client.Config.UpdateCustomerEndpoint = "update"
+	client.Config.GetCustomerBalanceEndpoint = "balance"
+	client.Config.GetCustomersEndpoint = "customers"
+	client.Config.GetTransactionsEndpoint = "transactions"
+	client.Config.GetItemsEndpoint = "items"
+	client.Config.GetPaymentsEndpoint = "payments"
+	client.Config.GetReportJobEndpoint = "report-job"
+	client.Config.SubmitReportJobEndpoint = "report"
+	client.Config.OpenShiftEndpoint = "shift/open"
+	client.Config.CloseShiftEndpoint = "shift/close"
+	client.Config.VoidTransactionEndpoint = "void"
+	return client
 }
 
-func (w *RestyLogWriter) write(format string, v ...interface{}) {
-	w.t.Logf(format, v...)
+// exportError drains the results of an exporter and returns the first error
+func exportError[T any](results <-chan goarpa.ExportResult[T]) error {
+	var err error
+	for result := range results {
+		if result.Err != nil && err == nil {
+			err = result.Err
+		}
+	}
+	return err
 }
 
-func GetConfig(t testing.TB) *Config {
-	configOnce.Do(func() {
-		rand.Seed(uint64(time.Now().UTC().UnixNano()))
-		configFileName, ok := os.LookupEnv("GOARPA_TEST_CONFIG")
-		if !ok {
-			configFileName = filepath.Join("testdata", "config.json")
-		}
-		configFile, err := os.Open(configFileName)
-		require.NoError(t, err, "cannot open config.json")
-		defer func() {
-			err := configFile.Close()
-			require.NoError(t, err, "cannot close config file")
-		}()
-		data, err := ioutil.ReadAll(configFile)
-		require.NoError(t, err, "cannot read config.json")
-		config = &Config{}
-		err = json.Unmarshal(data, config)
-		require.NoError(t, err, "cannot parse config.json")
-		http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-		if len(config.Proxy) != 0 {
-			proxy, err := url.Parse(config.Proxy)
-			require.NoError(t, err, "incorrect proxy url: "+config.Proxy)
-			http.DefaultTransport.(*http.Transport).Proxy = http.ProxyURL(proxy)
-		}
-	})
-	return config
+// bulkItemError returns the error of the first failed item of a bulk execution
+func bulkItemError(err error) error {
+	var bulkErr *goarpa.BulkError
+	if errors.As(err, &bulkErr) && len(bulkErr.Errors) > 0 {
+		return bulkErr.Errors[0].Err
+	}
+	return err
 }
 
-func NewClientWithDebug(t testing.TB) *goarpa.GoArpa {
-	cfg := GetConfig(t)
-	client := goarpa.NewClient(cfg.HostName)
-	cond := func(resp *resty.Response, err error) bool {
-		if resp != nil && resp.IsError() {
-			if e, ok := resp.Error().(*goarpa.HTTPErrorResponse); ok {
-				msg := e.String()
-				return strings.Contains(msg, "Cached clientScope not found") || strings.Contains(msg, "unknown_error")
-			}
+// iterError iterates over the sequence and returns its error
+func iterError[T any](seq iter.Seq2[T, error]) error {
+	for _, err := range seq {
+		if err != nil {
+			return err
 		}
-		return false
 	}
-
-	restyClient := client.RestyClient()
-
-	// restyClient.AddRetryCondition(
-	// 	func(r *resty.Response, err error) bool {
-	// 		if err != nil || r.RawResponse.StatusCode == 500 || r.RawResponse.StatusCode == 502 {
-	// 			return true
-	// 		}
-
-	// 		return false
-	// 	},
-	// ).SetRetryCount(5).SetRetryWaitTime(10 * time.Millisecond)
-
-	restyClient.
-		// SetDebug(true).
-		SetLogger(&RestyLogWriter{
-			t: t,
-		}).
-		SetRetryCount(10).
-		SetRetryWaitTime(2 * time.Second).
-		AddRetryCondition(cond)
-
-	return client
+	return nil
 }
 
-// FailRequest fails requests and returns an error
-//
-//	err - returned error or nil to return the default error
-//	failN - number of requests to be failed
-//	skipN = number of requests to be executed and not failed by this function
-func FailRequest(client *goarpa.GoArpa, err error, failN, skipN int) *goarpa.GoArpa {
-	client.RestyClient().OnBeforeRequest(
-		func(c *resty.Client, r *resty.Request) error {
-			if skipN > 0 {
-				skipN--
-				return nil
+func Test_ClientMethods(t *testing.T) {
+	t.Parallel()
+	cookie := []*http.Cookie{{Name: "ASP.NET_SessionId", Value: "session"}}
+	for _, tc := range clientMethodCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			contentType := "application/json"
+			if tc.name == "GetAdminToken" {
+				contentType = "text/plain"
 			}
-			if failN == 0 {
-				return nil
+			server, requests := newCaptureServer(t, http.StatusOK, contentType, tc.response)
+			client := newMethodTestClient(server)
+			require.NoError(t, tc.call(context.Background(), client, cookie))
+
+			received := requests()
+			require.Len(t, received, 1)
+			req := received[0]
+			assert.Equal(t, tc.method, req.Method)
+			assert.Equal(t, tc.path, req.Path)
+			for key, value := range tc.query {
+				assert.Equal(t, value, req.Query.Get(key), key)
 			}
-			failN--
-			if err == nil {
-				err = fmt.Errorf("an error for request: %+v", r)
+			for key, value := range tc.body {
+				assert.Equal(t, value, req.Body[key], key)
 			}
-			return err
-		},
-	)
-	return client
+			if tc.method == http.MethodPost {
+				assert.Contains(t, req.Header.Get("Content-Type"), "application/json")
+			}
+			if tc.name != "GetAdminToken" {
+				assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+			}
+			if tc.cookie {
+				assert.Contains(t, req.Header.Get("Cookie"), "ASP.NET_SessionId=session")
+			}
+		})
+	}
 }
 
-func GetToken(t testing.TB, client *goarpa.GoArpa) (string, []*http.Cookie) {
-	cfg := GetConfig(t)
-	token, cookie, err := client.GetAdminToken(
-		context.Background(),
-		cfg.Admin.UserName,
-		cfg.Admin.Password,
-	)
-	require.NoError(t, err, "Login failed")
-	require.NotEmpty(t, token, "Got an empty token")
-	return token, cookie
+// Test_ClientMutatingMethods checks that every POST of clientMethodCases is skipped in dry-run mode and audited
+func Test_ClientMutatingMethods(t *testing.T) {
+	t.Parallel()
+	cookie := []*http.Cookie{{Name: "ASP.NET_SessionId", Value: "session"}}
+	for _, tc := range clientMethodCases {
+		if tc.method != http.MethodPost {
+			continue
+		}
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			server, requests := newCaptureServer(t, http.StatusOK, "application/json", tc.response)
+
+			client := newMethodTestClient(server, goarpa.WithDryRun())
+			require.NoError(t, tc.call(context.Background(), client, cookie))
+			assert.Empty(t, requests(), "sent in dry-run mode")
+
+			sink := &memoryAuditSink{}
+			client = newMethodTestClient(server, goarpa.WithAuditSink(sink, nil))
+			require.NoError(t, tc.call(context.Background(), client, cookie))
+			require.Len(t, sink.records, 1)
+			assert.Equal(t, http.MethodPost, sink.records[0].Method)
+			assert.Equal(t, tc.path, sink.records[0].Endpoint)
+		})
+	}
 }
 
-// ---------
-// API tests
-// ---------
-
-func Test_GetAdminToken(t *testing.T) {
+func Test_ClientMethodErrors(t *testing.T) {
 	t.Parallel()
-	cfg := GetConfig(t)
-	client := NewClientWithDebug(t)
-
-	// Obtain the token from AdminAuthenticate
-	newToken, cookie, err := client.GetAdminToken(
-		context.Background(),
-		cfg.Admin.UserName,
-		cfg.Admin.Password,
-	)
-
-	require.NoError(t, err, "Login failed")
-	require.NotEmpty(t, newToken, "Got an empty token")
-	require.NotEmpty(t, cookie, "Got an empty cookie")
-
-	t.Logf("New token: %s", newToken)
-	t.Logf("New cookie: %s", cookie)
+	for _, tc := range clientMethodCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			// an error page of IIS
+			server, _ := newCaptureServer(t, http.StatusInternalServerError, "text/html", "<html><body><h1>Server Error</h1></body></html>")
+			err := tc.call(ctx, newMethodTestClient(server), nil)
+			var apiErr *goarpa.APIError
+			require.True(t, errors.As(err, &apiErr), "%v", err)
+			assert.Equal(t, http.StatusInternalServerError, apiErr.Code)
+
+			// an error in the envelope of a successful response
+			server, _ = newCaptureServer(t, http.StatusOK, "application/json", `{"data":null,"error":{"code":"E12","message":"invalid request"}}`)
+			err = tc.call(ctx, newMethodTestClient(server), nil)
+			require.True(t, errors.As(err, &apiErr), "%v", err)
+			assert.Equal(t, goarpa.APIErrTypeArpa, apiErr.Type)
+			assert.ErrorContains(t, err, "E12: invalid request")
+		})
+	}
 }
 
-func Test_GetCustomerByMobile(t *testing.T) {
+func Test_ParseBasePath(t *testing.T) {
 	t.Parallel()
-	client := NewClientWithDebug(t)
-	token, cookie := GetToken(t, client)
-
-	customerInfo, err := client.GetCustomerByMobile(
-		context.Background(),
-		token,
-		cookie,
-		"09128575183",
-	)
-	require.NoError(t, err, "Expected no error when fetching valid customer info")
-	require.NotNil(t, customerInfo, "Expected customer info, got nil")
-	t.Logf("Customer Info: %+v", customerInfo)
-
-	FailRequest(client, nil, 1, 0)
-
-	_, err = client.GetCustomerByMobile(
-		context.Background(),
-		token,
-		cookie,
-		"09128575183",
-	)
-	require.Error(t, err, "Expected an error when request fails")
+	for input, expected := range map[string]string{
+		"https://host":               "https://host",
+		"https://host/arpa/":         "https://host/arpa",
+		" http://host:8080//arpa// ": "http://host:8080/arpa",
+	} {
+		basePath, err := goarpa.ParseBasePath(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, basePath, input)
+	}
+	for _, input := range []string{"", "host/arpa", "ftp://host", "https:///arpa", "https://host/arpa?x=1", "http://[::1"} {
+		_, err := goarpa.ParseBasePath(input)
+		assert.True(t, errors.Is(err, goarpa.ErrInvalidBasePath), input)
+	}
 
-	assert.Contains(t, err.Error(), "could not get customer info", "Error message mismatch")
+	_, err := goarpa.NewValidatedClient("host")
+	assert.ErrorContains(t, err, `invalid base path "host": the scheme must be http or https`)
 }
 
-func Test_GetCustomerByBusinessCode(t *testing.T) {
+func Test_APIPrefix(t *testing.T) {
 	t.Parallel()
-	client := NewClientWithDebug(t)
-	token, cookie := GetToken(t, client)
-
-	customerInfo, err := client.GetCustomerByBusinessCode(
-		context.Background(),
-		token,
-		cookie,
-		"127013",
-	)
-	require.NoError(t, err, "Expected no error when fetching valid customer info")
-	require.NotNil(t, customerInfo, "Expected customer info, got nil")
-	t.Logf("Customer Info: %+v", customerInfo)
-
-	FailRequest(client, nil, 1, 0)
-
-	_, err = client.GetCustomerByBusinessCode(
-		context.Background(),
-		token,
-		cookie,
-		"127013",
-	)
-	require.Error(t, err, "Expected an error when request fails")
-
-	assert.Contains(t, err.Error(), "could not get customer info", "Error message mismatch")
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := goarpa.NewValidatedClient(server.URL+"/arpa/", goarpa.WithAPIPrefix("/api/v2/"))
+	require.NoError(t, err)
+	client.Config.GetCustomersEndpoint = "/custom/customers"
+	ctx := context.Background()
+
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.NoError(t, err)
+	_, err = client.GetCustomers(ctx, "token", nil, goarpa.GetCustomersParams{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/arpa/api/v2/api/GetBusiness", "/arpa/custom/customers"}, paths)
 }
 
-func Test_GetServiceByItemCode(t *testing.T) {
+func Test_RequestDecorators(t *testing.T) {
 	t.Parallel()
-	client := NewClientWithDebug(t)
-	token, cookie := GetToken(t, client)
-
-	customerInfo, err := client.GetServiceByItemCode(
-		context.Background(),
-		token,
-		cookie,
-		"650304",
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "3", r.Header.Get(goarpa.BranchHeader))
+		assert.Equal(t, "1403", r.Header.Get(goarpa.FiscalPeriodHeader))
+		assert.Equal(t, "pos-1", r.Header.Get("X-Terminal"))
+		if r.URL.Path == "/serv/token/GetServiceToken" {
+			_, _ = w.Write([]byte("token"))
+			return
+		}
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL,
+		goarpa.WithBranch(3),
+		goarpa.WithFiscalPeriod(1403),
+		goarpa.WithRequestDecorator(func(req *resty.Request) {
+			req.SetHeader("X-Terminal", "pos-1")
+		}),
 	)
-	require.NoError(t, err, "Expected no error when fetching valid service info")
-	require.NotNil(t, customerInfo, "Expected service info, got nil")
-	t.Logf("Service Info: %+v", customerInfo)
-
-	FailRequest(client, nil, 1, 0)
+	ctx := context.Background()
+	token, _, err := client.GetAdminToken(ctx, "user", "pass")
+	require.NoError(t, err)
+	_, err = client.GetCustomerByMobile(ctx, token, nil, "09120000000")
+	require.NoError(t, err)
+	_, err = client.CreateTransaction(ctx, token, goarpa.CreateTransactionRequest{Data: goarpa.Data{TransStateID: goarpa.TransStateDraft, FactorTypeID: goarpa.FactorTypeSale}})
+	require.NoError(t, err)
+}
 
-	_, err = client.GetServiceByItemCode(
-		context.Background(),
-		token,
-		cookie,
-		"650304",
-	)
-	require.Error(t, err, "Expected an error when request fails")
+func Test_WithLocale(t *testing.T) {
+	t.Parallel()
+	server, requests := newCaptureServer(t, http.StatusOK, "application/json", `{"data":[{"TransactionID":"42"}],"error":null}`)
+
+	client := goarpa.NewClient(server.URL, goarpa.WithLocale(goarpa.LocaleFa))
+	ctx := context.Background()
+	_, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.NoError(t, err)
+	_, err = client.CreateTransaction(ctx, "token", goarpa.CreateTransactionRequest{})
+	require.NoError(t, err)
+
+	// the culture parameter can be renamed
+	client = goarpa.NewClient(server.URL, goarpa.WithLocale(goarpa.LocaleEn), goarpa.WithQueryKeyNames(map[constant.QueryKey]string{constant.CultureKey: "lang"}))
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.NoError(t, err)
+
+	received := requests()
+	require.Len(t, received, 3)
+	for _, req := range received[:2] {
+		assert.Equal(t, "fa", req.Header.Get("Accept-Language"), req.Path)
+		assert.Equal(t, "fa-IR", req.Query.Get("culture"), req.Path)
+	}
+	assert.Equal(t, "09120000000", received[0].Query.Get("MobileNo"))
+	assert.Equal(t, "en", received[2].Header.Get("Accept-Language"))
+	assert.Equal(t, "en-US", received[2].Query.Get("lang"))
+	assert.False(t, received[2].Query.Has("culture"))
+}
 
-	assert.Contains(t, err.Error(), "could not get service info", "Error message mismatch")
+func Test_WithCallBranch(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/branches":
+			assert.Equal(t, "1", r.Header.Get(goarpa.BranchHeader))
+			_, _ = w.Write([]byte(`{"data":[{"BranchID":"1","BranchName":"Tehran","StockID":"3"},{"BranchID":"2","BranchName":"Tabriz","StockID":"4"}],"error":null}`))
+		case "/stock":
+			assert.Equal(t, "2", r.Header.Get(goarpa.BranchHeader))
+			_, _ = w.Write([]byte(`{"data":[{"StockID":"4","Qty":"6"}],"error":null}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithBranch(1))
+	client.Config.GetBranchesEndpoint = "branches"
+	client.Config.GetItemStockEndpoint = "stock"
+	ctx := context.Background()
+	branches, err := client.GetBranches(ctx, "token", nil)
+	require.NoError(t, err)
+	require.Len(t, branches.Data, 2)
+	assert.Equal(t, goarpa.StringInt64(4), branches.Data[1].WarehouseID)
+
+	branch := branches.Data[1]
+	stock, err := client.GetItemStock(goarpa.WithCallBranch(ctx, branch.ID.Int64()), "token", nil, 7, branch.WarehouseID.Int64())
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.EnforcedFloat(6), stock.Qty)
 }
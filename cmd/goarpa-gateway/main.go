@@ -0,0 +1,70 @@
+// Command goarpa-gateway serves clean JSON APIs in front of an Arpa server.
+//
+//	ARPA_USERNAME=user ARPA_PASSWORD=secret goarpa-gateway -url https://arpa.example.com -addr :8080 -rate 10
+//
+// The credentials are read from the environment so that they do not show up in the process list,
+// or from the file of -credentials, which may be encrypted, see the credentials package.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/credentials"
+	"github.com/erfandiakoo/goarpa/v2/gateway"
+)
+
+func main() {
+	var (
+		addr    = flag.String("addr", ":8080", "listen address")
+		baseURL = flag.String("url", "", "base URL of the Arpa server")
+		rate    = flag.Float64("rate", 0, "maximum requests per second forwarded to Arpa, unlimited when zero")
+		burst   = flag.Int("burst", 1, "requests allowed above the rate")
+		timeout = flag.Duration("timeout", 30*time.Second, "timeout of the calls to Arpa")
+		creds   = flag.String("credentials", "", "credentials file used when ARPA_USERNAME and ARPA_PASSWORD are not set")
+	)
+	flag.Parse()
+
+	if *baseURL == "" {
+		log.Fatal("-url is required")
+	}
+	admin, err := credentials.Load(*creds)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := goarpa.NewValidatedClient(*baseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client.RestyClient().SetTimeout(*timeout)
+	tokens := goarpa.NewTokenManager(client, admin.Username, admin.Password, goarpa.TokenManagerOptions{})
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           gateway.New(client, tokens, gateway.Options{Rate: *rate, Burst: *burst}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("listening on %s", *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
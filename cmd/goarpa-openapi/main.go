@@ -0,0 +1,67 @@
+// Command goarpa-openapi prints the OpenAPI 3 document of the Arpa endpoints known by goarpa.
+//
+//	goarpa-openapi -version 1.0.0 -endpoint GetCustomersEndpoint=serv/api/GetBusinesses -o arpa.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/openapi"
+)
+
+// endpoints collects the -endpoint flags
+type endpoints map[string]string
+
+func (e endpoints) String() string {
+	return fmt.Sprint(map[string]string(e))
+}
+
+func (e endpoints) Set(value string) error {
+	name, endpoint, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected Name=endpoint, got %q", value)
+	}
+	e[name] = endpoint
+	return nil
+}
+
+func main() {
+	var (
+		output    = flag.String("o", "", "output file, stdout by default")
+		version   = flag.String("version", "dev", "version of the document")
+		overrides = endpoints{}
+	)
+	flag.Var(overrides, "endpoint", "endpoint of the client config, e.g. GetCustomersEndpoint=serv/api/GetBusinesses (repeatable)")
+	flag.Parse()
+
+	client := goarpa.NewClient("")
+	config := reflect.ValueOf(&client.Config).Elem()
+	for name, endpoint := range overrides {
+		field := config.FieldByName(name)
+		if !field.IsValid() || field.Kind() != reflect.String {
+			log.Fatalf("unknown endpoint %s", name)
+		}
+		field.SetString(endpoint)
+	}
+
+	data, err := openapi.Generate(client, *version).MarshalIndent()
+	if err != nil {
+		log.Fatal(err)
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(*output, data, 0o644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
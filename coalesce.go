@@ -0,0 +1,111 @@
+package goarpa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// WithRequestCoalescing makes identical GET requests in flight at the same time share a single upstream call,
+// e.g. when a burst of terminals looks up the same customer. The requests are identical when they have
+// the same URL, query params, token, cookies and language. Each caller gets its own copy of the response.
+func WithRequestCoalescing() func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.coalesceReads = true
+	}
+}
+
+// coalescingTransport shares the response of a GET between the identical requests waiting for it
+type coalescingTransport struct {
+	next http.RoundTripper
+
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+// coalescedCall is an upstream call whose response is shared
+type coalescedCall struct {
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// wrapCoalescingTransport installs the coalescing below the charset conversion, once
+func wrapCoalescingTransport(restyClient *resty.Client) {
+	charset, ok := restyClient.GetClient().Transport.(*charsetTransport)
+	if !ok {
+		return
+	}
+	if _, ok := charset.next.(*coalescingTransport); ok {
+		return
+	}
+	charset.next = &coalescingTransport{next: charset.next, calls: make(map[string]*coalescedCall)}
+}
+
+func (t *coalescingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the downloads and the streams are not buffered
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" || req.Context().Value(streamingContextKey) != nil {
+		return t.next.RoundTrip(req)
+	}
+
+	key := coalescingKey(req)
+	t.mu.Lock()
+	if call, ok := t.calls[key]; ok {
+		t.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		// the call was canceled by the context of the first caller, not by ours
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			return t.next.RoundTrip(req)
+		}
+		return call.response(req)
+	}
+	call := &coalescedCall{done: make(chan struct{})}
+	t.calls[key] = call
+	t.mu.Unlock()
+
+	call.resp, call.err = t.next.RoundTrip(req)
+	if call.err == nil {
+		call.body, call.err = io.ReadAll(call.resp.Body)
+		_ = call.resp.Body.Close()
+	}
+
+	t.mu.Lock()
+	delete(t.calls, key)
+	t.mu.Unlock()
+	close(call.done)
+
+	return call.response(req)
+}
+
+// response returns a copy of the shared response for the request
+func (c *coalescedCall) response(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	resp := *c.resp
+	resp.Header = c.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	resp.Request = req
+	return &resp, nil
+}
+
+func coalescingKey(req *http.Request) string {
+	return strings.Join([]string{
+		req.URL.String(),
+		req.Header.Get("Authorization"),
+		req.Header.Get("Cookie"),
+		req.Header.Get("Accept"),
+		req.Header.Get("Accept-Language"),
+	}, "\n")
+}
@@ -0,0 +1,58 @@
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RequestCoalescing(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// keep the first call in flight while the others arrive
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"BusinessID":"42","BusName":"Ali"}]}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithRequestCoalescing())
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	responses := make([]*goarpa.GetCustomerResponse, 10)
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+			assert.NoError(t, err)
+			responses[i] = response
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+	for _, response := range responses {
+		require.NotNil(t, response)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, goarpa.BusinessID(42), response.Data[0].BusinessID)
+	}
+	// every caller decodes its own copy
+	assert.NotSame(t, &responses[0].Data[0], &responses[1].Data[0])
+
+	// a different lookup is not coalesced
+	_, err := client.GetCustomerByMobile(ctx, "token", nil, "09121111111")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}
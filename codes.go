@@ -0,0 +1,112 @@
+package goarpa
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NationalCode is an Iranian national code.
+// Leading zeros are significant, so it is kept as a string. Bare JSON numbers are accepted and zero padded.
+type NationalCode string
+
+// Validate checks the length and the check digit of the national code
+func (n NationalCode) Validate() error {
+	code := string(n)
+	if len(code) != 10 || !isDigits(code) {
+		return fmt.Errorf("invalid national code %q: must be 10 digits", code)
+	}
+
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(code[i]-'0') * (10 - i)
+	}
+	check := int(code[9] - '0')
+	if r := sum % 11; (r < 2 && check != r) || (r >= 2 && check != 11-r) {
+		return fmt.Errorf("invalid national code %q: wrong check digit", code)
+	}
+	return nil
+}
+
+// MarshalJSON validates the national code and marshals it as a JSON string
+func (n NationalCode) MarshalJSON() ([]byte, error) {
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(n))
+}
+
+// UnmarshalJSON accepts a JSON string or a number
+func (n *NationalCode) UnmarshalJSON(data []byte) error {
+	code, err := unmarshalNumericCode(data)
+	if err != nil {
+		return fmt.Errorf("invalid national code: %w", err)
+	}
+	if code != "" && len(code) < 10 {
+		code = strings.Repeat("0", 10-len(code)) + code
+	}
+	*n = NationalCode(code)
+	return nil
+}
+
+// NumericCode is a code made of digits, e.g. an ID number or a financial code,
+// which is kept as a string to preserve leading zeros
+type NumericCode string
+
+// Validate checks that the code only contains digits
+func (c NumericCode) Validate() error {
+	if !isDigits(string(c)) {
+		return fmt.Errorf("invalid code %q: must only contain digits", string(c))
+	}
+	return nil
+}
+
+// MarshalJSON validates the code and marshals it as a JSON string
+func (c NumericCode) MarshalJSON() ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(c))
+}
+
+// UnmarshalJSON accepts a JSON string or a number
+func (c *NumericCode) UnmarshalJSON(data []byte) error {
+	code, err := unmarshalNumericCode(data)
+	if err != nil {
+		return fmt.Errorf("invalid code: %w", err)
+	}
+	*c = NumericCode(code)
+	return nil
+}
+
+func unmarshalNumericCode(data []byte) (string, error) {
+	if string(data) == "null" {
+		return "", nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var code string
+		err := json.Unmarshal(data, &code)
+		return code, err
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return "", err
+	}
+	if _, err := number.Int64(); err != nil {
+		return "", err
+	}
+	return number.String(), nil
+}
+
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
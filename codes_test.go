@@ -0,0 +1,29 @@
+package goarpa_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NationalCode(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, goarpa.NationalCode("0012345679").Validate())
+	assert.Error(t, goarpa.NationalCode("0012345678").Validate())
+	assert.Error(t, goarpa.NationalCode("12345").Validate())
+
+	var request goarpa.CreateCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"NationalCode":12345679,"IDNo":"00123"}`), &request))
+	assert.Equal(t, goarpa.NationalCode("0012345679"), *request.NationalCode)
+	assert.Equal(t, goarpa.NumericCode("00123"), *request.IDNo)
+
+	b, err := json.Marshal(request.NationalCode)
+	require.NoError(t, err)
+	assert.Equal(t, `"0012345679"`, string(b))
+
+	_, err = json.Marshal(goarpa.ToPtr(goarpa.NationalCode("0012345678")))
+	assert.Error(t, err)
+}
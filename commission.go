@@ -0,0 +1,67 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RepresentorSalesParams select the period and the representors of GetRepresentorSales
+type RepresentorSalesParams struct {
+	FromDate *time.Time `json:"FromDate,omitempty"`
+	ToDate   *time.Time `json:"ToDate,omitempty"`
+	// RepresentorID restricts the report to a representor, all the representors with sales by default
+	RepresentorID *int64 `json:"RepresentorID,omitempty"`
+}
+
+// RepresentorSales are the sales of a representor in a period, see Customer.RepresentorID
+type RepresentorSales struct {
+	RepresentorID   StringInt64    `json:"RepresentorID"`
+	RepresentorCode EnforcedString `json:"RepresentorCode"`
+	RepresentorName EnforcedString `json:"RepresentorName"`
+	// InvoiceCount is the number of sale invoices
+	InvoiceCount EnforcedInt `json:"InvoiceCount"`
+	// SalesAmount is the total of the sale invoices
+	SalesAmount Money `json:"SalesAmount"`
+	// ReturnsAmount is the total of the sale returns
+	ReturnsAmount Money `json:"ReturnsAmount"`
+	// DiscountAmount is the total of the discounts of the sale invoices
+	DiscountAmount Money `json:"DiscountAmount"`
+}
+
+// NetAmount returns the sales net of the returns
+func (s RepresentorSales) NetAmount() Money {
+	return s.SalesAmount.Sub(s.ReturnsAmount)
+}
+
+// Commission returns the percent of the net amount
+func (s RepresentorSales) Commission(percent float64) Money {
+	return s.NetAmount().Percent(percent)
+}
+
+// RepresentorSalesResponse is the response of GetRepresentorSales
+type RepresentorSalesResponse = APIResponse[RepresentorSales]
+
+// GetRepresentorSales returns the sales of the representors in the period ordered by representor, e.g. for
+// a commission run. It returns ErrNotSupported when the installation has no representor sales endpoint.
+func (g *GoArpa) GetRepresentorSales(ctx context.Context, accessToken string, cookie []*http.Cookie, params RepresentorSalesParams) ([]RepresentorSales, error) {
+	const errMessage = "could not get representor sales"
+
+	if params.FromDate != nil && params.ToDate != nil && params.ToDate.Before(*params.FromDate) {
+		return nil, errors.Wrap(errors.New("the period ends before it starts"), errMessage)
+	}
+
+	var response RepresentorSalesResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetRepresentorSalesEndpoint, params, &response, errMessage); err != nil {
+		return nil, err
+	}
+
+	sales := []RepresentorSales(response.Data)
+	sort.SliceStable(sales, func(i, j int) bool {
+		return sales[i].RepresentorID < sales[j].RepresentorID
+	})
+	return sales, nil
+}
@@ -0,0 +1,52 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetRepresentorSales(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/representors", r.URL.Path)
+		assert.NotEmpty(t, r.URL.Query().Get("FromDate"))
+		assert.NotEmpty(t, r.URL.Query().Get("ToDate"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[
+			{"RepresentorID":"9","RepresentorName":"Sara","InvoiceCount":"3","SalesAmount":"3000000","ReturnsAmount":"0"},
+			{"RepresentorID":"4","RepresentorName":"Reza","InvoiceCount":12,"SalesAmount":5000000,"ReturnsAmount":"1000000","DiscountAmount":"250000"}
+		],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	ctx := context.Background()
+	params := goarpa.RepresentorSalesParams{
+		FromDate: goarpa.ToPtr(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)),
+		ToDate:   goarpa.ToPtr(time.Date(2024, 4, 19, 0, 0, 0, 0, time.UTC)),
+	}
+	_, err := client.GetRepresentorSales(ctx, "token", nil, params)
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+
+	client.Config.GetRepresentorSalesEndpoint = "representors"
+	sales, err := client.GetRepresentorSales(ctx, "token", nil, params)
+	require.NoError(t, err)
+	require.Len(t, sales, 2)
+	assert.Equal(t, goarpa.StringInt64(4), sales[0].RepresentorID)
+	assert.Equal(t, goarpa.EnforcedInt(12), sales[0].InvoiceCount)
+	assert.Equal(t, "4000000", sales[0].NetAmount().String())
+	assert.Equal(t, "80000", sales[0].Commission(2).String())
+	assert.Equal(t, goarpa.EnforcedString("Sara"), sales[1].RepresentorName)
+
+	params.FromDate, params.ToDate = params.ToDate, params.FromDate
+	_, err = client.GetRepresentorSales(ctx, "token", nil, params)
+	assert.ErrorContains(t, err, "the period ends before it starts")
+}
@@ -0,0 +1,74 @@
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_ConcurrentUse calls one client from many goroutines while its configuration and its resty client change,
+// run it with -race (make test-race)
+func Test_ConcurrentUse(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/token"):
+			_, _ = w.Write([]byte("token"))
+		case strings.HasSuffix(r.URL.Path, "/NewTransaction"):
+			_, _ = w.Write([]byte(`{"data":{"TransactionID":"7","Number":"1"},"error":null}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"42","BusinessCode":"1001","ItemID":"1"}],"error":null}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL,
+		goarpa.WithRequestCoalescing(),
+		goarpa.WithTextNormalization(),
+		goarpa.WithRetryHook(func(goarpa.RetryInfo) {}),
+	)
+	client.Config.GetServiceTokenEndpoint = "token"
+	tokens := goarpa.NewTokenManager(client, "user", "pass", goarpa.TokenManagerOptions{})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				token, cookies, err := tokens.Session(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+				_, err = client.GetCustomerByMobile(ctx, token, cookies, "09121234567")
+				assert.NoError(t, err)
+				_, err = client.GetServiceByItemCode(ctx, token, cookies, "PEN")
+				assert.NoError(t, err)
+				_, err = client.CreateTransaction(ctx, token, benchmarkTransaction)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 20; j++ {
+			client.UpdateConfig(func(config *goarpa.ClientConfig) {
+				config.GetCustomersEndpoint = "serv/api/GetBusinesses"
+			})
+			client.SetRestyClient(resty.New())
+			_ = client.RestyClient()
+		}
+	}()
+	wg.Wait()
+}
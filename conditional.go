@@ -0,0 +1,181 @@
+package goarpa
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultResponseCacheSize is the number of responses kept by the cache of WithConditionalRequests by default
+const DefaultResponseCacheSize = 1000
+
+// CachedResponse is a response kept to revalidate a GET with the server
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// ResponseCache keeps the responses of the conditional requests, it must be safe for concurrent use.
+// The keys contain the URL of the requests and a SHA-256 hash of their token and their cookies.
+type ResponseCache interface {
+	Get(key string) (CachedResponse, bool)
+	Set(key string, response CachedResponse)
+}
+
+// WithConditionalRequests revalidates the GET requests answered with an ETag or a Last-Modified header,
+// e.g. by IIS for the static lookups, with If-None-Match and If-Modified-Since. A response
+// Not Modified is answered from the cache, so that a repeated catalog pull does not transfer the catalog again.
+// The cache is a MemoryResponseCache of DefaultResponseCacheSize when it is nil.
+func WithConditionalRequests(cache ResponseCache) func(*GoArpa) {
+	return func(g *GoArpa) {
+		if cache == nil {
+			cache = NewMemoryResponseCache(DefaultResponseCacheSize)
+		}
+		g.responseCache = cache
+	}
+}
+
+// conditionalTransport revalidates the cached responses of the GET requests
+type conditionalTransport struct {
+	next  http.RoundTripper
+	cache ResponseCache
+}
+
+// wrapConditionalTransport installs the revalidation below the charset conversion, once
+func wrapConditionalTransport(restyClient *resty.Client, cache ResponseCache) {
+	charset, ok := restyClient.GetClient().Transport.(*charsetTransport)
+	if !ok || cache == nil {
+		return
+	}
+	if _, ok := charset.next.(*conditionalTransport); ok {
+		return
+	}
+	charset.next = &conditionalTransport{next: charset.next, cache: cache}
+}
+
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the downloads and the streams are not buffered
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" || req.Context().Value(streamingContextKey) != nil {
+		return t.next.RoundTrip(req)
+	}
+
+	key := responseCacheKey(req)
+	cached, ok := t.cache.Get(key)
+	if ok {
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if ok && resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		return cached.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK || (resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	cached = CachedResponse{StatusCode: resp.StatusCode, Header: resp.Header.Clone(), Body: body}
+	t.cache.Set(key, cached)
+	return cached.response(req), nil
+}
+
+// responseCacheKey identifies the response of the request like coalescingKey,
+// the credentials are hashed to stay out of the store of the cache
+func responseCacheKey(req *http.Request) string {
+	credentials := sha256.Sum256([]byte(req.Header.Get("Authorization") + "\n" + req.Header.Get("Cookie")))
+	return strings.Join([]string{
+		req.URL.String(),
+		hex.EncodeToString(credentials[:]),
+		req.Header.Get("Accept"),
+		req.Header.Get("Accept-Language"),
+	}, "\n")
+}
+
+// response returns a copy of the cached response for the request
+func (c CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.StatusCode, http.StatusText(c.StatusCode)),
+		StatusCode:    c.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// MemoryResponseCache is a ResponseCache evicting the least recently used responses
+type MemoryResponseCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key      string
+	response CachedResponse
+}
+
+// NewMemoryResponseCache returns a cache of up to size responses
+func NewMemoryResponseCache(size int) *MemoryResponseCache {
+	if size < 1 {
+		size = DefaultResponseCacheSize
+	}
+	return &MemoryResponseCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get returns the response of the key
+func (m *MemoryResponseCache) Get(key string) (CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	element, ok := m.entries[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*memoryCacheEntry).response, true
+}
+
+// Set keeps the response of the key, evicting the least recently used response when the cache is full
+func (m *MemoryResponseCache) Set(key string, response CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryCacheEntry).response = response
+		m.order.MoveToFront(element)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryCacheEntry{key: key, response: response})
+	if m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
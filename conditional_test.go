@@ -0,0 +1,104 @@
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConditionalRequests(t *testing.T) {
+	t.Parallel()
+	var full, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"data":[{"ItemID":"1","ItemCode":"PEN"},{"ItemID":"2","ItemCode":"INK"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithConditionalRequests(nil))
+	client.Config.GetItemsEndpoint = "items"
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		items, err := client.GetItems(ctx, "token", nil, goarpa.GetItemsParams{})
+		require.NoError(t, err)
+		require.Len(t, items.Data, 2)
+		assert.Equal(t, "INK", items.Data[1].ItemCode)
+	}
+	assert.Equal(t, int32(1), full.Load())
+	assert.Equal(t, int32(2), notModified.Load())
+
+	// the responses are not shared between tokens
+	_, err := client.GetItems(ctx, "other", nil, goarpa.GetItemsParams{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), full.Load())
+}
+
+// keysCache records the keys of a ResponseCache
+type keysCache struct {
+	*goarpa.MemoryResponseCache
+	mu   sync.Mutex
+	keys []string
+}
+
+func (c *keysCache) Set(key string, response goarpa.CachedResponse) {
+	c.mu.Lock()
+	c.keys = append(c.keys, key)
+	c.mu.Unlock()
+	c.MemoryResponseCache.Set(key, response)
+}
+
+func Test_ConditionalRequestsCacheKey(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+	}))
+	defer server.Close()
+
+	cache := &keysCache{MemoryResponseCache: goarpa.NewMemoryResponseCache(10)}
+	client := goarpa.NewClient(server.URL, goarpa.WithConditionalRequests(cache))
+	client.Config.GetItemsEndpoint = "items"
+	cookie := []*http.Cookie{{Name: "ASP.NET_SessionId", Value: "session-secret"}}
+	_, err := client.GetItems(context.Background(), "token-secret", cookie, goarpa.GetItemsParams{})
+	require.NoError(t, err)
+
+	// the credentials are not written to the store of the cache
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	require.Len(t, cache.keys, 1)
+	assert.Contains(t, cache.keys[0], "/items")
+	assert.NotContains(t, cache.keys[0], "token-secret")
+	assert.NotContains(t, cache.keys[0], "session-secret")
+}
+
+func Test_MemoryResponseCache(t *testing.T) {
+	t.Parallel()
+	cache := goarpa.NewMemoryResponseCache(2)
+	cache.Set("a", goarpa.CachedResponse{StatusCode: 200})
+	cache.Set("b", goarpa.CachedResponse{StatusCode: 200})
+	_, _ = cache.Get("a")
+	cache.Set("c", goarpa.CachedResponse{StatusCode: 200})
+
+	_, ok := cache.Get("b")
+	assert.False(t, ok)
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+}
@@ -0,0 +1,224 @@
+// Package conformance runs a scenario against a sandbox Arpa server and reports which operations
+// of this client work with it, so that a backend upgrade can be validated before it reaches production.
+//
+// The scenario creates real records: it must only be run against a sandbox company.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+)
+
+// Config is the sandbox the scenario runs against
+type Config struct {
+	BaseURL  string `json:"baseUrl"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// ServerVersion labels the report with the version of the Arpa server
+	ServerVersion string `json:"serverVersion"`
+	// ItemID is the item sold by the test transaction
+	ItemID goarpa.ItemID `json:"itemId"`
+	// Endpoints overrides the endpoints of the client, keyed by their Config field name
+	Endpoints map[string]string `json:"endpoints,omitempty"`
+}
+
+// StepStatus is the outcome of a step
+type StepStatus string
+
+const (
+	// StepPassed is a step which worked
+	StepPassed StepStatus = "passed"
+	// StepFailed is a step which is not compatible with the server
+	StepFailed StepStatus = "failed"
+	// StepSkipped is a step which could not run or is not supported by the server
+	StepSkipped StepStatus = "skipped"
+)
+
+// StepResult is the outcome of a step of the scenario
+type StepResult struct {
+	Name     string        `json:"name"`
+	Status   StepStatus    `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Report is the compatibility report of a server
+type Report struct {
+	ServerVersion string       `json:"serverVersion"`
+	StartedAt     time.Time    `json:"startedAt"`
+	Steps         []StepResult `json:"steps"`
+}
+
+// Compatible returns true if no step has failed
+func (r *Report) Compatible() bool {
+	for _, step := range r.Steps {
+		if step.Status == StepFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// errSkipped marks a step which could not run
+type errSkipped struct {
+	reason string
+}
+
+func (e errSkipped) Error() string {
+	return e.reason
+}
+
+// Run runs the scenario: login, create customer, fetch customer, create transaction, fetch transaction
+// and void transaction. The steps depending on a failed step are skipped, as well as the operations
+// the server does not support.
+func Run(ctx context.Context, config Config) (*Report, error) {
+	client, err := goarpa.NewValidatedClient(config.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := setEndpoints(client, config.Endpoints); err != nil {
+		return nil, err
+	}
+
+	report := &Report{ServerVersion: config.ServerVersion, StartedAt: time.Now()}
+	var (
+		token         string
+		customer      goarpa.EnsureCustomerResult
+		transactionID goarpa.TransactionID
+	)
+	suffix := fmt.Sprintf("%08d", rand.IntN(100000000))
+
+	step := func(name string, depends bool, run func() error) bool {
+		result := StepResult{Name: name}
+		start := time.Now()
+		var err error
+		if !depends {
+			err = errSkipped{"a previous step failed"}
+		} else {
+			err = run()
+		}
+		result.Duration = time.Since(start)
+
+		var skipped errSkipped
+		switch {
+		case err == nil:
+			result.Status = StepPassed
+		case errors.As(err, &skipped):
+			result.Status = StepSkipped
+			result.Error = err.Error()
+		case errors.Is(err, goarpa.ErrNotSupported):
+			result.Status = StepSkipped
+			result.Error = err.Error()
+		default:
+			result.Status = StepFailed
+			result.Error = err.Error()
+		}
+		report.Steps = append(report.Steps, result)
+		return result.Status == StepPassed
+	}
+
+	loggedIn := step("login", true, func() error {
+		var err error
+		token, _, err = client.GetAdminToken(ctx, config.Username, config.Password)
+		return err
+	})
+
+	created := step("create customer", loggedIn, func() error {
+		response, err := client.CreateCustomer(ctx, token, nil, goarpa.CreateCustomerRequest{
+			BusName: "conformance " + suffix,
+			Name:    goarpa.StringP("conformance"),
+			Family:  goarpa.StringP(suffix),
+			Mobile:  goarpa.StringP("091" + suffix),
+		})
+		var exists *goarpa.CustomerExistsError
+		if errors.As(err, &exists) {
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		customer.BusinessID = response.Data.BusinessID
+		customer.BusinessCode = string(response.Data.BusinessCode)
+		if customer.BusinessID == 0 {
+			return errors.New("the response has no business id")
+		}
+		return nil
+	})
+
+	step("fetch customer", created, func() error {
+		response, err := client.GetCustomerByBusinessCode(ctx, token, nil, customer.BusinessCode)
+		if err != nil {
+			return err
+		}
+		datum, ok := response.First()
+		if !ok || datum.BusinessID != customer.BusinessID {
+			return fmt.Errorf("business %d not returned by its code %s", customer.BusinessID, customer.BusinessCode)
+		}
+		return nil
+	})
+
+	transacted := step("create transaction", created, func() error {
+		if config.ItemID == 0 {
+			return errSkipped{"no item configured"}
+		}
+		response, err := client.CreateTransaction(ctx, token, goarpa.CreateTransactionRequest{
+			Data: goarpa.Data{
+				BusinessID:   customer.BusinessID,
+				TransStateID: goarpa.TransStateDraft,
+				FactorTypeID: goarpa.FactorTypeSale,
+				Description:  "conformance " + suffix,
+			},
+			Items: []goarpa.TransactionItem{{ItemID: config.ItemID, Qty: 1, Price: goarpa.NewMoney(1000)}},
+		})
+		if err != nil {
+			return err
+		}
+		datum, ok := response.First()
+		if !ok || datum.TransactionID == 0 {
+			return errors.New("the response has no transaction id")
+		}
+		transactionID = datum.TransactionID
+		return nil
+	})
+
+	step("fetch transaction", transacted, func() error {
+		for transaction, err := range client.IterateTransactions(ctx, token, nil, goarpa.GetTransactionsParams{BusinessID: &customer.BusinessID}) {
+			if err != nil {
+				return err
+			}
+			if transaction.TransactionID == transactionID {
+				return nil
+			}
+		}
+		return fmt.Errorf("transaction %d not listed", transactionID)
+	})
+
+	step("void transaction", transacted, func() error {
+		if client.Config.VoidTransactionEndpoint == "" {
+			return errSkipped{"no void transaction endpoint configured"}
+		}
+		_, err := client.VoidTransaction(ctx, token, nil, goarpa.VoidTransactionRequest{TransactionID: transactionID})
+		return err
+	})
+
+	return report, nil
+}
+
+// setEndpoints overrides the endpoints of the client
+func setEndpoints(client *goarpa.GoArpa, endpoints map[string]string) error {
+	config := reflect.ValueOf(&client.Config).Elem()
+	for name, endpoint := range endpoints {
+		field := config.FieldByName(name)
+		if !field.IsValid() || field.Kind() != reflect.String {
+			return fmt.Errorf("unknown endpoint %s", name)
+		}
+		field.SetString(endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package conformance_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2/conformance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Conformance runs the scenario against the sandbox described by the GOARPA_CONFORMANCE_CONFIG file
+func Test_Conformance(t *testing.T) {
+	configFile, ok := os.LookupEnv("GOARPA_CONFORMANCE_CONFIG")
+	if !ok {
+		t.Skip("GOARPA_CONFORMANCE_CONFIG is not set")
+	}
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	var config conformance.Config
+	require.NoError(t, json.Unmarshal(data, &config))
+
+	report, err := conformance.Run(context.Background(), config)
+	require.NoError(t, err)
+	out, err := json.MarshalIndent(report, "", "  ")
+	require.NoError(t, err)
+	t.Logf("report:\n%s", out)
+	assert.True(t, report.Compatible())
+}
+
+func Test_RunReport(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "GetServiceToken"):
+			_, _ = w.Write([]byte("token"))
+		case strings.HasSuffix(r.URL.Path, "PostBusiness"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"Data":{"BusinessId":"77","BusinessCode":"C77","Existed":"0"},"Error":null}`))
+		case strings.HasSuffix(r.URL.Path, "GetBusiness"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"Data":[{"BusinessID":"76"}],"Error":null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	report, err := conformance.Run(context.Background(), conformance.Config{BaseURL: server.URL, ServerVersion: "test"})
+	require.NoError(t, err)
+
+	statuses := make(map[string]conformance.StepStatus)
+	for _, step := range report.Steps {
+		statuses[step.Name] = step.Status
+	}
+	assert.Equal(t, map[string]conformance.StepStatus{
+		"login":              conformance.StepPassed,
+		"create customer":    conformance.StepPassed,
+		"fetch customer":     conformance.StepFailed,
+		"create transaction": conformance.StepSkipped,
+		"fetch transaction":  conformance.StepSkipped,
+		"void transaction":   conformance.StepSkipped,
+	}, statuses)
+	assert.False(t, report.Compatible())
+}
+
+func Test_RunVoidTransaction(t *testing.T) {
+	t.Parallel()
+	var voided string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "GetServiceToken"):
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("token"))
+		case strings.HasSuffix(r.URL.Path, "PostBusiness"):
+			_, _ = w.Write([]byte(`{"Data":{"BusinessId":"77","BusinessCode":"C77","Existed":"0"},"Error":null}`))
+		case strings.HasSuffix(r.URL.Path, "GetBusiness"):
+			_, _ = w.Write([]byte(`{"Data":[{"BusinessID":"77"}],"Error":null}`))
+		case strings.HasSuffix(r.URL.Path, "NewTransaction"):
+			_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+		case strings.HasSuffix(r.URL.Path, "transactions"):
+			_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42"}]}`))
+		case strings.HasSuffix(r.URL.Path, "void"):
+			var body map[string]json.RawMessage
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			voided = string(body["TransactionID"])
+			_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := conformance.Config{BaseURL: server.URL, ServerVersion: "test", ItemID: 5, Endpoints: map[string]string{
+		"GetTransactionsEndpoint": "transactions",
+	}}
+	report, err := conformance.Run(context.Background(), config)
+	require.NoError(t, err)
+	require.Len(t, report.Steps, 6)
+	// the void step is skipped without its endpoint
+	assert.Equal(t, conformance.StepSkipped, report.Steps[5].Status)
+	assert.Contains(t, report.Steps[5].Error, "no void transaction endpoint")
+
+	config.Endpoints["VoidTransactionEndpoint"] = "void"
+	report, err = conformance.Run(context.Background(), config)
+	require.NoError(t, err)
+	for _, step := range report.Steps {
+		assert.Equal(t, conformance.StepPassed, step.Status, step.Name+": "+step.Error)
+	}
+	assert.True(t, report.Compatible())
+	assert.Equal(t, "42", strings.Trim(voided, `"`))
+}
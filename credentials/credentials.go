@@ -0,0 +1,184 @@
+// Package credentials loads the Arpa admin credentials from the environment, a JSON file
+// or a file encrypted with AES-256-GCM, so that deployments do not have to commit plaintext passwords.
+//
+// An encrypted file is created with Encrypt and decrypted with the base64 key in ARPA_CREDENTIALS_KEY:
+//
+//	key, _ := credentials.NewKey()
+//	blob, _ := credentials.Encrypt(credentials.Credentials{Username: "admin", Password: "secret"}, key)
+//	_ = os.WriteFile("arpa.credentials", blob, 0o600)
+//	// ARPA_CREDENTIALS_KEY=$(base64 key) at runtime
+//	creds, err := credentials.Load("arpa.credentials")
+package credentials
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// The environment variables read by FromEnv and Load
+const (
+	UsernameEnv = "ARPA_USERNAME"
+	PasswordEnv = "ARPA_PASSWORD"
+	// KeyEnv holds the base64 encoded key of the encrypted files
+	KeyEnv = "ARPA_CREDENTIALS_KEY"
+)
+
+// KeySize is the size of the AES-256 key of the encrypted files
+const KeySize = 32
+
+// encryptedPrefix starts the content of an encrypted file, followed by the base64 nonce and ciphertext
+const encryptedPrefix = "goarpa-aesgcm:v1:"
+
+// ErrNoCredentials is returned when the source has no username or password
+var ErrNoCredentials = errors.New("no credentials")
+
+// Credentials are the username and password of GetAdminToken
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// String hides the password, so that the credentials can be logged
+func (c Credentials) String() string {
+	return fmt.Sprintf("%s:****", c.Username)
+}
+
+func (c Credentials) validate() error {
+	if c.Username == "" || c.Password == "" {
+		return ErrNoCredentials
+	}
+	return nil
+}
+
+// Load returns the credentials of ARPA_USERNAME and ARPA_PASSWORD when they are set, otherwise those of the file.
+// The file is decrypted with the key of ARPA_CREDENTIALS_KEY when it was created by Encrypt.
+func Load(path string) (Credentials, error) {
+	if creds, err := FromEnv(); err == nil || path == "" {
+		return creds, err
+	}
+	return FromFile(path)
+}
+
+// FromEnv returns the credentials of ARPA_USERNAME and ARPA_PASSWORD
+func FromEnv() (Credentials, error) {
+	creds := Credentials{Username: os.Getenv(UsernameEnv), Password: os.Getenv(PasswordEnv)}
+	if err := creds.validate(); err != nil {
+		return Credentials{}, fmt.Errorf("%w in %s and %s", err, UsernameEnv, PasswordEnv)
+	}
+	return creds, nil
+}
+
+// FromFile returns the credentials of a JSON file, e.g. {"username":"admin","password":"secret"},
+// or of a file created by Encrypt, decrypted with the key of ARPA_CREDENTIALS_KEY
+func FromFile(path string) (Credentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("could not read credentials: %w", err)
+	}
+
+	var creds Credentials
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(encryptedPrefix)) {
+		key, err := KeyFromEnv()
+		if err != nil {
+			return Credentials{}, err
+		}
+		creds, err = Decrypt(data, key)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("%s: %w", path, err)
+		}
+	} else if err := json.Unmarshal(data, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("could not parse credentials %s: %w", path, err)
+	}
+
+	if err := creds.validate(); err != nil {
+		return Credentials{}, fmt.Errorf("%w in %s", err, path)
+	}
+	return creds, nil
+}
+
+// NewKey returns a random key for Encrypt
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// KeyFromEnv returns the key of ARPA_CREDENTIALS_KEY
+func KeyFromEnv() ([]byte, error) {
+	encoded := os.Getenv(KeyEnv)
+	if encoded == "" {
+		return nil, fmt.Errorf("%s is not set", KeyEnv)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", KeyEnv, err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid %s: the key must be %d bytes", KeyEnv, KeySize)
+	}
+	return key, nil
+}
+
+// Encrypt returns the content of an encrypted credentials file
+func Encrypt(creds Credentials, key []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(encryptedPrefix))
+	return append([]byte(encryptedPrefix), base64.StdEncoding.EncodeToString(sealed)...), nil
+}
+
+// Decrypt returns the credentials of the content of an encrypted credentials file
+func Decrypt(data []byte, key []byte) (Credentials, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return Credentials{}, err
+	}
+	encoded, ok := bytes.CutPrefix(bytes.TrimSpace(data), []byte(encryptedPrefix))
+	if !ok {
+		return Credentials{}, errors.New("not an encrypted credentials file")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return Credentials{}, errors.New("corrupted encrypted credentials")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(encryptedPrefix))
+	if err != nil {
+		return Credentials{}, errors.New("could not decrypt credentials, wrong key or corrupted file")
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("could not parse decrypted credentials: %w", err)
+	}
+	return creds, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("the key must be %d bytes", KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,65 @@
+package credentials_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the tests set environment variables and cannot run in parallel
+
+func Test_EncryptedFile(t *testing.T) {
+	key, err := credentials.NewKey()
+	require.NoError(t, err)
+	blob, err := credentials.Encrypt(credentials.Credentials{Username: "admin", Password: "secret"}, key)
+	require.NoError(t, err)
+	assert.NotContains(t, string(blob), "secret")
+
+	path := filepath.Join(t.TempDir(), "arpa.credentials")
+	require.NoError(t, os.WriteFile(path, blob, 0o600))
+
+	t.Setenv(credentials.UsernameEnv, "")
+	t.Setenv(credentials.KeyEnv, "")
+	_, err = credentials.Load(path)
+	assert.ErrorContains(t, err, credentials.KeyEnv+" is not set")
+
+	t.Setenv(credentials.KeyEnv, base64.StdEncoding.EncodeToString(key))
+	creds, err := credentials.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, credentials.Credentials{Username: "admin", Password: "secret"}, creds)
+	assert.Equal(t, "admin:****", creds.String())
+
+	other, err := credentials.NewKey()
+	require.NoError(t, err)
+	_, err = credentials.Decrypt(blob, other)
+	assert.ErrorContains(t, err, "wrong key")
+}
+
+func Test_LoadFromEnvAndFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"username":"file","password":"pass"}`), 0o600))
+
+	t.Setenv(credentials.UsernameEnv, "env")
+	t.Setenv(credentials.PasswordEnv, "pass")
+	creds, err := credentials.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "env", creds.Username)
+
+	t.Setenv(credentials.UsernameEnv, "")
+	creds, err = credentials.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "file", creds.Username)
+
+	_, err = credentials.Load("")
+	assert.True(t, errors.Is(err, credentials.ErrNoCredentials))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"username":"file"}`), 0o600))
+	_, err = credentials.FromFile(path)
+	assert.True(t, errors.Is(err, credentials.ErrNoCredentials))
+}
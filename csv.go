@@ -0,0 +1,294 @@
+package goarpa
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVMapping maps the names of the struct fields to the CSV column headers, e.g. {"Name": "نام"}.
+// When nil, every field is mapped to a column named after the field. The exported columns follow
+// the order of the struct fields.
+type CSVMapping map[string]string
+
+// CSVRowError is the error of a CSV row, Row being its line number in the file
+type CSVRowError struct {
+	Row int
+	Err error
+}
+
+// Error stringifies the CSVRowError
+func (e *CSVRowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+// Unwrap returns the error of the row
+func (e *CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// CSVImportResult is the outcome of a CSV import
+type CSVImportResult struct {
+	Rows     int
+	Imported int
+	Errors   []CSVRowError
+}
+
+// ImportCustomersCSV creates the businesses of the CSV rows which do not exist yet.
+// The first row holds the column headers. The errors of the rows are reported in the result
+// and do not stop the import.
+func (g *GoArpa) ImportCustomersCSV(ctx context.Context, accessToken string, cookie []*http.Cookie, r io.Reader, mapping CSVMapping) (*CSVImportResult, error) {
+	return importCSV(ctx, r, mapping, func(ctx context.Context, customer Customer) error {
+		request, err := customer.ToCreateCustomerRequest()
+		if err != nil {
+			return err
+		}
+		_, err = g.EnsureCustomer(ctx, accessToken, cookie, request)
+		return err
+	})
+}
+
+// ExportCustomersCSV writes the businesses matching the params as CSV, with a header row
+func (g *GoArpa) ExportCustomersCSV(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams, w io.Writer, mapping CSVMapping) error {
+	return exportCSV(w, mapping, g.IterateCustomers(ctx, accessToken, cookie, params))
+}
+
+// ImportItemsCSV creates the services of the CSV rows.
+// The first row holds the column headers. The errors of the rows are reported in the result
+// and do not stop the import.
+func (g *GoArpa) ImportItemsCSV(ctx context.Context, accessToken string, r io.Reader, mapping CSVMapping) (*CSVImportResult, error) {
+	return importCSV(ctx, r, mapping, func(ctx context.Context, service CreateServiceRequest) error {
+		_, err := g.CreateService(ctx, accessToken, service)
+		return err
+	})
+}
+
+// ExportItemsCSV writes the items matching the params as CSV, with a header row
+func (g *GoArpa) ExportItemsCSV(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams, w io.Writer, mapping CSVMapping) error {
+	return exportCSV(w, mapping, g.IterateItems(ctx, accessToken, cookie, params))
+}
+
+// csvField is a struct field mapped to a column
+type csvField struct {
+	index  int
+	header string
+}
+
+// csvFields returns the fields of the struct type which are mapped to a column
+func csvFields(t reflect.Type, mapping CSVMapping) []csvField {
+	var fields []csvField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		header := field.Name
+		if mapping != nil {
+			var ok bool
+			if header, ok = mapping[field.Name]; !ok {
+				continue
+			}
+		}
+		fields = append(fields, csvField{index: i, header: header})
+	}
+	return fields
+}
+
+func exportCSV[T any](w io.Writer, mapping CSVMapping, records func(yield func(T, error) bool)) error {
+	fields := csvFields(reflect.TypeFor[T](), mapping)
+	writer := csv.NewWriter(w)
+
+	row := make([]string, len(fields))
+	for i, field := range fields {
+		row[i] = field.header
+	}
+	if err := writer.Write(row); err != nil {
+		return err
+	}
+
+	for record, err := range records {
+		if err != nil {
+			return err
+		}
+		value := reflect.ValueOf(record)
+		for i, field := range fields {
+			row[i] = formatCSVValue(value.Field(field.index))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func importCSV[T any](ctx context.Context, r io.Reader, mapping CSVMapping, create func(ctx context.Context, record T) error) (*CSVImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(headers))
+	for i, header := range headers {
+		columns[strings.TrimSpace(strings.TrimPrefix(header, "\ufeff"))] = i
+	}
+	fields := csvFields(reflect.TypeFor[T](), mapping)
+
+	result := &CSVImportResult{}
+	for row := 2; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		cells, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		result.Rows++
+		if err != nil {
+			result.Errors = append(result.Errors, CSVRowError{Row: row, Err: err})
+			continue
+		}
+
+		var record T
+		value := reflect.ValueOf(&record).Elem()
+		for _, field := range fields {
+			column, ok := columns[field.header]
+			if !ok || column >= len(cells) {
+				continue
+			}
+			if err = parseCSVValue(value.Field(field.index), cells[column]); err != nil {
+				err = fmt.Errorf("column %s: %w", field.header, err)
+				break
+			}
+		}
+		if err == nil {
+			err = create(ctx, record)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, CSVRowError{Row: row, Err: err})
+			continue
+		}
+		result.Imported++
+	}
+}
+
+var moneyType = reflect.TypeOf(Money{})
+
+func formatCSVValue(value reflect.Value) string {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch value.Type() {
+	case timeType, customTimeType:
+		var t time.Time
+		if value.Type() == customTimeType {
+			t = value.Interface().(CustomTime).Time
+		} else {
+			t = value.Interface().(time.Time)
+		}
+		if t.IsZero() {
+			return ""
+		}
+		return t.In(CustomTimeLocation).Format(CustomTimeLayout)
+	case moneyType:
+		return value.Interface().(Money).String()
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+func parseCSVValue(value reflect.Value, cell string) error {
+	cell = strings.TrimSpace(cell)
+	if cell == "" {
+		return nil
+	}
+	if value.Kind() == reflect.Pointer {
+		value.Set(reflect.New(value.Type().Elem()))
+		value = value.Elem()
+	}
+
+	switch value.Type() {
+	case timeType, customTimeType:
+		var t CustomTime
+		if err := t.UnmarshalJSON([]byte(strconv.Quote(latinDigits(cell)))); err != nil {
+			return err
+		}
+		if value.Type() == customTimeType {
+			value.Set(reflect.ValueOf(t))
+		} else {
+			value.Set(reflect.ValueOf(t.Time))
+		}
+		return nil
+	case moneyType:
+		money, err := ParseMoney(strings.ReplaceAll(latinDigits(cell), ",", ""))
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(money))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(cell)
+	case reflect.Bool:
+		switch strings.ToLower(cell) {
+		case "1", "true", "yes", "بله":
+			value.SetBool(true)
+		case "0", "false", "no", "خیر":
+			value.SetBool(false)
+		default:
+			return fmt.Errorf("invalid boolean %q", cell)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(latinDigits(cell), 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(latinDigits(cell), 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(latinDigits(cell), 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(v)
+	default:
+		return fmt.Errorf("unsupported field type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package goarpa_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ImportItemsCSV(t *testing.T) {
+	t.Parallel()
+	var created []goarpa.CreateServiceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var service goarpa.CreateServiceRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&service))
+		created = append(created, service)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	input := "کد,نام,گروه\n" +
+		"S1,نصب,۱۲\n" +
+		"S2,تعمیر,abc\n" +
+		"S3,مشاوره,\n"
+	result, err := client.ImportItemsCSV(context.Background(), "token", strings.NewReader(input), goarpa.CSVMapping{
+		"ServiceCode":    "کد",
+		"ServiceName":    "نام",
+		"ItemCategoryID": "گروه",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Rows)
+	assert.Equal(t, 2, result.Imported)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, 3, result.Errors[0].Row)
+
+	require.Len(t, created, 2)
+	assert.Equal(t, goarpa.CreateServiceRequest{ServiceName: "نصب", ServiceCode: "S1", ItemCategoryID: 12}, created[0])
+	assert.Equal(t, "S3", created[1].ServiceCode)
+}
+
+func Test_ImportCustomersCSV(t *testing.T) {
+	t.Parallel()
+	var (
+		lookups []string
+		created []map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			lookups = append(lookups, r.URL.RawQuery)
+			_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+			return
+		}
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		created = append(created, body)
+		_, _ = w.Write([]byte(`{"data":{"BusinessID":"1","BusinessCode":"1001"},"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	// the columns are in any order, the BOM of the header is ignored and the unmapped columns too
+	input := "\ufeffموبایل,نام,شهر,کد ملی,توضیحات\n" +
+		"09120000000,علی,۳۰۱,,مشتری قدیمی\n" +
+		"09121111111,رضا,abc,,\n" +
+		"09122222222,مریم,,0013542418,\n" +
+		",شرکت پارس,,0013542419,\n"
+	result, err := client.ImportCustomersCSV(context.Background(), "token", nil, strings.NewReader(input), goarpa.CSVMapping{
+		"Name":         "نام",
+		"Mobile":       "موبایل",
+		"CityID":       "شهر",
+		"NationalCode": "کد ملی",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Rows)
+	assert.Equal(t, 2, result.Imported)
+
+	// the bad rows are reported with their line numbers and are not sent
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, 3, result.Errors[0].Row)
+	assert.ErrorContains(t, &result.Errors[0], "row 3: column شهر")
+	assert.Equal(t, 4, result.Errors[1].Row)
+	assert.ErrorContains(t, &result.Errors[1], "invalid national code")
+
+	// the businesses are looked up by mobile or national code before being created
+	assert.Equal(t, []string{"MobileNo=09120000000", "NationalCode=0013542419"}, lookups)
+	require.Len(t, created, 2)
+	assert.Equal(t, "علی", created[0]["BusName"])
+	assert.Equal(t, "09120000000", created[0]["Mobile"])
+	assert.Equal(t, 301.0, created[0]["CityId"])
+	assert.Equal(t, "شرکت پارس", created[1]["BusName"])
+	assert.Equal(t, "0013542419", created[1]["NationalCode"])
+	assert.Nil(t, created[1]["Mobile"])
+}
+
+func Test_ExportCustomersCSV(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"BusinessID":"7","BusinessName":"Ali, Co","InActive":"1","UnCashCredit":"1500.5"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetCustomersEndpoint = "customers"
+
+	var out bytes.Buffer
+	err := client.ExportCustomersCSV(context.Background(), "token", nil, goarpa.GetCustomersParams{}, &out, goarpa.CSVMapping{
+		"ID":           "id",
+		"Name":         "name",
+		"Inactive":     "inactive",
+		"UnCashCredit": "credit",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "id,name,credit,inactive\n7,\"Ali, Co\",1500.5,true\n", out.String())
+}
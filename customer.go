@@ -0,0 +1,374 @@
+package goarpa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2/shared/constant"
+)
+
+// Customer is a business (customer or vendor) with proper Go types,
+// hiding the stringly-typed wire format of Arpa
+type Customer struct {
+	ID             BusinessID
+	Code           string
+	Name           string
+	LatinName      string
+	FirstName      string
+	LastName       string
+	FatherName     string
+	NationalCode   string
+	IDNo           string
+	FinCode        string
+	RegisterNumber string
+	// Sexuality and RealOrFinancial are omitted from the JSON when they are unknown, zero being no valid value
+	Sexuality          Sexuality       `json:",omitempty"`
+	RealOrFinancial    RealOrFinancial `json:",omitempty"`
+	Mobile             string
+	Phone              string
+	Fax                string
+	Email              string
+	WebSite            string
+	Address            string
+	PostalCode         string
+	ProvinceID         int64
+	CityID             int64
+	BusinessCategoryID int64
+	PriceLevelID       int64
+	RepresentorID      int64
+	RepresentorCode    string
+	DefaultDiscount    float64
+	CheckCredit        Money
+	UnCashCredit       Money
+	Creditable         bool
+	WithoutCredit      bool
+	Inactive           bool
+	IsCustomer         bool
+	IsVendor           bool
+	TaxExempt          bool
+	CreatedAt          time.Time
+	ModifiedAt         time.Time
+}
+
+// ToCustomer converts the wire model to a Customer
+func (d Datum2) ToCustomer() Customer {
+	customer := Customer{
+		ID:                 d.BusinessID,
+		Code:               d.BusinessCode,
+		Name:               d.BusinessName,
+		LatinName:          d.LatinName,
+		FirstName:          d.Name,
+		LastName:           d.Family,
+		FatherName:         d.FatherName,
+		NationalCode:       d.NationalCode,
+		IDNo:               d.IDNo,
+		FinCode:            d.FinCode,
+		RegisterNumber:     d.RegisterNumber,
+		Mobile:             d.Mobile,
+		Phone:              d.PhoneNo,
+		Fax:                d.Fax,
+		Email:              d.Email,
+		WebSite:            d.WebSite,
+		Address:            d.Address,
+		PostalCode:         d.PostalCode,
+		ProvinceID:         d.ProvinceID.Int64(),
+		CityID:             d.CityID.Int64(),
+		BusinessCategoryID: d.BusinessCategoryID.Int64(),
+		PriceLevelID:       d.PriceLevelID.Int64(),
+		RepresentorID:      d.RepresentorID.Int64(),
+		RepresentorCode:    d.RepresentorCode,
+		DefaultDiscount:    d.DefaultDiscount,
+		CheckCredit:        d.CheckCredit,
+		UnCashCredit:       d.UnCashCredit,
+		Creditable:         d.Creditable.Bool(),
+		WithoutCredit:      d.WithoutCredit.Bool(),
+		Inactive:           d.InActive.Bool(),
+		IsCustomer:         d.IsCustomer.Bool(),
+		IsVendor:           d.IsVendor.Bool(),
+		TaxExempt:          d.TaxExempt.Bool(),
+	}
+
+	if v, err := strconv.ParseInt(d.Sexuality, 10, 64); err == nil && Sexuality(v).Valid() {
+		customer.Sexuality = Sexuality(v)
+	}
+	if v, err := strconv.ParseInt(d.RealOrFinancial, 10, 64); err == nil && RealOrFinancial(v).Valid() {
+		customer.RealOrFinancial = RealOrFinancial(v)
+	}
+	if d.CreationDate != nil {
+		customer.CreatedAt = d.CreationDate.Time
+	}
+	if d.ModificationDate != nil {
+		customer.ModifiedAt = d.ModificationDate.Time
+	}
+
+	return customer
+}
+
+// ToCreateCustomerRequest converts the customer to the request model used to create or update it.
+// Empty fields are left nil so that they are not overwritten.
+func (c Customer) ToCreateCustomerRequest() (CreateCustomerRequest, error) {
+	request := CreateCustomerRequest{
+		BusName:            c.Name,
+		ProvinceID:         nonZeroInt64P(c.ProvinceID),
+		CityID:             nonZeroInt64P(c.CityID),
+		Email:              nonEmptyStringP(c.Email),
+		Mobile:             nonEmptyStringP(c.Mobile),
+		PhoneNo:            nonEmptyStringP(c.Phone),
+		Name:               nonEmptyStringP(c.FirstName),
+		Family:             nonEmptyStringP(c.LastName),
+		Address:            nonEmptyStringP(c.Address),
+		BusinessCategoryID: nonZeroInt64P(c.BusinessCategoryID),
+	}
+
+	if c.TaxExempt {
+		request.TaxExempt = ToPtr(true)
+	}
+	if c.Sexuality != 0 {
+		sexuality := c.Sexuality
+		request.Sexuality = &sexuality
+	}
+	if c.RealOrFinancial != 0 {
+		realOrFinancial := c.RealOrFinancial
+		request.RealOrFinancial = &realOrFinancial
+	}
+
+	if c.NationalCode != "" {
+		nationalCode := NationalCode(c.NationalCode)
+		if err := nationalCode.Validate(); err != nil {
+			return request, err
+		}
+		request.NationalCode = &nationalCode
+	}
+	if c.FinCode != "" {
+		finCode := NumericCode(c.FinCode)
+		if err := finCode.Validate(); err != nil {
+			return request, err
+		}
+		request.FinCode = &finCode
+	}
+	if c.IDNo != "" {
+		idNo := NumericCode(c.IDNo)
+		if err := idNo.Validate(); err != nil {
+			return request, err
+		}
+		request.IDNo = &idNo
+	}
+
+	var err error
+	if request.RegisterNumber, err = parseInt64P("register number", c.RegisterNumber); err != nil {
+		return request, err
+	}
+
+	return request, nil
+}
+
+func nonEmptyStringP(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func nonZeroInt64P(value int64) *int64 {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
+func parseInt64P(name string, value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	return &v, nil
+}
+
+// CustomerChanges are the changed fields of a business keyed by their Arpa names
+type CustomerChanges map[string]json.RawMessage
+
+// IsEmpty returns true if nothing has changed
+func (c CustomerChanges) IsEmpty() bool {
+	return len(c) == 0
+}
+
+// DiffCustomers returns the fields of the create/update request which differ between the old and the new customer.
+// Fields which are maintained inside Arpa only (credit, representor...) are never part of the changes.
+// Fields which the request omits when they are cleared, e.g. TaxExempt, are sent with their cleared value.
+func DiffCustomers(old Customer, new Customer) (CustomerChanges, error) {
+	oldFields, err := customerRequestFields(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := customerRequestFields(new)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(CustomerChanges)
+	for key, value := range newFields {
+		if !bytes.Equal(oldFields[key], value) {
+			changes[key] = value
+		}
+	}
+	for key := range oldFields {
+		if _, ok := newFields[key]; !ok {
+			changes[key] = clearedCustomerField(key)
+		}
+	}
+	return changes, nil
+}
+
+// clearedCustomerField returns the value clearing the field which the create request omits
+func clearedCustomerField(key string) json.RawMessage {
+	if key == string(constant.TaxExemptField) {
+		return json.RawMessage("false")
+	}
+	return json.RawMessage("null")
+}
+
+func customerRequestFields(customer Customer) (map[string]json.RawMessage, error) {
+	request, err := customer.ToCreateCustomerRequest()
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(b, &fields)
+	return fields, err
+}
+
+// EnsureCustomerPath tells how EnsureCustomer obtained the business
+type EnsureCustomerPath string
+
+const (
+	// CustomerFound means the business was found by its mobile number or its national code
+	CustomerFound EnsureCustomerPath = "found"
+	// CustomerExisted means Arpa reported the business as existing when creating it
+	CustomerExisted EnsureCustomerPath = "existed"
+	// CustomerCreated means a new business has been created
+	CustomerCreated EnsureCustomerPath = "created"
+)
+
+// EnsureCustomerResult is the business returned by EnsureCustomer
+type EnsureCustomerResult struct {
+	BusinessID   BusinessID
+	BusinessCode string
+	Path         EnsureCustomerPath
+}
+
+// CustomerBalance is the account balance of a business, positive when the business is a debtor
+type CustomerBalance struct {
+	BusinessID BusinessID `json:"BusinessID"`
+	Balance    Money      `json:"Balance"`
+}
+
+// CreditCheckResult is the decision of CheckCustomerCredit with its details
+type CreditCheckResult struct {
+	Allowed     bool
+	Amount      Money
+	Balance     Money
+	CreditLimit Money
+	Available   Money
+	Reason      string
+}
+
+// checkCredit decides whether the amount can be sold on credit.
+// Businesses flagged WithoutCredit are not credit checked, the limit of the others is their UnCashCredit.
+func checkCredit(customer Datum2, balance Money, amount Money) CreditCheckResult {
+	result := CreditCheckResult{
+		Amount:      amount,
+		Balance:     balance,
+		CreditLimit: customer.UnCashCredit,
+		Available:   customer.UnCashCredit.Sub(balance),
+	}
+
+	switch {
+	case customer.WithoutCredit.Bool():
+		result.Allowed = true
+		result.Reason = "credit check is disabled for the customer"
+	case customer.InActive.Bool():
+		result.Reason = "customer is inactive"
+	case amount.GreaterThan(result.Available.Decimal):
+		result.Reason = "amount exceeds the available credit"
+	default:
+		result.Allowed = true
+	}
+	return result
+}
+
+// IsActive reports whether the business is not deactivated
+func (c Customer) IsActive() bool {
+	return !c.Inactive
+}
+
+// IsActive reports whether the business is not deactivated
+func (d Datum2) IsActive() bool {
+	return !d.InActive.Bool()
+}
+
+// InactiveFilter selects the businesses of the lookups and lists by their InActive flag
+type InactiveFilter int
+
+const (
+	// InactiveDefault uses the filter of the client, see WithInactiveFilter
+	InactiveDefault InactiveFilter = iota
+	// IncludeInactive returns the active and the deactivated businesses, the default of the client
+	IncludeInactive
+	// ExcludeInactive returns only the active businesses
+	ExcludeInactive
+	// OnlyInactive returns only the deactivated businesses
+	OnlyInactive
+)
+
+// WithInactiveFilter sets the filter of the customer lookups and lists which do not set one,
+// e.g. ExcludeInactive so that the deactivated businesses are not found anymore.
+// Arpa is not asked to filter: the businesses are filtered after they are received.
+func WithInactiveFilter(filter InactiveFilter) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.inactiveFilter = filter
+	}
+}
+
+// keep reports whether a business with the flag passes the filter
+func (f InactiveFilter) keep(inactive bool) bool {
+	switch f {
+	case ExcludeInactive:
+		return !inactive
+	case OnlyInactive:
+		return inactive
+	default:
+		return true
+	}
+}
+
+// or returns the filter, or fallback when it is InactiveDefault
+func (f InactiveFilter) or(fallback InactiveFilter) InactiveFilter {
+	if f == InactiveDefault {
+		return fallback
+	}
+	return f
+}
+
+// filterCustomers removes the businesses which do not pass the filter from the response
+func filterCustomers(response *GetCustomerResponse, filter InactiveFilter) {
+	if filter == InactiveDefault || filter == IncludeInactive {
+		return
+	}
+	kept := response.Data[:0]
+	for _, datum := range response.Data {
+		if filter.keep(!datum.IsActive()) {
+			kept = append(kept, datum)
+		}
+	}
+	response.Data = kept
+}
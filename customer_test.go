@@ -0,0 +1,327 @@
+package goarpa_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateCustomerExisted(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]bool{
+		`{"data":{"BusinessId":"42","BusinessCode":"1001","Existed":"0"},"error":null}`:       false,
+		`{"data":[{"BusinessID":42,"BusinessCode":1001,"Existed":true}],"error":null}`:        true,
+		`{"data":{"BusinessId":"42","BusinessCode":"1001","Existed":"True"},"error":null}`:    true,
+		`{"data":[{"BusinessId":"42","BusinessCode":"1001","Existed":"False"}],"error":null}`: false,
+	}
+	for body, existed := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}))
+
+		client := goarpa.NewClient(server.URL)
+		response, err := client.CreateCustomer(context.Background(), "token", []*http.Cookie{{Name: "session", Value: "1"}}, goarpa.CreateCustomerRequest{BusName: "Test"})
+		server.Close()
+
+		require.NotNil(t, response, body)
+		assert.Equal(t, int64(42), response.Data.BusinessID.Int64(), body)
+		assert.Equal(t, goarpa.EnforcedString("1001"), response.Data.BusinessCode, body)
+		if !existed {
+			assert.NoError(t, err, body)
+			continue
+		}
+
+		require.True(t, errors.Is(err, goarpa.ErrCustomerAlreadyExists), body)
+		var existsErr *goarpa.CustomerExistsError
+		require.True(t, errors.As(err, &existsErr), body)
+		assert.Equal(t, goarpa.BusinessID(42), existsErr.BusinessID, body)
+	}
+}
+
+func Test_DiffCustomers(t *testing.T) {
+	t.Parallel()
+	old := goarpa.Customer{ID: 42, Name: "Test", Mobile: "09121234567", CityID: 3, CheckCredit: goarpa.NewMoney(1000)}
+	updated := old
+	updated.Mobile = "09127654321"
+	updated.CheckCredit = goarpa.NewMoney(2000)
+
+	changes, err := goarpa.DiffCustomers(old, updated)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.JSONEq(t, `"09127654321"`, string(changes["Mobile"]))
+
+	changes, err = goarpa.DiffCustomers(old, old)
+	require.NoError(t, err)
+	assert.True(t, changes.IsEmpty())
+
+	// clearing the exemption is sent although the request omits it
+	exempt := old
+	exempt.TaxExempt = true
+	changes, err = goarpa.DiffCustomers(exempt, old)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.JSONEq(t, `false`, string(changes["TaxExempt"]))
+
+	changes, err = goarpa.DiffCustomers(old, exempt)
+	require.NoError(t, err)
+	assert.JSONEq(t, `true`, string(changes["TaxExempt"]))
+}
+
+func Test_CustomerRoundTrip(t *testing.T) {
+	t.Parallel()
+	var datum goarpa.Datum2
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"BusinessID": "42", "BusinessCode": "1001", "BusinessName": "علی رضایی", "Name": "علی", "Family": "رضایی",
+		"NationalCode": "0013542419", "IDNo": "123", "FinCode": "456", "RegisterNumber": "789",
+		"Sexuality": "1", "RealOrFinancial": "2", "Mobile": "09120000000", "PhoneNo": "02166001122",
+		"Email": "ali@example.com", "Address": "تهران", "ProvinceID": "8", "CityID": "301", "BusinessCategoryID": "3",
+		"UnCashCredit": "25000000.0000", "InActive": "False", "TaxExempt": "True",
+		"Creation_Date": "2023-06-11T09:12:44.387"
+	}`), &datum))
+
+	customer := datum.ToCustomer()
+	assert.Equal(t, goarpa.BusinessID(42), customer.ID)
+	assert.Equal(t, "1001", customer.Code)
+	assert.Equal(t, "علی رضایی", customer.Name)
+	assert.Equal(t, "علی", customer.FirstName)
+	assert.Equal(t, "رضایی", customer.LastName)
+	assert.Equal(t, goarpa.SexualityMale, customer.Sexuality)
+	assert.Equal(t, goarpa.RealOrFinancial(2), customer.RealOrFinancial)
+	assert.Equal(t, int64(8), customer.ProvinceID)
+	assert.Equal(t, int64(301), customer.CityID)
+	assert.Equal(t, "25000000", customer.UnCashCredit.String())
+	assert.False(t, customer.Inactive)
+	assert.True(t, customer.TaxExempt)
+	assert.Equal(t, 2023, customer.CreatedAt.Year())
+
+	request, err := customer.ToCreateCustomerRequest()
+	require.NoError(t, err)
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"BusName": "علی رضایی", "Name": "علی", "Family": "رضایی", "NationalCode": "0013542419", "IDNo": "123",
+		"FinCode": "456", "RegisterNumber": 789, "Sexuality": "1", "RealOrFinancial": 2, "Mobile": "09120000000",
+		"PhoneNo": "02166001122", "Email": "ali@example.com", "Address": "تهران", "ProvinceId": 8, "CityId": 301,
+		"BusinessCategoryId": 3, "BirthDate": null, "TaxExempt": true
+	}`, string(data))
+}
+
+func Test_CustomerRoundTripInvalidEnums(t *testing.T) {
+	t.Parallel()
+	// the unknown values of Arpa are dropped instead of failing the conversion
+	customer := goarpa.Datum2{BusinessName: "Ali", Sexuality: "9", RealOrFinancial: "x"}.ToCustomer()
+	assert.Zero(t, customer.Sexuality)
+	assert.Zero(t, customer.RealOrFinancial)
+
+	request, err := customer.ToCreateCustomerRequest()
+	require.NoError(t, err)
+	assert.Nil(t, request.Sexuality)
+	assert.Nil(t, request.RealOrFinancial)
+
+	// the enums set out of range on a customer are rejected when the request is marshalled
+	customer.Sexuality = goarpa.Sexuality(9)
+	request, err = customer.ToCreateCustomerRequest()
+	require.NoError(t, err)
+	_, err = json.Marshal(request)
+	assert.ErrorContains(t, err, "invalid sexuality: 9")
+}
+
+func Test_CustomerRoundTripEmptyFields(t *testing.T) {
+	t.Parallel()
+	customer := goarpa.Datum2{}.ToCustomer()
+	assert.Equal(t, goarpa.Customer{}, customer)
+
+	// the empty fields are left nil so that they are not overwritten
+	request, err := customer.ToCreateCustomerRequest()
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.CreateCustomerRequest{}, request)
+
+	for _, invalid := range []goarpa.Customer{
+		{NationalCode: "0013542418"},
+		{FinCode: "12a"},
+		{IDNo: "x"},
+		{RegisterNumber: "12a"},
+	} {
+		_, err := invalid.ToCreateCustomerRequest()
+		assert.Error(t, err, "%+v", invalid)
+	}
+}
+
+func Test_EnsureCustomer(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"BusinessId":"42","BusinessCode":"1001","Existed":"1"},"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	result, err := client.EnsureCustomer(context.Background(), "token", []*http.Cookie{{Name: "session", Value: "1"}}, goarpa.CreateCustomerRequest{
+		BusName: "Test",
+		Mobile:  goarpa.StringP("09121234567"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.CustomerExisted, result.Path)
+	assert.Equal(t, goarpa.BusinessID(42), result.BusinessID)
+}
+
+func Test_EnsureCustomerByNationalCode(t *testing.T) {
+	t.Parallel()
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			return
+		}
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("NationalCode") == "0012345679" {
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"42","BusinessCode":"1001"}],"error":null}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	cookie := []*http.Cookie{{Name: "session", Value: "1"}}
+	nationalCode := goarpa.NationalCode("0012345679")
+
+	// the mobile is not found
+	result, err := client.EnsureCustomer(context.Background(), "token", cookie, goarpa.CreateCustomerRequest{
+		BusName:      "Test",
+		Mobile:       goarpa.StringP("09121234567"),
+		NationalCode: &nationalCode,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.CustomerFound, result.Path)
+	assert.Equal(t, goarpa.BusinessID(42), result.BusinessID)
+	assert.Equal(t, []string{"MobileNo=09121234567", "NationalCode=0012345679"}, queries)
+
+	// there is no mobile
+	queries = nil
+	result, err = client.EnsureCustomer(context.Background(), "token", cookie, goarpa.CreateCustomerRequest{
+		BusName:      "Test",
+		NationalCode: &nationalCode,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.CustomerFound, result.Path)
+	assert.Equal(t, "1001", result.BusinessCode)
+	assert.Equal(t, []string{"NationalCode=0012345679"}, queries)
+}
+
+func Test_CheckCustomerCredit(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/serv/api/GetBusiness":
+			assert.Equal(t, "42", r.URL.Query().Get("BusinessID"))
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"42","BusinessCode":"1001","UnCashCredit":1000000,"WithoutCredit":"0"}],"error":null}`))
+		case "/balance":
+			assert.Equal(t, "42", r.URL.Query().Get("BusinessID"))
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":42,"Balance":"800000"}],"error":null}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	cookie := []*http.Cookie{{Name: "session", Value: "1"}}
+
+	_, err := client.CheckCustomerCredit(context.Background(), "token", cookie, 42, goarpa.NewMoney(300000))
+	require.ErrorIs(t, err, goarpa.ErrNotSupported)
+
+	client.Config.GetCustomerBalanceEndpoint = "balance"
+	result, err := client.CheckCustomerCredit(context.Background(), "token", cookie, 42, goarpa.NewMoney(300000))
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "200000", result.Available.String())
+
+	result, err = client.CheckCustomerCredit(context.Background(), "token", cookie, 42, goarpa.NewMoney(200000))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func Test_TextNormalization(t *testing.T) {
+	t.Parallel()
+	var queries []string
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+			_, _ = w.Write([]byte(`{"data":{"BusinessId":"1"}}`))
+			return
+		}
+		queries = append(queries, r.URL.RawQuery)
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithTextNormalization())
+	ctx := context.Background()
+	name := "علي"
+	_, err := client.CreateCustomer(ctx, "token", nil, goarpa.CreateCustomerRequest{BusName: "فروشگاه  كتاب", Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "علي", name)
+	_, _ = client.GetCustomerByBusinessCode(ctx, "token", nil, "ك۱۲")
+	_, _ = client.GetServiceByItemCode(ctx, "token", nil, "۷۷")
+
+	require.Len(t, bodies, 1)
+	assert.Equal(t, "فروشگاه کتاب", bodies[0]["BusName"])
+	assert.Equal(t, "علی", bodies[0]["Name"])
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[0], url.QueryEscape("ک12"))
+	assert.Contains(t, queries[1], "77")
+}
+
+func Test_CustomerAttributes(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			assert.Equal(t, "42", r.URL.Query().Get("BusinessID"))
+			_, _ = w.Write([]byte(`{"data":[{"AttributeName":"segment","AttributeValue":"vip"},{"AttributeName":"source","AttributeValue":"web"}],"error":null}`))
+			return
+		}
+		var request map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, map[string]interface{}{
+			"BusinessID": float64(42),
+			"Attributes": []interface{}{
+				map[string]interface{}{"AttributeName": "segment", "AttributeValue": "churned"},
+				map[string]interface{}{"AttributeName": "tier", "AttributeValue": "2"},
+			},
+		}, request)
+		_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	ctx := context.Background()
+	_, err := client.GetCustomerAttributes(ctx, "token", nil, 42)
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+
+	client.Config.GetCustomerAttributesEndpoint = "attributes"
+	client.Config.SetCustomerAttributesEndpoint = "attributes"
+	attributes, err := client.GetCustomerAttributes(ctx, "token", nil, 42)
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.CustomerAttributes{"segment": "vip", "source": "web"}, attributes)
+
+	require.NoError(t, client.SetCustomerAttributes(ctx, "token", nil, 42, goarpa.CustomerAttributes{"tier": "2", "segment": "churned"}))
+}
@@ -0,0 +1,127 @@
+package goarpa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxDownloadResumes is the number of times DownloadFile resumes an interrupted download
+const maxDownloadResumes = 3
+
+// ErrIncompleteDownload is matched by the error DownloadFile returns when the body is shorter than its Content-Length
+var ErrIncompleteDownload = errors.New("incomplete download")
+
+// DownloadFile streams the body of a GET to the endpoint into w, e.g. an attachment or a report export,
+// without buffering it in memory, and returns the number of bytes written.
+// The length of the body is checked against its Content-Length. When the connection breaks and the server
+// accepts byte ranges, the download is resumed from where it stopped.
+func (g *GoArpa) DownloadFile(ctx context.Context, accessToken string, cookie []*http.Cookie, endpoint string, params interface{}, w io.Writer) (int64, error) {
+	const errMessage = "could not download file"
+
+	url, err := g.endpointURL(endpoint)
+	if err != nil {
+		return 0, errors.Wrap(err, errMessage)
+	}
+
+	queryParams, err := GetQueryParams(params)
+	if err != nil {
+		return 0, errors.Wrap(err, errMessage)
+	}
+
+	var written int64
+	total := int64(-1)
+	for resumes := 0; ; resumes++ {
+		req := g.GetRequestWithBearerAuthWithCookie(context.WithValue(ctx, streamingContextKey, true), accessToken, cookie).
+			SetQueryParams(queryParams).
+			SetDoNotParseResponse(true)
+		if written > 0 {
+			req.SetHeader("Range", fmt.Sprintf("bytes=%d-", written))
+		}
+
+		resp, err := req.Get(url)
+		if err := checkForError(resp, err, errMessage); err != nil {
+			if resp != nil && resp.RawBody() != nil {
+				resp.RawBody().Close()
+			}
+			return written, err
+		}
+
+		if written == 0 {
+			total = resp.RawResponse.ContentLength
+		} else if err := checkContentRange(resp.RawResponse, written, total); err != nil {
+			resp.RawBody().Close()
+			return written, errors.Wrap(err, errMessage)
+		}
+
+		dst := &downloadWriter{w: w}
+		n, err := io.Copy(dst, resp.RawBody())
+		resp.RawBody().Close()
+		written += n
+		if dst.err != nil {
+			return written, errors.Wrap(dst.err, errMessage)
+		}
+
+		switch {
+		case total >= 0 && written == total:
+			return written, nil
+		case total < 0 && err == nil:
+			return written, nil
+		case ctx.Err() != nil:
+			return written, errors.Wrap(ctx.Err(), errMessage)
+		case !acceptsRanges(resp.RawResponse) || resumes == maxDownloadResumes:
+			if err == nil {
+				err = fmt.Errorf("%w: %d of %d bytes", ErrIncompleteDownload, written, total)
+			}
+			return written, errors.Wrap(err, errMessage)
+		}
+	}
+}
+
+// acceptsRanges reports whether the server allows resuming the download
+func acceptsRanges(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusPartialContent || strings.EqualFold(resp.Header.Get("Accept-Ranges"), "bytes")
+}
+
+// checkContentRange checks that a resumed response continues the body at offset
+func checkContentRange(resp *http.Response, offset int64, total int64) error {
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("server did not resume the download, status %s", resp.Status)
+	}
+
+	// Content-Range: bytes 100-199/200, the size may be * when unknown
+	contentRange := resp.Header.Get("Content-Range")
+	span, sizeText, ok := strings.Cut(strings.TrimPrefix(contentRange, "bytes "), "/")
+	startText, _, _ := strings.Cut(span, "-")
+	start, err := strconv.ParseInt(startText, 10, 64)
+	if !ok || err != nil {
+		return fmt.Errorf("invalid Content-Range %q", contentRange)
+	}
+	size, err := strconv.ParseInt(sizeText, 10, 64)
+	if err != nil {
+		size = -1
+	}
+	if start != offset || (total >= 0 && size >= 0 && size != total) {
+		return fmt.Errorf("unexpected Content-Range %q resuming at %d", contentRange, offset)
+	}
+	return nil
+}
+
+// downloadWriter records the errors of the destination, which must not be retried as a broken download
+type downloadWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (d *downloadWriter) Write(p []byte) (int, error) {
+	n, err := d.w.Write(p)
+	if err != nil {
+		d.err = err
+	}
+	return n, err
+}
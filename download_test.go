@@ -0,0 +1,63 @@
+package goarpa_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DownloadFileResumes(t *testing.T) {
+	t.Parallel()
+	content := strings.Repeat("0123456789", 1000)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "7", r.URL.Query().Get("AttachmentID"))
+		if requests.Add(1) == 1 {
+			// the connection breaks after half of the body
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write([]byte(content[:len(content)/2]))
+			return
+		}
+		assert.Equal(t, "bytes=5000-", r.Header.Get("Range"))
+		http.ServeContent(w, r, "export.csv", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	var buf bytes.Buffer
+	n, err := client.DownloadFile(context.Background(), "token", nil, "attachments", map[string]string{"AttachmentID": "7"}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), n)
+	assert.Equal(t, content, buf.String())
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func Test_DownloadFileIncomplete(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		_, _ = w.Write([]byte("truncated"))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	var buf bytes.Buffer
+	n, err := client.DownloadFile(context.Background(), "token", nil, "attachments", nil, &buf)
+	require.Error(t, err)
+	assert.Equal(t, int64(9), n)
+
+	_, err = client.DownloadFile(context.Background(), "token", nil, "", nil, &buf)
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+}
@@ -0,0 +1,76 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// FinalizeTransactionRequest moves a draft transaction to the final state
+type FinalizeTransactionRequest struct {
+	TransactionID TransactionID `json:"TransactionID"`
+	TransStateID  TransState    `json:"TransStateId"`
+}
+
+// CreateDraftTransaction creates the transaction as a draft, which is not posted to the ledger until it is
+// finalized with FinalizeTransaction, e.g. so that the documents of an integration are reviewed first.
+// The state of the transaction and the TransStateID of the InstallationProfile are ignored.
+func (g *GoArpa) CreateDraftTransaction(ctx context.Context, accessToken string, transaction CreateTransactionRequest) (*CreateTransactionResponse, error) {
+	return createDraftTransaction(ctx, g, accessToken, transaction)
+}
+
+// createDraftTransaction creates the transaction with the draft state
+func createDraftTransaction(ctx context.Context, client GoArpaIface, accessToken string, transaction CreateTransactionRequest) (*CreateTransactionResponse, error) {
+	transaction.Data.TransStateID = TransStateDraft
+	response, err := client.CreateTransaction(ctx, accessToken, transaction)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create draft transaction")
+	}
+	return response, nil
+}
+
+// FinalizeTransaction posts a draft transaction of CreateDraftTransaction to the ledger.
+// It returns ErrNotSupported when the installation has no finalize endpoint.
+func (g *GoArpa) FinalizeTransaction(ctx context.Context, accessToken string, cookie []*http.Cookie, transactionID TransactionID) (*CreateTransactionResponse, error) {
+	const errMessage = "could not finalize transaction"
+
+	url, err := g.endpointURL(g.config().FinalizeTransactionEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+	if transactionID == 0 {
+		return nil, errors.Wrap(errors.New("no transaction"), errMessage)
+	}
+
+	body, err := marshalBody(FinalizeTransactionRequest{TransactionID: transactionID, TransStateID: TransStateFinal})
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var response CreateTransactionResponse
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody(body).
+		SetResult(&response)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &CreateTransactionResponse{Data: []Datum{{TransactionID: transactionID}}}, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "FinalizeTransaction", url, body, resp, err, response.Error, func() map[string]string {
+		return map[string]string{"TransactionID": transactionID.String()}
+	})
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, response.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
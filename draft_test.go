@@ -0,0 +1,77 @@
+package goarpa_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DraftTransaction(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/serv/api/NewTransaction":
+			data := body["Data"].(map[string]interface{})
+			assert.Equal(t, 1.0, data["TransStateId"])
+			_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":7}],"error":null}`))
+		case "/finalize":
+			assert.Equal(t, 42.0, body["TransactionID"])
+			assert.Equal(t, 2.0, body["TransStateId"])
+			_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":7}],"error":null}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithInstallationProfile(goarpa.InstallationProfile{TransStateID: goarpa.TransStateFinal}))
+	ctx := context.Background()
+	created, err := client.CreateDraftTransaction(ctx, "token", goarpa.CreateTransactionRequest{Data: goarpa.Data{TransStateID: goarpa.TransStateFinal, FactorTypeID: goarpa.FactorTypeSale}})
+	require.NoError(t, err)
+	transactionID := created.Data[0].TransactionID
+
+	_, err = client.FinalizeTransaction(ctx, "token", nil, transactionID)
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+
+	client.Config.FinalizeTransactionEndpoint = "finalize"
+	finalized, err := client.FinalizeTransaction(ctx, "token", nil, transactionID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), finalized.Data[0].TransNumber)
+}
+
+func Test_SimulatedClientDraftTransaction(t *testing.T) {
+	t.Parallel()
+	simulated := goarpa.NewSimulatedClient()
+	pen := simulated.AddItem(goarpa.GetServiceResponse{ItemCode: "PEN", ItemName: "Pen", SalePrice: goarpa.NewMoney(1000)})
+	ctx := context.Background()
+
+	customer, err := simulated.CreateCustomer(ctx, goarpa.SimulatedToken, nil, goarpa.CreateCustomerRequest{BusName: "Ali"})
+	require.NoError(t, err)
+	created, err := simulated.CreateDraftTransaction(ctx, goarpa.SimulatedToken, goarpa.CreateTransactionRequest{
+		Data:  goarpa.Data{BusinessID: customer.Data.BusinessID, FactorTypeID: goarpa.FactorTypeSale},
+		Items: []goarpa.TransactionItem{{ItemID: pen, Qty: 1}},
+	})
+	require.NoError(t, err)
+	transactionID := created.Data[0].TransactionID
+
+	transactions, err := simulated.GetTransactions(ctx, goarpa.SimulatedToken, nil, goarpa.GetTransactionsParams{})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.TransStateDraft, transactions.Data[0].TransStateID)
+
+	_, err = simulated.FinalizeTransaction(ctx, goarpa.SimulatedToken, nil, transactionID)
+	require.NoError(t, err)
+	transactions, err = simulated.GetTransactions(ctx, goarpa.SimulatedToken, nil, goarpa.GetTransactionsParams{})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.TransStateFinal, transactions.Data[0].TransStateID)
+
+	_, err = simulated.FinalizeTransaction(ctx, goarpa.SimulatedToken, nil, transactionID)
+	assert.ErrorContains(t, err, "is not a draft")
+}
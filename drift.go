@@ -0,0 +1,204 @@
+package goarpa
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// SchemaDriftKind is the kind of a difference between a payload and its model
+type SchemaDriftKind string
+
+const (
+	// SchemaDriftUnknownField is a field of the payload which the model does not have
+	SchemaDriftUnknownField SchemaDriftKind = "unknown field"
+	// SchemaDriftTypeMismatch is a value of the payload which does not match the type of the model field
+	SchemaDriftTypeMismatch SchemaDriftKind = "type mismatch"
+)
+
+// SchemaDrift describes a difference between an Arpa payload and the model it is decoded into
+type SchemaDrift struct {
+	URL    string
+	Path   string
+	Kind   SchemaDriftKind
+	Detail string
+}
+
+// String returns a string representation of the drift
+func (d SchemaDrift) String() string {
+	return fmt.Sprintf("%s: %s at %s (%s)", d.URL, d.Kind, d.Path, d.Detail)
+}
+
+// SchemaDriftHandler receives the differences found in a response
+type SchemaDriftHandler func(drift SchemaDrift)
+
+// WithSchemaDriftHandler enables the strict decoding mode: every response is compared with its model
+// and unknown fields and type mismatches are reported to the handler instead of being silently ignored
+func WithSchemaDriftHandler(handler SchemaDriftHandler) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.schemaDriftHandler = handler
+	}
+}
+
+// WithSchemaDriftLogging enables the strict decoding mode logging the differences
+func WithSchemaDriftLogging() func(*GoArpa) {
+	return WithSchemaDriftHandler(func(drift SchemaDrift) {
+		log.Printf("goarpa: schema drift: %s", drift)
+	})
+}
+
+func (g *GoArpa) detectSchemaDrift(_ *resty.Client, resp *resty.Response) error {
+	if g.schemaDriftHandler == nil || resp.Request == nil || resp.Request.Result == nil || len(resp.Body()) == 0 {
+		return nil
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(resp.Body(), &payload); err != nil {
+		return nil
+	}
+
+	url := resp.Request.URL
+	compareSchema("$", payload, reflect.TypeOf(resp.Request.Result), func(path string, kind SchemaDriftKind, detail string) {
+		g.schemaDriftHandler(SchemaDrift{URL: url, Path: path, Kind: kind, Detail: detail})
+	})
+	return nil
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+type schemaDriftReporter func(path string, kind SchemaDriftKind, detail string)
+
+// compareSchema walks the decoded payload along the model type
+func compareSchema(path string, payload interface{}, t reflect.Type, report schemaDriftReporter) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if payload == nil || t.Kind() == reflect.Interface {
+		return
+	}
+
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		// custom decoders are trusted, only the objects they wrap are compared
+		switch value := payload.(type) {
+		case map[string]interface{}:
+			if t.Kind() == reflect.Slice {
+				compareSchema(path, value, t.Elem(), report)
+			} else if t.Kind() == reflect.Struct {
+				compareStruct(path, value, t, report)
+			}
+		case []interface{}:
+			itemType := t
+			if t.Kind() == reflect.Slice {
+				itemType = t.Elem()
+			}
+			if itemType.Kind() == reflect.Struct || itemType.Kind() == reflect.Pointer {
+				for i, item := range value {
+					compareSchema(fmt.Sprintf("%s[%d]", path, i), item, itemType, report)
+				}
+			}
+		}
+		return
+	}
+
+	mismatch := func() {
+		report(path, SchemaDriftTypeMismatch, fmt.Sprintf("got JSON %s, expected %s", jsonKind(payload), t))
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := payload.(map[string]interface{})
+		if !ok {
+			mismatch()
+			return
+		}
+		compareStruct(path, obj, t, report)
+	case reflect.Map:
+		obj, ok := payload.(map[string]interface{})
+		if !ok {
+			mismatch()
+			return
+		}
+		for key, value := range obj {
+			compareSchema(path+"."+key, value, t.Elem(), report)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := payload.([]interface{})
+		if !ok {
+			mismatch()
+			return
+		}
+		for i, item := range items {
+			compareSchema(fmt.Sprintf("%s[%d]", path, i), item, t.Elem(), report)
+		}
+	case reflect.String:
+		if _, ok := payload.(string); !ok {
+			mismatch()
+		}
+	case reflect.Bool:
+		if _, ok := payload.(bool); !ok {
+			mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, ok := payload.(float64); !ok {
+			mismatch()
+		}
+	}
+}
+
+func compareStruct(path string, obj map[string]interface{}, t reflect.Type, report schemaDriftReporter) {
+	fields := make(map[string]reflect.Type)
+	collectJSONFields(t, fields)
+
+	for key, value := range obj {
+		fieldType, ok := fields[strings.ToLower(key)]
+		if !ok {
+			report(path+"."+key, SchemaDriftUnknownField, fmt.Sprintf("%s has no field %q", t, key))
+			continue
+		}
+		compareSchema(path+"."+key, value, fieldType, report)
+	}
+}
+
+// collectJSONFields collects the lower cased JSON names of the fields, like encoding/json matches them
+func collectJSONFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			collectJSONFields(indirectType(field.Type), fields)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+}
+
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
@@ -0,0 +1,35 @@
+package goarpa
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// WithDryRun makes the client skip all mutating calls.
+// The fully built request is logged and a synthesized response is returned instead.
+func WithDryRun() func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.dryRun = true
+	}
+}
+
+// logDryRun logs the request which would have been sent
+func logDryRun(req *resty.Request, method string, url string) {
+	header := req.Header.Clone()
+	if header.Get("Authorization") != "" {
+		header.Set("Authorization", "<redacted>")
+	}
+
+	body, ok := req.Body.([]byte)
+	if !ok {
+		var err error
+		if body, err = json.Marshal(req.Body); err != nil {
+			body = []byte(err.Error())
+		}
+	}
+
+	log.Printf("goarpa: dry run: %s %s query=%v header=%v body=%s", method, url, req.QueryParam, http.Header(header), body)
+}
@@ -0,0 +1,470 @@
+// Code generated by internal/cmd/gen-endpoints from endpoints.json. DO NOT EDIT.
+
+package goarpa
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// GeneratedEndpoints are the endpoints of the methods generated from endpoints.json
+type GeneratedEndpoints struct {
+	GetDocAliasesEndpoint    string
+	GetSettlementsEndpoint   string
+	GetDepartmentsEndpoint   string
+	OpenShiftEndpoint        string
+	GetShiftSummaryEndpoint  string
+	CloseShiftEndpoint       string
+	GetItemGroupsEndpoint    string
+	GetWarehousesEndpoint    string
+	GetBranchesEndpoint      string
+	GetCurrencyRatesEndpoint string
+	VoidTransactionEndpoint  string
+}
+
+// defaultGeneratedEndpoints returns the default endpoints of the generated methods
+func defaultGeneratedEndpoints() GeneratedEndpoints {
+	return GeneratedEndpoints{}
+}
+
+// DocAlias is a numbering series of the transactions
+type DocAlias struct {
+	ID   StringInt64 `json:"DocAliasID"`
+	Name string      `json:"DocAliasName"`
+}
+
+// Settlement is a settlement type of the transactions, e.g. cash or credit
+type Settlement struct {
+	ID   StringInt64 `json:"SettlementID"`
+	Name string      `json:"SettlementName"`
+}
+
+// Department is a department of the business the transactions are recorded in
+type Department struct {
+	ID   StringInt64 `json:"DepartmentID"`
+	Name string      `json:"DepartmentName"`
+}
+
+// OpenShiftRequest opens a cashier shift
+type OpenShiftRequest struct {
+	CashierID  int64  `json:"CashierID"`
+	TerminalID string `json:"TerminalID,omitempty"`
+	// OpeningCash is the cash in the drawer when the shift opens
+	OpeningCash Money `json:"OpeningCash"`
+}
+
+// Shift is a cashier shift of the POS module
+type Shift struct {
+	ShiftID     StringInt64 `json:"ShiftID"`
+	CashierID   StringInt64 `json:"CashierID"`
+	TerminalID  string      `json:"TerminalID"`
+	OpenedAt    *CustomTime `json:"OpenDate"`
+	OpeningCash Money       `json:"OpeningCash"`
+}
+
+// ShiftParams select the shift of GetShiftSummary
+type ShiftParams struct {
+	ShiftID int64 `json:"ShiftID"`
+}
+
+// ShiftSummary are the totals of a shift by settlement
+type ShiftSummary struct {
+	ShiftID          StringInt64 `json:"ShiftID"`
+	TransactionCount StringInt64 `json:"TransactionCount"`
+	CashTotal        Money       `json:"CashTotal"`
+	CardTotal        Money       `json:"CardTotal"`
+	CreditTotal      Money       `json:"CreditTotal"`
+	Total            Money       `json:"Total"`
+	// CountedCash is the cash counted when the shift closed
+	CountedCash Money `json:"CountedCash"`
+	// CashDifference is the counted cash minus the expected cash, negative when cash is missing
+	CashDifference Money `json:"CashDifference"`
+	// ClosedAt is nil while the shift is open
+	ClosedAt *CustomTime `json:"CloseDate"`
+}
+
+// CloseShiftRequest closes a cashier shift
+type CloseShiftRequest struct {
+	ShiftID int64 `json:"ShiftID"`
+	// CountedCash is the cash in the drawer when the shift closes
+	CountedCash Money `json:"CountedCash"`
+}
+
+// ItemGroup is a group of items, e.g. a category of the catalog
+type ItemGroup struct {
+	ID StringInt64 `json:"IAGroupID"`
+	// ParentID is zero for the top level groups
+	ParentID StringInt64 `json:"ParentID"`
+	Code     string      `json:"IAGroupCode"`
+	Name     string      `json:"IAGroupName"`
+}
+
+// Warehouse is a warehouse the stock of the items is kept in
+type Warehouse struct {
+	ID   StringInt64 `json:"StockID"`
+	Name string      `json:"StockName"`
+}
+
+// Branch is a store of a multi-branch retailer
+type Branch struct {
+	ID   StringInt64 `json:"BranchID"`
+	Name string      `json:"BranchName"`
+	// WarehouseID is the warehouse the branch sells from
+	WarehouseID StringInt64 `json:"StockID"`
+}
+
+// CurrencyRate is a foreign currency and its rate
+type CurrencyRate struct {
+	ID   StringInt64 `json:"CurrencyID"`
+	Code string      `json:"CurrencyCode"`
+	Name string      `json:"CurrencyName"`
+	// Rate is the value of one unit of the currency in the local currency
+	Rate     Money       `json:"CurrencyRate"`
+	RateDate *CustomTime `json:"RateDate"`
+}
+
+// VoidTransactionRequest voids a transaction
+type VoidTransactionRequest struct {
+	TransactionID TransactionID `json:"TransactionID"`
+}
+
+// GetDocAliases returns the document aliases of the installation, see InstallationProfile
+func (g *GoArpa) GetDocAliases(ctx context.Context, accessToken string, cookie []*http.Cookie) (*APIResponse[DocAlias], error) {
+	const errMessage = "could not get document aliases"
+
+	url, err := g.endpointURL(g.config().GetDocAliasesEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[DocAlias]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetResult(&result)
+
+	resp, err := req.Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetSettlements returns the settlement types of the installation, see InstallationProfile
+func (g *GoArpa) GetSettlements(ctx context.Context, accessToken string, cookie []*http.Cookie) (*APIResponse[Settlement], error) {
+	const errMessage = "could not get settlements"
+
+	url, err := g.endpointURL(g.config().GetSettlementsEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[Settlement]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetResult(&result)
+
+	resp, err := req.Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetDepartments returns the departments of the installation, see InstallationProfile
+func (g *GoArpa) GetDepartments(ctx context.Context, accessToken string, cookie []*http.Cookie) (*APIResponse[Department], error) {
+	const errMessage = "could not get departments"
+
+	url, err := g.endpointURL(g.config().GetDepartmentsEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[Department]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetResult(&result)
+
+	resp, err := req.Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// OpenShift opens a cashier shift of the POS module. The transactions are attributed to it with Data.ShiftID.
+func (g *GoArpa) OpenShift(ctx context.Context, accessToken string, cookie []*http.Cookie, request OpenShiftRequest) (*APIResponse[Shift], error) {
+	const errMessage = "could not open shift"
+
+	url, err := g.endpointURL(g.config().OpenShiftEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	body, err := marshalBody(request)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[Shift]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody(body).
+		SetResult(&result)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &result, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "OpenShift", url, body, resp, err, result.Error, nil)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetShiftSummary returns the totals of a shift so far, the X-report of the POS module
+func (g *GoArpa) GetShiftSummary(ctx context.Context, accessToken string, cookie []*http.Cookie, request ShiftParams) (*APIResponse[ShiftSummary], error) {
+	const errMessage = "could not get shift summary"
+
+	url, err := g.endpointURL(g.config().GetShiftSummaryEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	queryParams, err := GetQueryParams(request)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[ShiftSummary]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetQueryParams(queryParams).
+		SetResult(&result)
+
+	resp, err := req.Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CloseShift closes a cashier shift and returns its summary, the Z-report of the POS module
+func (g *GoArpa) CloseShift(ctx context.Context, accessToken string, cookie []*http.Cookie, request CloseShiftRequest) (*APIResponse[ShiftSummary], error) {
+	const errMessage = "could not close shift"
+
+	url, err := g.endpointURL(g.config().CloseShiftEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	body, err := marshalBody(request)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[ShiftSummary]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody(body).
+		SetResult(&result)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &result, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "CloseShift", url, body, resp, err, result.Error, nil)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetItemGroups returns the item groups as a flat list, see GetItemGroupTree
+func (g *GoArpa) GetItemGroups(ctx context.Context, accessToken string, cookie []*http.Cookie) (*APIResponse[ItemGroup], error) {
+	const errMessage = "could not get item groups"
+
+	url, err := g.endpointURL(g.config().GetItemGroupsEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[ItemGroup]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetResult(&result)
+
+	resp, err := req.Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetWarehouses returns the warehouses of the installation, see ValidateTransactionWarehouses
+func (g *GoArpa) GetWarehouses(ctx context.Context, accessToken string, cookie []*http.Cookie) (*APIResponse[Warehouse], error) {
+	const errMessage = "could not get warehouses"
+
+	url, err := g.endpointURL(g.config().GetWarehousesEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[Warehouse]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetResult(&result)
+
+	resp, err := req.Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetBranches returns the branches of the installation, see WithBranch and WithCallBranch
+func (g *GoArpa) GetBranches(ctx context.Context, accessToken string, cookie []*http.Cookie) (*APIResponse[Branch], error) {
+	const errMessage = "could not get branches"
+
+	url, err := g.endpointURL(g.config().GetBranchesEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[Branch]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetResult(&result)
+
+	resp, err := req.Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetCurrencyRates returns the currencies of the installation with their current rates, see Data.SetCurrency
+func (g *GoArpa) GetCurrencyRates(ctx context.Context, accessToken string, cookie []*http.Cookie) (*APIResponse[CurrencyRate], error) {
+	const errMessage = "could not get currency rates"
+
+	url, err := g.endpointURL(g.config().GetCurrencyRatesEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[CurrencyRate]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetResult(&result)
+
+	resp, err := req.Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// VoidTransaction voids a transaction, e.g. the draft posted by ValidateSetup
+func (g *GoArpa) VoidTransaction(ctx context.Context, accessToken string, cookie []*http.Cookie, request VoidTransactionRequest) (*APIResponse[Datum], error) {
+	const errMessage = "could not void transaction"
+
+	url, err := g.endpointURL(g.config().VoidTransactionEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	body, err := marshalBody(request)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var result APIResponse[Datum]
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody(body).
+		SetResult(&result)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &result, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "VoidTransaction", url, body, resp, err, result.Error, nil)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,163 @@
+package goarpa
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Sexuality is the gender of a real person
+type Sexuality int64
+
+const (
+	// SexualityMale is a male person
+	SexualityMale Sexuality = 1
+	// SexualityFemale is a female person
+	SexualityFemale Sexuality = 2
+)
+
+// Valid returns true if the value is a known sexuality
+func (s Sexuality) Valid() bool {
+	return s == SexualityMale || s == SexualityFemale
+}
+
+// MarshalJSON marshals the sexuality as a quoted number, as Arpa expects
+func (s Sexuality) MarshalJSON() ([]byte, error) {
+	return marshalEnum(int64(s), s.Valid(), "sexuality", true)
+}
+
+// UnmarshalJSON unmarshals a quoted or a bare number
+func (s *Sexuality) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalEnum(data)
+	if err != nil {
+		return err
+	}
+	if !Sexuality(v).Valid() {
+		return fmt.Errorf("invalid sexuality: %d", v)
+	}
+	*s = Sexuality(v)
+	return nil
+}
+
+// RealOrFinancial tells whether a business is a real (natural) or a financial (legal) person
+type RealOrFinancial int64
+
+const (
+	// RealPerson is a natural person
+	RealPerson RealOrFinancial = 1
+	// FinancialPerson is a legal person, e.g. a company
+	FinancialPerson RealOrFinancial = 2
+)
+
+// Valid returns true if the value is a known person type
+func (r RealOrFinancial) Valid() bool {
+	return r == RealPerson || r == FinancialPerson
+}
+
+// MarshalJSON marshals the person type as a number
+func (r RealOrFinancial) MarshalJSON() ([]byte, error) {
+	return marshalEnum(int64(r), r.Valid(), "real or financial", false)
+}
+
+// UnmarshalJSON unmarshals a quoted or a bare number
+func (r *RealOrFinancial) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalEnum(data)
+	if err != nil {
+		return err
+	}
+	if !RealOrFinancial(v).Valid() {
+		return fmt.Errorf("invalid real or financial: %d", v)
+	}
+	*r = RealOrFinancial(v)
+	return nil
+}
+
+// TransState is the state of a transaction
+type TransState int64
+
+const (
+	// TransStateDraft is a transaction which is not posted to the ledger yet
+	TransStateDraft TransState = 1
+	// TransStateFinal is a finalized transaction
+	TransStateFinal TransState = 2
+)
+
+// Valid returns true if the value is a known transaction state
+func (s TransState) Valid() bool {
+	return s == TransStateDraft || s == TransStateFinal
+}
+
+// MarshalJSON marshals the transaction state as a number
+func (s TransState) MarshalJSON() ([]byte, error) {
+	return marshalEnum(int64(s), s.Valid(), "transaction state", false)
+}
+
+// UnmarshalJSON unmarshals a quoted or a bare number, null is ignored
+func (s *TransState) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	v, err := unmarshalEnum(data)
+	if err != nil {
+		return err
+	}
+	if !TransState(v).Valid() {
+		return fmt.Errorf("invalid transaction state: %d", v)
+	}
+	*s = TransState(v)
+	return nil
+}
+
+// FactorType is the type of an invoice
+type FactorType int64
+
+const (
+	// FactorTypeSale is a sale invoice
+	FactorTypeSale FactorType = 1
+	// FactorTypeSaleReturn is a sale return invoice
+	FactorTypeSaleReturn FactorType = 2
+	// FactorTypePurchase is a purchase invoice
+	FactorTypePurchase FactorType = 3
+	// FactorTypePurchaseReturn is a purchase return invoice
+	FactorTypePurchaseReturn FactorType = 4
+)
+
+// Valid returns true if the value is a known invoice type
+func (f FactorType) Valid() bool {
+	return f >= FactorTypeSale && f <= FactorTypePurchaseReturn
+}
+
+// MarshalJSON marshals the invoice type as a number
+func (f FactorType) MarshalJSON() ([]byte, error) {
+	return marshalEnum(int64(f), f.Valid(), "factor type", false)
+}
+
+// UnmarshalJSON unmarshals a quoted or a bare number, null is ignored
+func (f *FactorType) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	v, err := unmarshalEnum(data)
+	if err != nil {
+		return err
+	}
+	if !FactorType(v).Valid() {
+		return fmt.Errorf("invalid factor type: %d", v)
+	}
+	*f = FactorType(v)
+	return nil
+}
+
+func marshalEnum(value int64, valid bool, name string, quoted bool) ([]byte, error) {
+	if !valid {
+		return nil, fmt.Errorf("invalid %s: %d", name, value)
+	}
+	if quoted {
+		return marshalQuotedInt(value), nil
+	}
+	return marshalInt(value), nil
+}
+
+func unmarshalEnum(data []byte) (int64, error) {
+	return strconv.ParseInt(string(bytes.Trim(data, `"`)), 10, 64)
+}
@@ -1,9 +1,48 @@
 package goarpa
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 )
 
+// ErrNotSupported is returned when the Arpa endpoint of an operation is not available or not configured
+var ErrNotSupported = errors.New("operation is not supported")
+
+// ErrInvalidBasePath is matched by the errors of the malformed base paths, see ParseBasePath
+var ErrInvalidBasePath = errors.New("invalid base path")
+
+// ErrSessionExpired is matched by the errors of the calls rejected because the token or the cookies
+// of the session expired, whether Arpa answered with an error message or with a redirect to its login page.
+// The session must be renewed with GetAdminToken, see TokenManager.Invalidate.
+var ErrSessionExpired = errors.New("session expired")
+
+// ErrCustomerNotFound is returned when a lookup does not find the business
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// ErrTransactionNotFound is returned when a lookup does not find the transaction
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ErrCustomerAlreadyExists is matched by the error CreateCustomer returns when the customer already existed
+var ErrCustomerAlreadyExists = errors.New("customer already exists")
+
+// CustomerExistsError is returned by CreateCustomer when Arpa reports that the customer already existed
+type CustomerExistsError struct {
+	BusinessID   BusinessID
+	BusinessCode string
+}
+
+// Error stringifies the CustomerExistsError
+func (e *CustomerExistsError) Error() string {
+	return fmt.Sprintf("%s: business id %d, business code %s", ErrCustomerAlreadyExists, e.BusinessID, e.BusinessCode)
+}
+
+// Is allows matching the error with errors.Is(err, ErrCustomerAlreadyExists)
+func (e *CustomerExistsError) Is(target error) bool {
+	return target == ErrCustomerAlreadyExists
+}
+
 // HTTPErrorResponse is a model of an error response
 type HTTPErrorResponse struct {
 	Error       string `json:"error,omitempty"`
@@ -36,3 +75,84 @@ func (e HTTPErrorResponse) String() string {
 func (e HTTPErrorResponse) NotEmpty() bool {
 	return len(e.Error) > 0 || len(e.Message) > 0 || len(e.Description) > 0
 }
+
+// ArpaError is the error object of the Arpa response envelopes.
+// Arpa sends it either as a plain string or as an object.
+type ArpaError struct {
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message,omitempty"`
+	MessageEn string `json:"messageEn,omitempty"`
+}
+
+var (
+	arpaErrorCodeKeys      = []string{"code", "errorcode", "status"}
+	arpaErrorMessageKeys   = []string{"message", "messagefa", "msg", "errormessage", "error"}
+	arpaErrorMessageEnKeys = []string{"messageen", "englishmessage", "error_description"}
+)
+
+// UnmarshalJSON decodes the error from a JSON string or a JSON object
+func (e *ArpaError) UnmarshalJSON(data []byte) error {
+	*e = ArpaError{}
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+
+	if data[0] == '"' {
+		return json.Unmarshal(data, &e.Message)
+	}
+
+	if data[0] != '{' {
+		var value EnforcedString
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		e.Message = string(value)
+		return nil
+	}
+
+	var obj map[string]EnforcedString
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	fields := make(map[string]string, len(obj))
+	for key, value := range obj {
+		fields[strings.ToLower(key)] = string(value)
+	}
+	e.Code = firstNotEmpty(fields, arpaErrorCodeKeys)
+	e.Message = firstNotEmpty(fields, arpaErrorMessageKeys)
+	e.MessageEn = firstNotEmpty(fields, arpaErrorMessageEnKeys)
+	return nil
+}
+
+// NotEmpty validates that error is not empty
+func (e *ArpaError) NotEmpty() bool {
+	return e != nil && (len(e.Code) > 0 || len(e.Message) > 0 || len(e.MessageEn) > 0)
+}
+
+// Error returns the Persian message, falling back to the English message and the code
+func (e *ArpaError) Error() string {
+	var res strings.Builder
+	if len(e.Code) > 0 {
+		res.WriteString(e.Code)
+	}
+	message := e.Message
+	if len(message) == 0 {
+		message = e.MessageEn
+	}
+	if len(message) > 0 {
+		if res.Len() > 0 {
+			res.WriteString(": ")
+		}
+		res.WriteString(message)
+	}
+	return res.String()
+}
+
+func firstNotEmpty(fields map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := fields[key]; len(value) > 0 {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+)
+
+// ExportResult is an item streamed by the exporters, or the error which stopped the export
+type ExportResult[T any] struct {
+	Item T
+	Err  error
+}
+
+// ExportCustomers streams all the businesses into the returned channel.
+// Up to concurrency pages are fetched at the same time and the order of the pages is preserved.
+// The channel is closed when the export is complete, failed or the context is done.
+func (g *GoArpa) ExportCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams, concurrency int) <-chan ExportResult[Customer] {
+	filter := params.Inactive.or(g.inactiveFilter)
+	params.Inactive = IncludeInactive
+	return filterExport(ctx, exportPages(ctx, params.ListParams, concurrency, func(ctx context.Context, paging ListParams) ([]Customer, error) {
+		params := params
+		params.ListParams = paging
+		result, err := g.GetCustomers(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		customers := make([]Customer, 0, len(result.Data))
+		for _, datum := range result.Data {
+			customers = append(customers, datum.ToCustomer())
+		}
+		return customers, nil
+	}), func(customer Customer) bool {
+		return filter.keep(customer.Inactive)
+	})
+}
+
+// ExportTransactions streams all the transactions into the returned channel, see ExportCustomers
+func (g *GoArpa) ExportTransactions(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams, concurrency int) <-chan ExportResult[Transaction] {
+	return exportPages(ctx, params.ListParams, concurrency, func(ctx context.Context, paging ListParams) ([]Transaction, error) {
+		params := params
+		params.ListParams = paging
+		result, err := g.GetTransactions(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+}
+
+// ExportItems streams all the items and services into the returned channel, see ExportCustomers
+func (g *GoArpa) ExportItems(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams, concurrency int) <-chan ExportResult[GetServiceResponse] {
+	return exportPages(ctx, params.ListParams, concurrency, func(ctx context.Context, paging ListParams) ([]GetServiceResponse, error) {
+		params := params
+		params.ListParams = paging
+		result, err := g.GetItems(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+}
+
+// filterExport forwards the items of in which are kept and the errors, see filterSeq
+func filterExport[T any](ctx context.Context, in <-chan ExportResult[T], keep func(T) bool) <-chan ExportResult[T] {
+	out := make(chan ExportResult[T], cap(in))
+	go func() {
+		defer close(out)
+		for result := range in {
+			if result.Err == nil && !keep(result.Item) {
+				continue
+			}
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				// drained so that the producer, which stops with the context, is not blocked
+				for range in {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// exportPages fetches the pages with a sliding window of concurrent requests,
+// emitting their items in page order until a page has less items than the page size
+func exportPages[T any](ctx context.Context, paging ListParams, concurrency int, fetch func(ctx context.Context, paging ListParams) ([]T, error)) <-chan ExportResult[T] {
+	paging = paging.normalize()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	out := make(chan ExportResult[T], paging.PageSize)
+
+	go func() {
+		defer close(out)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type page struct {
+			items []T
+			err   error
+		}
+		pending := make([]chan page, 0, concurrency)
+		next := paging.Page
+		start := func() {
+			// buffered, so that the fetches which are not needed anymore never block
+			ch := make(chan page, 1)
+			pageParams := paging
+			pageParams.Page = next
+			next++
+			go func() {
+				items, err := fetch(ctx, pageParams)
+				ch <- page{items: items, err: err}
+			}()
+			pending = append(pending, ch)
+		}
+		send := func(result ExportResult[T]) bool {
+			select {
+			case out <- result:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for len(pending) < concurrency {
+			start()
+		}
+		for len(pending) > 0 {
+			result := <-pending[0]
+			pending = pending[1:]
+
+			if result.err != nil {
+				send(ExportResult[T]{Err: result.err})
+				return
+			}
+			for _, item := range result.items {
+				if !send(ExportResult[T]{Item: item}) {
+					return
+				}
+			}
+			if len(result.items) < paging.PageSize {
+				return
+			}
+			start()
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,180 @@
+// Package faultinject injects failures, latency and canned responses into the requests of a client,
+// so that the services using this package can test how they handle the errors of Arpa.
+package faultinject
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/go-resty/resty/v2"
+)
+
+// Rule describes the fault injected into the matching requests.
+// A rule with neither Err nor StatusCode only delays the requests by its Latency.
+type Rule struct {
+	// Endpoint restricts the rule to the URLs ending with the endpoint, e.g. client.Config.GetCustomerEndpoint
+	Endpoint string
+	// Method restricts the rule to a HTTP method
+	Method string
+	// Skip is the number of matching requests let through before the rule applies
+	Skip int
+	// Times is the number of requests the rule applies to, 0 for every request
+	Times int
+	// Latency delays the requests
+	Latency time.Duration
+	// Err is returned as a transport error
+	Err error
+	// StatusCode, Header and Body are the response returned instead of calling the server
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// Nth returns a rule failing only the nth request of the endpoint with the status code
+func Nth(endpoint string, n int, statusCode int) Rule {
+	return Rule{Endpoint: endpoint, Skip: n - 1, Times: 1, StatusCode: statusCode}
+}
+
+type rule struct {
+	Rule
+	matched int
+}
+
+// Injector is a http.RoundTripper applying the rules before calling the next transport
+type Injector struct {
+	mu    sync.Mutex
+	next  http.RoundTripper
+	rules []*rule
+}
+
+// New returns an injector calling next for the requests which are not faulted
+func New(next http.RoundTripper) *Injector {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Injector{next: next}
+}
+
+// Install inserts an injector in front of the transport of the client
+func Install(client *goarpa.GoArpa) *Injector {
+	httpClient := client.RestyClient().GetClient()
+	injector := New(httpClient.Transport)
+	httpClient.Transport = injector
+	return injector
+}
+
+// Add adds a rule, the first matching rule applies
+func (i *Injector) Add(r Rule) *Injector {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rules = append(i.rules, &rule{Rule: r})
+	return i
+}
+
+// Reset removes all the rules
+func (i *Injector) Reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rules = nil
+}
+
+// RoundTrip applies the first matching rule to the request
+func (i *Injector) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := i.match(req)
+	if r == nil {
+		return i.next.RoundTrip(req)
+	}
+
+	if r.Latency > 0 {
+		timer := time.NewTimer(r.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	switch {
+	case r.Err != nil:
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, r.Err
+	case r.StatusCode != 0:
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		header := r.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+			StatusCode:    r.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(r.Body)),
+			ContentLength: int64(len(r.Body)),
+			Request:       req,
+		}, nil
+	}
+	return i.next.RoundTrip(req)
+}
+
+// match returns the first rule applying to the request and counts it
+func (i *Injector) match(req *http.Request) *rule {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, r := range i.rules {
+		if r.Method != "" && !strings.EqualFold(r.Method, req.Method) {
+			continue
+		}
+		if r.Endpoint != "" && !strings.HasSuffix(req.URL.Path, "/"+strings.TrimPrefix(r.Endpoint, "/")) {
+			continue
+		}
+
+		r.matched++
+		if r.matched <= r.Skip {
+			continue
+		}
+		if r.Times > 0 && r.matched > r.Skip+r.Times {
+			continue
+		}
+		return r
+	}
+	return nil
+}
+
+// FailRequest fails requests and returns an error
+//
+//	err - returned error or nil to return the default error
+//	failN - number of requests to be failed
+//	skipN = number of requests to be executed and not failed by this function
+func FailRequest(client *goarpa.GoArpa, err error, failN, skipN int) *goarpa.GoArpa {
+	client.RestyClient().OnBeforeRequest(
+		func(c *resty.Client, r *resty.Request) error {
+			if skipN > 0 {
+				skipN--
+				return nil
+			}
+			if failN == 0 {
+				return nil
+			}
+			failN--
+			if err == nil {
+				err = fmt.Errorf("an error for request: %+v", r)
+			}
+			return err
+		},
+	)
+	return client
+}
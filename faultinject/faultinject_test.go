@@ -0,0 +1,49 @@
+package faultinject_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/faultinject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Injector(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Data":[{"BusinessID":"1"}],"Error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	injector := faultinject.Install(client).
+		Add(faultinject.Nth(client.Config.GetCustomerEndpoint, 2, http.StatusServiceUnavailable)).
+		Add(faultinject.Rule{Endpoint: client.Config.GetItemEndpoint, Latency: 20 * time.Millisecond})
+
+	ctx := context.Background()
+	_, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.NoError(t, err)
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	var apiErr *goarpa.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.Code)
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.GetServiceByItemCode(ctx, "token", nil, "1")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	injector.Reset()
+	injector.Add(faultinject.Rule{Err: errors.New("connection reset")})
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	assert.ErrorContains(t, err, "connection reset")
+}
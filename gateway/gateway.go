@@ -0,0 +1,308 @@
+// Package gateway is an HTTP server fronting Arpa with clean JSON APIs.
+//
+// It is an anti-corruption layer packaged with the client: the callers get typed camelCase models and
+// consistent error responses, while the login, the session cookies, the throttling of the calls to
+// Arpa and the quirks of its responses stay inside the gateway. The routes are:
+//
+//	POST /v1/customers                    create the customer unless a business has its mobile number
+//	GET  /v1/customers/by-mobile/{mobile} find a customer by its mobile number
+//	GET  /v1/customers/by-code/{code}     find a customer by its business code
+//	POST /v1/transactions                 create a transaction
+//	GET  /v1/items/{code}                 find an item by its code
+//	GET  /healthz                         liveness probe
+//	GET  /metrics                         metrics in the Prometheus text format
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+)
+
+// Options configure a Gateway
+type Options struct {
+	// Rate is the maximum number of API requests per second forwarded to Arpa, unlimited when zero
+	Rate float64
+	// Burst is the number of requests allowed above the rate, one by default
+	Burst int
+	// MaxBodySize is the maximum size of the request bodies, 1MB by default
+	MaxBodySize int64
+}
+
+// Gateway is the http.Handler of the gateway
+type Gateway struct {
+	client  *goarpa.GoArpa
+	tokens  *goarpa.TokenManager
+	options Options
+	limiter *limiter
+	metrics *metrics
+	mux     *http.ServeMux
+}
+
+// New returns a gateway calling Arpa with the client, logging in with the token manager
+func New(client *goarpa.GoArpa, tokens *goarpa.TokenManager, options Options) *Gateway {
+	if options.Burst < 1 {
+		options.Burst = 1
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = 1 << 20
+	}
+
+	g := &Gateway{
+		client:  client,
+		tokens:  tokens,
+		options: options,
+		limiter: newLimiter(options.Rate, options.Burst),
+		metrics: newMetrics(),
+		mux:     http.NewServeMux(),
+	}
+	g.handle("POST /v1/customers", g.createCustomer)
+	g.handle("GET /v1/customers/by-mobile/{mobile}", g.getCustomerByMobile)
+	g.handle("GET /v1/customers/by-code/{code}", g.getCustomerByCode)
+	g.handle("POST /v1/transactions", g.createTransaction)
+	g.handle("GET /v1/items/{code}", g.getItem)
+	g.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	g.mux.Handle("GET /metrics", g.metrics)
+	return g
+}
+
+// ServeHTTP serves the gateway routes
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// handle registers an API route, rate limited and measured
+func (g *Gateway) handle(pattern string, handler func(r *http.Request) (int, any, error)) {
+	g.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		status := g.serve(w, r, handler)
+		g.metrics.observe(pattern, status, time.Since(start))
+	})
+}
+
+func (g *Gateway) serve(w http.ResponseWriter, r *http.Request, handler func(r *http.Request) (int, any, error)) int {
+	if wait := g.limiter.reserve(); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		return writeError(w, http.StatusTooManyRequests, "rate_limited", "too many requests")
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, g.options.MaxBodySize)
+	status, body, err := handler(r)
+	if err != nil {
+		status, code := errorStatus(err)
+		return writeError(w, status, code, err.Error())
+	}
+	return writeJSON(w, status, body)
+}
+
+// call runs fn with a session, logging in again once if Arpa rejects the token or the session expired
+func (g *Gateway) call(ctx context.Context, fn func(token string, cookies []*http.Cookie) error) error {
+	for attempt := 0; ; attempt++ {
+		token, cookies, err := g.tokens.Session(ctx)
+		if err != nil {
+			return err
+		}
+		err = fn(token, cookies)
+		var apiErr *goarpa.APIError
+		if attempt == 0 && (errors.Is(err, goarpa.ErrSessionExpired) || errors.As(err, &apiErr) && apiErr.Code == http.StatusUnauthorized) {
+			g.tokens.Invalidate()
+			continue
+		}
+		return err
+	}
+}
+
+func (g *Gateway) createCustomer(r *http.Request) (int, any, error) {
+	var request CustomerRequest
+	if err := decodeJSON(r, &request); err != nil {
+		return 0, nil, err
+	}
+	customer, err := request.toArpa()
+	if err != nil {
+		return 0, nil, badRequest(err)
+	}
+
+	var result *goarpa.EnsureCustomerResult
+	err = g.call(r.Context(), func(token string, cookies []*http.Cookie) error {
+		result, err = g.client.EnsureCustomer(r.Context(), token, cookies, customer)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	response := CustomerResponse{
+		BusinessID:   result.BusinessID.Int64(),
+		BusinessCode: result.BusinessCode,
+		Existed:      result.Path != goarpa.CustomerCreated,
+	}
+	if response.Existed {
+		return http.StatusOK, response, nil
+	}
+	return http.StatusCreated, response, nil
+}
+
+func (g *Gateway) getCustomerByMobile(r *http.Request) (int, any, error) {
+	return g.getCustomer(r, func(token string, cookies []*http.Cookie) (*goarpa.GetCustomerResponse, error) {
+		return g.client.GetCustomerByMobile(r.Context(), token, cookies, r.PathValue("mobile"))
+	})
+}
+
+func (g *Gateway) getCustomerByCode(r *http.Request) (int, any, error) {
+	return g.getCustomer(r, func(token string, cookies []*http.Cookie) (*goarpa.GetCustomerResponse, error) {
+		return g.client.GetCustomerByBusinessCode(r.Context(), token, cookies, r.PathValue("code"))
+	})
+}
+
+func (g *Gateway) getCustomer(r *http.Request, get func(token string, cookies []*http.Cookie) (*goarpa.GetCustomerResponse, error)) (int, any, error) {
+	var resp *goarpa.GetCustomerResponse
+	err := g.call(r.Context(), func(token string, cookies []*http.Cookie) error {
+		var err error
+		resp, err = get(token, cookies)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	customer, ok := resp.First()
+	if !ok {
+		return 0, nil, goarpa.ErrCustomerNotFound
+	}
+	return http.StatusOK, NewCustomer(customer.ToCustomer()), nil
+}
+
+func (g *Gateway) createTransaction(r *http.Request) (int, any, error) {
+	var request TransactionRequest
+	if err := decodeJSON(r, &request); err != nil {
+		return 0, nil, err
+	}
+	transaction, err := request.toArpa()
+	if err != nil {
+		return 0, nil, badRequest(err)
+	}
+
+	var resp *goarpa.CreateTransactionResponse
+	err = g.call(r.Context(), func(token string, _ []*http.Cookie) error {
+		resp, err = g.client.CreateTransaction(r.Context(), token, transaction)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	created, ok := resp.First()
+	if !ok {
+		return 0, nil, errors.New("arpa returned no transaction")
+	}
+	return http.StatusCreated, TransactionResponse{
+		TransactionID: created.TransactionID.Int64(),
+		TransNumber:   created.TransNumber,
+	}, nil
+}
+
+func (g *Gateway) getItem(r *http.Request) (int, any, error) {
+	code := r.PathValue("code")
+
+	var resp *goarpa.RetServiceResponse
+	err := g.call(r.Context(), func(token string, cookies []*http.Cookie) error {
+		var err error
+		resp, err = g.client.GetServiceByItemCode(r.Context(), token, cookies, code)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	item, ok := resp.First()
+	if !ok {
+		return 0, nil, &statusError{status: http.StatusNotFound, code: "not_found", err: fmt.Errorf("item %s not found", code)}
+	}
+	return http.StatusOK, Item{
+		ItemID:        item.ItemID.Int64(),
+		ItemCode:      item.ItemCode,
+		ItemName:      item.ItemName,
+		SalePrice:     item.SalePrice,
+		ConsumerPrice: item.ConsumerPrice,
+	}, nil
+}
+
+// statusError is an error answered with the given status and code
+type statusError struct {
+	status int
+	code   string
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+func badRequest(err error) error {
+	return &statusError{status: http.StatusBadRequest, code: "invalid_request", err: err}
+}
+
+func decodeJSON(r *http.Request, value any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return badRequest(fmt.Errorf("invalid body: %w", err))
+	}
+	return nil
+}
+
+// errorStatus maps an error to the HTTP status and the code of the error response
+func errorStatus(err error) (int, string) {
+	var (
+		statusErr *statusError
+		apiErr    *goarpa.APIError
+	)
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.status, statusErr.code
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "timeout"
+	case errors.Is(err, goarpa.ErrNotSupported):
+		return http.StatusNotImplemented, "not_supported"
+	case errors.Is(err, goarpa.ErrCustomerNotFound):
+		return http.StatusNotFound, "not_found"
+	case errors.Is(err, goarpa.ErrCustomerAlreadyExists):
+		return http.StatusConflict, "already_exists"
+	case errors.Is(err, goarpa.ErrSessionExpired):
+		return http.StatusBadGateway, "session_expired"
+	case errors.As(err, &apiErr) && apiErr.Type == goarpa.APIErrTypeArpa:
+		return http.StatusUnprocessableEntity, "rejected"
+	case errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests:
+		return http.StatusServiceUnavailable, "throttled"
+	case goarpa.IsRetryableError(err):
+		return http.StatusBadGateway, "unavailable"
+	default:
+		return http.StatusBadGateway, "upstream_error"
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) int {
+	return writeJSON(w, status, struct {
+		Error Error `json:"error"`
+	}{Error{Code: code, Message: message}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) int {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+	return status
+}
@@ -0,0 +1,135 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/gateway"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGateway(t *testing.T, options gateway.Options, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	arpa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "GetServiceToken") {
+			_, _ = w.Write([]byte("token"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+	t.Cleanup(arpa.Close)
+
+	client := goarpa.NewClient(arpa.URL)
+	tokens := goarpa.NewTokenManager(client, "user", "pass", goarpa.TokenManagerOptions{})
+	server := httptest.NewServer(gateway.New(client, tokens, options))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_GatewayGetCustomer(t *testing.T) {
+	t.Parallel()
+	server := newGateway(t, gateway.Options{}, func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, "09120000000") {
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"12","BusinessCode":"1001","BusinessName":"Ali","InActive":"1"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	})
+
+	resp, err := http.Get(server.URL + "/v1/customers/by-mobile/09120000000")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var customer gateway.Customer
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&customer))
+	assert.Equal(t, int64(12), customer.BusinessID)
+	assert.Equal(t, "Ali", customer.Name)
+	assert.True(t, customer.Inactive)
+
+	resp, err = http.Get(server.URL + "/v1/customers/by-mobile/09129999999")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	var body struct {
+		Error gateway.Error `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "not_found", body.Error.Code)
+}
+
+func Test_GatewayCreateTransaction(t *testing.T) {
+	t.Parallel()
+	server := newGateway(t, gateway.Options{}, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+	})
+
+	resp, err := http.Post(server.URL+"/v1/transactions", "application/json",
+		strings.NewReader(`{"businessId":7,"transState":1,"factorType":1,"items":[{"itemId":3,"qty":2,"price":"1500"}]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created gateway.TransactionResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.Equal(t, gateway.TransactionResponse{TransactionID: 42, TransNumber: 9}, created)
+
+	resp, err = http.Post(server.URL+"/v1/transactions", "application/json", strings.NewReader(`{"businessId":7,"transState":5}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func Test_GatewayRelogin(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := newGateway(t, gateway.Options{}, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"ItemID":"5","ItemCode":"A1","ItemName":"Pen","SalePrice":"1200"}]}`))
+	})
+
+	resp, err := http.Get(server.URL + "/v1/items/A1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var item gateway.Item
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&item))
+	assert.Equal(t, int64(5), item.ItemID)
+	assert.Equal(t, "1200", item.SalePrice.String())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func Test_GatewayRateLimitAndMetrics(t *testing.T) {
+	t.Parallel()
+	server := newGateway(t, gateway.Options{Rate: 0.001, Burst: 1}, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	})
+
+	resp, err := http.Get(server.URL + "/v1/items/A1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/v1/items/A1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	resp, err = http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	metrics, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(metrics), `goarpa_gateway_requests_total{route="GET /v1/items/{code}",status="404"} 1`)
+	assert.Contains(t, string(metrics), `goarpa_gateway_requests_total{route="GET /v1/items/{code}",status="429"} 1`)
+}
@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// metrics counts the requests of the API routes by status and sums their duration
+type metrics struct {
+	mu       sync.Mutex
+	requests map[metricKey]int64
+	duration map[string]time.Duration
+}
+
+type metricKey struct {
+	route  string
+	status int
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests: make(map[metricKey]int64),
+		duration: make(map[string]time.Duration),
+	}
+}
+
+func (m *metrics) observe(route string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[metricKey{route: route, status: status}]++
+	m.duration[route] += duration
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m.mu.Lock()
+	keys := make([]metricKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	routes := make([]string, 0, len(m.duration))
+	for route := range m.duration {
+		routes = append(routes, route)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].status < keys[j].status
+	})
+	sort.Strings(routes)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP goarpa_gateway_requests_total Requests served by route and status.")
+	fmt.Fprintln(w, "# TYPE goarpa_gateway_requests_total counter")
+	for _, key := range keys {
+		fmt.Fprintf(w, "goarpa_gateway_requests_total{route=%s,status=\"%d\"} %d\n", strconv.Quote(key.route), key.status, m.requests[key])
+	}
+	fmt.Fprintln(w, "# HELP goarpa_gateway_request_duration_seconds_sum Time spent serving the requests by route.")
+	fmt.Fprintln(w, "# TYPE goarpa_gateway_request_duration_seconds_sum counter")
+	for _, route := range routes {
+		fmt.Fprintf(w, "goarpa_gateway_request_duration_seconds_sum{route=%s} %g\n", strconv.Quote(route), m.duration[route].Seconds())
+	}
+	m.mu.Unlock()
+}
@@ -0,0 +1,205 @@
+package gateway
+
+import (
+	"errors"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+)
+
+// Customer is a business as returned by the gateway
+type Customer struct {
+	BusinessID         int64      `json:"businessId"`
+	BusinessCode       string     `json:"businessCode"`
+	Name               string     `json:"name"`
+	FirstName          string     `json:"firstName,omitempty"`
+	LastName           string     `json:"lastName,omitempty"`
+	NationalCode       string     `json:"nationalCode,omitempty"`
+	Mobile             string     `json:"mobile,omitempty"`
+	Phone              string     `json:"phone,omitempty"`
+	Email              string     `json:"email,omitempty"`
+	Address            string     `json:"address,omitempty"`
+	PostalCode         string     `json:"postalCode,omitempty"`
+	ProvinceID         int64      `json:"provinceId,omitempty"`
+	CityID             int64      `json:"cityId,omitempty"`
+	BusinessCategoryID int64      `json:"businessCategoryId,omitempty"`
+	Inactive           bool       `json:"inactive"`
+	CreatedAt          *time.Time `json:"createdAt,omitempty"`
+	ModifiedAt         *time.Time `json:"modifiedAt,omitempty"`
+}
+
+// CustomerRequest is the body of the customer creation
+type CustomerRequest struct {
+	Name               string                 `json:"name"`
+	FirstName          string                 `json:"firstName"`
+	LastName           string                 `json:"lastName"`
+	NationalCode       string                 `json:"nationalCode"`
+	Mobile             string                 `json:"mobile"`
+	Phone              string                 `json:"phone"`
+	Email              string                 `json:"email"`
+	Address            string                 `json:"address"`
+	ProvinceID         int64                  `json:"provinceId"`
+	CityID             int64                  `json:"cityId"`
+	BusinessCategoryID int64                  `json:"businessCategoryId"`
+	Sexuality          goarpa.Sexuality       `json:"sexuality"`
+	RealOrFinancial    goarpa.RealOrFinancial `json:"realOrFinancial"`
+}
+
+// CustomerResponse is the response of the customer creation
+type CustomerResponse struct {
+	BusinessID   int64  `json:"businessId"`
+	BusinessCode string `json:"businessCode"`
+	Existed      bool   `json:"existed"`
+}
+
+// TransactionItem is a line of a TransactionRequest
+type TransactionItem struct {
+	ItemID          int64        `json:"itemId"`
+	Qty             float64      `json:"qty"`
+	Price           goarpa.Money `json:"price"`
+	DiscountAmount  goarpa.Money `json:"discountAmount"`
+	DiscountPercent float64      `json:"discountPercent"`
+	TaxExempt       bool         `json:"taxExempt"`
+	FreeQty         float64      `json:"freeQty"`
+}
+
+// AddSub is an addition or deduction of a TransactionRequest
+type AddSub struct {
+	AddSubID int64        `json:"addSubId"`
+	Amount   goarpa.Money `json:"amount"`
+}
+
+// TransactionRequest is the body of the transaction creation
+type TransactionRequest struct {
+	BusinessID      int64             `json:"businessId"`
+	DocAliasID      int64             `json:"docAliasId"`
+	TransState      goarpa.TransState `json:"transState"`
+	FactorType      goarpa.FactorType `json:"factorType"`
+	CalcTaxAndToll  bool              `json:"calcTaxAndToll"`
+	DiscountAmount  goarpa.Money      `json:"discountAmount"`
+	DiscountPercent float64           `json:"discountPercent"`
+	DepartmentID    int64             `json:"departmentId"`
+	SettlementID    int64             `json:"settlementId"`
+	Description     string            `json:"description"`
+	Items           []TransactionItem `json:"items"`
+	AddSubs         []AddSub          `json:"addSubs"`
+}
+
+// TransactionResponse is the response of the transaction creation
+type TransactionResponse struct {
+	TransactionID int64 `json:"transactionId"`
+	TransNumber   int64 `json:"transNumber"`
+}
+
+// Item is an item as returned by the gateway
+type Item struct {
+	ItemID        int64        `json:"itemId"`
+	ItemCode      string       `json:"itemCode"`
+	ItemName      string       `json:"itemName"`
+	SalePrice     goarpa.Money `json:"salePrice"`
+	ConsumerPrice goarpa.Money `json:"consumerPrice"`
+}
+
+// Error is the body of the error responses
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewCustomer returns the business as returned by the gateway
+func NewCustomer(customer goarpa.Customer) Customer {
+	c := Customer{
+		BusinessID:         customer.ID.Int64(),
+		BusinessCode:       customer.Code,
+		Name:               customer.Name,
+		FirstName:          customer.FirstName,
+		LastName:           customer.LastName,
+		NationalCode:       customer.NationalCode,
+		Mobile:             customer.Mobile,
+		Phone:              customer.Phone,
+		Email:              customer.Email,
+		Address:            customer.Address,
+		PostalCode:         customer.PostalCode,
+		ProvinceID:         customer.ProvinceID,
+		CityID:             customer.CityID,
+		BusinessCategoryID: customer.BusinessCategoryID,
+		Inactive:           customer.Inactive,
+	}
+	if !customer.CreatedAt.IsZero() {
+		c.CreatedAt = &customer.CreatedAt
+	}
+	if !customer.ModifiedAt.IsZero() {
+		c.ModifiedAt = &customer.ModifiedAt
+	}
+	return c
+}
+
+func (r CustomerRequest) toArpa() (goarpa.CreateCustomerRequest, error) {
+	if r.Name == "" {
+		return goarpa.CreateCustomerRequest{}, errors.New("name is required")
+	}
+	if r.Sexuality != 0 && !r.Sexuality.Valid() {
+		return goarpa.CreateCustomerRequest{}, errors.New("invalid sexuality")
+	}
+	if r.RealOrFinancial != 0 && !r.RealOrFinancial.Valid() {
+		return goarpa.CreateCustomerRequest{}, errors.New("invalid realOrFinancial")
+	}
+
+	customer := goarpa.Customer{
+		Name:               r.Name,
+		FirstName:          r.FirstName,
+		LastName:           r.LastName,
+		NationalCode:       r.NationalCode,
+		Mobile:             r.Mobile,
+		Phone:              r.Phone,
+		Email:              r.Email,
+		Address:            r.Address,
+		ProvinceID:         r.ProvinceID,
+		CityID:             r.CityID,
+		BusinessCategoryID: r.BusinessCategoryID,
+		Sexuality:          r.Sexuality,
+		RealOrFinancial:    r.RealOrFinancial,
+	}
+	return customer.ToCreateCustomerRequest()
+}
+
+func (r TransactionRequest) toArpa() (goarpa.CreateTransactionRequest, error) {
+	if r.BusinessID == 0 {
+		return goarpa.CreateTransactionRequest{}, errors.New("businessId is required")
+	}
+	if len(r.Items) == 0 {
+		return goarpa.CreateTransactionRequest{}, errors.New("at least one item is required")
+	}
+
+	transaction := goarpa.CreateTransactionRequest{
+		Data: goarpa.Data{
+			BusinessID:           goarpa.BusinessID(r.BusinessID),
+			DocAliasID:           r.DocAliasID,
+			TransStateID:         r.TransState,
+			FactorTypeID:         r.FactorType,
+			TransDiscountAmount:  r.DiscountAmount,
+			TransDiscountPercent: r.DiscountPercent,
+			DepartmentID:         r.DepartmentID,
+			SettlementID:         r.SettlementID,
+			Description:          r.Description,
+		},
+	}
+	if r.CalcTaxAndToll {
+		transaction.Data.CalcTaxAndToll = 1
+	}
+	for _, item := range r.Items {
+		transaction.Items = append(transaction.Items, goarpa.TransactionItem{
+			ItemID:          goarpa.ItemID(item.ItemID),
+			Qty:             item.Qty,
+			Price:           item.Price,
+			DiscountAmount:  item.DiscountAmount,
+			DiscountPercent: item.DiscountPercent,
+			TaxExempt:       item.TaxExempt,
+			FreeQty:         item.FreeQty,
+		})
+	}
+	for _, addSub := range r.AddSubs {
+		transaction.AddSub = append(transaction.AddSub, goarpa.AddSub{AddSubID: addSub.AddSubID, TASAmount: addSub.Amount})
+	}
+	return transaction, nil
+}
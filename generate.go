@@ -0,0 +1,3 @@
+package goarpa
+
+//go:generate go run ./internal/cmd/gen-endpoints -spec endpoints.json -out endpoints_gen.go -test endpoints_gen_test.go
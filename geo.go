@@ -0,0 +1,329 @@
+package goarpa
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Province is a province of Iran, see Customer.ProvinceID
+type Province struct {
+	ID   StringInt64    `json:"ProvinceID"`
+	Name EnforcedString `json:"ProvinceName"`
+}
+
+// City is a city of a province, see Customer.CityID
+type City struct {
+	ID         StringInt64    `json:"CityID"`
+	ProvinceID StringInt64    `json:"ProvinceID"`
+	Name       EnforcedString `json:"CityName"`
+}
+
+// ProvincesResponse is the response of GetProvinces
+type ProvincesResponse = APIResponse[Province]
+
+// CitiesResponse is the response of GetCities
+type CitiesResponse = APIResponse[City]
+
+// GetCitiesParams select the cities of GetCities
+type GetCitiesParams struct {
+	// ProvinceID restricts the cities to a province, all the cities by default
+	ProvinceID *int64 `json:"ProvinceID,omitempty"`
+}
+
+// GeoDataset are the provinces and the cities known by the client
+type GeoDataset struct {
+	Provinces []Province `json:"Provinces"`
+	Cities    []City     `json:"Cities"`
+}
+
+// CitiesOf returns the cities of the province, all the cities when the province is 0
+func (d *GeoDataset) CitiesOf(provinceID int64) []City {
+	var cities []City
+	for _, city := range d.Cities {
+		if provinceID == 0 || city.ProvinceID.Int64() == provinceID {
+			cities = append(cities, city)
+		}
+	}
+	return cities
+}
+
+//go:embed geodata/iran.json
+var embeddedGeoDataset []byte
+
+// EmbeddedGeoDataset returns the 31 provinces of Iran and their capitals only. Their IDs are a numbering of the
+// client, the provinces in alphabetical order and the capital of a province its ID times 100 plus 1,
+// which is not verified against an installation: they must not be written to customers. The clients serve
+// them with a GeoFallbackError until the server returned its own, see GetProvinces and RefreshGeoDataset.
+var EmbeddedGeoDataset = sync.OnceValue(func() GeoDataset {
+	var dataset GeoDataset
+	if err := json.Unmarshal(embeddedGeoDataset, &dataset); err != nil {
+		panic(errors.Wrap(err, "invalid embedded geo dataset"))
+	}
+	return dataset
+})
+
+// ErrGeoFallback is matched by the errors returned by GetProvinces and GetCities with the entries
+// of the dataset of the client, see GeoFallbackError
+var ErrGeoFallback = errors.New("geo lookup served from the dataset of the client")
+
+// GeoFallbackError is returned by GetProvinces and GetCities along with the entries of the dataset of the client,
+// see GeoDataset, when the installation has no lookup endpoint or the server cannot be reached.
+// The entries may be stale, the caller decides whether they are good enough:
+//
+//	provinces, err := client.GetProvinces(ctx, token, cookie)
+//	var fallback *goarpa.GeoFallbackError
+//	if errors.As(err, &fallback) && !fallback.Embedded {
+//		err = nil // the last provinces returned by the server
+//	}
+type GeoFallbackError struct {
+	// Embedded is true when some entries come from EmbeddedGeoDataset, whose IDs are not those of the server
+	Embedded bool
+	// Err is the error of the lookup
+	Err error
+}
+
+// Error stringifies the GeoFallbackError
+func (e *GeoFallbackError) Error() string {
+	if e.Embedded {
+		return fmt.Sprintf("%s, with the embedded IDs: %s", ErrGeoFallback, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", ErrGeoFallback, e.Err)
+}
+
+// Is allows matching the error with errors.Is(err, ErrGeoFallback)
+func (e *GeoFallbackError) Is(target error) bool {
+	return target == ErrGeoFallback
+}
+
+// Unwrap returns the error of the lookup
+func (e *GeoFallbackError) Unwrap() error {
+	return e.Err
+}
+
+// geoCache is the dataset of a client, the embedded one updated with the entries returned by the server
+type geoCache struct {
+	mu sync.Mutex
+	// dataset is nil until the server returned entries
+	dataset *GeoDataset
+	// serverProvinces is true once the server returned the provinces
+	serverProvinces bool
+	// serverCities are the provinces whose cities the server returned, 0 for all the cities
+	serverCities map[int64]bool
+}
+
+// init copies the embedded dataset, the lock must be held
+func (c *geoCache) init() {
+	if c.dataset == nil {
+		embedded := EmbeddedGeoDataset()
+		c.dataset = &GeoDataset{Provinces: slices.Clone(embedded.Provinces), Cities: slices.Clone(embedded.Cities)}
+		c.serverCities = make(map[int64]bool)
+	}
+}
+
+func (c *geoCache) snapshot() GeoDataset {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	return GeoDataset{Provinces: slices.Clone(c.dataset.Provinces), Cities: slices.Clone(c.dataset.Cities)}
+}
+
+// provinces returns the provinces and whether they are the embedded ones
+func (c *geoCache) provinces() ([]Province, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	return slices.Clone(c.dataset.Provinces), !c.serverProvinces
+}
+
+// cities returns the cities of the province and whether some are the embedded ones
+func (c *geoCache) cities(provinceID int64) ([]City, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	return c.dataset.CitiesOf(provinceID), !c.serverCities[0] && (provinceID == 0 || !c.serverCities[provinceID])
+}
+
+func (c *geoCache) seedProvinces(provinces []Province) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	c.dataset.Provinces = slices.Clone(provinces)
+	sortGeo(c.dataset.Provinces, func(p Province) StringInt64 { return p.ID })
+	c.serverProvinces = true
+}
+
+// seedCities replaces the cities of the province, all the cities when the province is 0
+func (c *geoCache) seedCities(provinceID int64, cities []City) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	if provinceID == 0 {
+		c.dataset.Cities = slices.Clone(cities)
+	} else {
+		c.dataset.Cities = slices.DeleteFunc(c.dataset.Cities, func(city City) bool {
+			return city.ProvinceID.Int64() == provinceID
+		})
+		c.dataset.Cities = append(c.dataset.Cities, cities...)
+	}
+	sortGeo(c.dataset.Cities, func(c City) StringInt64 { return c.ID })
+	c.serverCities[provinceID] = true
+}
+
+// replace replaces the dataset with the entries of the server
+func (c *geoCache) replace(dataset GeoDataset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dataset = &dataset
+	c.serverProvinces = true
+	c.serverCities = map[int64]bool{0: true}
+}
+
+func sortGeo[T any](entries []T, id func(T) StringInt64) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return id(entries[i]) < id(entries[j])
+	})
+}
+
+// GeoDataset returns a copy of the dataset GetProvinces and GetCities fall back to: the embedded dataset
+// updated with the entries returned by the server
+func (g *GoArpa) GeoDataset() GeoDataset {
+	return g.geo.snapshot()
+}
+
+// GetProvinces returns the provinces, which the client keeps in its dataset, see GeoDataset. The provinces
+// of the dataset are returned with a *GeoFallbackError when the installation has no provinces endpoint
+// or the server cannot be reached.
+func (g *GoArpa) GetProvinces(ctx context.Context, accessToken string, cookie []*http.Cookie) ([]Province, error) {
+	const errMessage = "could not get provinces"
+
+	var response ProvincesResponse
+	err := g.getList(ctx, accessToken, cookie, g.config().GetProvincesEndpoint, nil, &response, errMessage)
+	if isGeoUnavailable(err) {
+		provinces, embedded := g.geo.provinces()
+		return provinces, &GeoFallbackError{Embedded: embedded, Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	g.geo.seedProvinces(response.Data)
+	return response.Data, nil
+}
+
+// GetCities returns the cities of the province, all the cities when the province is 0, which the client keeps
+// in its dataset, see GeoDataset. The cities of the dataset are returned with a *GeoFallbackError when
+// the installation has no cities endpoint or the server cannot be reached.
+func (g *GoArpa) GetCities(ctx context.Context, accessToken string, cookie []*http.Cookie, provinceID int64) ([]City, error) {
+	const errMessage = "could not get cities"
+
+	var params GetCitiesParams
+	if provinceID != 0 {
+		params.ProvinceID = &provinceID
+	}
+	var response CitiesResponse
+	err := g.getList(ctx, accessToken, cookie, g.config().GetCitiesEndpoint, params, &response, errMessage)
+	if isGeoUnavailable(err) {
+		cities, embedded := g.geo.cities(provinceID)
+		return cities, &GeoFallbackError{Embedded: embedded, Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	g.geo.seedCities(provinceID, response.Data)
+	return response.Data, nil
+}
+
+// isGeoUnavailable reports whether the lookups failed for want of an endpoint or a server,
+// rather than being refused by the server
+func isGeoUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrNotSupported) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Arpa != nil {
+		return false
+	}
+	return apiErr.Code == 0 || apiErr.Code == http.StatusNotFound || apiErr.Code >= http.StatusInternalServerError
+}
+
+// GeoChanges are the differences found by RefreshGeoDataset between the dataset of the client and the server
+type GeoChanges struct {
+	AddedProvinces   []Province
+	RemovedProvinces []Province
+	RenamedProvinces []Province
+	AddedCities      []City
+	RemovedCities    []City
+	// RenamedCities are the cities renamed or moved to another province
+	RenamedCities []City
+}
+
+// Empty reports whether the dataset of the client matched the server
+func (c GeoChanges) Empty() bool {
+	return len(c.AddedProvinces)+len(c.RemovedProvinces)+len(c.RenamedProvinces)+
+		len(c.AddedCities)+len(c.RemovedCities)+len(c.RenamedCities) == 0
+}
+
+// RefreshGeoDataset replaces the dataset of the client, see GeoDataset, with the provinces and the cities
+// of the server and returns the changes. Unlike GetProvinces and GetCities, it fails when the server has
+// no lookup endpoint or cannot be reached, and the dataset is then kept.
+func (g *GoArpa) RefreshGeoDataset(ctx context.Context, accessToken string, cookie []*http.Cookie) (*GeoChanges, error) {
+	const errMessage = "could not refresh geo dataset"
+
+	var provinces ProvincesResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetProvincesEndpoint, nil, &provinces, errMessage); err != nil {
+		return nil, err
+	}
+	var cities CitiesResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetCitiesEndpoint, GetCitiesParams{}, &cities, errMessage); err != nil {
+		return nil, err
+	}
+
+	refreshed := GeoDataset{Provinces: provinces.Data, Cities: cities.Data}
+	sortGeo(refreshed.Provinces, func(p Province) StringInt64 { return p.ID })
+	sortGeo(refreshed.Cities, func(c City) StringInt64 { return c.ID })
+
+	current := g.GeoDataset()
+	changes := &GeoChanges{}
+	changes.AddedProvinces, changes.RemovedProvinces, changes.RenamedProvinces = diffGeo(current.Provinces, refreshed.Provinces,
+		func(p Province) StringInt64 { return p.ID })
+	changes.AddedCities, changes.RemovedCities, changes.RenamedCities = diffGeo(current.Cities, refreshed.Cities,
+		func(c City) StringInt64 { return c.ID })
+	g.geo.replace(refreshed)
+	return changes, nil
+}
+
+// diffGeo returns the entries of refreshed missing from current, the entries of current missing from refreshed,
+// and the entries of refreshed which differ from current, matched by ID in the order of the lists
+func diffGeo[T comparable](current []T, refreshed []T, id func(T) StringInt64) (added []T, removed []T, changed []T) {
+	known := make(map[StringInt64]T, len(current))
+	for _, entry := range current {
+		known[id(entry)] = entry
+	}
+	found := make(map[StringInt64]bool, len(refreshed))
+	for _, entry := range refreshed {
+		found[id(entry)] = true
+		previous, ok := known[id(entry)]
+		switch {
+		case !ok:
+			added = append(added, entry)
+		case previous != entry:
+			changed = append(changed, entry)
+		}
+	}
+	for _, entry := range current {
+		if !found[id(entry)] {
+			removed = append(removed, entry)
+		}
+	}
+	return added, removed, changed
+}
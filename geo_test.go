@@ -0,0 +1,112 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EmbeddedGeoDataset(t *testing.T) {
+	t.Parallel()
+	dataset := goarpa.EmbeddedGeoDataset()
+	assert.Len(t, dataset.Provinces, 31)
+	for _, province := range dataset.Provinces {
+		assert.NotEmpty(t, dataset.CitiesOf(province.ID.Int64()), "province %d", province.ID)
+	}
+	assert.Len(t, dataset.CitiesOf(0), len(dataset.Cities))
+}
+
+func Test_GetProvincesFallback(t *testing.T) {
+	t.Parallel()
+	var unavailable atomic.Bool
+	unavailable.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case unavailable.Load():
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/provinces":
+			_, _ = w.Write([]byte(`{"data":[{"ProvinceID":"8","ProvinceName":"Tehran"},{"ProvinceID":"40","ProvinceName":"New"}],"error":null}`))
+		case r.URL.Path == "/cities" && r.URL.Query().Get("ProvinceID") == "8":
+			_, _ = w.Write([]byte(`{"data":[{"CityID":"801","ProvinceID":"8","CityName":"Tehran"}],"error":null}`))
+		case r.URL.Path == "/cities":
+			_, _ = w.Write([]byte(`{"data":[{"CityID":"801","ProvinceID":"8","CityName":"Tehran"},{"CityID":"4001","ProvinceID":"40","CityName":"New"}],"error":null}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":[],"error":{"Message":"forbidden"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	ctx := context.Background()
+	embedded := goarpa.EmbeddedGeoDataset()
+
+	// no endpoint, the embedded IDs are flagged
+	provinces, err := client.GetProvinces(ctx, "token", nil)
+	var fallback *goarpa.GeoFallbackError
+	require.True(t, errors.As(err, &fallback))
+	assert.True(t, fallback.Embedded)
+	assert.True(t, errors.Is(err, goarpa.ErrGeoFallback))
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+	assert.Equal(t, embedded.Provinces, provinces)
+	_, err = client.RefreshGeoDataset(ctx, "token", nil)
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+
+	// server unavailable
+	client.Config.GetProvincesEndpoint = "provinces"
+	client.Config.GetCitiesEndpoint = "cities"
+	cities, err := client.GetCities(ctx, "token", nil, 8)
+	require.True(t, errors.As(err, &fallback))
+	assert.True(t, fallback.Embedded)
+	assert.Equal(t, embedded.CitiesOf(8), cities)
+	_, err = client.RefreshGeoDataset(ctx, "token", nil)
+	assert.Error(t, err)
+
+	unavailable.Store(false)
+	cities, err = client.GetCities(ctx, "token", nil, 8)
+	require.NoError(t, err)
+	require.Len(t, cities, 1)
+	assert.Equal(t, goarpa.EnforcedString("Tehran"), cities[0].Name)
+
+	// the cities returned by the server are served when it is unavailable again, without the embedded flag
+	unavailable.Store(true)
+	cities, err = client.GetCities(ctx, "token", nil, 8)
+	require.True(t, errors.As(err, &fallback))
+	assert.False(t, fallback.Embedded)
+	require.Len(t, cities, 1)
+	assert.Equal(t, goarpa.StringInt64(801), cities[0].ID)
+	_, err = client.GetCities(ctx, "token", nil, 1)
+	require.True(t, errors.As(err, &fallback))
+	assert.True(t, fallback.Embedded)
+	unavailable.Store(false)
+
+	changes, err := client.RefreshGeoDataset(ctx, "token", nil)
+	require.NoError(t, err)
+	require.Len(t, changes.AddedProvinces, 1)
+	assert.Equal(t, goarpa.StringInt64(40), changes.AddedProvinces[0].ID)
+	require.Len(t, changes.RenamedProvinces, 1)
+	assert.Equal(t, goarpa.StringInt64(8), changes.RenamedProvinces[0].ID)
+	assert.Len(t, changes.RemovedProvinces, len(embedded.Provinces)-1)
+	assert.Len(t, changes.AddedCities, 1)
+	assert.False(t, changes.Empty())
+
+	// the refreshed dataset is the fallback
+	client.Config.GetCitiesEndpoint = ""
+	cities, err = client.GetCities(ctx, "token", nil, 40)
+	require.True(t, errors.As(err, &fallback))
+	assert.False(t, fallback.Embedded)
+	require.Len(t, cities, 1)
+	assert.Equal(t, goarpa.StringInt64(4001), cities[0].ID)
+
+	// the errors of the server are returned
+	client.Config.GetProvincesEndpoint = "forbidden"
+	_, err = client.GetProvinces(ctx, "token", nil)
+	assert.ErrorContains(t, err, "forbidden")
+}
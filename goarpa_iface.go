@@ -0,0 +1,143 @@
+package goarpa
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// GoArpaIface is the interface of the client, it is implemented by *GoArpa
+type GoArpaIface interface {
+	// RestyClient returns the internal resty client
+	RestyClient() *resty.Client
+	// SetRestyClient overwrites the internal resty client
+	SetRestyClient(restyClient *resty.Client)
+
+	// GetAdminToken logs in and returns the access token with the cookies of the session
+	GetAdminToken(ctx context.Context, username string, password string) (string, []*http.Cookie, error)
+
+	// CreateCustomer creates a business
+	CreateCustomer(ctx context.Context, accessToken string, cookie []*http.Cookie, customer CreateCustomerRequest) (*RetCustomerResponse, error)
+	// UpdateCustomerPartial updates the changed fields of a business
+	UpdateCustomerPartial(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, changes CustomerChanges) (*RetCustomerResponse, error)
+	// SetCustomerTaxExempt exempts the business from the tax and toll or charges them again
+	SetCustomerTaxExempt(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, exempt bool) error
+	// EnsureCustomer returns the business with the mobile number or the national code of the customer, creating it if needed
+	EnsureCustomer(ctx context.Context, accessToken string, cookie []*http.Cookie, customer CreateCustomerRequest) (*EnsureCustomerResult, error)
+	// GetCustomerByMobile returns the businesses with the mobile number
+	GetCustomerByMobile(ctx context.Context, accessToken string, cookie []*http.Cookie, mobile string) (*GetCustomerResponse, error)
+	// GetCustomersByMobiles looks many mobile numbers up concurrently
+	GetCustomersByMobiles(ctx context.Context, session SessionSource, mobiles []string) (*MobileLookupResult, error)
+	// GetCustomerByBusinessCode returns the business with the code
+	GetCustomerByBusinessCode(ctx context.Context, accessToken string, cookie []*http.Cookie, businessCode string) (*GetCustomerResponse, error)
+	// GetCustomerByID returns the business with the ID
+	GetCustomerByID(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID) (*GetCustomerResponse, error)
+	// GetCustomerByNationalCode returns the businesses with the national code
+	GetCustomerByNationalCode(ctx context.Context, accessToken string, cookie []*http.Cookie, nationalCode string) (*GetCustomerResponse, error)
+	// GetCustomerBalance returns the account balance of a business
+	GetCustomerBalance(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID) (*CustomerBalance, error)
+	// CheckCustomerCredit decides whether the amount can be sold on credit to the business
+	CheckCustomerCredit(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, amount Money) (*CreditCheckResult, error)
+	// GetBusinessID returns the ID of the business with the code
+	GetBusinessID(ctx context.Context, accessToken string, cookie []*http.Cookie, businessCode string) (BusinessID, error)
+	// GetCustomerAttributes returns the extended attributes of the business
+	GetCustomerAttributes(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID) (CustomerAttributes, error)
+	// SetCustomerAttributes writes the extended attributes of the business
+	SetCustomerAttributes(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, attributes CustomerAttributes) error
+	// GetCustomerTransactionsAging returns the receivable of the businesses in aging buckets
+	GetCustomerTransactionsAging(ctx context.Context, accessToken string, cookie []*http.Cookie, params AgingParams) ([]CustomerAging, error)
+	// GetRepresentorSales returns the sales of the representors in a period
+	GetRepresentorSales(ctx context.Context, accessToken string, cookie []*http.Cookie, params RepresentorSalesParams) ([]RepresentorSales, error)
+	// GetProvinces returns the provinces
+	GetProvinces(ctx context.Context, accessToken string, cookie []*http.Cookie) ([]Province, error)
+	// GetCities returns the cities of a province
+	GetCities(ctx context.Context, accessToken string, cookie []*http.Cookie, provinceID int64) ([]City, error)
+	// RefreshGeoDataset reconciles the provinces and the cities with the server
+	RefreshGeoDataset(ctx context.Context, accessToken string, cookie []*http.Cookie) (*GeoChanges, error)
+	// GetCustomers returns a page of businesses
+	GetCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams) (*GetCustomerResponse, error)
+	// IterateCustomers iterates over all the pages of businesses
+	IterateCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams) iter.Seq2[Customer, error]
+	// GetCustomersStream calls fn with every business
+	GetCustomersStream(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams, fn func(Customer) error) error
+	// ExportCustomers fetches the pages of businesses concurrently
+	ExportCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams, concurrency int) <-chan ExportResult[Customer]
+	// CreateCustomers creates many businesses
+	CreateCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, customers []CreateCustomerRequest, options BulkOptions) ([]*RetCustomerResponse, error)
+	// ImportCustomersCSV creates the businesses of a CSV file
+	ImportCustomersCSV(ctx context.Context, accessToken string, cookie []*http.Cookie, r io.Reader, mapping CSVMapping) (*CSVImportResult, error)
+	// ExportCustomersCSV writes the businesses as CSV
+	ExportCustomersCSV(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams, w io.Writer, mapping CSVMapping) error
+
+	// CreateTransaction creates a transaction
+	CreateTransaction(ctx context.Context, accessToken string, transaction CreateTransactionRequest) (*CreateTransactionResponse, error)
+	// CreateTransactions creates many transactions
+	CreateTransactions(ctx context.Context, accessToken string, transactions []CreateTransactionRequest, options BulkOptions) ([]*CreateTransactionResponse, error)
+	// CreateDraftTransaction creates a transaction which is not posted to the ledger until it is finalized
+	CreateDraftTransaction(ctx context.Context, accessToken string, transaction CreateTransactionRequest) (*CreateTransactionResponse, error)
+	// FinalizeTransaction posts a draft transaction to the ledger
+	FinalizeTransaction(ctx context.Context, accessToken string, cookie []*http.Cookie, transactionID TransactionID) (*CreateTransactionResponse, error)
+	// GetTransactionByReference returns the transaction with the external reference
+	GetTransactionByReference(ctx context.Context, accessToken string, cookie []*http.Cookie, reference string, params GetTransactionsParams) (*Transaction, error)
+	// GetTransactions returns a page of transactions
+	GetTransactions(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams) (*GetTransactionsResponse, error)
+	// IterateTransactions iterates over all the pages of transactions
+	IterateTransactions(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams) iter.Seq2[Transaction, error]
+	// GetTransactionsStream calls fn with every transaction
+	GetTransactionsStream(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams, fn func(Transaction) error) error
+	// ExportTransactions fetches the pages of transactions concurrently
+	ExportTransactions(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams, concurrency int) <-chan ExportResult[Transaction]
+
+	// GetReceipts returns a page of the receipts of the treasury
+	GetReceipts(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) (*GetCashMovementsResponse, error)
+	// IterateReceipts iterates over all the pages of receipts
+	IterateReceipts(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) iter.Seq2[CashMovement, error]
+	// GetPayments returns a page of the payments of the treasury
+	GetPayments(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) (*GetCashMovementsResponse, error)
+	// IteratePayments iterates over all the pages of payments
+	IteratePayments(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) iter.Seq2[CashMovement, error]
+
+	// CreateService creates a service item
+	CreateService(ctx context.Context, accessToken string, service CreateServiceRequest) (*CreateServiceResponse, error)
+	// GetServiceByItemCode returns the item with the code
+	GetServiceByItemCode(ctx context.Context, accessToken string, cookie []*http.Cookie, itemCode string) (*RetServiceResponse, error)
+	// GetItems returns a page of items
+	GetItems(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams) (*RetServiceResponse, error)
+	// IterateItems iterates over all the pages of items
+	IterateItems(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams) iter.Seq2[GetServiceResponse, error]
+	// ExportItems fetches the pages of items concurrently
+	ExportItems(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams, concurrency int) <-chan ExportResult[GetServiceResponse]
+	// ImportItemsCSV creates the service items of a CSV file
+	ImportItemsCSV(ctx context.Context, accessToken string, r io.Reader, mapping CSVMapping) (*CSVImportResult, error)
+	// ExportItemsCSV writes the items as CSV
+	ExportItemsCSV(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams, w io.Writer, mapping CSVMapping) error
+	// GetItemPriceHistory returns the changes of the prices of the item between the dates
+	GetItemPriceHistory(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, from time.Time, to time.Time) ([]ItemPriceChange, error)
+	// GetItemAvailability returns the stock of the item by warehouse
+	GetItemAvailability(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, options ItemAvailabilityOptions) (*ItemAvailability, error)
+	// ReserveStock holds a quantity of the item during a checkout
+	ReserveStock(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, qty float64, reference string) (*StockReservation, error)
+	// ReleaseReservation returns the quantity held by the reservation to the stock
+	ReleaseReservation(ctx context.Context, accessToken string, cookie []*http.Cookie, reservationID string) error
+
+	// SubmitReportJob asks Arpa to generate a report asynchronously
+	SubmitReportJob(ctx context.Context, accessToken string, cookie []*http.Cookie, request ReportJobRequest) (*ReportJob, error)
+	// GetReportJob returns the current state of a report job
+	GetReportJob(ctx context.Context, accessToken string, cookie []*http.Cookie, jobID string) (*ReportJob, error)
+	// WaitForReport polls the report job until it is ready
+	WaitForReport(ctx context.Context, accessToken string, cookie []*http.Cookie, jobID string, pollInterval time.Duration) (*ReportJob, error)
+	// OpenReport returns the content of a ready report
+	OpenReport(ctx context.Context, accessToken string, cookie []*http.Cookie, jobID string) (io.ReadCloser, error)
+	// GetServerInfo returns the version and the enabled modules of the server
+	GetServerInfo(ctx context.Context, accessToken string, cookie []*http.Cookie) (*ServerInfo, error)
+	// DownloadCustomerStatement writes the account statement of the business into w
+	DownloadCustomerStatement(ctx context.Context, accessToken string, cookie []*http.Cookie, params CustomerStatementParams, w io.Writer) (int64, error)
+	// DownloadFile streams the body of the endpoint into w
+	DownloadFile(ctx context.Context, accessToken string, cookie []*http.Cookie, endpoint string, params interface{}, w io.Writer) (int64, error)
+}
+
+var _ GoArpaIface = (*GoArpa)(nil)
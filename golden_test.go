@@ -0,0 +1,68 @@
+package goarpa_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of testdata/golden")
+
+// goldenTypes are the response types decoded from the payloads of testdata/golden/<name>
+var goldenTypes = map[string]func() interface{}{
+	"GetCustomerResponse":       func() interface{} { return &goarpa.GetCustomerResponse{} },
+	"RetCustomerResponse":       func() interface{} { return &goarpa.RetCustomerResponse{} },
+	"CreateTransactionResponse": func() interface{} { return &goarpa.CreateTransactionResponse{} },
+	"RetServiceResponse":        func() interface{} { return &goarpa.RetServiceResponse{} },
+	"GetTransactionsResponse":   func() interface{} { return &goarpa.GetTransactionsResponse{} },
+}
+
+// Test_GoldenResponses decodes the captured payloads, compares their encoding with the golden files
+// and checks that the encoding does not change when it is decoded and encoded again.
+// Run with -update to rewrite the golden files after an intended model change.
+func Test_GoldenResponses(t *testing.T) {
+	t.Parallel()
+	for name, newValue := range goldenTypes {
+		payloads, err := filepath.Glob(filepath.Join("testdata", "golden", name, "*.json"))
+		require.NoError(t, err)
+		for _, payload := range payloads {
+			if strings.HasSuffix(payload, ".golden.json") {
+				continue
+			}
+			t.Run(name+"/"+filepath.Base(payload), func(t *testing.T) {
+				t.Parallel()
+				data, err := os.ReadFile(payload)
+				require.NoError(t, err)
+
+				value := newValue()
+				require.NoError(t, json.Unmarshal(data, value), "cannot decode the payload")
+				encoded, err := json.MarshalIndent(value, "", "  ")
+				require.NoError(t, err)
+
+				// the encoding is stable once decoded again
+				again := newValue()
+				require.NoError(t, json.Unmarshal(encoded, again), "cannot decode the encoding")
+				reencoded, err := json.MarshalIndent(again, "", "  ")
+				require.NoError(t, err)
+				assert.Equal(t, string(encoded), string(reencoded))
+
+				golden := strings.TrimSuffix(payload, ".json") + ".golden.json"
+				if *updateGolden {
+					require.NoError(t, os.WriteFile(golden, append(encoded, '\n'), 0o644))
+					return
+				}
+				expected, err := os.ReadFile(golden)
+				require.NoError(t, err, "missing golden file, run the test with -update")
+				assert.Equal(t, string(bytes.TrimSpace(expected)), string(encoded))
+			})
+		}
+	}
+}
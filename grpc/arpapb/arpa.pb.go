@@ -0,0 +1,1193 @@
+// The Arpa service exposes the operations of the goarpa client over gRPC.
+//
+// Amounts are decimal strings, e.g. "1250000" or "12.5", so that no precision is lost.
+// The Go code is generated with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative arpa.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: arpa.proto
+
+package arpapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Customer struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	BusinessId         int64                  `protobuf:"varint,1,opt,name=business_id,json=businessId,proto3" json:"business_id,omitempty"`
+	BusinessCode       string                 `protobuf:"bytes,2,opt,name=business_code,json=businessCode,proto3" json:"business_code,omitempty"`
+	Name               string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	FirstName          string                 `protobuf:"bytes,4,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName           string                 `protobuf:"bytes,5,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	NationalCode       string                 `protobuf:"bytes,6,opt,name=national_code,json=nationalCode,proto3" json:"national_code,omitempty"`
+	Mobile             string                 `protobuf:"bytes,7,opt,name=mobile,proto3" json:"mobile,omitempty"`
+	Phone              string                 `protobuf:"bytes,8,opt,name=phone,proto3" json:"phone,omitempty"`
+	Email              string                 `protobuf:"bytes,9,opt,name=email,proto3" json:"email,omitempty"`
+	Address            string                 `protobuf:"bytes,10,opt,name=address,proto3" json:"address,omitempty"`
+	PostalCode         string                 `protobuf:"bytes,11,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	ProvinceId         int64                  `protobuf:"varint,12,opt,name=province_id,json=provinceId,proto3" json:"province_id,omitempty"`
+	CityId             int64                  `protobuf:"varint,13,opt,name=city_id,json=cityId,proto3" json:"city_id,omitempty"`
+	BusinessCategoryId int64                  `protobuf:"varint,14,opt,name=business_category_id,json=businessCategoryId,proto3" json:"business_category_id,omitempty"`
+	Inactive           bool                   `protobuf:"varint,15,opt,name=inactive,proto3" json:"inactive,omitempty"`
+	CreatedAt          *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ModifiedAt         *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=modified_at,json=modifiedAt,proto3" json:"modified_at,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Customer) Reset() {
+	*x = Customer{}
+	mi := &file_arpa_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Customer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Customer) ProtoMessage() {}
+
+func (x *Customer) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Customer.ProtoReflect.Descriptor instead.
+func (*Customer) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Customer) GetBusinessId() int64 {
+	if x != nil {
+		return x.BusinessId
+	}
+	return 0
+}
+
+func (x *Customer) GetBusinessCode() string {
+	if x != nil {
+		return x.BusinessCode
+	}
+	return ""
+}
+
+func (x *Customer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Customer) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *Customer) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *Customer) GetNationalCode() string {
+	if x != nil {
+		return x.NationalCode
+	}
+	return ""
+}
+
+func (x *Customer) GetMobile() string {
+	if x != nil {
+		return x.Mobile
+	}
+	return ""
+}
+
+func (x *Customer) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *Customer) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Customer) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Customer) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *Customer) GetProvinceId() int64 {
+	if x != nil {
+		return x.ProvinceId
+	}
+	return 0
+}
+
+func (x *Customer) GetCityId() int64 {
+	if x != nil {
+		return x.CityId
+	}
+	return 0
+}
+
+func (x *Customer) GetBusinessCategoryId() int64 {
+	if x != nil {
+		return x.BusinessCategoryId
+	}
+	return 0
+}
+
+func (x *Customer) GetInactive() bool {
+	if x != nil {
+		return x.Inactive
+	}
+	return false
+}
+
+func (x *Customer) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Customer) GetModifiedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModifiedAt
+	}
+	return nil
+}
+
+type CreateCustomerRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Name               string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	FirstName          string                 `protobuf:"bytes,2,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName           string                 `protobuf:"bytes,3,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	NationalCode       string                 `protobuf:"bytes,4,opt,name=national_code,json=nationalCode,proto3" json:"national_code,omitempty"`
+	Mobile             string                 `protobuf:"bytes,5,opt,name=mobile,proto3" json:"mobile,omitempty"`
+	Phone              string                 `protobuf:"bytes,6,opt,name=phone,proto3" json:"phone,omitempty"`
+	Email              string                 `protobuf:"bytes,7,opt,name=email,proto3" json:"email,omitempty"`
+	Address            string                 `protobuf:"bytes,8,opt,name=address,proto3" json:"address,omitempty"`
+	ProvinceId         int64                  `protobuf:"varint,9,opt,name=province_id,json=provinceId,proto3" json:"province_id,omitempty"`
+	CityId             int64                  `protobuf:"varint,10,opt,name=city_id,json=cityId,proto3" json:"city_id,omitempty"`
+	BusinessCategoryId int64                  `protobuf:"varint,11,opt,name=business_category_id,json=businessCategoryId,proto3" json:"business_category_id,omitempty"`
+	// 1 for male, 2 for female, 0 when unknown
+	Sexuality int32 `protobuf:"varint,12,opt,name=sexuality,proto3" json:"sexuality,omitempty"`
+	// 1 for a real person, 2 for a legal entity, 0 when unknown
+	RealOrFinancial int32 `protobuf:"varint,13,opt,name=real_or_financial,json=realOrFinancial,proto3" json:"real_or_financial,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateCustomerRequest) Reset() {
+	*x = CreateCustomerRequest{}
+	mi := &file_arpa_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCustomerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCustomerRequest) ProtoMessage() {}
+
+func (x *CreateCustomerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCustomerRequest.ProtoReflect.Descriptor instead.
+func (*CreateCustomerRequest) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateCustomerRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateCustomerRequest) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *CreateCustomerRequest) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *CreateCustomerRequest) GetNationalCode() string {
+	if x != nil {
+		return x.NationalCode
+	}
+	return ""
+}
+
+func (x *CreateCustomerRequest) GetMobile() string {
+	if x != nil {
+		return x.Mobile
+	}
+	return ""
+}
+
+func (x *CreateCustomerRequest) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *CreateCustomerRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateCustomerRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CreateCustomerRequest) GetProvinceId() int64 {
+	if x != nil {
+		return x.ProvinceId
+	}
+	return 0
+}
+
+func (x *CreateCustomerRequest) GetCityId() int64 {
+	if x != nil {
+		return x.CityId
+	}
+	return 0
+}
+
+func (x *CreateCustomerRequest) GetBusinessCategoryId() int64 {
+	if x != nil {
+		return x.BusinessCategoryId
+	}
+	return 0
+}
+
+func (x *CreateCustomerRequest) GetSexuality() int32 {
+	if x != nil {
+		return x.Sexuality
+	}
+	return 0
+}
+
+func (x *CreateCustomerRequest) GetRealOrFinancial() int32 {
+	if x != nil {
+		return x.RealOrFinancial
+	}
+	return 0
+}
+
+type CreateCustomerResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	BusinessId   int64                  `protobuf:"varint,1,opt,name=business_id,json=businessId,proto3" json:"business_id,omitempty"`
+	BusinessCode string                 `protobuf:"bytes,2,opt,name=business_code,json=businessCode,proto3" json:"business_code,omitempty"`
+	// existed is true when the business was found or reported as existing by Arpa
+	Existed       bool `protobuf:"varint,3,opt,name=existed,proto3" json:"existed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCustomerResponse) Reset() {
+	*x = CreateCustomerResponse{}
+	mi := &file_arpa_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCustomerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCustomerResponse) ProtoMessage() {}
+
+func (x *CreateCustomerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCustomerResponse.ProtoReflect.Descriptor instead.
+func (*CreateCustomerResponse) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateCustomerResponse) GetBusinessId() int64 {
+	if x != nil {
+		return x.BusinessId
+	}
+	return 0
+}
+
+func (x *CreateCustomerResponse) GetBusinessCode() string {
+	if x != nil {
+		return x.BusinessCode
+	}
+	return ""
+}
+
+func (x *CreateCustomerResponse) GetExisted() bool {
+	if x != nil {
+		return x.Existed
+	}
+	return false
+}
+
+type GetCustomerByMobileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mobile        string                 `protobuf:"bytes,1,opt,name=mobile,proto3" json:"mobile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCustomerByMobileRequest) Reset() {
+	*x = GetCustomerByMobileRequest{}
+	mi := &file_arpa_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCustomerByMobileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCustomerByMobileRequest) ProtoMessage() {}
+
+func (x *GetCustomerByMobileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCustomerByMobileRequest.ProtoReflect.Descriptor instead.
+func (*GetCustomerByMobileRequest) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetCustomerByMobileRequest) GetMobile() string {
+	if x != nil {
+		return x.Mobile
+	}
+	return ""
+}
+
+type GetCustomerByBusinessCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BusinessCode  string                 `protobuf:"bytes,1,opt,name=business_code,json=businessCode,proto3" json:"business_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCustomerByBusinessCodeRequest) Reset() {
+	*x = GetCustomerByBusinessCodeRequest{}
+	mi := &file_arpa_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCustomerByBusinessCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCustomerByBusinessCodeRequest) ProtoMessage() {}
+
+func (x *GetCustomerByBusinessCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCustomerByBusinessCodeRequest.ProtoReflect.Descriptor instead.
+func (*GetCustomerByBusinessCodeRequest) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetCustomerByBusinessCodeRequest) GetBusinessCode() string {
+	if x != nil {
+		return x.BusinessCode
+	}
+	return ""
+}
+
+type ListCustomersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// modified_since limits the businesses to the ones modified after the time
+	ModifiedSince *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=modified_since,json=modifiedSince,proto3" json:"modified_since,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCustomersRequest) Reset() {
+	*x = ListCustomersRequest{}
+	mi := &file_arpa_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCustomersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCustomersRequest) ProtoMessage() {}
+
+func (x *ListCustomersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCustomersRequest.ProtoReflect.Descriptor instead.
+func (*ListCustomersRequest) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListCustomersRequest) GetModifiedSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModifiedSince
+	}
+	return nil
+}
+
+func (x *ListCustomersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type TransactionItem struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ItemId          int64                  `protobuf:"varint,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Qty             float64                `protobuf:"fixed64,2,opt,name=qty,proto3" json:"qty,omitempty"`
+	Price           string                 `protobuf:"bytes,3,opt,name=price,proto3" json:"price,omitempty"`
+	DiscountAmount  string                 `protobuf:"bytes,4,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	DiscountPercent float64                `protobuf:"fixed64,5,opt,name=discount_percent,json=discountPercent,proto3" json:"discount_percent,omitempty"`
+	TaxExempt       bool                   `protobuf:"varint,6,opt,name=tax_exempt,json=taxExempt,proto3" json:"tax_exempt,omitempty"`
+	FreeQty         float64                `protobuf:"fixed64,7,opt,name=free_qty,json=freeQty,proto3" json:"free_qty,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TransactionItem) Reset() {
+	*x = TransactionItem{}
+	mi := &file_arpa_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionItem) ProtoMessage() {}
+
+func (x *TransactionItem) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionItem.ProtoReflect.Descriptor instead.
+func (*TransactionItem) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TransactionItem) GetItemId() int64 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *TransactionItem) GetQty() float64 {
+	if x != nil {
+		return x.Qty
+	}
+	return 0
+}
+
+func (x *TransactionItem) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *TransactionItem) GetDiscountAmount() string {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return ""
+}
+
+func (x *TransactionItem) GetDiscountPercent() float64 {
+	if x != nil {
+		return x.DiscountPercent
+	}
+	return 0
+}
+
+func (x *TransactionItem) GetTaxExempt() bool {
+	if x != nil {
+		return x.TaxExempt
+	}
+	return false
+}
+
+func (x *TransactionItem) GetFreeQty() float64 {
+	if x != nil {
+		return x.FreeQty
+	}
+	return 0
+}
+
+type AddSub struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AddSubId      int64                  `protobuf:"varint,1,opt,name=add_sub_id,json=addSubId,proto3" json:"add_sub_id,omitempty"`
+	Amount        string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddSub) Reset() {
+	*x = AddSub{}
+	mi := &file_arpa_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSub) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSub) ProtoMessage() {}
+
+func (x *AddSub) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSub.ProtoReflect.Descriptor instead.
+func (*AddSub) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AddSub) GetAddSubId() int64 {
+	if x != nil {
+		return x.AddSubId
+	}
+	return 0
+}
+
+func (x *AddSub) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+type CreateTransactionRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	BusinessId      int64                  `protobuf:"varint,1,opt,name=business_id,json=businessId,proto3" json:"business_id,omitempty"`
+	DocAliasId      int64                  `protobuf:"varint,2,opt,name=doc_alias_id,json=docAliasId,proto3" json:"doc_alias_id,omitempty"`
+	TransStateId    int32                  `protobuf:"varint,3,opt,name=trans_state_id,json=transStateId,proto3" json:"trans_state_id,omitempty"`
+	FactorTypeId    int32                  `protobuf:"varint,4,opt,name=factor_type_id,json=factorTypeId,proto3" json:"factor_type_id,omitempty"`
+	CalcTaxAndToll  bool                   `protobuf:"varint,5,opt,name=calc_tax_and_toll,json=calcTaxAndToll,proto3" json:"calc_tax_and_toll,omitempty"`
+	DiscountAmount  string                 `protobuf:"bytes,6,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	DiscountPercent float64                `protobuf:"fixed64,7,opt,name=discount_percent,json=discountPercent,proto3" json:"discount_percent,omitempty"`
+	DepartmentId    int64                  `protobuf:"varint,8,opt,name=department_id,json=departmentId,proto3" json:"department_id,omitempty"`
+	SettlementId    int64                  `protobuf:"varint,9,opt,name=settlement_id,json=settlementId,proto3" json:"settlement_id,omitempty"`
+	Description     string                 `protobuf:"bytes,10,opt,name=description,proto3" json:"description,omitempty"`
+	Items           []*TransactionItem     `protobuf:"bytes,11,rep,name=items,proto3" json:"items,omitempty"`
+	AddSubs         []*AddSub              `protobuf:"bytes,12,rep,name=add_subs,json=addSubs,proto3" json:"add_subs,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateTransactionRequest) Reset() {
+	*x = CreateTransactionRequest{}
+	mi := &file_arpa_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransactionRequest) ProtoMessage() {}
+
+func (x *CreateTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransactionRequest.ProtoReflect.Descriptor instead.
+func (*CreateTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateTransactionRequest) GetBusinessId() int64 {
+	if x != nil {
+		return x.BusinessId
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetDocAliasId() int64 {
+	if x != nil {
+		return x.DocAliasId
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetTransStateId() int32 {
+	if x != nil {
+		return x.TransStateId
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetFactorTypeId() int32 {
+	if x != nil {
+		return x.FactorTypeId
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetCalcTaxAndToll() bool {
+	if x != nil {
+		return x.CalcTaxAndToll
+	}
+	return false
+}
+
+func (x *CreateTransactionRequest) GetDiscountAmount() string {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetDiscountPercent() float64 {
+	if x != nil {
+		return x.DiscountPercent
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetDepartmentId() int64 {
+	if x != nil {
+		return x.DepartmentId
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetSettlementId() int64 {
+	if x != nil {
+		return x.SettlementId
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetItems() []*TransactionItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CreateTransactionRequest) GetAddSubs() []*AddSub {
+	if x != nil {
+		return x.AddSubs
+	}
+	return nil
+}
+
+type CreateTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId int64                  `protobuf:"varint,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	TransNumber   int64                  `protobuf:"varint,2,opt,name=trans_number,json=transNumber,proto3" json:"trans_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransactionResponse) Reset() {
+	*x = CreateTransactionResponse{}
+	mi := &file_arpa_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransactionResponse) ProtoMessage() {}
+
+func (x *CreateTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransactionResponse.ProtoReflect.Descriptor instead.
+func (*CreateTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreateTransactionResponse) GetTransactionId() int64 {
+	if x != nil {
+		return x.TransactionId
+	}
+	return 0
+}
+
+func (x *CreateTransactionResponse) GetTransNumber() int64 {
+	if x != nil {
+		return x.TransNumber
+	}
+	return 0
+}
+
+type GetItemByCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemCode      string                 `protobuf:"bytes,1,opt,name=item_code,json=itemCode,proto3" json:"item_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetItemByCodeRequest) Reset() {
+	*x = GetItemByCodeRequest{}
+	mi := &file_arpa_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItemByCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemByCodeRequest) ProtoMessage() {}
+
+func (x *GetItemByCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetItemByCodeRequest.ProtoReflect.Descriptor instead.
+func (*GetItemByCodeRequest) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetItemByCodeRequest) GetItemCode() string {
+	if x != nil {
+		return x.ItemCode
+	}
+	return ""
+}
+
+type Item struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        int64                  `protobuf:"varint,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	ItemCode      string                 `protobuf:"bytes,2,opt,name=item_code,json=itemCode,proto3" json:"item_code,omitempty"`
+	ItemName      string                 `protobuf:"bytes,3,opt,name=item_name,json=itemName,proto3" json:"item_name,omitempty"`
+	SalePrice     string                 `protobuf:"bytes,4,opt,name=sale_price,json=salePrice,proto3" json:"sale_price,omitempty"`
+	ConsumerPrice string                 `protobuf:"bytes,5,opt,name=consumer_price,json=consumerPrice,proto3" json:"consumer_price,omitempty"`
+	Active        bool                   `protobuf:"varint,6,opt,name=active,proto3" json:"active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Item) Reset() {
+	*x = Item{}
+	mi := &file_arpa_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Item) ProtoMessage() {}
+
+func (x *Item) ProtoReflect() protoreflect.Message {
+	mi := &file_arpa_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Item.ProtoReflect.Descriptor instead.
+func (*Item) Descriptor() ([]byte, []int) {
+	return file_arpa_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Item) GetItemId() int64 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *Item) GetItemCode() string {
+	if x != nil {
+		return x.ItemCode
+	}
+	return ""
+}
+
+func (x *Item) GetItemName() string {
+	if x != nil {
+		return x.ItemName
+	}
+	return ""
+}
+
+func (x *Item) GetSalePrice() string {
+	if x != nil {
+		return x.SalePrice
+	}
+	return ""
+}
+
+func (x *Item) GetConsumerPrice() string {
+	if x != nil {
+		return x.ConsumerPrice
+	}
+	return ""
+}
+
+func (x *Item) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+var File_arpa_proto protoreflect.FileDescriptor
+
+const file_arpa_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"arpa.proto\x12\tgoarpa.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc4\x04\n" +
+	"\bCustomer\x12\x1f\n" +
+	"\vbusiness_id\x18\x01 \x01(\x03R\n" +
+	"businessId\x12#\n" +
+	"\rbusiness_code\x18\x02 \x01(\tR\fbusinessCode\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x04 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x05 \x01(\tR\blastName\x12#\n" +
+	"\rnational_code\x18\x06 \x01(\tR\fnationalCode\x12\x16\n" +
+	"\x06mobile\x18\a \x01(\tR\x06mobile\x12\x14\n" +
+	"\x05phone\x18\b \x01(\tR\x05phone\x12\x14\n" +
+	"\x05email\x18\t \x01(\tR\x05email\x12\x18\n" +
+	"\aaddress\x18\n" +
+	" \x01(\tR\aaddress\x12\x1f\n" +
+	"\vpostal_code\x18\v \x01(\tR\n" +
+	"postalCode\x12\x1f\n" +
+	"\vprovince_id\x18\f \x01(\x03R\n" +
+	"provinceId\x12\x17\n" +
+	"\acity_id\x18\r \x01(\x03R\x06cityId\x120\n" +
+	"\x14business_category_id\x18\x0e \x01(\x03R\x12businessCategoryId\x12\x1a\n" +
+	"\binactive\x18\x0f \x01(\bR\binactive\x129\n" +
+	"\n" +
+	"created_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12;\n" +
+	"\vmodified_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"modifiedAt\"\xa0\x03\n" +
+	"\x15CreateCustomerRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x02 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x03 \x01(\tR\blastName\x12#\n" +
+	"\rnational_code\x18\x04 \x01(\tR\fnationalCode\x12\x16\n" +
+	"\x06mobile\x18\x05 \x01(\tR\x06mobile\x12\x14\n" +
+	"\x05phone\x18\x06 \x01(\tR\x05phone\x12\x14\n" +
+	"\x05email\x18\a \x01(\tR\x05email\x12\x18\n" +
+	"\aaddress\x18\b \x01(\tR\aaddress\x12\x1f\n" +
+	"\vprovince_id\x18\t \x01(\x03R\n" +
+	"provinceId\x12\x17\n" +
+	"\acity_id\x18\n" +
+	" \x01(\x03R\x06cityId\x120\n" +
+	"\x14business_category_id\x18\v \x01(\x03R\x12businessCategoryId\x12\x1c\n" +
+	"\tsexuality\x18\f \x01(\x05R\tsexuality\x12*\n" +
+	"\x11real_or_financial\x18\r \x01(\x05R\x0frealOrFinancial\"x\n" +
+	"\x16CreateCustomerResponse\x12\x1f\n" +
+	"\vbusiness_id\x18\x01 \x01(\x03R\n" +
+	"businessId\x12#\n" +
+	"\rbusiness_code\x18\x02 \x01(\tR\fbusinessCode\x12\x18\n" +
+	"\aexisted\x18\x03 \x01(\bR\aexisted\"4\n" +
+	"\x1aGetCustomerByMobileRequest\x12\x16\n" +
+	"\x06mobile\x18\x01 \x01(\tR\x06mobile\"G\n" +
+	" GetCustomerByBusinessCodeRequest\x12#\n" +
+	"\rbusiness_code\x18\x01 \x01(\tR\fbusinessCode\"v\n" +
+	"\x14ListCustomersRequest\x12A\n" +
+	"\x0emodified_since\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\rmodifiedSince\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\"\xe0\x01\n" +
+	"\x0fTransactionItem\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\x03R\x06itemId\x12\x10\n" +
+	"\x03qty\x18\x02 \x01(\x01R\x03qty\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\tR\x05price\x12'\n" +
+	"\x0fdiscount_amount\x18\x04 \x01(\tR\x0ediscountAmount\x12)\n" +
+	"\x10discount_percent\x18\x05 \x01(\x01R\x0fdiscountPercent\x12\x1d\n" +
+	"\n" +
+	"tax_exempt\x18\x06 \x01(\bR\ttaxExempt\x12\x19\n" +
+	"\bfree_qty\x18\a \x01(\x01R\afreeQty\">\n" +
+	"\x06AddSub\x12\x1c\n" +
+	"\n" +
+	"add_sub_id\x18\x01 \x01(\x03R\baddSubId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\tR\x06amount\"\xf4\x03\n" +
+	"\x18CreateTransactionRequest\x12\x1f\n" +
+	"\vbusiness_id\x18\x01 \x01(\x03R\n" +
+	"businessId\x12 \n" +
+	"\fdoc_alias_id\x18\x02 \x01(\x03R\n" +
+	"docAliasId\x12$\n" +
+	"\x0etrans_state_id\x18\x03 \x01(\x05R\ftransStateId\x12$\n" +
+	"\x0efactor_type_id\x18\x04 \x01(\x05R\ffactorTypeId\x12)\n" +
+	"\x11calc_tax_and_toll\x18\x05 \x01(\bR\x0ecalcTaxAndToll\x12'\n" +
+	"\x0fdiscount_amount\x18\x06 \x01(\tR\x0ediscountAmount\x12)\n" +
+	"\x10discount_percent\x18\a \x01(\x01R\x0fdiscountPercent\x12#\n" +
+	"\rdepartment_id\x18\b \x01(\x03R\fdepartmentId\x12#\n" +
+	"\rsettlement_id\x18\t \x01(\x03R\fsettlementId\x12 \n" +
+	"\vdescription\x18\n" +
+	" \x01(\tR\vdescription\x120\n" +
+	"\x05items\x18\v \x03(\v2\x1a.goarpa.v1.TransactionItemR\x05items\x12,\n" +
+	"\badd_subs\x18\f \x03(\v2\x11.goarpa.v1.AddSubR\aaddSubs\"e\n" +
+	"\x19CreateTransactionResponse\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\x03R\rtransactionId\x12!\n" +
+	"\ftrans_number\x18\x02 \x01(\x03R\vtransNumber\"3\n" +
+	"\x14GetItemByCodeRequest\x12\x1b\n" +
+	"\titem_code\x18\x01 \x01(\tR\bitemCode\"\xb7\x01\n" +
+	"\x04Item\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\x03R\x06itemId\x12\x1b\n" +
+	"\titem_code\x18\x02 \x01(\tR\bitemCode\x12\x1b\n" +
+	"\titem_name\x18\x03 \x01(\tR\bitemName\x12\x1d\n" +
+	"\n" +
+	"sale_price\x18\x04 \x01(\tR\tsalePrice\x12%\n" +
+	"\x0econsumer_price\x18\x05 \x01(\tR\rconsumerPrice\x12\x16\n" +
+	"\x06active\x18\x06 \x01(\bR\x06active2\xd2\x04\n" +
+	"\x04Arpa\x12U\n" +
+	"\x0eCreateCustomer\x12 .goarpa.v1.CreateCustomerRequest\x1a!.goarpa.v1.CreateCustomerResponse\x12U\n" +
+	"\x0eEnsureCustomer\x12 .goarpa.v1.CreateCustomerRequest\x1a!.goarpa.v1.CreateCustomerResponse\x12Q\n" +
+	"\x13GetCustomerByMobile\x12%.goarpa.v1.GetCustomerByMobileRequest\x1a\x13.goarpa.v1.Customer\x12]\n" +
+	"\x19GetCustomerByBusinessCode\x12+.goarpa.v1.GetCustomerByBusinessCodeRequest\x1a\x13.goarpa.v1.Customer\x12G\n" +
+	"\rListCustomers\x12\x1f.goarpa.v1.ListCustomersRequest\x1a\x13.goarpa.v1.Customer0\x01\x12^\n" +
+	"\x11CreateTransaction\x12#.goarpa.v1.CreateTransactionRequest\x1a$.goarpa.v1.CreateTransactionResponse\x12A\n" +
+	"\rGetItemByCode\x12\x1f.goarpa.v1.GetItemByCodeRequest\x1a\x0f.goarpa.v1.ItemB.Z,github.com/erfandiakoo/goarpa/v2/grpc/arpapbb\x06proto3"
+
+var (
+	file_arpa_proto_rawDescOnce sync.Once
+	file_arpa_proto_rawDescData []byte
+)
+
+func file_arpa_proto_rawDescGZIP() []byte {
+	file_arpa_proto_rawDescOnce.Do(func() {
+		file_arpa_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_arpa_proto_rawDesc), len(file_arpa_proto_rawDesc)))
+	})
+	return file_arpa_proto_rawDescData
+}
+
+var file_arpa_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_arpa_proto_goTypes = []any{
+	(*Customer)(nil),                         // 0: goarpa.v1.Customer
+	(*CreateCustomerRequest)(nil),            // 1: goarpa.v1.CreateCustomerRequest
+	(*CreateCustomerResponse)(nil),           // 2: goarpa.v1.CreateCustomerResponse
+	(*GetCustomerByMobileRequest)(nil),       // 3: goarpa.v1.GetCustomerByMobileRequest
+	(*GetCustomerByBusinessCodeRequest)(nil), // 4: goarpa.v1.GetCustomerByBusinessCodeRequest
+	(*ListCustomersRequest)(nil),             // 5: goarpa.v1.ListCustomersRequest
+	(*TransactionItem)(nil),                  // 6: goarpa.v1.TransactionItem
+	(*AddSub)(nil),                           // 7: goarpa.v1.AddSub
+	(*CreateTransactionRequest)(nil),         // 8: goarpa.v1.CreateTransactionRequest
+	(*CreateTransactionResponse)(nil),        // 9: goarpa.v1.CreateTransactionResponse
+	(*GetItemByCodeRequest)(nil),             // 10: goarpa.v1.GetItemByCodeRequest
+	(*Item)(nil),                             // 11: goarpa.v1.Item
+	(*timestamppb.Timestamp)(nil),            // 12: google.protobuf.Timestamp
+}
+var file_arpa_proto_depIdxs = []int32{
+	12, // 0: goarpa.v1.Customer.created_at:type_name -> google.protobuf.Timestamp
+	12, // 1: goarpa.v1.Customer.modified_at:type_name -> google.protobuf.Timestamp
+	12, // 2: goarpa.v1.ListCustomersRequest.modified_since:type_name -> google.protobuf.Timestamp
+	6,  // 3: goarpa.v1.CreateTransactionRequest.items:type_name -> goarpa.v1.TransactionItem
+	7,  // 4: goarpa.v1.CreateTransactionRequest.add_subs:type_name -> goarpa.v1.AddSub
+	1,  // 5: goarpa.v1.Arpa.CreateCustomer:input_type -> goarpa.v1.CreateCustomerRequest
+	1,  // 6: goarpa.v1.Arpa.EnsureCustomer:input_type -> goarpa.v1.CreateCustomerRequest
+	3,  // 7: goarpa.v1.Arpa.GetCustomerByMobile:input_type -> goarpa.v1.GetCustomerByMobileRequest
+	4,  // 8: goarpa.v1.Arpa.GetCustomerByBusinessCode:input_type -> goarpa.v1.GetCustomerByBusinessCodeRequest
+	5,  // 9: goarpa.v1.Arpa.ListCustomers:input_type -> goarpa.v1.ListCustomersRequest
+	8,  // 10: goarpa.v1.Arpa.CreateTransaction:input_type -> goarpa.v1.CreateTransactionRequest
+	10, // 11: goarpa.v1.Arpa.GetItemByCode:input_type -> goarpa.v1.GetItemByCodeRequest
+	2,  // 12: goarpa.v1.Arpa.CreateCustomer:output_type -> goarpa.v1.CreateCustomerResponse
+	2,  // 13: goarpa.v1.Arpa.EnsureCustomer:output_type -> goarpa.v1.CreateCustomerResponse
+	0,  // 14: goarpa.v1.Arpa.GetCustomerByMobile:output_type -> goarpa.v1.Customer
+	0,  // 15: goarpa.v1.Arpa.GetCustomerByBusinessCode:output_type -> goarpa.v1.Customer
+	0,  // 16: goarpa.v1.Arpa.ListCustomers:output_type -> goarpa.v1.Customer
+	9,  // 17: goarpa.v1.Arpa.CreateTransaction:output_type -> goarpa.v1.CreateTransactionResponse
+	11, // 18: goarpa.v1.Arpa.GetItemByCode:output_type -> goarpa.v1.Item
+	12, // [12:19] is the sub-list for method output_type
+	5,  // [5:12] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_arpa_proto_init() }
+func file_arpa_proto_init() {
+	if File_arpa_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_arpa_proto_rawDesc), len(file_arpa_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_arpa_proto_goTypes,
+		DependencyIndexes: file_arpa_proto_depIdxs,
+		MessageInfos:      file_arpa_proto_msgTypes,
+	}.Build()
+	File_arpa_proto = out.File
+	file_arpa_proto_goTypes = nil
+	file_arpa_proto_depIdxs = nil
+}
@@ -0,0 +1,375 @@
+// The Arpa service exposes the operations of the goarpa client over gRPC.
+//
+// Amounts are decimal strings, e.g. "1250000" or "12.5", so that no precision is lost.
+// The Go code is generated with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative arpa.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: arpa.proto
+
+package arpapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Arpa_CreateCustomer_FullMethodName            = "/goarpa.v1.Arpa/CreateCustomer"
+	Arpa_EnsureCustomer_FullMethodName            = "/goarpa.v1.Arpa/EnsureCustomer"
+	Arpa_GetCustomerByMobile_FullMethodName       = "/goarpa.v1.Arpa/GetCustomerByMobile"
+	Arpa_GetCustomerByBusinessCode_FullMethodName = "/goarpa.v1.Arpa/GetCustomerByBusinessCode"
+	Arpa_ListCustomers_FullMethodName             = "/goarpa.v1.Arpa/ListCustomers"
+	Arpa_CreateTransaction_FullMethodName         = "/goarpa.v1.Arpa/CreateTransaction"
+	Arpa_GetItemByCode_FullMethodName             = "/goarpa.v1.Arpa/GetItemByCode"
+)
+
+// ArpaClient is the client API for Arpa service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ArpaClient interface {
+	// CreateCustomer creates a business, failing with ALREADY_EXISTS when Arpa reports it as existing.
+	CreateCustomer(ctx context.Context, in *CreateCustomerRequest, opts ...grpc.CallOption) (*CreateCustomerResponse, error)
+	// EnsureCustomer returns the business with the mobile number, creating it if needed.
+	EnsureCustomer(ctx context.Context, in *CreateCustomerRequest, opts ...grpc.CallOption) (*CreateCustomerResponse, error)
+	// GetCustomerByMobile fails with NOT_FOUND when no business has the mobile number.
+	GetCustomerByMobile(ctx context.Context, in *GetCustomerByMobileRequest, opts ...grpc.CallOption) (*Customer, error)
+	// GetCustomerByBusinessCode fails with NOT_FOUND when no business has the code.
+	GetCustomerByBusinessCode(ctx context.Context, in *GetCustomerByBusinessCodeRequest, opts ...grpc.CallOption) (*Customer, error)
+	// ListCustomers streams the businesses, fails with UNIMPLEMENTED when the endpoint is not configured.
+	ListCustomers(ctx context.Context, in *ListCustomersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Customer], error)
+	// CreateTransaction creates a transaction (invoice, proforma...).
+	CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*CreateTransactionResponse, error)
+	// GetItemByCode fails with NOT_FOUND when no item has the code.
+	GetItemByCode(ctx context.Context, in *GetItemByCodeRequest, opts ...grpc.CallOption) (*Item, error)
+}
+
+type arpaClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArpaClient(cc grpc.ClientConnInterface) ArpaClient {
+	return &arpaClient{cc}
+}
+
+func (c *arpaClient) CreateCustomer(ctx context.Context, in *CreateCustomerRequest, opts ...grpc.CallOption) (*CreateCustomerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateCustomerResponse)
+	err := c.cc.Invoke(ctx, Arpa_CreateCustomer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arpaClient) EnsureCustomer(ctx context.Context, in *CreateCustomerRequest, opts ...grpc.CallOption) (*CreateCustomerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateCustomerResponse)
+	err := c.cc.Invoke(ctx, Arpa_EnsureCustomer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arpaClient) GetCustomerByMobile(ctx context.Context, in *GetCustomerByMobileRequest, opts ...grpc.CallOption) (*Customer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Customer)
+	err := c.cc.Invoke(ctx, Arpa_GetCustomerByMobile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arpaClient) GetCustomerByBusinessCode(ctx context.Context, in *GetCustomerByBusinessCodeRequest, opts ...grpc.CallOption) (*Customer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Customer)
+	err := c.cc.Invoke(ctx, Arpa_GetCustomerByBusinessCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arpaClient) ListCustomers(ctx context.Context, in *ListCustomersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Customer], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Arpa_ServiceDesc.Streams[0], Arpa_ListCustomers_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListCustomersRequest, Customer]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Arpa_ListCustomersClient = grpc.ServerStreamingClient[Customer]
+
+func (c *arpaClient) CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*CreateTransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateTransactionResponse)
+	err := c.cc.Invoke(ctx, Arpa_CreateTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arpaClient) GetItemByCode(ctx context.Context, in *GetItemByCodeRequest, opts ...grpc.CallOption) (*Item, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Item)
+	err := c.cc.Invoke(ctx, Arpa_GetItemByCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ArpaServer is the server API for Arpa service.
+// All implementations must embed UnimplementedArpaServer
+// for forward compatibility.
+type ArpaServer interface {
+	// CreateCustomer creates a business, failing with ALREADY_EXISTS when Arpa reports it as existing.
+	CreateCustomer(context.Context, *CreateCustomerRequest) (*CreateCustomerResponse, error)
+	// EnsureCustomer returns the business with the mobile number, creating it if needed.
+	EnsureCustomer(context.Context, *CreateCustomerRequest) (*CreateCustomerResponse, error)
+	// GetCustomerByMobile fails with NOT_FOUND when no business has the mobile number.
+	GetCustomerByMobile(context.Context, *GetCustomerByMobileRequest) (*Customer, error)
+	// GetCustomerByBusinessCode fails with NOT_FOUND when no business has the code.
+	GetCustomerByBusinessCode(context.Context, *GetCustomerByBusinessCodeRequest) (*Customer, error)
+	// ListCustomers streams the businesses, fails with UNIMPLEMENTED when the endpoint is not configured.
+	ListCustomers(*ListCustomersRequest, grpc.ServerStreamingServer[Customer]) error
+	// CreateTransaction creates a transaction (invoice, proforma...).
+	CreateTransaction(context.Context, *CreateTransactionRequest) (*CreateTransactionResponse, error)
+	// GetItemByCode fails with NOT_FOUND when no item has the code.
+	GetItemByCode(context.Context, *GetItemByCodeRequest) (*Item, error)
+	mustEmbedUnimplementedArpaServer()
+}
+
+// UnimplementedArpaServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedArpaServer struct{}
+
+func (UnimplementedArpaServer) CreateCustomer(context.Context, *CreateCustomerRequest) (*CreateCustomerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCustomer not implemented")
+}
+func (UnimplementedArpaServer) EnsureCustomer(context.Context, *CreateCustomerRequest) (*CreateCustomerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnsureCustomer not implemented")
+}
+func (UnimplementedArpaServer) GetCustomerByMobile(context.Context, *GetCustomerByMobileRequest) (*Customer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCustomerByMobile not implemented")
+}
+func (UnimplementedArpaServer) GetCustomerByBusinessCode(context.Context, *GetCustomerByBusinessCodeRequest) (*Customer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCustomerByBusinessCode not implemented")
+}
+func (UnimplementedArpaServer) ListCustomers(*ListCustomersRequest, grpc.ServerStreamingServer[Customer]) error {
+	return status.Errorf(codes.Unimplemented, "method ListCustomers not implemented")
+}
+func (UnimplementedArpaServer) CreateTransaction(context.Context, *CreateTransactionRequest) (*CreateTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTransaction not implemented")
+}
+func (UnimplementedArpaServer) GetItemByCode(context.Context, *GetItemByCodeRequest) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItemByCode not implemented")
+}
+func (UnimplementedArpaServer) mustEmbedUnimplementedArpaServer() {}
+func (UnimplementedArpaServer) testEmbeddedByValue()              {}
+
+// UnsafeArpaServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArpaServer will
+// result in compilation errors.
+type UnsafeArpaServer interface {
+	mustEmbedUnimplementedArpaServer()
+}
+
+func RegisterArpaServer(s grpc.ServiceRegistrar, srv ArpaServer) {
+	// If the following call pancis, it indicates UnimplementedArpaServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Arpa_ServiceDesc, srv)
+}
+
+func _Arpa_CreateCustomer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArpaServer).CreateCustomer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Arpa_CreateCustomer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArpaServer).CreateCustomer(ctx, req.(*CreateCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Arpa_EnsureCustomer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArpaServer).EnsureCustomer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Arpa_EnsureCustomer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArpaServer).EnsureCustomer(ctx, req.(*CreateCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Arpa_GetCustomerByMobile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCustomerByMobileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArpaServer).GetCustomerByMobile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Arpa_GetCustomerByMobile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArpaServer).GetCustomerByMobile(ctx, req.(*GetCustomerByMobileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Arpa_GetCustomerByBusinessCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCustomerByBusinessCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArpaServer).GetCustomerByBusinessCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Arpa_GetCustomerByBusinessCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArpaServer).GetCustomerByBusinessCode(ctx, req.(*GetCustomerByBusinessCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Arpa_ListCustomers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListCustomersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ArpaServer).ListCustomers(m, &grpc.GenericServerStream[ListCustomersRequest, Customer]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Arpa_ListCustomersServer = grpc.ServerStreamingServer[Customer]
+
+func _Arpa_CreateTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArpaServer).CreateTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Arpa_CreateTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArpaServer).CreateTransaction(ctx, req.(*CreateTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Arpa_GetItemByCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemByCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArpaServer).GetItemByCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Arpa_GetItemByCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArpaServer).GetItemByCode(ctx, req.(*GetItemByCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Arpa_ServiceDesc is the grpc.ServiceDesc for Arpa service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Arpa_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "goarpa.v1.Arpa",
+	HandlerType: (*ArpaServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateCustomer",
+			Handler:    _Arpa_CreateCustomer_Handler,
+		},
+		{
+			MethodName: "EnsureCustomer",
+			Handler:    _Arpa_EnsureCustomer_Handler,
+		},
+		{
+			MethodName: "GetCustomerByMobile",
+			Handler:    _Arpa_GetCustomerByMobile_Handler,
+		},
+		{
+			MethodName: "GetCustomerByBusinessCode",
+			Handler:    _Arpa_GetCustomerByBusinessCode_Handler,
+		},
+		{
+			MethodName: "CreateTransaction",
+			Handler:    _Arpa_CreateTransaction_Handler,
+		},
+		{
+			MethodName: "GetItemByCode",
+			Handler:    _Arpa_GetItemByCode_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListCustomers",
+			Handler:       _Arpa_ListCustomers_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "arpa.proto",
+}
@@ -0,0 +1,28 @@
+module github.com/erfandiakoo/goarpa/v2/grpc
+
+go 1.23.1
+
+require (
+	github.com/erfandiakoo/goarpa/v2 v2.0.0
+	github.com/stretchr/testify v1.9.0
+	google.golang.org/grpc v1.73.1
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-resty/resty/v2 v2.16.0 // indirect
+	github.com/opentracing/opentracing-go v1.2.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/shopspring/decimal v1.4.0 // indirect
+	github.com/yaa110/go-persian-calendar v1.2.1 // indirect
+	golang.org/x/net v0.38.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// the server is developed alongside the client
+replace github.com/erfandiakoo/goarpa/v2 => ../
@@ -0,0 +1,373 @@
+// Package grpc exposes the operations of the goarpa client as a gRPC service, so that services written
+// in any language can talk to Arpa through a single gateway process instead of each embedding the HTTP
+// logic, the token handling and the quirks of the Arpa responses.
+//
+// The service is described by arpapb/arpa.proto. A gateway is a few lines:
+//
+//	server := grpc.NewServer()
+//	arpagrpc.Register(server, arpagrpc.New(client, token))
+//	server.Serve(listener)
+//
+// The errors of the client are mapped to gRPC status codes, e.g. ErrCustomerNotFound to NOT_FOUND
+// and ErrNotSupported to UNIMPLEMENTED.
+//
+// The package is a module of its own, so that the users of the client do not depend on gRPC.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/grpc/arpapb"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements the Arpa gRPC service with a goarpa client
+type Server struct {
+	arpapb.UnimplementedArpaServer
+
+	client *goarpa.GoArpa
+	token  goarpa.TokenSource
+}
+
+// New returns a server calling Arpa with the client and the tokens of the token source
+func New(client *goarpa.GoArpa, token goarpa.TokenSource) *Server {
+	return &Server{client: client, token: token}
+}
+
+// Register registers the server on the gRPC server
+func Register(registrar grpclib.ServiceRegistrar, server *Server) {
+	arpapb.RegisterArpaServer(registrar, server)
+}
+
+// CreateCustomer creates a business
+func (s *Server) CreateCustomer(ctx context.Context, req *arpapb.CreateCustomerRequest) (*arpapb.CreateCustomerResponse, error) {
+	customer, err := createCustomerRequest(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	token, err := s.token(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp, err := s.client.CreateCustomer(ctx, token, nil, customer)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &arpapb.CreateCustomerResponse{
+		BusinessId:   resp.Data.BusinessID.Int64(),
+		BusinessCode: string(resp.Data.BusinessCode),
+	}, nil
+}
+
+// EnsureCustomer returns the business with the mobile number, creating it if needed
+func (s *Server) EnsureCustomer(ctx context.Context, req *arpapb.CreateCustomerRequest) (*arpapb.CreateCustomerResponse, error) {
+	customer, err := createCustomerRequest(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	token, err := s.token(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	result, err := s.client.EnsureCustomer(ctx, token, nil, customer)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &arpapb.CreateCustomerResponse{
+		BusinessId:   result.BusinessID.Int64(),
+		BusinessCode: result.BusinessCode,
+		Existed:      result.Path != goarpa.CustomerCreated,
+	}, nil
+}
+
+// GetCustomerByMobile returns the business with the mobile number
+func (s *Server) GetCustomerByMobile(ctx context.Context, req *arpapb.GetCustomerByMobileRequest) (*arpapb.Customer, error) {
+	if req.GetMobile() == "" {
+		return nil, status.Error(codes.InvalidArgument, "mobile is required")
+	}
+	token, err := s.token(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp, err := s.client.GetCustomerByMobile(ctx, token, nil, req.GetMobile())
+	return firstCustomer(resp, err)
+}
+
+// GetCustomerByBusinessCode returns the business with the code
+func (s *Server) GetCustomerByBusinessCode(ctx context.Context, req *arpapb.GetCustomerByBusinessCodeRequest) (*arpapb.Customer, error) {
+	if req.GetBusinessCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "business code is required")
+	}
+	token, err := s.token(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp, err := s.client.GetCustomerByBusinessCode(ctx, token, nil, req.GetBusinessCode())
+	return firstCustomer(resp, err)
+}
+
+// ListCustomers streams the businesses
+func (s *Server) ListCustomers(req *arpapb.ListCustomersRequest, stream grpclib.ServerStreamingServer[arpapb.Customer]) error {
+	ctx := stream.Context()
+	token, err := s.token(ctx)
+	if err != nil {
+		return statusError(err)
+	}
+
+	params := goarpa.GetCustomersParams{ListParams: goarpa.ListParams{PageSize: int(req.GetPageSize())}}
+	if req.GetModifiedSince() != nil {
+		since := req.GetModifiedSince().AsTime()
+		params.ModifiedSince = &since
+	}
+	for customer, err := range s.client.IterateCustomers(ctx, token, nil, params) {
+		if err != nil {
+			return statusError(err)
+		}
+		if err := stream.Send(toCustomer(customer)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateTransaction creates a transaction
+func (s *Server) CreateTransaction(ctx context.Context, req *arpapb.CreateTransactionRequest) (*arpapb.CreateTransactionResponse, error) {
+	transaction, err := createTransactionRequest(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	token, err := s.token(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp, err := s.client.CreateTransaction(ctx, token, transaction)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	created, ok := resp.First()
+	if !ok {
+		return nil, status.Error(codes.Internal, "arpa returned no transaction")
+	}
+	return &arpapb.CreateTransactionResponse{
+		TransactionId: created.TransactionID.Int64(),
+		TransNumber:   created.TransNumber,
+	}, nil
+}
+
+// GetItemByCode returns the item with the code
+func (s *Server) GetItemByCode(ctx context.Context, req *arpapb.GetItemByCodeRequest) (*arpapb.Item, error) {
+	if req.GetItemCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "item code is required")
+	}
+	token, err := s.token(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp, err := s.client.GetServiceByItemCode(ctx, token, nil, req.GetItemCode())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	item, ok := resp.First()
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "item %s not found", req.GetItemCode())
+	}
+	return &arpapb.Item{
+		ItemId:        item.ItemID.Int64(),
+		ItemCode:      item.ItemCode,
+		ItemName:      item.ItemName,
+		SalePrice:     item.SalePrice.String(),
+		ConsumerPrice: item.ConsumerPrice.String(),
+		Active:        item.Active(),
+	}, nil
+}
+
+func firstCustomer(resp *goarpa.GetCustomerResponse, err error) (*arpapb.Customer, error) {
+	if err != nil {
+		return nil, statusError(err)
+	}
+	customer, ok := resp.First()
+	if !ok {
+		return nil, statusError(goarpa.ErrCustomerNotFound)
+	}
+	return toCustomer(customer.ToCustomer()), nil
+}
+
+func toCustomer(customer goarpa.Customer) *arpapb.Customer {
+	return &arpapb.Customer{
+		BusinessId:         customer.ID.Int64(),
+		BusinessCode:       customer.Code,
+		Name:               customer.Name,
+		FirstName:          customer.FirstName,
+		LastName:           customer.LastName,
+		NationalCode:       customer.NationalCode,
+		Mobile:             customer.Mobile,
+		Phone:              customer.Phone,
+		Email:              customer.Email,
+		Address:            customer.Address,
+		PostalCode:         customer.PostalCode,
+		ProvinceId:         customer.ProvinceID,
+		CityId:             customer.CityID,
+		BusinessCategoryId: customer.BusinessCategoryID,
+		Inactive:           customer.Inactive,
+		CreatedAt:          timestamp(customer.CreatedAt),
+		ModifiedAt:         timestamp(customer.ModifiedAt),
+	}
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func createCustomerRequest(req *arpapb.CreateCustomerRequest) (goarpa.CreateCustomerRequest, error) {
+	customer := goarpa.Customer{
+		Name:               req.GetName(),
+		FirstName:          req.GetFirstName(),
+		LastName:           req.GetLastName(),
+		NationalCode:       req.GetNationalCode(),
+		Mobile:             req.GetMobile(),
+		Phone:              req.GetPhone(),
+		Email:              req.GetEmail(),
+		Address:            req.GetAddress(),
+		ProvinceID:         req.GetProvinceId(),
+		CityID:             req.GetCityId(),
+		BusinessCategoryID: req.GetBusinessCategoryId(),
+		Sexuality:          goarpa.Sexuality(req.GetSexuality()),
+		RealOrFinancial:    goarpa.RealOrFinancial(req.GetRealOrFinancial()),
+	}
+	if customer.Name == "" {
+		return goarpa.CreateCustomerRequest{}, errors.New("name is required")
+	}
+	if customer.Sexuality != 0 && !customer.Sexuality.Valid() {
+		return goarpa.CreateCustomerRequest{}, errors.New("invalid sexuality")
+	}
+	if customer.RealOrFinancial != 0 && !customer.RealOrFinancial.Valid() {
+		return goarpa.CreateCustomerRequest{}, errors.New("invalid real or financial")
+	}
+	return customer.ToCreateCustomerRequest()
+}
+
+func createTransactionRequest(req *arpapb.CreateTransactionRequest) (goarpa.CreateTransactionRequest, error) {
+	if req.GetBusinessId() == 0 {
+		return goarpa.CreateTransactionRequest{}, errors.New("business id is required")
+	}
+	if len(req.GetItems()) == 0 {
+		return goarpa.CreateTransactionRequest{}, errors.New("at least one item is required")
+	}
+
+	discountAmount, err := parseMoney(req.GetDiscountAmount())
+	if err != nil {
+		return goarpa.CreateTransactionRequest{}, err
+	}
+
+	transaction := goarpa.CreateTransactionRequest{
+		Data: goarpa.Data{
+			BusinessID:           goarpa.BusinessID(req.GetBusinessId()),
+			DocAliasID:           req.GetDocAliasId(),
+			TransStateID:         goarpa.TransState(req.GetTransStateId()),
+			FactorTypeID:         goarpa.FactorType(req.GetFactorTypeId()),
+			TransDiscountAmount:  discountAmount,
+			TransDiscountPercent: req.GetDiscountPercent(),
+			DepartmentID:         req.GetDepartmentId(),
+			SettlementID:         req.GetSettlementId(),
+			Description:          req.GetDescription(),
+		},
+	}
+	if req.GetCalcTaxAndToll() {
+		transaction.Data.CalcTaxAndToll = 1
+	}
+
+	for _, line := range req.GetItems() {
+		item := goarpa.TransactionItem{
+			ItemID:          goarpa.ItemID(line.GetItemId()),
+			Qty:             line.GetQty(),
+			DiscountPercent: line.GetDiscountPercent(),
+			TaxExempt:       line.GetTaxExempt(),
+			FreeQty:         line.GetFreeQty(),
+		}
+		if item.Price, err = parseMoney(line.GetPrice()); err != nil {
+			return transaction, err
+		}
+		if item.DiscountAmount, err = parseMoney(line.GetDiscountAmount()); err != nil {
+			return transaction, err
+		}
+		transaction.Items = append(transaction.Items, item)
+	}
+	for _, addSub := range req.GetAddSubs() {
+		amount, err := parseMoney(addSub.GetAmount())
+		if err != nil {
+			return transaction, err
+		}
+		transaction.AddSub = append(transaction.AddSub, goarpa.AddSub{AddSubID: addSub.GetAddSubId(), TASAmount: amount})
+	}
+
+	return transaction, nil
+}
+
+func parseMoney(value string) (goarpa.Money, error) {
+	if value == "" {
+		return goarpa.Money{}, nil
+	}
+	return goarpa.ParseMoney(value)
+}
+
+// statusError maps an error of the client to a gRPC status error
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.Unknown
+	var apiErr *goarpa.APIError
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, goarpa.ErrNotSupported):
+		code = codes.Unimplemented
+	case errors.Is(err, goarpa.ErrCustomerNotFound):
+		code = codes.NotFound
+	case errors.Is(err, goarpa.ErrCustomerAlreadyExists):
+		code = codes.AlreadyExists
+	case errors.As(err, &apiErr):
+		code = apiErrorCode(apiErr)
+	}
+	return status.Error(code, err.Error())
+}
+
+func apiErrorCode(apiErr *goarpa.APIError) codes.Code {
+	switch {
+	case apiErr.Type == goarpa.APIErrTypeArpa:
+		return codes.FailedPrecondition
+	case apiErr.Type == goarpa.APIErrTypeSessionExpired, apiErr.Code == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case apiErr.Code == http.StatusForbidden:
+		return codes.PermissionDenied
+	case apiErr.Code == http.StatusNotFound:
+		return codes.NotFound
+	case apiErr.Code == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case apiErr.Code == http.StatusBadRequest:
+		return codes.InvalidArgument
+	case goarpa.IsRetryableError(apiErr):
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,120 @@
+package grpc_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	arpagrpc "github.com/erfandiakoo/goarpa/v2/grpc"
+	"github.com/erfandiakoo/goarpa/v2/grpc/arpapb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newArpaClient(t *testing.T, handler http.HandlerFunc) arpapb.ArpaClient {
+	t.Helper()
+	arpa := httptest.NewServer(handler)
+	t.Cleanup(arpa.Close)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	arpagrpc.Register(server, arpagrpc.New(goarpa.NewClient(arpa.URL), func(context.Context) (string, error) {
+		return "token", nil
+	}))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return arpapb.NewArpaClient(conn)
+}
+
+func Test_ServerGetCustomerByMobile(t *testing.T) {
+	t.Parallel()
+	client := newArpaClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.RawQuery, "09120000000") {
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"12","BusinessCode":"1001","BusinessName":"Ali","Mobile":"09120000000","InActive":"0","Creation_Date":"2024-01-02 10:00:00"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	})
+
+	ctx := context.Background()
+	customer, err := client.GetCustomerByMobile(ctx, &arpapb.GetCustomerByMobileRequest{Mobile: "09120000000"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), customer.GetBusinessId())
+	assert.Equal(t, "1001", customer.GetBusinessCode())
+	assert.Equal(t, "Ali", customer.GetName())
+	assert.NotNil(t, customer.GetCreatedAt())
+	assert.Nil(t, customer.GetModifiedAt())
+
+	_, err = client.GetCustomerByMobile(ctx, &arpapb.GetCustomerByMobileRequest{Mobile: "09129999999"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetCustomerByMobile(ctx, &arpapb.GetCustomerByMobileRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_ServerCreateTransaction(t *testing.T) {
+	t.Parallel()
+	client := newArpaClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"BusinessID":7`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+	})
+
+	resp, err := client.CreateTransaction(context.Background(), &arpapb.CreateTransactionRequest{
+		BusinessId:   7,
+		TransStateId: 1,
+		FactorTypeId: 1,
+		Items:        []*arpapb.TransactionItem{{ItemId: 3, Qty: 2, Price: "1500"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), resp.GetTransactionId())
+	assert.Equal(t, int64(9), resp.GetTransNumber())
+
+	_, err = client.CreateTransaction(context.Background(), &arpapb.CreateTransactionRequest{
+		BusinessId: 7,
+		Items:      []*arpapb.TransactionItem{{ItemId: 3, Qty: 1, Price: "abc"}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_ServerErrorCodes(t *testing.T) {
+	t.Parallel()
+	client := newArpaClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	ctx := context.Background()
+	_, err := client.GetItemByCode(ctx, &arpapb.GetItemByCodeRequest{ItemCode: "A1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// the list endpoint has no default
+	stream, err := client.ListCustomers(ctx, &arpapb.ListCustomersRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -0,0 +1,116 @@
+package goarpa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BusinessID is the ID of a business (customer or vendor)
+type BusinessID int64
+
+// ItemID is the ID of an item or a service
+type ItemID int64
+
+// TransactionID is the ID of a transaction
+type TransactionID int64
+
+// ParseBusinessID parses a business ID from its string representation
+func ParseBusinessID(value string) (BusinessID, error) {
+	id, err := parseID("business", value)
+	return BusinessID(id), err
+}
+
+// Int64 returns the ID as int64
+func (id BusinessID) Int64() int64 {
+	return int64(id)
+}
+
+// String returns the ID as string
+func (id BusinessID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// MarshalJSON marshals the ID as a JSON number
+func (id BusinessID) MarshalJSON() ([]byte, error) {
+	return marshalInt(int64(id)), nil
+}
+
+// UnmarshalJSON accepts a quoted or a bare number, "" is zero
+func (id *BusinessID) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalID("business", data)
+	*id = BusinessID(v)
+	return err
+}
+
+// ParseItemID parses an item ID from its string representation
+func ParseItemID(value string) (ItemID, error) {
+	id, err := parseID("item", value)
+	return ItemID(id), err
+}
+
+// Int64 returns the ID as int64
+func (id ItemID) Int64() int64 {
+	return int64(id)
+}
+
+// String returns the ID as string
+func (id ItemID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// MarshalJSON marshals the ID as a JSON number
+func (id ItemID) MarshalJSON() ([]byte, error) {
+	return marshalInt(int64(id)), nil
+}
+
+// UnmarshalJSON accepts a quoted or a bare number, "" is zero
+func (id *ItemID) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalID("item", data)
+	*id = ItemID(v)
+	return err
+}
+
+// ParseTransactionID parses a transaction ID from its string representation
+func ParseTransactionID(value string) (TransactionID, error) {
+	id, err := parseID("transaction", value)
+	return TransactionID(id), err
+}
+
+// Int64 returns the ID as int64
+func (id TransactionID) Int64() int64 {
+	return int64(id)
+}
+
+// String returns the ID as string
+func (id TransactionID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// MarshalJSON marshals the ID as a JSON number
+func (id TransactionID) MarshalJSON() ([]byte, error) {
+	return marshalInt(int64(id)), nil
+}
+
+// UnmarshalJSON accepts a quoted or a bare number, "" is zero
+func (id *TransactionID) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalID("transaction", data)
+	*id = TransactionID(v)
+	return err
+}
+
+func parseID(name string, value string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s id %q: %w", name, value, err)
+	}
+	return id, nil
+}
+
+func unmarshalID(name string, data []byte) (int64, error) {
+	str := strings.TrimSpace(strings.Trim(string(data), `"`))
+	if str == "" || str == "null" {
+		return 0, nil
+	}
+	return parseID(name, str)
+}
@@ -0,0 +1,42 @@
+// Command gen-endpoints generates endpoints_gen.go and endpoints_gen_test.go from endpoints.json
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/erfandiakoo/goarpa/v2/internal/codegen"
+)
+
+func main() {
+	var (
+		specPath   = flag.String("spec", "endpoints.json", "endpoint spec")
+		sourcePath = flag.String("out", "endpoints_gen.go", "generated methods")
+		testPath   = flag.String("test", "endpoints_gen_test.go", "generated tests")
+	)
+	flag.Parse()
+
+	spec, err := codegen.Load(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	source, tests, err := codegen.Generate(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*sourcePath, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	if tests == nil {
+		if err := os.Remove(*testPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := os.WriteFile(*testPath, tests, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,292 @@
+// Package codegen generates the client methods of the endpoints declared in endpoints.json.
+//
+// Every generated method follows the hand-written ones: bearer auth with the cookie, query params
+// or JSON body, checkForError, checkForArpaError for the enveloped responses and dry-run for the
+// POST endpoints. The endpoints are added to the client config through the GeneratedEndpoints struct.
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// Spec is the content of endpoints.json
+type Spec struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// Endpoint is an endpoint of the spec
+type Endpoint struct {
+	// Name is the name of the method, the config field being Name + "Endpoint"
+	Name string `json:"name"`
+	// Doc is the doc comment of the method, without the comment markers
+	Doc string `json:"doc"`
+	// Method is GET or POST
+	Method string `json:"method"`
+	// Path is the default endpoint, e.g. "serv/api/GetItemGroups". Without a path
+	// the method returns ErrNotSupported until the endpoint is configured.
+	Path string `json:"path,omitempty"`
+	// ErrMessage is the message of the errors of the method
+	ErrMessage string `json:"errMessage"`
+	// Request is sent as query params for GET and as JSON body for POST
+	Request *Model `json:"request,omitempty"`
+	// Response is the result of the method
+	Response Model `json:"response"`
+	// Envelope wraps the response in APIResponse and checks its Arpa error
+	Envelope bool `json:"envelope"`
+}
+
+// Model is a request or a response model, either an existing type or a struct generated from its fields
+type Model struct {
+	// Name is the name of the type
+	Name string `json:"name"`
+	// Doc is the doc comment of the generated struct
+	Doc string `json:"doc,omitempty"`
+	// Fields are the fields of the generated struct, none when the type exists
+	Fields []Field `json:"fields,omitempty"`
+}
+
+// Field is a field of a generated struct
+type Field struct {
+	Name      string `json:"name"`
+	JSON      string `json:"json"`
+	Type      string `json:"type"`
+	OmitEmpty bool   `json:"omitempty,omitempty"`
+	Doc       string `json:"doc,omitempty"`
+}
+
+// Load reads a spec file
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	return &spec, spec.validate()
+}
+
+func (s *Spec) validate() error {
+	names := make(map[string]bool)
+	for _, e := range s.Endpoints {
+		switch {
+		case e.Name == "":
+			return fmt.Errorf("endpoint without name")
+		case names[e.Name]:
+			return fmt.Errorf("endpoint %s: declared twice", e.Name)
+		case e.Method != http.MethodGet && e.Method != http.MethodPost:
+			return fmt.Errorf("endpoint %s: unsupported method %q", e.Name, e.Method)
+		case e.ErrMessage == "":
+			return fmt.Errorf("endpoint %s: missing errMessage", e.Name)
+		case e.Response.Name == "":
+			return fmt.Errorf("endpoint %s: missing response name", e.Name)
+		}
+		names[e.Name] = true
+	}
+	return nil
+}
+
+// Generate returns the source of the methods and models, and the source of their tests.
+// The tests are nil when the spec has no endpoint.
+func Generate(spec *Spec) (source []byte, tests []byte, err error) {
+	if source, err = execute(sourceTemplate, spec); err != nil {
+		return nil, nil, err
+	}
+	if len(spec.Endpoints) == 0 {
+		return source, nil, nil
+	}
+	if tests, err = execute(testTemplate, spec); err != nil {
+		return nil, nil, err
+	}
+	return source, tests, nil
+}
+
+func execute(tmpl *template.Template, spec *Spec) ([]byte, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, spec); err != nil {
+		return nil, err
+	}
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code: %w\n%s", err, b.String())
+	}
+	return source, nil
+}
+
+var funcs = template.FuncMap{
+	"comment": func(text string) string {
+		return "// " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n// ")
+	},
+	"resultType": func(e Endpoint) string {
+		if e.Envelope {
+			return "APIResponse[" + e.Response.Name + "]"
+		}
+		return e.Response.Name
+	},
+	"isGet": func(e Endpoint) bool {
+		return e.Method == http.MethodGet
+	},
+	"title": func(method string) string {
+		return method[:1] + strings.ToLower(method[1:])
+	},
+	"tag": func(f Field) string {
+		tag := f.JSON
+		if f.OmitEmpty {
+			tag += ",omitempty"
+		}
+		return fmt.Sprintf("`json:%q`", tag)
+	},
+	"models": func(spec *Spec) []Model {
+		var models []Model
+		seen := make(map[string]bool)
+		for _, e := range spec.Endpoints {
+			for _, model := range []*Model{e.Request, &e.Response} {
+				if model != nil && len(model.Fields) > 0 && !seen[model.Name] {
+					seen[model.Name] = true
+					models = append(models, *model)
+				}
+			}
+		}
+		return models
+	},
+}
+
+var sourceTemplate = template.Must(template.New("source").Funcs(funcs).Parse(`// Code generated by internal/cmd/gen-endpoints from endpoints.json. DO NOT EDIT.
+
+package goarpa
+
+{{- if .Endpoints}}
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+{{- end}}
+
+// GeneratedEndpoints are the endpoints of the methods generated from endpoints.json
+type GeneratedEndpoints struct {
+{{- range .Endpoints}}
+	{{.Name}}Endpoint string
+{{- end}}
+}
+
+// defaultGeneratedEndpoints returns the default endpoints of the generated methods
+func defaultGeneratedEndpoints() GeneratedEndpoints {
+	return GeneratedEndpoints{
+{{- range .Endpoints}}{{if .Path}}
+		{{.Name}}Endpoint: {{printf "%q" .Path}},
+{{- end}}{{end}}
+	}
+}
+{{range models .}}
+{{if .Doc}}{{comment .Doc}}
+{{end -}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{if .Doc}}{{comment .Doc}}
+	{{end -}}
+	{{.Name}} {{.Type}} {{tag .}}
+{{- end}}
+}
+{{end}}
+{{- range .Endpoints}}
+{{comment .Doc}}
+func (g *GoArpa) {{.Name}}(ctx context.Context, accessToken string, cookie []*http.Cookie{{if .Request}}, request {{.Request.Name}}{{end}}) (*{{resultType .}}, error) {
+	const errMessage = {{printf "%q" .ErrMessage}}
+
+	url, err := g.endpointURL(g.config().{{.Name}}Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+{{if and (isGet .) .Request}}
+	queryParams, err := GetQueryParams(request)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+{{end}}
+{{- if and .Request (not (isGet .))}}
+	body, err := marshalBody(request)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+{{end}}
+	var result {{resultType .}}
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+{{- if .Request}}{{if isGet .}}
+		SetQueryParams(queryParams).
+{{- else}}
+		SetBody(body).
+{{- end}}{{end}}
+		SetResult(&result)
+{{if not (isGet .)}}
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &result, nil
+	}
+{{end}}
+	resp, err := req.{{title .Method}}(url)
+{{- if not (isGet .)}}
+	g.audit(ctx, accessToken, {{printf "%q" .Name}}, url, {{if .Request}}body{{else}}nil{{end}}, resp, err, {{if .Envelope}}result.Error{{else}}nil{{end}}, nil)
+{{- end}}
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+{{if .Envelope}}
+	if err := g.checkForArpaError(resp, result.Error, errMessage); err != nil {
+		return nil, err
+	}
+{{end}}
+	return &result, nil
+}
+{{end}}`))
+
+var testTemplate = template.Must(template.New("tests").Funcs(funcs).Parse(`// Code generated by internal/cmd/gen-endpoints from endpoints.json. DO NOT EDIT.
+
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+{{range .Endpoints}}
+func Test_{{.Name}}Generated(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, {{printf "%q" .Method}}, r.Method)
+		assert.True(t, strings.HasSuffix(r.URL.Path, "/endpoint"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte({{if .Envelope}}` + "`" + `{"Data":[],"Error":null}` + "`" + `{{else}}` + "`{}`" + `{{end}}))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.{{.Name}}Endpoint = "endpoint"
+
+	result, err := client.{{.Name}}(context.Background(), "token", nil{{if .Request}}, goarpa.{{.Request.Name}}{}{{end}})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	client.Config.{{.Name}}Endpoint = ""
+	_, err = client.{{.Name}}(context.Background(), "token", nil{{if .Request}}, goarpa.{{.Request.Name}}{}{{end}})
+	assert.ErrorIs(t, err, goarpa.ErrNotSupported)
+}
+{{end}}`))
@@ -0,0 +1,55 @@
+package codegen_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2/internal/codegen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Generate(t *testing.T) {
+	t.Parallel()
+	spec, err := codegen.Load(filepath.Join("testdata", "spec.json"))
+	require.NoError(t, err)
+
+	source, tests, err := codegen.Generate(spec)
+	require.NoError(t, err)
+
+	code := string(source)
+	assert.Contains(t, code, "GetItemGroupsEndpoint: \"serv/api/GetItemGroups\",")
+	assert.NotContains(t, code, "VoidTransactionEndpoint: ")
+	assert.Contains(t, code, "func (g *GoArpa) GetItemGroups(ctx context.Context, accessToken string, cookie []*http.Cookie, request GetItemGroupsParams) (*APIResponse[ItemGroup], error) {")
+	assert.Contains(t, code, "ParentID *int64 `json:\"ParentID,omitempty\"`")
+	assert.Contains(t, code, "checkForArpaError(resp, result.Error, errMessage)")
+	assert.Contains(t, code, "func (g *GoArpa) VoidTransaction(ctx context.Context, accessToken string, cookie []*http.Cookie, request VoidTransactionRequest) (*RetCustomerResponse, error) {")
+	assert.Contains(t, code, "logDryRun(req, http.MethodPost, url)")
+	assert.Contains(t, code, `g.audit(ctx, accessToken, "VoidTransaction", url, body, resp, err, nil, nil)`)
+	// the existing response type is not generated
+	assert.NotContains(t, code, "type RetCustomerResponse struct")
+	assert.Contains(t, string(tests), "func Test_VoidTransactionGenerated(t *testing.T) {")
+}
+
+// Test_GeneratedUpToDate checks that endpoints_gen.go matches endpoints.json
+func Test_GeneratedUpToDate(t *testing.T) {
+	t.Parallel()
+	root := filepath.Join("..", "..")
+	spec, err := codegen.Load(filepath.Join(root, "endpoints.json"))
+	require.NoError(t, err)
+	source, _, err := codegen.Generate(spec)
+	require.NoError(t, err)
+
+	generated, err := os.ReadFile(filepath.Join(root, "endpoints_gen.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(source), string(generated), "run go generate")
+}
+
+func Test_LoadInvalid(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "spec.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"endpoints":[{"name":"A","method":"PUT","errMessage":"x","response":{"name":"B"}}]}`), 0o644))
+	_, err := codegen.Load(path)
+	assert.ErrorContains(t, err, "unsupported method")
+}
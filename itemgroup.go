@@ -0,0 +1,80 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ItemGroupNode is an item group with its subgroups
+type ItemGroupNode struct {
+	ItemGroup
+	Children []*ItemGroupNode
+}
+
+// Walk calls fn for the node and its descendants, depth first, with their depth below the node
+func (n *ItemGroupNode) Walk(fn func(node *ItemGroupNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *ItemGroupNode) walk(fn func(node *ItemGroupNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// GetItemGroupTree returns the item groups as a tree, with a single call to GetItemGroups
+func (g *GoArpa) GetItemGroupTree(ctx context.Context, accessToken string, cookie []*http.Cookie) ([]*ItemGroupNode, error) {
+	const errMessage = "could not get item group tree"
+
+	groups, err := g.GetItemGroups(ctx, accessToken, cookie)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+	return BuildItemGroupTree(groups.Data), nil
+}
+
+// BuildItemGroupTree returns the top level groups with their subgroups, in the order of the list.
+// The groups whose parent is unknown, or which would be their own ancestor, are returned at the top level.
+func BuildItemGroupTree(groups []ItemGroup) []*ItemGroupNode {
+	nodes := make(map[int64]*ItemGroupNode, len(groups))
+	ordered := make([]*ItemGroupNode, 0, len(groups))
+	for _, group := range groups {
+		if _, ok := nodes[group.ID.Int64()]; ok {
+			continue
+		}
+		node := &ItemGroupNode{ItemGroup: group}
+		nodes[group.ID.Int64()] = node
+		ordered = append(ordered, node)
+	}
+
+	var roots []*ItemGroupNode
+	// parents are the links of the tree built so far, which has no cycle
+	parents := make(map[int64]int64, len(groups))
+	for _, node := range ordered {
+		parent, ok := nodes[node.ParentID.Int64()]
+		if !ok || hasItemGroupAncestor(parents, parent.ID.Int64(), node.ID.Int64()) {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+		parents[node.ID.Int64()] = parent.ID.Int64()
+	}
+	return roots
+}
+
+// hasItemGroupAncestor reports whether the ancestor is the group or one of its ancestors in the tree
+func hasItemGroupAncestor(parents map[int64]int64, groupID int64, ancestorID int64) bool {
+	for {
+		if groupID == ancestorID {
+			return true
+		}
+		parent, ok := parents[groupID]
+		if !ok {
+			return false
+		}
+		groupID = parent
+	}
+}
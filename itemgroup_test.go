@@ -0,0 +1,61 @@
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetItemGroupTree(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[
+			{"IAGroupID":"3","ParentID":"1","IAGroupName":"Pens"},
+			{"IAGroupID":"1","ParentID":"0","IAGroupName":"Stationery"},
+			{"IAGroupID":"4","ParentID":"3","IAGroupName":"Fountain pens"},
+			{"IAGroupID":"2","ParentID":0,"IAGroupName":"Food"},
+			{"IAGroupID":"5","ParentID":"1","IAGroupName":"Paper"},
+			{"IAGroupID":"6","ParentID":"99","IAGroupName":"Orphan"}
+		],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetItemGroupsEndpoint = "groups"
+	roots, err := client.GetItemGroupTree(context.Background(), "token", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	var lines []string
+	for _, root := range roots {
+		root.Walk(func(node *goarpa.ItemGroupNode, depth int) {
+			lines = append(lines, strings.Repeat("  ", depth)+node.Name)
+		})
+	}
+	assert.Equal(t, []string{"Stationery", "  Pens", "    Fountain pens", "  Paper", "Food", "Orphan"}, lines)
+}
+
+func Test_BuildItemGroupTreeWithCycle(t *testing.T) {
+	t.Parallel()
+	roots := goarpa.BuildItemGroupTree([]goarpa.ItemGroup{
+		{ID: 1, ParentID: 2, Name: "A"},
+		{ID: 2, ParentID: 1, Name: "B"},
+		{ID: 3, ParentID: 3, Name: "C"},
+	})
+	require.Len(t, roots, 2)
+	assert.Equal(t, "B", roots[0].Name)
+	require.Len(t, roots[0].Children, 1)
+	assert.Equal(t, "A", roots[0].Children[0].Name)
+	assert.Equal(t, "C", roots[1].Name)
+	assert.Empty(t, roots[1].Children)
+}
@@ -0,0 +1,116 @@
+package goarpa
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/erfandiakoo/goarpa/v2/shared/constant"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// WithQueryKeyNames renames the query parameters of the requests of the client, for the installations
+// with customized parameter names, e.g. {constant.MobileKey: "Mobile"}. The parameters of the list params
+// and of the generated endpoints are renamed alike.
+func WithQueryKeyNames(names map[constant.QueryKey]string) func(*GoArpa) {
+	return func(g *GoArpa) {
+		if g.queryKeyNames == nil {
+			g.queryKeyNames = make(map[string]string, len(names))
+		}
+		for key, name := range names {
+			g.queryKeyNames[string(key)] = name
+		}
+	}
+}
+
+// WithFieldKeyNames renames the fields of the JSON bodies of the requests of the client, at any depth,
+// for the installations with customized field names, e.g. {constant.StockIDKey: "WarehouseID"}.
+// The bodies are renamed when they are sent, after the other options of the client saw them.
+func WithFieldKeyNames(names map[constant.FieldKey]string) func(*GoArpa) {
+	return func(g *GoArpa) {
+		if g.fieldKeyNames == nil {
+			g.fieldKeyNames = make(map[string]string, len(names))
+		}
+		for key, name := range names {
+			g.fieldKeyNames[string(key)] = name
+		}
+	}
+}
+
+// renameKeys renames the query parameters and the body fields of the request with the names of the client
+func (g *GoArpa) renameKeys(_ *resty.Client, req *resty.Request) error {
+	// the retries send the renamed request again
+	if req.Attempt > 1 {
+		return nil
+	}
+	if len(g.queryKeyNames) > 0 && len(req.QueryParam) > 0 {
+		renamed := make(url.Values, len(req.QueryParam))
+		for key, values := range req.QueryParam {
+			if name, ok := g.queryKeyNames[key]; ok {
+				key = name
+			}
+			renamed[key] = append(renamed[key], values...)
+		}
+		req.QueryParam = renamed
+	}
+	if len(g.fieldKeyNames) > 0 && strings.Contains(req.Header.Get("Content-Type"), "json") {
+		body, err := renameBodyFields(req.Body, g.fieldKeyNames)
+		if err != nil {
+			return errors.Wrap(err, "could not rename body fields")
+		}
+		req.Body = body
+	}
+	return nil
+}
+
+// renameBodyFields returns the JSON of the body with the fields renamed, the body when it is not JSON
+func renameBodyFields(body interface{}, names map[string]string) (interface{}, error) {
+	var data []byte
+	switch b := body.(type) {
+	case nil, io.Reader:
+		return body, nil
+	case []byte:
+		data = b
+	case string:
+		data = []byte(b)
+	default:
+		var err error
+		if data, err = json.Marshal(b); err != nil {
+			return nil, err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// the amounts are kept as they were sent
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body, nil
+	}
+	return json.Marshal(renameFields(value, names))
+}
+
+// renameFields renames the keys of the objects of the decoded JSON value
+func renameFields(value interface{}, names map[string]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			if name, ok := names[key]; ok {
+				key = name
+			}
+			renamed[key] = renameFields(field, names)
+		}
+		return renamed
+	case []interface{}:
+		for i, element := range v {
+			v[i] = renameFields(element, names)
+		}
+		return v
+	default:
+		return value
+	}
+}
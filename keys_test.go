@@ -0,0 +1,55 @@
+package goarpa_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/shared/constant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KeyNames(t *testing.T) {
+	t.Parallel()
+	var body struct {
+		Data map[string]interface{} `json:"Data"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			assert.Empty(t, r.URL.Query().Get("MobileNo"))
+			assert.Equal(t, "09120000000", r.URL.Query().Get("Mobile"))
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"7"}]}`))
+			return
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL,
+		goarpa.WithQueryKeyNames(map[constant.QueryKey]string{constant.MobileKey: "Mobile"}),
+		goarpa.WithFieldKeyNames(map[constant.FieldKey]string{constant.StockIDKey: "WarehouseID"}),
+	)
+	ctx := context.Background()
+
+	customer, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.NoError(t, err)
+	require.NotNil(t, customer)
+
+	transaction := goarpa.CreateTransactionRequest{Data: goarpa.Data{
+		BusinessID:   7,
+		FactorTypeID: goarpa.FactorTypeSale,
+		TransStateID: goarpa.TransStateFinal,
+		WarehouseID:  3,
+	}}
+	_, err = client.CreateTransaction(ctx, "token", transaction)
+	require.NoError(t, err)
+	assert.NotContains(t, body.Data, "StockID")
+	assert.Equal(t, 3.0, body.Data["WarehouseID"])
+	assert.Equal(t, 7.0, body.Data["BusinessID"])
+}
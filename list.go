@@ -0,0 +1,112 @@
+package goarpa
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// DefaultPageSize is the page size used when the list params do not set one
+const DefaultPageSize = 100
+
+// ListParams are the paging params of the list endpoints, pages start at 1
+type ListParams struct {
+	Page     int `json:"PageNumber,omitempty"`
+	PageSize int `json:"PageSize,omitempty"`
+}
+
+// GetCustomersParams are the params of GetCustomers
+type GetCustomersParams struct {
+	ListParams
+	ModifiedSince *time.Time `json:"ModifiedSince,omitempty"`
+	// Inactive selects the businesses by their InActive flag, the filter of the client by default
+	Inactive InactiveFilter `json:"-"`
+}
+
+// GetTransactionsParams are the params of GetTransactions
+type GetTransactionsParams struct {
+	ListParams
+	BusinessID    *BusinessID `json:"BusinessID,omitempty"`
+	FromDate      *time.Time  `json:"FromDate,omitempty"`
+	ToDate        *time.Time  `json:"ToDate,omitempty"`
+	ModifiedSince *time.Time  `json:"ModifiedSince,omitempty"`
+}
+
+// GetItemsParams are the params of GetItems
+type GetItemsParams struct {
+	ListParams
+	ModifiedSince *time.Time `json:"ModifiedSince,omitempty"`
+}
+
+// Transaction is a transaction header returned by the transaction list
+type Transaction struct {
+	TransactionID    TransactionID  `json:"TransactionID"`
+	TransNumber      EnforcedInt    `json:"TransNumber"`
+	BusinessID       BusinessID     `json:"BusinessID"`
+	TransDate        *CustomTime    `json:"TransDate"`
+	TransStateID     TransState     `json:"TransStateID,omitempty"`
+	FactorTypeID     FactorType     `json:"FactorTypeID,omitempty"`
+	TotalAmount      Money          `json:"TotalAmount"`
+	Description      EnforcedString `json:"Description"`
+	ModificationDate *CustomTime    `json:"Modification_Date"`
+}
+
+// GetTransactionsResponse is the response of GetTransactions
+type GetTransactionsResponse = APIResponse[Transaction]
+
+// normalize returns the params with the defaults applied
+func (p ListParams) normalize() ListParams {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 {
+		p.PageSize = DefaultPageSize
+	}
+	return p
+}
+
+// iteratePages yields the items of the pages returned by fetch, starting at the given page,
+// until a page has less items than the page size
+func iteratePages[T any](ctx context.Context, paging ListParams, fetch func(ctx context.Context, paging ListParams) ([]T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		paging := paging.normalize()
+		for {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			items, err := fetch(ctx, paging)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(items) < paging.PageSize {
+				return
+			}
+			paging.Page++
+		}
+	}
+}
+
+// filterSeq yields the items of seq which are kept and all the errors.
+// The lists are filtered after paging, a filtered page would end the iteration early.
+func filterSeq[T any](seq iter.Seq2[T, error], keep func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item, err := range seq {
+			if err == nil && !keep(item) {
+				continue
+			}
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
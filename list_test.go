@@ -0,0 +1,165 @@
+package goarpa_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IterateCustomers(t *testing.T) {
+	t.Parallel()
+	const total = 25
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("PageNumber"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("PageSize"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[`))
+		for i := (page-1)*pageSize + 1; i <= page*pageSize && i <= total; i++ {
+			if i > (page-1)*pageSize+1 {
+				_, _ = w.Write([]byte(","))
+			}
+			_, _ = fmt.Fprintf(w, `{"BusinessID":"%d"}`, i)
+		}
+		_, _ = w.Write([]byte(`],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetCustomersEndpoint = "customers"
+
+	var ids []goarpa.BusinessID
+	params := goarpa.GetCustomersParams{ListParams: goarpa.ListParams{PageSize: 10}}
+	for customer, err := range client.IterateCustomers(context.Background(), "token", []*http.Cookie{{Name: "session", Value: "1"}}, params) {
+		require.NoError(t, err)
+		ids = append(ids, customer.ID)
+	}
+	require.Len(t, ids, total)
+	assert.Equal(t, goarpa.BusinessID(1), ids[0])
+	assert.Equal(t, goarpa.BusinessID(total), ids[total-1])
+}
+
+func Test_ExportCustomers(t *testing.T) {
+	t.Parallel()
+	const total = 95
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("PageNumber"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("PageSize"))
+		// earlier pages answer slower, so that the order has to be restored
+		time.Sleep(time.Duration(10-page%10) * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[`))
+		for i := (page-1)*pageSize + 1; i <= page*pageSize && i <= total; i++ {
+			if i > (page-1)*pageSize+1 {
+				_, _ = w.Write([]byte(","))
+			}
+			_, _ = fmt.Fprintf(w, `{"BusinessID":"%d"}`, i)
+		}
+		_, _ = w.Write([]byte(`],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetCustomersEndpoint = "customers"
+
+	params := goarpa.GetCustomersParams{ListParams: goarpa.ListParams{PageSize: 10}}
+	var ids []goarpa.BusinessID
+	for result := range client.ExportCustomers(context.Background(), "token", []*http.Cookie{{Name: "session", Value: "1"}}, params, 4) {
+		require.NoError(t, result.Err)
+		ids = append(ids, result.Item.ID)
+	}
+	require.Len(t, ids, total)
+	for i, id := range ids {
+		assert.Equal(t, goarpa.BusinessID(i+1), id)
+	}
+}
+
+func Test_GetCustomersStream(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]int{
+		`{"data":[{"BusinessID":"1"},{"BusinessID":"2"},{"BusinessID":"3"}],"error":null}`: 3,
+		`{"error":null,"data":{"BusinessID":"1","BusinessName":"Test"}}`:                   1,
+		`{"data":null,"error":null}`: 0,
+	}
+	for body, count := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}))
+		client := goarpa.NewClient(server.URL)
+		client.Config.GetCustomersEndpoint = "customers"
+
+		var customers []goarpa.Customer
+		err := client.GetCustomersStream(context.Background(), "token", []*http.Cookie{{Name: "session", Value: "1"}}, goarpa.GetCustomersParams{}, func(customer goarpa.Customer) error {
+			customers = append(customers, customer)
+			return nil
+		})
+		server.Close()
+
+		require.NoError(t, err, body)
+		assert.Len(t, customers, count, body)
+	}
+}
+
+func Test_InactiveFilter(t *testing.T) {
+	t.Parallel()
+	const total = 25
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("Mobile") != "" {
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"1","InActive":"1"}],"error":null}`))
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("PageNumber"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("PageSize"))
+		_, _ = w.Write([]byte(`{"data":[`))
+		for i := (page-1)*pageSize + 1; i <= page*pageSize && i <= total; i++ {
+			if i > (page-1)*pageSize+1 {
+				_, _ = w.Write([]byte(","))
+			}
+			// the even businesses are deactivated
+			_, _ = fmt.Fprintf(w, `{"BusinessID":"%d","InActive":"%d"}`, i, 1-i%2)
+		}
+		_, _ = w.Write([]byte(`],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithInactiveFilter(goarpa.ExcludeInactive))
+	client.Config.GetCustomersEndpoint = "customers"
+	ctx := context.Background()
+
+	found, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.NoError(t, err)
+	assert.Empty(t, found.Data)
+
+	// the filtered pages do not end the iteration early
+	var ids []goarpa.BusinessID
+	params := goarpa.GetCustomersParams{ListParams: goarpa.ListParams{PageSize: 10}}
+	for customer, err := range client.IterateCustomers(ctx, "token", nil, params) {
+		require.NoError(t, err)
+		assert.True(t, customer.IsActive())
+		ids = append(ids, customer.ID)
+	}
+	assert.Len(t, ids, 13)
+
+	exported := 0
+	for result := range client.ExportCustomers(ctx, "token", nil, params, 2) {
+		require.NoError(t, result.Err)
+		exported++
+	}
+	assert.Equal(t, 13, exported)
+
+	params.Page = 1
+	params.Inactive = goarpa.OnlyInactive
+	page, err := client.GetCustomers(ctx, "token", nil, params)
+	require.NoError(t, err)
+	require.Len(t, page.Data, 5)
+	assert.False(t, page.Data[0].IsActive())
+}
@@ -0,0 +1,107 @@
+//go:build live
+
+package goarpa_test
+
+// The live tests run the API tests of cassette_test.go against the Arpa server of testdata/config.json,
+// or of the file named by GOARPA_TEST_CONFIG:
+//
+//	go test -tags live -run Test_GetAdminToken .
+//
+// Without a config they replay the cassettes of testdata/cassettes, as without the live tag.
+// The interactions are recorded into the cassettes with GOARPA_VCR=record.
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/vcr"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/rand"
+)
+
+func init() {
+	loadLiveConfig = loadConfig
+	recordCassette = newCassetteRecorder
+}
+
+// loadConfig loads the config of the server, or returns the replay config when there is no testdata/config.json
+func loadConfig(t testing.TB) *Config {
+	rand.Seed(uint64(time.Now().UTC().UnixNano()))
+	configFileName, ok := os.LookupEnv("GOARPA_TEST_CONFIG")
+	if !ok {
+		configFileName = filepath.Join("testdata", "config.json")
+	}
+	configFile, err := os.Open(configFileName)
+	if !ok && errors.Is(err, os.ErrNotExist) {
+		return replayConfig()
+	}
+	require.NoError(t, err, "cannot open config.json")
+	defer func() {
+		err := configFile.Close()
+		require.NoError(t, err, "cannot close config file")
+	}()
+	data, err := ioutil.ReadAll(configFile)
+	require.NoError(t, err, "cannot read config.json")
+	config := &Config{}
+	err = json.Unmarshal(data, config)
+	require.NoError(t, err, "cannot parse config.json")
+	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	if len(config.Proxy) != 0 {
+		proxy, err := url.Parse(config.Proxy)
+		require.NoError(t, err, "incorrect proxy url: "+config.Proxy)
+		http.DefaultTransport.(*http.Transport).Proxy = http.ProxyURL(proxy)
+	}
+	return config
+}
+
+// newCassetteRecorder records the interactions into the cassette when GOARPA_VCR=record
+func newCassetteRecorder(t testing.TB, path string) *vcr.Recorder {
+	if os.Getenv("GOARPA_VCR") != "record" {
+		return nil
+	}
+	recorder, err := vcr.New(path, vcr.ModeRecord)
+	require.NoError(t, err, "cannot create the recorder")
+	t.Cleanup(func() {
+		require.NoError(t, recorder.Stop(), "cannot write the cassette")
+	})
+	return recorder
+}
+
+// Test_RecordTotals posts the transactions of testdata/totals as drafts and records the TotalAmount listed by Arpa,
+// see testdata/totals/README.md
+func Test_RecordTotals(t *testing.T) {
+	if !*updateGolden || GetConfig(t).replay {
+		t.Skip("the totals are recorded against a server with -update")
+	}
+	client := NewClientWithDebug(t)
+	token, cookie := GetToken(t, client)
+	ctx := context.Background()
+	since := time.Now().Add(-24 * time.Hour)
+
+	for name, fixture := range readTotalsFixtures(t) {
+		transaction := fixture.Transaction
+		reference := fmt.Sprintf("goarpa-totals-%s-%d", name, time.Now().UnixNano())
+		require.NoError(t, transaction.Data.SetReference(reference))
+		_, err := client.CreateTransaction(ctx, token, transaction)
+		require.NoError(t, err, name)
+
+		posted, err := client.GetTransactionByReference(ctx, token, cookie, reference, goarpa.GetTransactionsParams{FromDate: &since})
+		require.NoError(t, err, name)
+		fixture.TotalAmount = posted.TotalAmount
+
+		data, err := json.MarshalIndent(fixture, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join("testdata", "totals", name+".json"), append(data, '\n'), 0o644))
+	}
+}
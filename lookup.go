@@ -0,0 +1,104 @@
+package goarpa
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultLookupConcurrency is the number of lookups GetCustomersByMobiles runs at the same time by default
+const DefaultLookupConcurrency = 8
+
+// WithLookupConcurrency sets the number of lookups the batch lookups run at the same time,
+// DefaultLookupConcurrency by default
+func WithLookupConcurrency(concurrency int) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.lookupConcurrency = concurrency
+	}
+}
+
+// MobileLookupResult is the result of GetCustomersByMobiles keyed by the mobile numbers as they were given.
+// A mobile number is either in Customers, with no business when none has it, or in Errors.
+type MobileLookupResult struct {
+	Customers map[string][]Customer
+	Errors    map[string]error
+}
+
+// Err returns the first error of the lookups in the order of the mobile numbers, nil if they all succeeded
+func (r *MobileLookupResult) Err(mobiles []string) error {
+	for _, mobile := range mobiles {
+		if err, ok := r.Errors[mobile]; ok {
+			return errors.Wrapf(err, "mobile %s", mobile)
+		}
+	}
+	return nil
+}
+
+// GetCustomersByMobiles looks the mobile numbers up with GetCustomerByMobile concurrently, e.g. to enrich
+// the contacts of a CRM. The duplicated numbers are looked up once. The error is only returned when
+// the session cannot be obtained or the context is done, the errors of the lookups are in the result.
+func (g *GoArpa) GetCustomersByMobiles(ctx context.Context, session SessionSource, mobiles []string) (*MobileLookupResult, error) {
+	concurrency := g.lookupConcurrency
+	if concurrency < 1 {
+		concurrency = DefaultLookupConcurrency
+	}
+	return customersByMobiles(ctx, g, session, mobiles, concurrency)
+}
+
+// customersByMobiles looks up the mobile numbers with up to concurrency lookups at the same time
+func customersByMobiles(ctx context.Context, client GoArpaIface, session SessionSource, mobiles []string, concurrency int) (*MobileLookupResult, error) {
+	const errMessage = "could not get customers by mobiles"
+
+	accessToken, cookie, err := session.Session(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	result := &MobileLookupResult{
+		Customers: make(map[string][]Customer, len(mobiles)),
+		Errors:    make(map[string]error),
+	}
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		seen      = make(map[string]bool, len(mobiles))
+		semaphore = make(chan struct{}, concurrency)
+	)
+	for _, mobile := range mobiles {
+		if seen[mobile] {
+			continue
+		}
+		seen[mobile] = true
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return result, errors.Wrap(ctx.Err(), errMessage)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			response, err := client.GetCustomerByMobile(ctx, accessToken, cookie, mobile)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors[mobile] = err
+				return
+			}
+			customers := make([]Customer, 0, len(response.Data))
+			for _, datum := range response.Data {
+				customers = append(customers, datum.ToCustomer())
+			}
+			result.Customers[mobile] = customers
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return result, errors.Wrap(err, errMessage)
+	}
+	return result, nil
+}
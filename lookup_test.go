@@ -0,0 +1,62 @@
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetCustomersByMobiles(t *testing.T) {
+	t.Parallel()
+	var inFlight, maxInFlight, lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/serv/token/GetServiceToken" {
+			_, _ = w.Write([]byte("token"))
+			return
+		}
+		lookups.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("MobileNo") {
+		case "09120000001":
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"1","Mobile":"09120000001"}],"error":null}`))
+		case "09120000002":
+			_, _ = w.Write([]byte(`{"data":[],"error":"internal error"}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithLookupConcurrency(2))
+	session := goarpa.NewTokenManager(client, "user", "pass", goarpa.TokenManagerOptions{})
+	mobiles := []string{"09120000001", "09120000002", "09120000003", "09120000004", "09120000001"}
+	result, err := client.GetCustomersByMobiles(context.Background(), session, mobiles)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(4), lookups.Load())
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	require.Len(t, result.Customers["09120000001"], 1)
+	assert.Equal(t, goarpa.BusinessID(1), result.Customers["09120000001"][0].ID)
+	assert.Empty(t, result.Customers["09120000003"])
+	assert.Contains(t, result.Customers, "09120000004")
+	assert.NotContains(t, result.Customers, "09120000002")
+	require.Len(t, result.Errors, 1)
+	assert.ErrorContains(t, result.Err(mobiles), "mobile 09120000002")
+}
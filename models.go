@@ -2,35 +2,177 @@ package goarpa
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
+
+	ptime "github.com/yaa110/go-persian-calendar"
 )
 
 // GetQueryParams converts the struct to map[string]string
-// The fields tags must have `json:"<name>,string,omitempty"` format for all types, except strings
-// The string fields must have: `json:"<name>,omitempty"`. The `json:"<name>,string,omitempty"` tag for string field
-// will add additional double quotes.
-// "string" tag allows to convert the non-string fields of a structure to map[string]string.
-// "omitempty" allows to skip the fields with default values.
+// The keys are taken from the `json` tags of the fields, "omitempty" allows to skip the fields with zero values
+// and "-" skips the field. The ",string" option of the tag is accepted but not required anymore.
+// Nested structs are flattened using "parent.child" keys, embedded structs are flattened as is,
+// slices are joined with commas, maps use "parent[key]" keys and times use the Arpa date time format.
 func GetQueryParams(s interface{}) (map[string]string, error) {
-	// if obj, ok := s.(GetGroupsParams); ok {
-	// 	obj.OnMarshal()
-	// 	s = obj
-	// }
-	b, err := json.Marshal(s)
-	if err != nil {
-		return nil, err
-	}
-	var res map[string]string
-	err = json.Unmarshal(b, &res)
-	if err != nil {
+	res := make(map[string]string)
+	if err := flattenQueryParam(res, "", reflect.ValueOf(s), false); err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	customTimeType    = reflect.TypeOf(CustomTime{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func flattenQueryParam(res map[string]string, key string, v reflect.Value, omitEmpty bool) error {
+	// like encoding/json, a non-nil pointer is never considered empty
+	if !v.IsValid() || (omitEmpty && v.IsZero()) {
+		return nil
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if value, ok, err := formatQueryValue(v); ok || err != nil {
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			return fmt.Errorf("cannot convert %s to query params", v.Type())
+		}
+		res[key] = value
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !(field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct) {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" && opts == "" {
+				continue
+			}
+			fieldOmitEmpty := strings.Contains(","+opts+",", ",omitempty,")
+			fieldValue := v.Field(i)
+
+			if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+				if err := flattenQueryParam(res, key, fieldValue, fieldOmitEmpty); err != nil {
+					return err
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if key != "" {
+				name = key + "." + name
+			}
+			if err := flattenQueryParam(res, name, fieldValue, fieldOmitEmpty); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("cannot convert map with %s keys to query params", v.Type().Key())
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			name := iter.Key().String()
+			if key != "" {
+				name = key + "[" + name + "]"
+			}
+			if err := flattenQueryParam(res, name, iter.Value(), false); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			for item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface {
+				if item.IsNil() {
+					break
+				}
+				item = item.Elem()
+			}
+			value, ok, err := formatQueryValue(item)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("cannot convert slice of %s to query params", item.Type())
+			}
+			values = append(values, value)
+		}
+		if key == "" {
+			return fmt.Errorf("cannot convert %s to query params", v.Type())
+		}
+		res[key] = strings.Join(values, ",")
+		return nil
+	}
+
+	return fmt.Errorf("cannot convert %s to query params", v.Type())
+}
+
+// formatQueryValue formats scalar values, ok is false if the value is not a scalar
+func formatQueryValue(v reflect.Value) (string, bool, error) {
+	if !v.IsValid() || ((v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil()) {
+		return "", true, nil
+	}
+
+	switch v.Type() {
+	case customTimeType:
+		return v.Interface().(CustomTime).In(CustomTimeLocation).Format(CustomTimeLayout), true, nil
+	case timeType:
+		return v.Interface().(time.Time).In(CustomTimeLocation).Format(CustomTimeLayout), true, nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), true, err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true, nil
+	}
+	return "", false, nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
 // StringOrArray represents a value that can either be a string or an array of strings
 type StringOrArray []string
 
@@ -61,11 +203,51 @@ func (s *StringOrArray) MarshalJSON() ([]byte, error) {
 	return json.Marshal([]string(*s))
 }
 
+// ObjectOrArray represents a value that can either be a single object or an array of objects
+type ObjectOrArray[T any] []T
+
+// UnmarshalJSON unmarshals a single object or an array of objects, null is an empty array
+func (o *ObjectOrArray[T]) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		*o = nil
+		return nil
+	}
+
+	if data[0] == '[' {
+		var obj []T
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		*o = ObjectOrArray[T](obj)
+		return nil
+	}
+
+	var obj T
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*o = ObjectOrArray[T]([]T{obj})
+	return nil
+}
+
+// MarshalJSON converts the objects to a JSON array or a JSON object if there is only one item in the array
+func (o ObjectOrArray[T]) MarshalJSON() ([]byte, error) {
+	if len(o) == 1 {
+		return json.Marshal(o[0])
+	}
+	return json.Marshal([]T(o))
+}
+
 // EnforcedString can be used when the expected value is string but Keycloak in some cases gives you mixed types
 type EnforcedString string
 
-// UnmarshalJSON modify data as string before json unmarshal
+// UnmarshalJSON modify data as string before json unmarshal, null being an empty string
 func (s *EnforcedString) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = ""
+		return nil
+	}
 	if data[0] != '"' {
 		// Escape unescaped quotes
 		data = bytes.ReplaceAll(data, []byte(`"`), []byte(`\"`))
@@ -87,6 +269,163 @@ func (s *EnforcedString) MarshalJSON() ([]byte, error) {
 	return json.Marshal(*s)
 }
 
+// EnforcedInt can be used when the expected value is an integer but Arpa in some cases gives you "123", 123 or 123.0
+type EnforcedInt int64
+
+// UnmarshalJSON accepts quoted and bare numbers, a number with a zero fraction and "" (as zero)
+func (i *EnforcedInt) UnmarshalJSON(data []byte) error {
+	str := trimEnforcedNumber(data)
+	if str == "" {
+		*i = 0
+		return nil
+	}
+
+	if v, err := strconv.ParseInt(str, 10, 64); err == nil {
+		*i = EnforcedInt(v)
+		return nil
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil || f != math.Trunc(f) {
+		return fmt.Errorf("invalid integer value %s", data)
+	}
+	*i = EnforcedInt(f)
+	return nil
+}
+
+// MarshalJSON return json marshal
+func (i EnforcedInt) MarshalJSON() ([]byte, error) {
+	return marshalInt(int64(i)), nil
+}
+
+// EnforcedFloat can be used when the expected value is a float but Arpa in some cases gives you a quoted number
+type EnforcedFloat float64
+
+// UnmarshalJSON accepts quoted and bare numbers and "" (as zero)
+func (f *EnforcedFloat) UnmarshalJSON(data []byte) error {
+	v, err := parseEnforcedNumber(data)
+	if err != nil {
+		return err
+	}
+	*f = EnforcedFloat(v)
+	return nil
+}
+
+// MarshalJSON return json marshal
+func (f EnforcedFloat) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(f))
+}
+
+// marshalInt marshals the number without going through the reflection of encoding/json
+func marshalInt(value int64) []byte {
+	return strconv.AppendInt(make([]byte, 0, 20), value, 10)
+}
+
+// marshalQuotedInt marshals the number as a JSON string
+func marshalQuotedInt(value int64) []byte {
+	b := strconv.AppendInt(append(make([]byte, 0, 22), '"'), value, 10)
+	return append(b, '"')
+}
+
+// appendJSONFloat appends the number formatted like encoding/json does
+func appendJSONFloat(b []byte, f float64) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("unsupported float value %v", f)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b, nil
+}
+
+func parseEnforcedNumber(data []byte) (float64, error) {
+	str := trimEnforcedNumber(data)
+	if str == "" {
+		return 0, nil
+	}
+
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number value %s", data)
+	}
+	return v, nil
+}
+
+func trimEnforcedNumber(data []byte) string {
+	str := strings.TrimSpace(strings.Trim(string(data), `"`))
+	if str == "null" {
+		return ""
+	}
+	return str
+}
+
+// StringInt64 is an int64 which Arpa sends either as a quoted or as a bare number
+type StringInt64 int64
+
+// UnmarshalJSON accepts "123", 123 and "" (as zero)
+func (s *StringInt64) UnmarshalJSON(data []byte) error {
+	str := strings.TrimSpace(strings.Trim(string(data), `"`))
+	if str == "" || str == "null" {
+		*s = 0
+		return nil
+	}
+
+	v, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid int64 value %s: %w", data, err)
+	}
+	*s = StringInt64(v)
+	return nil
+}
+
+// MarshalJSON marshals the value as a quoted number
+func (s StringInt64) MarshalJSON() ([]byte, error) {
+	return marshalQuotedInt(int64(s)), nil
+}
+
+// Int64 returns the value as int64
+func (s StringInt64) Int64() int64 {
+	return int64(s)
+}
+
+// StringBool is a boolean which Arpa sends as "1"/"0", "True"/"False" or a bare JSON value
+type StringBool bool
+
+// UnmarshalJSON accepts quoted or bare 1/0 and true/false values, an empty value is false
+func (s *StringBool) UnmarshalJSON(data []byte) error {
+	str := strings.TrimSpace(strings.Trim(string(data), `"`))
+	switch strings.ToLower(str) {
+	case "", "null", "0", "false":
+		*s = false
+	case "1", "true":
+		*s = true
+	default:
+		return fmt.Errorf("invalid bool value %s", data)
+	}
+	return nil
+}
+
+// MarshalJSON marshals the value as "1" or "0"
+func (s StringBool) MarshalJSON() ([]byte, error) {
+	if s {
+		return []byte(`"1"`), nil
+	}
+	return []byte(`"0"`), nil
+}
+
+// Bool returns the value as bool
+func (s StringBool) Bool() bool {
+	return bool(s)
+}
+
 // APIErrType is a field containing more specific API error types
 // that may be checked by the receiver.
 type APIErrType string
@@ -99,6 +438,14 @@ const (
 	// APIErrTypeInvalidGrant corresponds with Keycloak's
 	// OAuthErrorException due to "invalid_grant".
 	APIErrTypeInvalidGrant = "oauth: invalid grant"
+
+	// APIErrTypeArpa is for errors returned in the "error" field
+	// of an Arpa response envelope.
+	APIErrTypeArpa APIErrType = "arpa"
+
+	// APIErrTypeSessionExpired is for the calls rejected because
+	// the session expired, see ErrSessionExpired.
+	APIErrTypeSessionExpired APIErrType = "session expired"
 )
 
 // ParseAPIErrType is a convenience method for returning strongly
@@ -116,10 +463,15 @@ func ParseAPIErrType(err error) APIErrType {
 }
 
 // APIError holds message and statusCode for api errors
+// LastErr is the last transport error, Attempts and RetryWait are filled when the request has been retried
 type APIError struct {
-	Code    int        `json:"code"`
-	Message string     `json:"message"`
-	Type    APIErrType `json:"type"`
+	Code      int           `json:"code"`
+	Message   string        `json:"message"`
+	Type      APIErrType    `json:"type"`
+	Attempts  int           `json:"attempts,omitempty"`
+	RetryWait time.Duration `json:"retryWait,omitempty"`
+	LastErr   error         `json:"-"`
+	Arpa      *ArpaError    `json:"arpa,omitempty"`
 }
 
 // Error stringifies the APIError
@@ -127,139 +479,174 @@ func (apiError APIError) Error() string {
 	return apiError.Message
 }
 
+// Unwrap returns the last transport error of the retried request
+func (apiError APIError) Unwrap() error {
+	return apiError.LastErr
+}
+
+// Is allows matching the error with errors.Is(err, ErrSessionExpired)
+func (apiError APIError) Is(target error) bool {
+	return target == ErrSessionExpired && apiError.Type == APIErrTypeSessionExpired
+}
+
 type CreateCustomerRequest struct {
-	BusName            string  `json:"BusName"`
-	ProvinceID         *int64  `json:"ProvinceId"`
-	CityID             *int64  `json:"CityId"`
-	Email              *string `json:"Email"`
-	Mobile             *string `json:"Mobile"`
-	PhoneNo            *string `json:"PhoneNo"`
-	Name               *string `json:"Name"`
-	Family             *string `json:"Family"`
-	NationalCode       *int64  `json:"NationalCode"`
-	BirthDate          *string `json:"BirthDate"`
-	Sexuality          *string `json:"Sexuality"`
-	RealOrFinancial    *int64  `json:"RealOrFinancial"`
-	Address            *string `json:"Address"`
-	FinCode            *int64  `json:"FinCode"`
-	IDNo               *int64  `json:"IDNo"`
-	RegisterNumber     *int64  `json:"RegisterNumber"`
-	BusinessCategoryID *int64  `json:"BusinessCategoryId"`
+	BusName            string           `json:"BusName"`
+	ProvinceID         *int64           `json:"ProvinceId"`
+	CityID             *int64           `json:"CityId"`
+	Email              *string          `json:"Email"`
+	Mobile             *string          `json:"Mobile"`
+	PhoneNo            *string          `json:"PhoneNo"`
+	Name               *string          `json:"Name"`
+	Family             *string          `json:"Family"`
+	NationalCode       *NationalCode    `json:"NationalCode"`
+	BirthDate          *string          `json:"BirthDate"`
+	Sexuality          *Sexuality       `json:"Sexuality"`
+	RealOrFinancial    *RealOrFinancial `json:"RealOrFinancial"`
+	Address            *string          `json:"Address"`
+	FinCode            *NumericCode     `json:"FinCode"`
+	IDNo               *NumericCode     `json:"IDNo"`
+	RegisterNumber     *int64           `json:"RegisterNumber"`
+	BusinessCategoryID *int64           `json:"BusinessCategoryId"`
+	// TaxExempt exempts the business from the tax and toll, see Customer.TaxExempt
+	TaxExempt *bool `json:"TaxExempt,omitempty"`
 }
 
 type RetCustomerResponse struct {
 	Data  CreateCustomerResponse `json:"data"`
-	Error interface{}            `json:"error"`
+	Error *ArpaError             `json:"error"`
 }
 
+// CreateCustomerResponse is the created business, or the existing one if Existed is true
 type CreateCustomerResponse struct {
-	BusinessID   string `json:"BusinessId"`
-	BusinessCode string `json:"BusinessCode"`
-	Existed      string `json:"Existed"`
+	BusinessID   BusinessID     `json:"BusinessId"`
+	BusinessCode EnforcedString `json:"BusinessCode"`
+	Existed      StringBool     `json:"Existed"`
+}
+
+// UnmarshalJSON decodes the response from an object or from the first item of an array,
+// which Arpa returns when the customer already existed
+func (r *CreateCustomerResponse) UnmarshalJSON(data []byte) error {
+	type createCustomerResponse CreateCustomerResponse
+	var items ObjectOrArray[createCustomerResponse]
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	*r = CreateCustomerResponse{}
+	if len(items) > 0 {
+		*r = CreateCustomerResponse(items[0])
+	}
+	return nil
 }
 
 type CreateTransactionRequest struct {
-	Data   Data                `json:"Data"`
-	Items  []map[string]*int64 `json:"Items"`
-	AddSub []AddSub            `json:"AddSub"`
+	Data   Data              `json:"Data"`
+	Items  []TransactionItem `json:"Items"`
+	AddSub []AddSub          `json:"AddSub"`
 }
 
 type AddSub struct {
 	AddSubID  int64 `json:"AddSubID"`
-	TASAmount int64 `json:"TASAmount"`
+	TASAmount Money `json:"TASAmount"`
 }
 
 type Data struct {
-	TransactionID        interface{} `json:"TransactionID"`
-	BusinessID           int64       `json:"BusinessID"`
-	DocAliasID           int64       `json:"DocAliasId"`
-	TransStateID         int64       `json:"TransStateId"`
-	FactorTypeID         int64       `json:"FactorTypeId"`
-	CalcTaxAndToll       int64       `json:"CalcTaxAndToll"`
-	TransDiscountAmount  int64       `json:"TransDiscountAmount"`
-	TransDiscountPercent float64     `json:"TransDiscountPercent"`
-	DepartmentID         int64       `json:"DepartmentID"`
-	SettlementID         int64       `json:"SettlementID"`
-	Description          string      `json:"Description"`
+	TransactionID *TransactionID `json:"TransactionID"`
+	BusinessID    BusinessID     `json:"BusinessID"`
+	DocAliasID    int64          `json:"DocAliasId"`
+	// TransStateID and FactorTypeID are left to the defaults of Arpa when they are zero
+	TransStateID         TransState `json:"TransStateId,omitempty"`
+	FactorTypeID         FactorType `json:"FactorTypeId,omitempty"`
+	CalcTaxAndToll       int64      `json:"CalcTaxAndToll"`
+	TransDiscountAmount  Money      `json:"TransDiscountAmount"`
+	TransDiscountPercent float64    `json:"TransDiscountPercent"`
+	DepartmentID         int64      `json:"DepartmentID"`
+	SettlementID         int64      `json:"SettlementID"`
+	Description          string     `json:"Description"`
+	// ShiftID attributes the transaction to a cashier shift, see OpenShift
+	ShiftID int64 `json:"ShiftID,omitempty"`
+	// WarehouseID is the warehouse of the lines which have none, see TransactionItem.WarehouseID
+	WarehouseID int64 `json:"StockID,omitempty"`
+	// CurrencyID and CurrencyRate post the amounts of the transaction in a foreign currency,
+	// Arpa converts them with the rate. See SetCurrency.
+	CurrencyID   int64  `json:"CurrencyID,omitempty"`
+	CurrencyRate *Money `json:"CurrencyRate,omitempty"`
 }
 
-type GetCustomerResponse struct {
-	Data  []Datum2    `json:"data"`
-	Error interface{} `json:"error"`
-}
+// GetCustomerResponse is the response of the customer lookups
+type GetCustomerResponse = APIResponse[Datum2]
 
 type Datum2 struct {
-	RowNumber           string  `json:"RowNumber"`
-	BusinessID          string  `json:"BusinessID"`
-	BusinessCode        string  `json:"BusinessCode"`
-	BusinessName        string  `json:"BusinessName"`
-	Address             string  `json:"Address"`
-	PhoneNo             string  `json:"PhoneNo"`
-	FinCode             string  `json:"FinCode"`
-	Mobile              string  `json:"Mobile"`
-	Fax                 string  `json:"Fax"`
-	PriceLevelID        string  `json:"PriceLevelID"`
-	DefaultDiscount     float64 `json:"DefaultDiscount"`
-	BusinessCategoryID  string  `json:"BusinessCategoryID"`
-	AccID               string  `json:"AccID"`
-	PostalCode          string  `json:"PostalCode"`
-	GeoRegionID         string  `json:"GeoRegionID"`
-	DeliveryRegionID    string  `json:"DeliveryRegionID"`
-	DefaultSettlementID string  `json:"DefaultSettlementID"`
-	WithoutCredit       string  `json:"WithoutCredit"`
-	County              string  `json:"County"`
-	RegisterNumber      string  `json:"RegisterNumber"`
-	LatinName           string  `json:"LatinName"`
-	BusinessActivity    string  `json:"BusinessActivity"`
-	BusDescription      string  `json:"BusDescription"`
-	InActive            string  `json:"InActive"`
-	Name                string  `json:"Name"`
-	Family              string  `json:"Family"`
-	FatherName          string  `json:"FatherName"`
-	NationalCode        string  `json:"NationalCode"`
-	IDNo                string  `json:"IDNo"`
+	RowNumber           StringInt64 `json:"RowNumber"`
+	BusinessID          BusinessID  `json:"BusinessID"`
+	BusinessCode        string      `json:"BusinessCode"`
+	BusinessName        string      `json:"BusinessName"`
+	Address             string      `json:"Address"`
+	PhoneNo             string      `json:"PhoneNo"`
+	FinCode             string      `json:"FinCode"`
+	Mobile              string      `json:"Mobile"`
+	Fax                 string      `json:"Fax"`
+	PriceLevelID        StringInt64 `json:"PriceLevelID"`
+	DefaultDiscount     float64     `json:"DefaultDiscount"`
+	BusinessCategoryID  StringInt64 `json:"BusinessCategoryID"`
+	AccID               StringInt64 `json:"AccID"`
+	PostalCode          string      `json:"PostalCode"`
+	GeoRegionID         StringInt64 `json:"GeoRegionID"`
+	DeliveryRegionID    StringInt64 `json:"DeliveryRegionID"`
+	DefaultSettlementID StringInt64 `json:"DefaultSettlementID"`
+	WithoutCredit       StringBool  `json:"WithoutCredit"`
+	County              string      `json:"County"`
+	RegisterNumber      string      `json:"RegisterNumber"`
+	LatinName           string      `json:"LatinName"`
+	BusinessActivity    string      `json:"BusinessActivity"`
+	BusDescription      string      `json:"BusDescription"`
+	InActive            StringBool  `json:"InActive"`
+	Name                string      `json:"Name"`
+	Family              string      `json:"Family"`
+	FatherName          string      `json:"FatherName"`
+	NationalCode        string      `json:"NationalCode"`
+	IDNo                string      `json:"IDNo"`
 	//BirthDate           *CustomTime `json:"BirthDate"`
 	BirthPlace        string      `json:"BirthPlace"`
-	BankID            string      `json:"BankID"`
+	BankID            StringInt64 `json:"BankID"`
 	AccountType       string      `json:"AccountType"`
 	AccountNo         string      `json:"AccountNo"`
 	Sexuality         string      `json:"Sexuality"`
-	Creditable        string      `json:"Creditable"`
-	ProvinceID        string      `json:"ProvinceID"`
-	CityID            string      `json:"CityID"`
+	Creditable        StringBool  `json:"Creditable"`
+	ProvinceID        StringInt64 `json:"ProvinceID"`
+	CityID            StringInt64 `json:"CityID"`
 	TaxCityCode       string      `json:"TaxCityCode"`
 	TaxProvincesCode  string      `json:"TaxProvincesCode"`
 	PerCityCode       string      `json:"PerCityCode"`
 	Email             string      `json:"Email"`
 	WebSite           string      `json:"WebSite"`
-	RelatedUserID     string      `json:"RelatedUserID"`
-	CreatorUserID     string      `json:"Creator_UserID"`
+	RelatedUserID     StringInt64 `json:"RelatedUserID"`
+	CreatorUserID     StringInt64 `json:"Creator_UserID"`
 	CreationDate      *CustomTime `json:"Creation_Date"`
 	CardNumber        string      `json:"CardNumber"`
 	CardSerial        string      `json:"CardSerial"`
 	RepresentorCode   string      `json:"RepresentorCode"`
-	RepresentorID     string      `json:"RepresentorID"`
-	CheckCredit       float64     `json:"CheckCredit"`
-	UnCashCredit      float64     `json:"UnCashCredit"`
+	RepresentorID     StringInt64 `json:"RepresentorID"`
+	CheckCredit       Money       `json:"CheckCredit"`
+	UnCashCredit      Money       `json:"UnCashCredit"`
 	ModificationDate  *CustomTime `json:"Modification_Date"`
-	IsCustomer        string      `json:"IsCustomer"`
-	IsVendor          string      `json:"IsVendor"`
-	IsSaleManager     string      `json:"IsSaleManager"`
-	IsRepresentor     string      `json:"IsRepresentor"`
-	IsDeliveryManager string      `json:"IsDeliveryManager"`
+	IsCustomer        StringBool  `json:"IsCustomer"`
+	IsVendor          StringBool  `json:"IsVendor"`
+	IsSaleManager     StringBool  `json:"IsSaleManager"`
+	IsRepresentor     StringBool  `json:"IsRepresentor"`
+	IsDeliveryManager StringBool  `json:"IsDeliveryManager"`
 	RealOrFinancial   string      `json:"RealOrFinancial"`
+	TaxExempt         StringBool  `json:"TaxExempt"`
 }
 
-type CreateTransactionResponse struct {
-	Data  []Datum     `json:"data"`
-	Error interface{} `json:"error"`
-}
+// CreateTransactionResponse is the response of CreateTransaction
+type CreateTransactionResponse = APIResponse[Datum]
 
 type Datum struct {
-	TransactionID int64 `json:"TransactionID"`
-	TransNumber   int64 `json:"TransNumber"`
-	TransLineID   int64 `json:"TransLineID"`
-	ItemID        int64 `json:"ItemID"`
+	TransactionID TransactionID `json:"TransactionID"`
+	TransNumber   int64         `json:"TransNumber"`
+	TransLineID   int64         `json:"TransLineID"`
+	ItemID        ItemID        `json:"ItemID"`
 }
 
 type CreateServiceRequest struct {
@@ -270,70 +657,176 @@ type CreateServiceRequest struct {
 }
 
 type CreateServiceResponse struct {
-	ServiceName    string `json:"ServiceName"`
-	ItemCategoryID int64  `json:"ItemCategoryId"`
+	ServiceName    string     `json:"ServiceName"`
+	ItemCategoryID int64      `json:"ItemCategoryId"`
+	Error          *ArpaError `json:"error,omitempty"`
 }
 
+// CustomTimeLayout is the layout Arpa uses for date times
+const CustomTimeLayout = "2006-01-02 15:04:05"
+
+// customTimeLayouts are the layouts accepted when unmarshalling a CustomTime
+var customTimeLayouts = []string{
+	CustomTimeLayout,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02",
+}
+
+// CustomTimeLocation is the location of the date times without a zone returned by Arpa
+var CustomTimeLocation = ptime.Iran()
+
+// CustomTime is a time which is (un)marshalled using the Arpa date time formats
 type CustomTime struct {
 	time.Time
 }
 
+// UnmarshalJSON parses any of the known Arpa date time formats, including .NET "/Date(ticks)/" values
 func (ct *CustomTime) UnmarshalJSON(data []byte) error {
-	str := strings.Trim(string(data), `"`)
-	if str == "" {
+	// the string is unescaped, .NET escapes the slashes of its dates: "\/Date(...)\/"
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("failed to parse time: %w", err)
+	}
+	if str == "" || str == "null" {
 		return nil
 	}
 
-	t, err := time.Parse("2006-01-02 15:04:05", str)
-	if err != nil {
-		return fmt.Errorf("failed to parse time: %w", err)
+	if strings.HasPrefix(str, "/Date(") && strings.HasSuffix(str, ")/") {
+		t, err := parseDotNetDate(str)
+		if err != nil {
+			return fmt.Errorf("failed to parse time: %w", err)
+		}
+		ct.Time = t
+		return nil
 	}
 
-	ct.Time = t
-	return nil
+	var err error
+	for _, layout := range customTimeLayouts {
+		var t time.Time
+		t, err = time.ParseInLocation(layout, str, CustomTimeLocation)
+		if err == nil {
+			ct.Time = t
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to parse time: %w", err)
 }
 
-type RetServiceResponse struct {
-	Data  []GetServiceResponse `json:"data"`
-	Error interface{}          `json:"error"`
+// MarshalJSON formats the time using the Arpa date time format
+func (ct CustomTime) MarshalJSON() ([]byte, error) {
+	if ct.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(ct.In(CustomTimeLocation).Format(CustomTimeLayout))
+}
+
+// parseDotNetDate parses "/Date(1700000000000)/" and "/Date(1700000000000+0330)/" values
+func parseDotNetDate(str string) (time.Time, error) {
+	value := strings.TrimSuffix(strings.TrimPrefix(str, "/Date("), ")/")
+	// the offset is informational only, the milliseconds are always UTC based
+	if i := strings.LastIndexAny(value, "+-"); i > 0 {
+		value = value[:i]
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(ms).In(CustomTimeLocation), nil
 }
 
+// RetServiceResponse is the response of the service lookups
+type RetServiceResponse = APIResponse[GetServiceResponse]
+
+// GetServiceResponse is an item or a service returned by the item lookups and lists.
+// The numbers and the flags accept the quoted and the bare values sent by the different Arpa versions.
 type GetServiceResponse struct {
-	RowNumber              string     `json:"RowNumber"`
-	ItemID                 string     `json:"ItemID"`
-	ItemCode               string     `json:"ItemCode"`
-	ItemName               string     `json:"ItemName"`
-	SalePrice              int64      `json:"SalePrice"`
-	ConsumerPrice          string     `json:"ConsumerPrice"`
-	IAGroupID              string     `json:"IAGroupID"`
-	ConstItemName          string     `json:"ConstItemName"`
-	Factory                string     `json:"Factory"`
-	IsActive               string     `json:"IsActive"`
-	ItemLatinName          string     `json:"ItemLatinName"`
-	LimitQty               string     `json:"LimitQty"`
-	Qty                    string     `json:"Qty"`
-	ItemNote               string     `json:"ItemNote"`
-	ItemCustomFieldsDesc   string     `json:"ItemCustomFieldsDesc"`
-	LastPurchasePrice      int64      `json:"LastPurchasePrice"`
-	Serialized             string     `json:"Serialized"`
-	ItemType               string     `json:"ItemType"`
-	MainGroup              string     `json:"MainGroup"`
-	MaxSalePrice           int64      `json:"MaxSalePrice"`
-	MinSalePrice           int64      `json:"MinSalePrice"`
-	TechnicalNumber        string     `json:"TechnicalNumber"`
-	UnitsRatio             string     `json:"UnitsRatio"`
-	InverseUnitsRatio      int64      `json:"InverseUnitsRatio"`
-	MjUnitName             string     `json:"MjUnitName"`
-	MnUnitName             string     `json:"MnUnitName"`
-	DefaultStockAreaID     string     `json:"DefaultStockAreaID"`
-	ItemCategoryID         string     `json:"ItemCategoryID"`
-	DefaultDiscountPercent int64      `json:"DefaultDiscountPercent"`
-	DefaultDiscountValue   int64      `json:"DefaultDiscountValue"`
-	CreationDate           CustomTime `json:"Creation_Date"`
-	HasTaxAndToll          string     `json:"HasTaxAndToll"`
-	Geramazh               string     `json:"Geramazh"`
-	DefaultPartOfNQty1     string     `json:"DefaultPartOfNQty1"`
-	ICCategoryID           string     `json:"ICCategoryID"`
-	Weight                 int64      `json:"Weight"`
-	IsProduct              string     `json:"IsProduct"`
+	RowNumber StringInt64 `json:"RowNumber"`
+	ItemID    ItemID      `json:"ItemID"`
+	ItemCode  string      `json:"ItemCode"`
+	ItemName  string      `json:"ItemName"`
+	// SalePrice is the price of the first price level, see PriceForLevel
+	SalePrice     Money       `json:"SalePrice"`
+	SalePrice2    Money       `json:"SalePrice2"`
+	SalePrice3    Money       `json:"SalePrice3"`
+	SalePrice4    Money       `json:"SalePrice4"`
+	SalePrice5    Money       `json:"SalePrice5"`
+	ConsumerPrice Money       `json:"ConsumerPrice"`
+	IAGroupID     StringInt64 `json:"IAGroupID"`
+	ConstItemName string      `json:"ConstItemName"`
+	Factory       string      `json:"Factory"`
+	// IsActive is empty when Arpa does not send it, the item is active then, see Active
+	IsActive      EnforcedString `json:"IsActive"`
+	ItemLatinName string         `json:"ItemLatinName"`
+	LimitQty      EnforcedFloat  `json:"LimitQty"`
+	// Qty is the quantity in stock, empty when the stock of the item is not tracked, see Stock
+	Qty                  EnforcedString `json:"Qty"`
+	ItemNote             string         `json:"ItemNote"`
+	ItemCustomFieldsDesc string         `json:"ItemCustomFieldsDesc"`
+	LastPurchasePrice    Money          `json:"LastPurchasePrice"`
+	Serialized           StringBool     `json:"Serialized"`
+	ItemType             EnforcedString `json:"ItemType"`
+	MainGroup            EnforcedString `json:"MainGroup"`
+	MaxSalePrice         Money          `json:"MaxSalePrice"`
+	MinSalePrice         Money          `json:"MinSalePrice"`
+	TechnicalNumber      string         `json:"TechnicalNumber"`
+	// UnitsRatio converts a quantity of the main unit, MjUnitName, to the secondary unit, MnUnitName,
+	// and InverseUnitsRatio converts it back
+	UnitsRatio        EnforcedFloat `json:"UnitsRatio"`
+	InverseUnitsRatio EnforcedFloat `json:"InverseUnitsRatio"`
+	MjUnitName        string        `json:"MjUnitName"`
+	MnUnitName        string        `json:"MnUnitName"`
+	// DefaultStockAreaID is the warehouse the item is taken from by default
+	DefaultStockAreaID     StringInt64    `json:"DefaultStockAreaID"`
+	ItemCategoryID         StringInt64    `json:"ItemCategoryID"`
+	DefaultDiscountPercent EnforcedFloat  `json:"DefaultDiscountPercent"`
+	DefaultDiscountValue   Money          `json:"DefaultDiscountValue"`
+	CreationDate           CustomTime     `json:"Creation_Date"`
+	ModificationDate       *CustomTime    `json:"Modification_Date"`
+	HasTaxAndToll          StringBool     `json:"HasTaxAndToll"`
+	Geramazh               EnforcedString `json:"Geramazh"`
+	DefaultPartOfNQty1     EnforcedFloat  `json:"DefaultPartOfNQty1"`
+	ICCategoryID           StringInt64    `json:"ICCategoryID"`
+	Weight                 EnforcedFloat  `json:"Weight"`
+	IsProduct              StringBool     `json:"IsProduct"`
+}
+
+// Active reports whether the item can be sold, the items whose IsActive is empty are active
+func (i GetServiceResponse) Active() bool {
+	switch strings.ToLower(strings.TrimSpace(string(i.IsActive))) {
+	case "0", "false":
+		return false
+	}
+	return true
+}
+
+// Stock returns the quantity in stock of the item and false if its stock is not tracked
+func (i GetServiceResponse) Stock() (float64, bool) {
+	if strings.TrimSpace(string(i.Qty)) == "" {
+		return 0, false
+	}
+	qty, err := strconv.ParseFloat(strings.TrimSpace(string(i.Qty)), 64)
+	return qty, err == nil
+}
+
+// PriceForLevel returns the sale price of the price level of a business, see Datum2.PriceLevelID.
+// The first level, the levels without a price and the unknown levels have the SalePrice.
+func (i GetServiceResponse) PriceForLevel(level int64) Money {
+	var price Money
+	switch level {
+	case 2:
+		price = i.SalePrice2
+	case 3:
+		price = i.SalePrice3
+	case 4:
+		price = i.SalePrice4
+	case 5:
+		price = i.SalePrice5
+	}
+	if price.IsZero() {
+		return i.SalePrice
+	}
+	return price
 }
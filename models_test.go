@@ -0,0 +1,215 @@
+package goarpa_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CustomTimeUnmarshal(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		input    string
+		expected time.Time
+	}{
+		{`"2024-03-20 10:30:00"`, time.Date(2024, 3, 20, 10, 30, 0, 0, goarpa.CustomTimeLocation)},
+		{`"2024-03-20T10:30:00"`, time.Date(2024, 3, 20, 10, 30, 0, 0, goarpa.CustomTimeLocation)},
+		{`"2024-03-20"`, time.Date(2024, 3, 20, 0, 0, 0, 0, goarpa.CustomTimeLocation)},
+		{`"/Date(1710916200000)/"`, time.UnixMilli(1710916200000)},
+		{`"/Date(1710916200000+0330)/"`, time.UnixMilli(1710916200000)},
+		{`"\/Date(1700000000000+0330)\/"`, time.UnixMilli(1700000000000)},
+		{`""`, time.Time{}},
+		{`null`, time.Time{}},
+	}
+	for _, testCase := range testCases {
+		var ct goarpa.CustomTime
+		require.NoError(t, json.Unmarshal([]byte(testCase.input), &ct), testCase.input)
+		assert.True(t, testCase.expected.Equal(ct.Time), testCase.input)
+	}
+
+	var ct goarpa.CustomTime
+	assert.Error(t, json.Unmarshal([]byte(`"20/03/2024"`), &ct))
+}
+
+func Test_CustomTimeRoundTrip(t *testing.T) {
+	t.Parallel()
+	input := []byte(`"2024-03-20 10:30:00"`)
+	var ct goarpa.CustomTime
+	require.NoError(t, json.Unmarshal(input, &ct))
+
+	output, err := json.Marshal(ct)
+	require.NoError(t, err)
+	assert.Equal(t, string(input), string(output))
+
+	output, err = json.Marshal(goarpa.CustomTime{})
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(output))
+}
+
+func Test_StringInt64AndStringBool(t *testing.T) {
+	t.Parallel()
+	var datum goarpa.Datum2
+	err := json.Unmarshal([]byte(`{"BusinessID":"127013","ProvinceID":8,"CityID":"","InActive":"0","IsCustomer":"True","IsVendor":1}`), &datum)
+	require.NoError(t, err)
+	assert.Equal(t, int64(127013), datum.BusinessID.Int64())
+	assert.Equal(t, int64(8), datum.ProvinceID.Int64())
+	assert.Equal(t, int64(0), datum.CityID.Int64())
+	assert.False(t, datum.InActive.Bool())
+	assert.True(t, datum.IsCustomer.Bool())
+	assert.True(t, datum.IsVendor.Bool())
+
+	var id goarpa.StringInt64
+	assert.Error(t, json.Unmarshal([]byte(`"12a"`), &id))
+	var flag goarpa.StringBool
+	assert.Error(t, json.Unmarshal([]byte(`"yes"`), &flag))
+}
+
+func Test_MoneyJSON(t *testing.T) {
+	t.Parallel()
+	var addSub goarpa.AddSub
+	require.NoError(t, json.Unmarshal([]byte(`{"AddSubID":1,"TASAmount":"1250000.35"}`), &addSub))
+	assert.Equal(t, "1250000.35", addSub.TASAmount.String())
+
+	addSub.TASAmount = goarpa.NewMoney(1000000).Add(goarpa.NewMoney(1000000).Percent(9))
+	b, err := json.Marshal(addSub)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"AddSubID":1,"TASAmount":1090000}`, string(b))
+}
+
+type queryFilter struct {
+	From time.Time `json:"From,omitempty"`
+	To   time.Time `json:"To,omitempty"`
+}
+
+type queryPaging struct {
+	Page     int `json:"Page,string,omitempty"`
+	PageSize int `json:"PageSize,omitempty"`
+}
+
+type queryParams struct {
+	queryPaging
+	Name     string            `json:"Name,omitempty"`
+	Mobile   *string           `json:"Mobile,omitempty"`
+	Active   *bool             `json:"Active,omitempty"`
+	Discount float64           `json:"Discount"`
+	IDs      []int64           `json:"IDs,omitempty"`
+	Filter   queryFilter       `json:"Filter"`
+	Extra    map[string]string `json:"Extra,omitempty"`
+	Ignored  string            `json:"-"`
+}
+
+func Test_GetQueryParams(t *testing.T) {
+	t.Parallel()
+	params, err := goarpa.GetQueryParams(queryParams{
+		queryPaging: queryPaging{Page: 2, PageSize: 50},
+		Name:        "Ali",
+		Active:      goarpa.BoolP(false),
+		IDs:         []int64{1, 2, 3},
+		Filter:      queryFilter{From: time.Date(2024, 3, 20, 0, 0, 0, 0, goarpa.CustomTimeLocation)},
+		Extra:       map[string]string{"Key": "Value"},
+		Ignored:     "ignored",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Page":        "2",
+		"PageSize":    "50",
+		"Name":        "Ali",
+		"Active":      "false",
+		"Discount":    "0",
+		"IDs":         "1,2,3",
+		"Filter.From": "2024-03-20 00:00:00",
+		"Extra[Key]":  "Value",
+	}, params)
+
+	_, err = goarpa.GetQueryParams([]string{"a"})
+	assert.Error(t, err)
+}
+
+type benchmarkQueryParams struct {
+	BusinessCode string `json:"BusinessCode,omitempty"`
+	Mobile       string `json:"MobileNo,omitempty"`
+	Page         int    `json:"Page,string,omitempty"`
+	PageSize     int    `json:"PageSize,string,omitempty"`
+	Active       bool   `json:"Active,string,omitempty"`
+}
+
+var benchmarkParams = benchmarkQueryParams{BusinessCode: "127013", Mobile: "09121234567", Page: 3, PageSize: 100, Active: true}
+
+func Benchmark_GetQueryParams(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := goarpa.GetQueryParams(benchmarkParams); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark_GetQueryParamsJSON measures the previous marshal/unmarshal implementation for comparison
+func Benchmark_GetQueryParamsJSON(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := json.Marshal(benchmarkParams)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var res map[string]string
+		if err := json.Unmarshal(data, &res); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Test_EnforcedNumbers(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{`"123"`, `123`, `123.0`, `"123.0"`} {
+		var i goarpa.EnforcedInt
+		require.NoError(t, json.Unmarshal([]byte(input), &i), input)
+		assert.Equal(t, goarpa.EnforcedInt(123), i, input)
+	}
+	var i goarpa.EnforcedInt
+	assert.Error(t, json.Unmarshal([]byte(`123.5`), &i))
+
+	for _, input := range []string{`"12.5"`, `12.5`} {
+		var f goarpa.EnforcedFloat
+		require.NoError(t, json.Unmarshal([]byte(input), &f), input)
+		assert.Equal(t, goarpa.EnforcedFloat(12.5), f, input)
+	}
+}
+
+func Test_GetServiceResponse(t *testing.T) {
+	t.Parallel()
+	var item goarpa.GetServiceResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"RowNumber": 1, "ItemID": "7", "SalePrice": "1000", "SalePrice3": 900, "IAGroupID": 4,
+		"IsActive": 1, "Qty": 12.5, "LimitQty": "2", "Serialized": true, "UnitsRatio": "12",
+		"InverseUnitsRatio": "0.5", "DefaultStockAreaID": "3", "ItemCategoryID": "", "Weight": "1.5", "IsProduct": "1"
+	}`), &item))
+	assert.Equal(t, goarpa.ItemID(7), item.ItemID)
+	assert.Equal(t, goarpa.StringInt64(4), item.IAGroupID)
+	assert.True(t, item.Active())
+	assert.True(t, item.Serialized.Bool())
+	assert.True(t, item.IsProduct.Bool())
+	assert.Equal(t, goarpa.EnforcedFloat(12), item.UnitsRatio)
+	assert.Equal(t, goarpa.EnforcedFloat(0.5), item.InverseUnitsRatio)
+	assert.Equal(t, goarpa.StringInt64(3), item.DefaultStockAreaID)
+	assert.Equal(t, goarpa.EnforcedFloat(1.5), item.Weight)
+
+	qty, ok := item.Stock()
+	assert.True(t, ok)
+	assert.Equal(t, 12.5, qty)
+	assert.Equal(t, "900", item.PriceForLevel(3).String())
+	assert.Equal(t, "1000", item.PriceForLevel(2).String())
+	assert.Equal(t, "1000", item.PriceForLevel(0).String())
+
+	// the items which do not send the flags are active and their stock is not tracked
+	item = goarpa.GetServiceResponse{}
+	assert.True(t, item.Active())
+	_, ok = item.Stock()
+	assert.False(t, ok)
+	item.IsActive = "False"
+	assert.False(t, item.Active())
+}
@@ -0,0 +1,79 @@
+package goarpa
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Money is an exact decimal amount, e.g. a rial amount with tax fractions.
+// It is marshalled as a bare JSON number and unmarshalled from a quoted or a bare number.
+type Money struct {
+	decimal.Decimal
+}
+
+// NewMoney returns an amount of whole units
+func NewMoney(value int64) Money {
+	return Money{decimal.NewFromInt(value)}
+}
+
+// NewMoneyFromFloat returns an amount from a float value
+func NewMoneyFromFloat(value float64) Money {
+	return Money{decimal.NewFromFloat(value)}
+}
+
+// ParseMoney parses an amount from its string representation
+func ParseMoney(value string) (Money, error) {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %w", value, err)
+	}
+	return Money{d}, nil
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) Money {
+	return Money{m.Decimal.Add(other.Decimal)}
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) Money {
+	return Money{m.Decimal.Sub(other.Decimal)}
+}
+
+// Percent returns the given percent of the amount, e.g. m.Percent(9) for 9% tax
+func (m Money) Percent(percent float64) Money {
+	return Money{m.Decimal.Mul(decimal.NewFromFloat(percent)).Div(decimal.NewFromInt(100))}
+}
+
+// Times returns the amount multiplied by a quantity
+func (m Money) Times(qty float64) Money {
+	return Money{m.Decimal.Mul(decimal.NewFromFloat(qty))}
+}
+
+// Round rounds the amount half away from zero to the given decimal places
+func (m Money) Round(places int32) Money {
+	return Money{m.Decimal.Round(places)}
+}
+
+// MarshalJSON marshals the amount as a bare JSON number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal.String()), nil
+}
+
+// UnmarshalJSON accepts "123.45", 123.45 and "" (as zero)
+func (m *Money) UnmarshalJSON(data []byte) error {
+	str := strings.TrimSpace(strings.Trim(string(data), `"`))
+	if str == "" || str == "null" {
+		*m = Money{}
+		return nil
+	}
+
+	money, err := ParseMoney(str)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
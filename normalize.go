@@ -0,0 +1,134 @@
+package goarpa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithPhoneNormalization makes the client normalize mobile and phone numbers
+// of the customer create and lookup methods, since Arpa only matches exact formats
+func WithPhoneNormalization() func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.normalizePhones = true
+	}
+}
+
+// WithTextNormalization makes the client normalize the Persian text of the lookups and of the created customers
+// with NormalizeText, since Arpa searches by exact match and the Arabic and Persian forms of letters differ
+func WithTextNormalization() func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.normalizeText = true
+	}
+}
+
+// NormalizeDigits replaces the Persian and Arabic digits with latin digits
+func NormalizeDigits(value string) string {
+	return latinDigits(value)
+}
+
+// NormalizeText converts Persian text to the canonical form stored by Arpa:
+// the Arabic yeh and kaf are replaced with the Persian ones, the digits are converted to latin digits,
+// the diacritics and the tatweel are removed and the runs of spaces are collapsed.
+func NormalizeText(value string) string {
+	text := strings.Map(func(r rune) rune {
+		switch {
+		case r == 'ي' || r == 'ى':
+			return 'ی'
+		case r == 'ك':
+			return 'ک'
+		case r == 'ة':
+			return 'ه'
+		case r == 'ـ' || r >= '\u064B' && r <= '\u0652':
+			return -1
+		}
+		return r
+	}, latinDigits(value))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// NormalizeMobile converts a mobile number to the 09XXXXXXXXX format.
+// Persian and Arabic digits are converted, separators are removed and +98/0098 prefixes are replaced.
+func NormalizeMobile(mobile string) (string, error) {
+	number := normalizeNumber(mobile)
+	if len(number) == 10 && strings.HasPrefix(number, "9") {
+		number = "0" + number
+	}
+
+	if len(number) != 11 || !strings.HasPrefix(number, "09") || !isDigits(number) {
+		return "", fmt.Errorf("invalid mobile number %q", mobile)
+	}
+	return number, nil
+}
+
+// NormalizePhone converts a phone number to its digits only format.
+// Persian and Arabic digits are converted, separators are removed and +98/0098 prefixes are replaced.
+func NormalizePhone(phone string) (string, error) {
+	number := normalizeNumber(phone)
+	if len(number) < 8 || len(number) > 11 || !isDigits(number) {
+		return "", fmt.Errorf("invalid phone number %q", phone)
+	}
+	return number, nil
+}
+
+func normalizeNumber(value string) string {
+	number := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			return -1
+		}
+		return r
+	}, latinDigits(strings.TrimSpace(value)))
+
+	switch {
+	case strings.HasPrefix(number, "+98"):
+		number = "0" + strings.TrimPrefix(number, "+98")
+	case strings.HasPrefix(number, "0098"):
+		number = "0" + strings.TrimPrefix(number, "0098")
+	}
+	return number
+}
+
+func normalizeCustomerPhones(customer *CreateCustomerRequest) error {
+	if !NilOrEmpty(customer.Mobile) {
+		mobile, err := NormalizeMobile(*customer.Mobile)
+		if err != nil {
+			return err
+		}
+		customer.Mobile = &mobile
+	}
+	if !NilOrEmpty(customer.PhoneNo) {
+		phone, err := NormalizePhone(*customer.PhoneNo)
+		if err != nil {
+			return err
+		}
+		customer.PhoneNo = &phone
+	}
+	return nil
+}
+
+// latinDigits replaces the Persian and Arabic digits with latin digits
+func latinDigits(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '۰' && r <= '۹':
+			return '0' + (r - '۰')
+		case r >= '٠' && r <= '٩':
+			return '0' + (r - '٠')
+		}
+		return r
+	}, value)
+}
+
+func normalizeCustomerText(customer *CreateCustomerRequest) {
+	customer.BusName = NormalizeText(customer.BusName)
+	// the fields are replaced, not modified, since they are shared with the caller
+	for _, field := range []**string{&customer.Name, &customer.Family, &customer.Address} {
+		if *field != nil {
+			*field = StringP(NormalizeText(**field))
+		}
+	}
+	if customer.NationalCode != nil {
+		nationalCode := NationalCode(NormalizeDigits(string(*customer.NationalCode)))
+		customer.NationalCode = &nationalCode
+	}
+}
@@ -0,0 +1,377 @@
+// Package openapi generates an OpenAPI 3 document of the Arpa endpoints and models this package knows about,
+// so that other teams can build against the same contract and the contract can be diffed across releases.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/shared/constant"
+)
+
+// Document is an OpenAPI 3 document, limited to what the generator emits
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem are the operations of a path keyed by their lower cased method
+type PathItem map[string]*Operation
+
+// Operation is an operation of a path
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Security    []map[string][]any  `json:"security,omitempty"`
+}
+
+// Parameter is a query parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the JSON body of an operation
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a content
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components are the reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme is an authentication scheme
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// Schema is a JSON schema
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// endpoint is an endpoint of the client
+type endpoint struct {
+	operationID string
+	summary     string
+	method      string
+	path        string
+	query       any
+	params      []string
+	body        any
+	response    any
+}
+
+// Generate returns the document of the endpoints configured on the client.
+// The endpoints which have no default and are not configured are left out.
+func Generate(client *goarpa.GoArpa, version string) *Document {
+	g := &generator{schemas: make(map[string]*Schema)}
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: "Arpa", Version: version},
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas:         g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{"bearer": {Type: "http", Scheme: "bearer"}},
+		},
+	}
+
+	config := client.Config
+	endpoints := []endpoint{
+		{"GetServiceToken", "Get an access token", http.MethodGet, config.GetServiceTokenEndpoint, nil, []string{"username", "password"}, nil, ""},
+		{"CreateCustomer", "Create a business", http.MethodPost, config.CreateCustomerEndpoint, nil, nil, goarpa.CreateCustomerRequest{}, goarpa.RetCustomerResponse{}},
+		{"UpdateCustomer", "Update fields of a business", http.MethodPost, config.UpdateCustomerEndpoint, nil, nil, goarpa.CustomerChanges{}, goarpa.RetCustomerResponse{}},
+		{"CreateTransaction", "Create a transaction", http.MethodPost, config.CreateTransactionEndpoint, nil, nil, goarpa.CreateTransactionRequest{}, goarpa.CreateTransactionResponse{}},
+		{"FinalizeTransaction", "Post a draft transaction to the ledger", http.MethodPost, config.FinalizeTransactionEndpoint, nil, nil, goarpa.FinalizeTransactionRequest{}, goarpa.CreateTransactionResponse{}},
+		{"CreateService", "Create a service", http.MethodPost, config.CreateServiceEndpoint, nil, nil, goarpa.CreateServiceRequest{}, goarpa.CreateServiceResponse{}},
+		{"GetCustomer", "Get a business by mobile, business code, ID or national code", http.MethodGet, config.GetCustomerEndpoint, nil, []string{string(constant.MobileKey), string(constant.BusinessCodeKey), string(constant.BusinessIDQueryKey), string(constant.NationalCodeKey)}, nil, goarpa.GetCustomerResponse{}},
+		{"GetCustomerBalance", "Get the balance of a business", http.MethodGet, config.GetCustomerBalanceEndpoint, nil, []string{string(constant.BusinessIDQueryKey)}, nil, goarpa.APIResponse[goarpa.CustomerBalance]{}},
+		{"GetCustomerAttributes", "Get the extended attributes of a business", http.MethodGet, config.GetCustomerAttributesEndpoint, nil, []string{string(constant.BusinessIDQueryKey)}, nil, goarpa.APIResponse[goarpa.CustomerAttribute]{}},
+		{"SetCustomerAttributes", "Write extended attributes of a business", http.MethodPost, config.SetCustomerAttributesEndpoint, nil, nil, goarpa.SetCustomerAttributesRequest{}, goarpa.APIResponse[goarpa.CustomerAttribute]{}},
+		{"GetCustomerTransactionsAging", "Get the receivable of the businesses by age", http.MethodGet, config.GetCustomerAgingEndpoint, goarpa.AgingParams{}, nil, nil, goarpa.CustomerAgingResponse{}},
+		{"GetRepresentorSales", "Get the sales of the representors in a period", http.MethodGet, config.GetRepresentorSalesEndpoint, goarpa.RepresentorSalesParams{}, nil, nil, goarpa.RepresentorSalesResponse{}},
+		{"GetProvinces", "List the provinces", http.MethodGet, config.GetProvincesEndpoint, nil, nil, nil, goarpa.ProvincesResponse{}},
+		{"GetCities", "List the cities", http.MethodGet, config.GetCitiesEndpoint, goarpa.GetCitiesParams{}, nil, nil, goarpa.CitiesResponse{}},
+		{"GetCustomers", "List the businesses", http.MethodGet, config.GetCustomersEndpoint, goarpa.GetCustomersParams{}, nil, nil, goarpa.GetCustomerResponse{}},
+		{"GetItem", "Get an item by code", http.MethodGet, config.GetItemEndpoint, nil, []string{string(constant.ItemCodeKey)}, nil, goarpa.RetServiceResponse{}},
+		{"GetItems", "List the items", http.MethodGet, config.GetItemsEndpoint, goarpa.GetItemsParams{}, nil, nil, goarpa.RetServiceResponse{}},
+		{"GetItemStock", "Get the stock of an item in a warehouse", http.MethodGet, config.GetItemStockEndpoint, goarpa.ItemStockParams{}, nil, nil, goarpa.ItemStockResponse{}},
+		{"GetItemAvailability", "Get the stock of an item by warehouse", http.MethodGet, config.GetItemAvailabilityEndpoint, goarpa.ItemStockParams{}, nil, nil, goarpa.ItemStockResponse{}},
+		{"GetItemPriceHistory", "Get the price changes of an item", http.MethodGet, config.GetItemPriceHistoryEndpoint, goarpa.ItemPriceHistoryParams{}, nil, nil, goarpa.ItemPriceHistoryResponse{}},
+		{"ReserveStock", "Hold a quantity of an item", http.MethodPost, config.ReserveStockEndpoint, nil, nil, goarpa.ReserveStockRequest{}, goarpa.StockReservationResponse{}},
+		{"ReleaseReservation", "Release a stock reservation", http.MethodPost, config.ReleaseReservationEndpoint, nil, nil, goarpa.ReleaseReservationRequest{}, goarpa.StockReservationResponse{}},
+		{"GetServerInfo", "Get the version and the enabled modules of the server", http.MethodGet, config.GetServerInfoEndpoint, nil, nil, nil, goarpa.ServerInfoResponse{}},
+		{"GetReceipts", "List the receipts of the treasury", http.MethodGet, config.GetReceiptsEndpoint, goarpa.GetCashMovementsParams{}, nil, nil, goarpa.GetCashMovementsResponse{}},
+		{"GetPayments", "List the payments of the treasury", http.MethodGet, config.GetPaymentsEndpoint, goarpa.GetCashMovementsParams{}, nil, nil, goarpa.GetCashMovementsResponse{}},
+		{"GetTransactions", "List the transactions", http.MethodGet, config.GetTransactionsEndpoint, goarpa.GetTransactionsParams{}, nil, nil, goarpa.GetTransactionsResponse{}},
+	}
+
+	for _, e := range endpoints {
+		if e.path == "" {
+			continue
+		}
+		operation := &Operation{
+			OperationID: e.operationID,
+			Summary:     e.summary,
+			Responses:   map[string]Response{},
+		}
+		if e.operationID != "GetServiceToken" {
+			operation.Security = []map[string][]any{{"bearer": {}}}
+		}
+		for _, name := range e.params {
+			operation.Parameters = append(operation.Parameters, Parameter{Name: name, In: "query", Schema: &Schema{Type: "string"}})
+		}
+		if e.query != nil {
+			operation.Parameters = append(operation.Parameters, g.queryParameters(reflect.TypeOf(e.query), "")...)
+		}
+		if e.body != nil {
+			operation.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				"application/json": {Schema: g.schema(reflect.TypeOf(e.body))},
+			}}
+		}
+		if e.response == "" {
+			operation.Responses["200"] = Response{Description: "The access token", Content: map[string]MediaType{
+				"text/plain": {Schema: &Schema{Type: "string"}},
+			}}
+		} else {
+			operation.Responses["200"] = Response{Description: "OK", Content: map[string]MediaType{
+				"application/json": {Schema: g.schema(reflect.TypeOf(e.response))},
+			}}
+		}
+
+		path := "/" + strings.TrimPrefix(e.path, "/")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(e.method)] = operation
+	}
+	return doc
+}
+
+// MarshalIndent returns the indented JSON of the document
+func (d *Document) MarshalIndent() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+type generator struct {
+	schemas map[string]*Schema
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	customTimeType      = reflect.TypeOf(goarpa.CustomTime{})
+	transactionItemType = reflect.TypeOf(goarpa.TransactionItem{})
+	arpaErrorType       = reflect.TypeOf(goarpa.ArpaError{})
+	objectOrArrayPrefix = "ObjectOrArray["
+)
+
+// schema returns the schema of the type, the structs being registered as components
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		schema := *g.schema(t.Elem())
+		if schema.Ref != "" {
+			return &Schema{OneOf: []*Schema{&schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return &schema
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case customTimeType:
+		return &Schema{Type: "string", Description: "date time formatted as " + goarpa.CustomTimeLayout, Nullable: true}
+	case reflect.TypeOf(goarpa.Money{}), reflect.TypeOf(goarpa.EnforcedFloat(0)):
+		return &Schema{Type: "number"}
+	case reflect.TypeOf(goarpa.BusinessID(0)), reflect.TypeOf(goarpa.ItemID(0)), reflect.TypeOf(goarpa.TransactionID(0)),
+		reflect.TypeOf(goarpa.EnforcedInt(0)):
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.TypeOf(goarpa.StringInt64(0)):
+		return &Schema{Type: "string", Pattern: "^[0-9]*$"}
+	case reflect.TypeOf(goarpa.StringBool(false)):
+		return &Schema{Type: "string", Enum: []any{"0", "1"}}
+	case reflect.TypeOf(goarpa.EnforcedString("")), reflect.TypeOf(goarpa.NumericCode("")):
+		return &Schema{Type: "string"}
+	case reflect.TypeOf(goarpa.NationalCode("")):
+		return &Schema{Type: "string", Pattern: "^[0-9]{10}$"}
+	case reflect.TypeOf(goarpa.Sexuality(0)):
+		return &Schema{Type: "string", Enum: []any{"1", "2"}}
+	case reflect.TypeOf(goarpa.RealOrFinancial(0)), reflect.TypeOf(goarpa.TransState(0)):
+		return &Schema{Type: "integer", Enum: []any{1, 2}}
+	case reflect.TypeOf(goarpa.FactorType(0)):
+		return &Schema{Type: "integer", Enum: []any{1, 2, 3, 4}}
+	case reflect.TypeOf(goarpa.StringOrArray{}):
+		return &Schema{OneOf: []*Schema{{Type: "string"}, {Type: "array", Items: &Schema{Type: "string"}}}}
+	case reflect.TypeOf(goarpa.CustomerChanges{}):
+		return &Schema{Type: "object", AdditionalProperties: &Schema{}}
+	case transactionItemType:
+		return g.component("TransactionItem", func() *Schema {
+			return &Schema{Type: "object", Properties: map[string]*Schema{
+				string(constant.ItemIDKey):          {Type: "integer", Format: "int64"},
+				string(constant.QtyKey):             {Type: "number"},
+				string(constant.PriceKey):           {Type: "number"},
+				string(constant.DiscountAmountKey):  {Type: "number"},
+				string(constant.DiscountPercentKey): {Type: "number"},
+				string(constant.CalcTaxAndTollKey):  {Type: "integer", Enum: []any{0, 1}},
+				string(constant.FreeQtyKey):         {Type: "number"},
+			}, AdditionalProperties: &Schema{Type: "integer", Nullable: true}}
+		})
+	case arpaErrorType:
+		object := g.component("ArpaError", func() *Schema { return g.object(arpaErrorType) })
+		return &Schema{OneOf: []*Schema{{Type: "string"}, object}}
+	}
+
+	if t.Kind() == reflect.Slice && strings.HasPrefix(t.Name(), objectOrArrayPrefix) {
+		item := g.schema(t.Elem())
+		return &Schema{OneOf: []*Schema{item, {Type: "array", Items: item}}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.component(componentName(t), func() *Schema { return g.object(t) })
+	}
+	return &Schema{}
+}
+
+// component registers the schema under the name and returns a reference to it
+func (g *generator) component(name string, build func() *Schema) *Schema {
+	if _, ok := g.schemas[name]; !ok {
+		// registered first to stop the recursion of recursive types
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *build()
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// object returns the object schema of a struct, the embedded structs being flattened
+func (g *generator) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			for key, property := range g.object(field.Type).Properties {
+				schema.Properties[key] = property
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		schema.Properties[name] = g.schema(field.Type)
+	}
+	return schema
+}
+
+// queryParameters returns the query parameters of a params struct, as flattened by goarpa.GetQueryParams
+func (g *generator) queryParameters(t reflect.Type, prefix string) []Parameter {
+	var parameters []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && fieldType.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			parameters = append(parameters, g.queryParameters(fieldType, prefix)...)
+			continue
+		}
+
+		schema := g.schema(fieldType)
+		if fieldType == timeType {
+			schema = &Schema{Type: "string", Description: "date time formatted as " + goarpa.CustomTimeLayout}
+		}
+		parameters = append(parameters, Parameter{Name: prefix + name, In: "query", Schema: schema})
+	}
+	sort.SliceStable(parameters, func(i, j int) bool { return parameters[i].Name < parameters[j].Name })
+	return parameters
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+// componentName returns the name of a struct, the generic instances being named after their type arguments
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	base, args, ok := strings.Cut(name, "[")
+	if !ok {
+		return name
+	}
+	args = strings.TrimSuffix(args, "]")
+	if i := strings.LastIndex(args, "."); i >= 0 {
+		args = args[i+1:]
+	}
+	return base + "_" + args
+}
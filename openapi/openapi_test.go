@@ -0,0 +1,44 @@
+package openapi_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update testdata/arpa.openapi.json")
+
+// Test_Generate compares the document of the default client with testdata/arpa.openapi.json,
+// so that the changes of the contract show up in the diffs
+func Test_Generate(t *testing.T) {
+	t.Parallel()
+	client := goarpa.NewClient("")
+	client.Config.GetCustomersEndpoint = "serv/api/GetBusinesses"
+
+	doc := openapi.Generate(client, "test")
+	require.Contains(t, doc.Paths, "/serv/api/GetBusinesses")
+	require.Contains(t, doc.Paths["/serv/api/PostBusiness"], "post")
+	assert.Contains(t, doc.Components.Schemas, "CreateCustomerRequest")
+	assert.Contains(t, doc.Components.Schemas, "APIResponse_Datum2")
+	assert.NotContains(t, doc.Paths, "/")
+
+	data, err := doc.MarshalIndent()
+	require.NoError(t, err)
+	data = append(data, '\n')
+
+	golden := filepath.Join("testdata", "arpa.openapi.json")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+		require.NoError(t, os.WriteFile(golden, data, 0o644))
+		return
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err, "missing document, run the test with -update")
+	assert.Equal(t, string(expected), string(data))
+}
@@ -0,0 +1,78 @@
+package goarpa
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Locale is a language which Arpa uses for messages and labels
+type Locale string
+
+const (
+	// LocaleFa requests Persian messages and labels
+	LocaleFa Locale = "fa"
+	// LocaleEn requests English messages and labels
+	LocaleEn Locale = "en"
+)
+
+// Culture returns the .NET culture name of the locale, e.g. fa-IR
+func (l Locale) Culture() string {
+	switch l {
+	case LocaleFa:
+		return "fa-IR"
+	case LocaleEn:
+		return "en-US"
+	default:
+		return string(l)
+	}
+}
+
+// WithLocale sets the Accept-Language header of all requests and their culture parameter, constant.CultureKey,
+// which Arpa reads its language from. The parameter can be renamed with WithQueryKeyNames.
+func WithLocale(locale Locale) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.locale = locale
+	}
+}
+
+const (
+	// BranchHeader is the header WithBranch sends the branch in
+	BranchHeader = "X-Branch-ID"
+	// FiscalPeriodHeader is the header WithFiscalPeriod sends the fiscal period in
+	FiscalPeriodHeader = "X-Fiscal-Period-ID"
+)
+
+// WithRequestDecorator calls decorate with every request of the client when it is created, e.g. to set a header
+// an installation requires. The decorators are called in the order of the options, before the methods set
+// the authorization, the body and the params of their requests.
+func WithRequestDecorator(decorate func(*resty.Request)) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.requestDecorators = append(g.requestDecorators, decorate)
+	}
+}
+
+// WithBranch sends the branch in the BranchHeader of all requests, for the installations with several branches.
+// WithCallBranch overrides it for the calls made with a context.
+func WithBranch(branchID int64) func(*GoArpa) {
+	return WithRequestDecorator(func(req *resty.Request) {
+		req.SetHeader(BranchHeader, strconv.FormatInt(branchID, 10))
+	})
+}
+
+// WithFiscalPeriod sends the fiscal period in the FiscalPeriodHeader of all requests,
+// for the installations which do not default to the current one
+func WithFiscalPeriod(fiscalPeriodID int64) func(*GoArpa) {
+	return WithRequestDecorator(func(req *resty.Request) {
+		req.SetHeader(FiscalPeriodHeader, strconv.FormatInt(fiscalPeriodID, 10))
+	})
+}
+
+var callBranchContextKey = &contextKey{"callBranch"}
+
+// WithCallBranch returns a context whose calls are made for the branch, whatever the branch of the client,
+// e.g. to post the transactions of a store or to query its stock. The branches are listed by GetBranches.
+func WithCallBranch(ctx context.Context, branchID int64) context.Context {
+	return context.WithValue(ctx, callBranchContextKey, branchID)
+}
@@ -0,0 +1,546 @@
+package goarpa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OutboxStatus is the status of a transaction of the outbox
+type OutboxStatus string
+
+const (
+	// OutboxPending is a transaction waiting to be submitted
+	OutboxPending OutboxStatus = "pending"
+	// OutboxSubmitting is a transaction being submitted. An entry left in this status after a crash
+	// may or may not have been posted and is never retried automatically.
+	OutboxSubmitting OutboxStatus = "submitting"
+	// OutboxSubmitted is a transaction posted to Arpa
+	OutboxSubmitted OutboxStatus = "submitted"
+	// OutboxFailed is a transaction rejected by Arpa or out of attempts
+	OutboxFailed OutboxStatus = "failed"
+	// OutboxUnknown is a transaction whose request may have reached Arpa without an answer.
+	// It has to be reconciled before being retried, to never post it twice.
+	OutboxUnknown OutboxStatus = "unknown"
+)
+
+// ErrOutboxEntryNotFound is returned by the outbox stores when an entry does not exist
+var ErrOutboxEntryNotFound = errors.New("outbox entry not found")
+
+// ErrOutboxEntryNotPending is returned by OutboxStore.Claim when the entry is not pending anymore
+var ErrOutboxEntryNotPending = errors.New("outbox entry not pending")
+
+// ErrTransactionNotSubmitted is matched by the error of a TransactionResult whose transaction was failed or became unknown
+var ErrTransactionNotSubmitted = errors.New("transaction not submitted")
+
+// maxAsyncRetryInterval caps the backoff of CreateTransactionAsync
+const maxAsyncRetryInterval = time.Minute
+
+// OutboxEntry is a transaction of the outbox with its submission state
+type OutboxEntry struct {
+	// ID is the idempotency key of the transaction, e.g. the ID of the order
+	ID            string                   `json:"id"`
+	Transaction   CreateTransactionRequest `json:"transaction"`
+	Status        OutboxStatus             `json:"status"`
+	Attempts      int                      `json:"attempts"`
+	LastError     string                   `json:"lastError,omitempty"`
+	TransactionID TransactionID            `json:"transactionId,omitempty"`
+	TransNumber   int64                    `json:"transNumber,omitempty"`
+	CreatedAt     time.Time                `json:"createdAt"`
+	UpdatedAt     time.Time                `json:"updatedAt"`
+}
+
+// OutboxStore persists the outbox entries
+type OutboxStore interface {
+	// Get returns the entry with the given ID or ErrOutboxEntryNotFound
+	Get(ctx context.Context, id string) (*OutboxEntry, error)
+	// Save inserts or updates the entry
+	Save(ctx context.Context, entry OutboxEntry) error
+	// Insert atomically inserts the entry unless an entry with its ID exists, and returns the stored entry.
+	// An existing entry is never overwritten, so that an entry being submitted is never reset to pending.
+	Insert(ctx context.Context, entry OutboxEntry) (*OutboxEntry, error)
+	// Pending returns up to limit pending entries, oldest first
+	Pending(ctx context.Context, limit int) ([]OutboxEntry, error)
+	// Claim atomically moves the pending entry to OutboxSubmitting, counting an attempt, and returns it.
+	// It returns ErrOutboxEntryNotPending when the entry is not pending, e.g. when another process claimed it,
+	// so that two processes sharing the store never post the same entry.
+	Claim(ctx context.Context, id string, now time.Time) (*OutboxEntry, error)
+}
+
+// TokenSource returns a valid access token
+type TokenSource func(ctx context.Context) (string, error)
+
+// TransactionSubmitterOptions configure a TransactionSubmitter
+type TransactionSubmitterOptions struct {
+	// MaxAttempts is the number of attempts before an entry is failed, 10 by default
+	MaxAttempts int
+	// BatchSize is the number of pending entries submitted per run, 50 by default
+	BatchSize int
+	// OnSubmitted is called after an entry has been submitted, failed or became unknown
+	OnSubmitted func(entry OutboxEntry)
+	// OnError is called when a run of Run fails, e.g. when the token cannot be obtained while Arpa is down.
+	// Run keeps submitting at the next interval.
+	OnError func(err error)
+	// RetryInterval is the first interval between the attempts of CreateTransactionAsync,
+	// doubling up to a minute, 1 second by default
+	RetryInterval time.Duration
+}
+
+// TransactionResult is the outcome of CreateTransactionAsync
+type TransactionResult struct {
+	// Entry is the outbox entry of the transaction, with the TransactionID and TransNumber once submitted
+	Entry OutboxEntry
+	// Err is nil when the transaction was submitted. It matches ErrTransactionNotSubmitted
+	// when the transaction was failed or became unknown, otherwise it is the error of the store.
+	Err error
+}
+
+// TransactionSubmitter is a durable outbox of transactions: the transactions are persisted first
+// and submitted with retries, so that invoices created while Arpa is down are never lost.
+// The entries are keyed by an idempotency key so that a transaction is never enqueued, nor posted, twice.
+type TransactionSubmitter struct {
+	client  *GoArpa
+	store   OutboxStore
+	token   TokenSource
+	options TransactionSubmitterOptions
+	mu      sync.Mutex
+	// inflight are the IDs of the entries being submitted, so that Run and CreateTransactionAsync never post one twice
+	inflight map[string]bool
+}
+
+// NewTransactionSubmitter returns a submitter posting the transactions of the store with the client
+func NewTransactionSubmitter(client *GoArpa, store OutboxStore, token TokenSource, options TransactionSubmitterOptions) *TransactionSubmitter {
+	if options.MaxAttempts < 1 {
+		options.MaxAttempts = 10
+	}
+	if options.BatchSize < 1 {
+		options.BatchSize = 50
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = time.Second
+	}
+	return &TransactionSubmitter{client: client, store: store, token: token, options: options, inflight: make(map[string]bool)}
+}
+
+// Enqueue persists the transaction under the idempotency key.
+// If the key is already known, the existing entry is returned and nothing is enqueued.
+func (s *TransactionSubmitter) Enqueue(ctx context.Context, id string, transaction CreateTransactionRequest) (*OutboxEntry, error) {
+	now := s.client.Clock().Now()
+	return s.store.Insert(ctx, OutboxEntry{
+		ID:          id,
+		Transaction: transaction,
+		Status:      OutboxPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+// SubmitPending submits a batch of pending entries and returns the number of submitted ones
+func (s *TransactionSubmitter) SubmitPending(ctx context.Context) (int, error) {
+	entries, err := s.store.Pending(ctx, s.options.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	submitted := 0
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return submitted, err
+		}
+		if !s.claim(entry.ID) {
+			continue
+		}
+		entry, err := s.submitClaimed(ctx, entry.ID)
+		s.release(entry.ID)
+		if err != nil {
+			return submitted, err
+		}
+		if entry.Status == OutboxSubmitted {
+			submitted++
+		}
+	}
+	return submitted, nil
+}
+
+// Run submits the pending entries every interval until the context is done.
+// The failed runs are reported to OnError and do not stop the submitter, it returns the error of the context.
+func (s *TransactionSubmitter) Run(ctx context.Context, interval time.Duration) error {
+	for {
+		if _, err := s.SubmitPending(ctx); err != nil && ctx.Err() == nil && s.options.OnError != nil {
+			s.options.OnError(err)
+		}
+		timer := s.client.Clock().NewTimer(interval)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// CreateTransactionAsync enqueues the transaction under the idempotency key and submits it in the background,
+// so that a web handler can answer without waiting for the posting latency of Arpa.
+// The channel receives one result, once the transaction was submitted, failed or became unknown, and is closed.
+//
+// The submission keeps the values of ctx but not its cancellation, so that it outlives the handler.
+// A retryable failure is attempted again after RetryInterval until MaxAttempts, and a transaction
+// already known under the key is not posted again: its current outcome is awaited instead.
+func (s *TransactionSubmitter) CreateTransactionAsync(ctx context.Context, id string, transaction CreateTransactionRequest) <-chan TransactionResult {
+	results := make(chan TransactionResult, 1)
+	go func() {
+		defer close(results)
+		entry, err := s.createTransaction(context.WithoutCancel(ctx), id, transaction)
+		results <- TransactionResult{Entry: entry, Err: err}
+	}()
+	return results
+}
+
+func (s *TransactionSubmitter) createTransaction(ctx context.Context, id string, transaction CreateTransactionRequest) (OutboxEntry, error) {
+	if _, err := s.Enqueue(ctx, id, transaction); err != nil {
+		return OutboxEntry{ID: id}, err
+	}
+
+	interval := s.options.RetryInterval
+	for {
+		var entry OutboxEntry
+		if s.claim(id) {
+			var err error
+			entry, err = s.submitClaimed(ctx, id)
+			s.release(id)
+			if err != nil {
+				return entry, err
+			}
+			if entry.Status == OutboxSubmitting {
+				// left by a crashed process, it has to be reconciled
+				return entry, fmt.Errorf("%w: %s", ErrTransactionNotSubmitted, entry.Status)
+			}
+		} else {
+			// another submission of the entry is in flight, its outcome is awaited
+			current, err := s.store.Get(ctx, id)
+			if err != nil {
+				return OutboxEntry{ID: id}, err
+			}
+			entry = *current
+		}
+
+		switch entry.Status {
+		case OutboxSubmitted:
+			return entry, nil
+		case OutboxFailed, OutboxUnknown:
+			return entry, fmt.Errorf("%w: %s: %s", ErrTransactionNotSubmitted, entry.Status, entry.LastError)
+		}
+
+		timer := s.client.Clock().NewTimer(interval)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return entry, retryCanceledError(ctx, fmt.Errorf("%w: %s", ErrTransactionNotSubmitted, entry.Status), 0)
+		}
+		interval = min(2*interval, maxAsyncRetryInterval)
+	}
+}
+
+// claim marks the entry as in flight and returns false if it already is
+func (s *TransactionSubmitter) claim(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inflight[id] {
+		return false
+	}
+	s.inflight[id] = true
+	return true
+}
+
+func (s *TransactionSubmitter) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inflight, id)
+}
+
+// submitClaimed submits the claimed entry if it is still pending, it may have been submitted since it was listed
+func (s *TransactionSubmitter) submitClaimed(ctx context.Context, id string) (OutboxEntry, error) {
+	entry, err := s.store.Get(ctx, id)
+	if err != nil {
+		return OutboxEntry{ID: id}, err
+	}
+	if entry.Status != OutboxPending {
+		return *entry, nil
+	}
+
+	token, err := s.token(ctx)
+	if err != nil {
+		return *entry, err
+	}
+
+	// the entry is claimed in the store too, another process may be submitting it
+	claimed, err := s.store.Claim(ctx, id, s.client.Clock().Now())
+	if errors.Is(err, ErrOutboxEntryNotPending) {
+		if entry, err = s.store.Get(ctx, id); err != nil {
+			return OutboxEntry{ID: id}, err
+		}
+		return *entry, nil
+	}
+	if err != nil {
+		return *entry, err
+	}
+	return s.submit(ctx, token, *claimed)
+}
+
+// submit posts the entry claimed in the store and saves its outcome
+func (s *TransactionSubmitter) submit(ctx context.Context, token string, entry OutboxEntry) (OutboxEntry, error) {
+	response, err := s.client.CreateTransaction(ctx, token, entry.Transaction)
+	entry.UpdatedAt = s.client.Clock().Now()
+	switch {
+	case err == nil:
+		entry.Status = OutboxSubmitted
+		entry.LastError = ""
+		if datum, ok := response.First(); ok {
+			entry.TransactionID = datum.TransactionID
+			entry.TransNumber = datum.TransNumber
+		}
+	case !requestWasNotSent(err) && !hasResponse(err):
+		entry.Status = OutboxUnknown
+		entry.LastError = err.Error()
+	case IsRetryableError(err) && entry.Attempts < s.options.MaxAttempts:
+		entry.Status = OutboxPending
+		entry.LastError = err.Error()
+	default:
+		entry.Status = OutboxFailed
+		entry.LastError = err.Error()
+	}
+
+	if err := s.store.Save(context.WithoutCancel(ctx), entry); err != nil {
+		return entry, err
+	}
+	if entry.Status != OutboxPending && s.options.OnSubmitted != nil {
+		s.options.OnSubmitted(entry)
+	}
+	return entry, nil
+}
+
+// hasResponse returns true if Arpa answered the request
+func hasResponse(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code != 0
+}
+
+// requestWasNotSent returns true for the errors which happen before the request reaches Arpa
+func requestWasNotSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// MemoryOutboxStore is an OutboxStore kept in memory, for tests and short lived processes
+type MemoryOutboxStore struct {
+	mu      sync.Mutex
+	entries map[string]OutboxEntry
+}
+
+// NewMemoryOutboxStore returns an empty in memory store
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{entries: make(map[string]OutboxEntry)}
+}
+
+// Get returns the entry with the given ID or ErrOutboxEntryNotFound
+func (m *MemoryOutboxStore) Get(_ context.Context, id string) (*OutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[id]
+	if !ok {
+		return nil, ErrOutboxEntryNotFound
+	}
+	return &entry, nil
+}
+
+// Save inserts or updates the entry
+func (m *MemoryOutboxStore) Save(_ context.Context, entry OutboxEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[entry.ID] = entry
+	return nil
+}
+
+// Insert inserts the entry unless an entry with its ID exists, see OutboxStore.Insert
+func (m *MemoryOutboxStore) Insert(_ context.Context, entry OutboxEntry) (*OutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.entries[entry.ID]; ok {
+		return &existing, nil
+	}
+	m.entries[entry.ID] = entry
+	return &entry, nil
+}
+
+// Pending returns up to limit pending entries, oldest first
+func (m *MemoryOutboxStore) Pending(_ context.Context, limit int) ([]OutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return pendingEntries(m.entries, limit), nil
+}
+
+// Claim atomically moves the pending entry to OutboxSubmitting, see OutboxStore.Claim
+func (m *MemoryOutboxStore) Claim(_ context.Context, id string, now time.Time) (*OutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, err := claimEntry(m.entries, id, now)
+	if err != nil {
+		return nil, err
+	}
+	m.entries[id] = entry
+	return &entry, nil
+}
+
+// FileOutboxStore is an OutboxStore persisted in a JSON file, which is atomically replaced on every change
+type FileOutboxStore struct {
+	path   string
+	memory *MemoryOutboxStore
+}
+
+// NewFileOutboxStore returns a store persisted at path, loading the existing entries
+func NewFileOutboxStore(path string) (*FileOutboxStore, error) {
+	store := &FileOutboxStore{path: path, memory: NewMemoryOutboxStore()}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.memory.entries); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Get returns the entry with the given ID or ErrOutboxEntryNotFound
+func (f *FileOutboxStore) Get(ctx context.Context, id string) (*OutboxEntry, error) {
+	return f.memory.Get(ctx, id)
+}
+
+// Save inserts or updates the entry and persists the file
+func (f *FileOutboxStore) Save(_ context.Context, entry OutboxEntry) error {
+	f.memory.mu.Lock()
+	defer f.memory.mu.Unlock()
+	return f.save(entry)
+}
+
+// Insert inserts the entry unless an entry with its ID exists and persists the file, see OutboxStore.Insert
+func (f *FileOutboxStore) Insert(_ context.Context, entry OutboxEntry) (*OutboxEntry, error) {
+	f.memory.mu.Lock()
+	defer f.memory.mu.Unlock()
+	if existing, ok := f.memory.entries[entry.ID]; ok {
+		return &existing, nil
+	}
+	if err := f.save(entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Pending returns up to limit pending entries, oldest first
+func (f *FileOutboxStore) Pending(ctx context.Context, limit int) ([]OutboxEntry, error) {
+	return f.memory.Pending(ctx, limit)
+}
+
+// Claim atomically moves the pending entry to OutboxSubmitting and persists the file, see OutboxStore.Claim.
+// The file is not locked, the processes sharing it are not guarded against each other.
+func (f *FileOutboxStore) Claim(_ context.Context, id string, now time.Time) (*OutboxEntry, error) {
+	f.memory.mu.Lock()
+	defer f.memory.mu.Unlock()
+	entry, err := claimEntry(f.memory.entries, id, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.save(entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// save updates the entry in memory and persists the file, restoring the previous entry when it fails.
+// The lock of the memory store is held.
+func (f *FileOutboxStore) save(entry OutboxEntry) error {
+	previous, existed := f.memory.entries[entry.ID]
+	f.memory.entries[entry.ID] = entry
+	if err := writeFileAtomic(f.path, f.memory.entries); err != nil {
+		if existed {
+			f.memory.entries[entry.ID] = previous
+		} else {
+			delete(f.memory.entries, entry.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// claimEntry returns the pending entry moved to OutboxSubmitting with one more attempt
+func claimEntry(entries map[string]OutboxEntry, id string, now time.Time) (OutboxEntry, error) {
+	entry, ok := entries[id]
+	if !ok {
+		return entry, ErrOutboxEntryNotFound
+	}
+	if entry.Status != OutboxPending {
+		return entry, ErrOutboxEntryNotPending
+	}
+	entry.Status = OutboxSubmitting
+	entry.Attempts++
+	entry.UpdatedAt = now
+	return entry, nil
+}
+
+func pendingEntries(entries map[string]OutboxEntry, limit int) []OutboxEntry {
+	pending := make([]OutboxEntry, 0)
+	for _, entry := range entries {
+		if entry.Status == OutboxPending {
+			pending = append(pending, entry)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+	if limit > 0 && len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending
+}
+
+// writeFileAtomic writes the value as JSON into a temporary file which then replaces path
+func writeFileAtomic(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,151 @@
+package goarpa
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLOutboxStore is an OutboxStore persisted in a SQL table through database/sql.
+// The queries use $N placeholders and ON CONFLICT, which are understood by both PostgreSQL and SQLite.
+type SQLOutboxStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLOutboxStore returns a store using the given table, which is created if it does not exist
+func NewSQLOutboxStore(ctx context.Context, db *sql.DB, table string) (*SQLOutboxStore, error) {
+	store := &SQLOutboxStore{db: db, table: table}
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id TEXT PRIMARY KEY,
+	transaction_data TEXT NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL,
+	last_error TEXT NOT NULL,
+	transaction_id BIGINT NOT NULL,
+	trans_number BIGINT NOT NULL,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+)`, table))
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Get returns the entry with the given ID or ErrOutboxEntryNotFound
+func (s *SQLOutboxStore) Get(ctx context.Context, id string) (*OutboxEntry, error) {
+	row := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT id, transaction_data, status, attempts, last_error,
+	transaction_id, trans_number, created_at, updated_at FROM %s WHERE id = $1`, s.table), id)
+	entry, err := scanOutboxEntry(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOutboxEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Save inserts or updates the entry
+func (s *SQLOutboxStore) Save(ctx context.Context, entry OutboxEntry) error {
+	transaction, err := json.Marshal(entry.Transaction)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (id, transaction_data, status, attempts, last_error,
+	transaction_id, trans_number, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (id) DO UPDATE SET transaction_data = excluded.transaction_data, status = excluded.status,
+	attempts = excluded.attempts, last_error = excluded.last_error, transaction_id = excluded.transaction_id,
+	trans_number = excluded.trans_number, updated_at = excluded.updated_at`, s.table),
+		entry.ID, string(transaction), string(entry.Status), entry.Attempts, entry.LastError,
+		entry.TransactionID.Int64(), entry.TransNumber, entry.CreatedAt.UnixNano(), entry.UpdatedAt.UnixNano())
+	return err
+}
+
+// Insert inserts the entry unless a row with its ID exists and returns the stored row, see OutboxStore.Insert
+func (s *SQLOutboxStore) Insert(ctx context.Context, entry OutboxEntry) (*OutboxEntry, error) {
+	transaction, err := json.Marshal(entry.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (id, transaction_data, status, attempts, last_error,
+	transaction_id, trans_number, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (id) DO NOTHING`, s.table),
+		entry.ID, string(transaction), string(entry.Status), entry.Attempts, entry.LastError,
+		entry.TransactionID.Int64(), entry.TransNumber, entry.CreatedAt.UnixNano(), entry.UpdatedAt.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, entry.ID)
+}
+
+// Pending returns up to limit pending entries, oldest first
+func (s *SQLOutboxStore) Pending(ctx context.Context, limit int) ([]OutboxEntry, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, transaction_data, status, attempts, last_error,
+	transaction_id, trans_number, created_at, updated_at FROM %s WHERE status = $1 ORDER BY created_at LIMIT $2`, s.table),
+		string(OutboxPending), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []OutboxEntry
+	for rows.Next() {
+		entry, err := scanOutboxEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Claim atomically moves the pending entry to OutboxSubmitting with a conditional update, see OutboxStore.Claim
+func (s *SQLOutboxStore) Claim(ctx context.Context, id string, now time.Time) (*OutboxEntry, error) {
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET status = $1, attempts = attempts + 1, updated_at = $2
+WHERE id = $3 AND status = $4`, s.table),
+		string(OutboxSubmitting), now.UnixNano(), id, string(OutboxPending))
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if claimed == 0 {
+		return nil, ErrOutboxEntryNotPending
+	}
+	return entry, nil
+}
+
+func scanOutboxEntry(row interface{ Scan(dest ...any) error }) (OutboxEntry, error) {
+	var (
+		entry                OutboxEntry
+		transaction          string
+		status               string
+		transactionID        int64
+		createdAt, updatedAt int64
+	)
+	err := row.Scan(&entry.ID, &transaction, &status, &entry.Attempts, &entry.LastError,
+		&transactionID, &entry.TransNumber, &createdAt, &updatedAt)
+	if err != nil {
+		return entry, err
+	}
+	if err := json.Unmarshal([]byte(transaction), &entry.Transaction); err != nil {
+		return entry, err
+	}
+	entry.Status = OutboxStatus(status)
+	entry.TransactionID = TransactionID(transactionID)
+	entry.CreatedAt = time.Unix(0, createdAt)
+	entry.UpdatedAt = time.Unix(0, updatedAt)
+	return entry, nil
+}
@@ -0,0 +1,121 @@
+package goarpa_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// newSQLiteDB returns a SQLite database in a temporary file
+func newSQLiteDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "goarpa.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func Test_SQLOutboxStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := goarpa.NewSQLOutboxStore(ctx, newSQLiteDB(t), "outbox")
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "order-1")
+	assert.ErrorIs(t, err, goarpa.ErrOutboxEntryNotFound)
+
+	created := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	transaction := goarpa.CreateTransactionRequest{Data: goarpa.Data{BusinessID: 42, FactorTypeID: goarpa.FactorTypeSale}}
+	for i, id := range []string{"order-2", "order-1", "order-3"} {
+		require.NoError(t, store.Save(ctx, goarpa.OutboxEntry{
+			ID:          id,
+			Transaction: transaction,
+			Status:      goarpa.OutboxPending,
+			CreatedAt:   created.Add(time.Duration(i) * time.Minute),
+			UpdatedAt:   created,
+		}))
+	}
+
+	entry, err := store.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.BusinessID(42), entry.Transaction.Data.BusinessID)
+	assert.Equal(t, goarpa.FactorTypeSale, entry.Transaction.Data.FactorTypeID)
+	assert.True(t, created.Add(time.Minute).Equal(entry.CreatedAt))
+
+	// the pending entries are listed oldest first
+	pending, err := store.Pending(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "order-2", pending[0].ID)
+	assert.Equal(t, "order-1", pending[1].ID)
+
+	// Save updates the entry
+	entry.Status = goarpa.OutboxSubmitted
+	entry.TransactionID = 7
+	entry.TransNumber = 9
+	require.NoError(t, store.Save(ctx, *entry))
+	entry, err = store.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxSubmitted, entry.Status)
+	assert.Equal(t, goarpa.TransactionID(7), entry.TransactionID)
+	assert.Equal(t, int64(9), entry.TransNumber)
+
+	pending, err = store.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+}
+
+func Test_SQLOutboxStoreClaim(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	store, err := goarpa.NewSQLOutboxStore(ctx, db, "outbox")
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	_, err = store.Claim(ctx, "order-1", now)
+	assert.ErrorIs(t, err, goarpa.ErrOutboxEntryNotFound)
+
+	require.NoError(t, store.Save(ctx, goarpa.OutboxEntry{ID: "order-1", Status: goarpa.OutboxPending, CreatedAt: now, UpdatedAt: now}))
+	entry, err := store.Claim(ctx, "order-1", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxSubmitting, entry.Status)
+	assert.Equal(t, 1, entry.Attempts)
+	assert.True(t, now.Add(time.Minute).Equal(entry.UpdatedAt))
+
+	// another process sharing the table cannot claim the entry again
+	other, err := goarpa.NewSQLOutboxStore(ctx, db, "outbox")
+	require.NoError(t, err)
+	_, err = other.Claim(ctx, "order-1", now)
+	assert.ErrorIs(t, err, goarpa.ErrOutboxEntryNotPending)
+	entry, err = store.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, entry.Attempts)
+}
+
+func Test_SQLOutboxStoreInsert(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := goarpa.NewSQLOutboxStore(ctx, newSQLiteDB(t), "outbox")
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	entry, err := store.Insert(ctx, goarpa.OutboxEntry{ID: "order-1", Status: goarpa.OutboxPending, CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxPending, entry.Status)
+	_, err = store.Claim(ctx, "order-1", now)
+	require.NoError(t, err)
+
+	// a late enqueue of the same ID never resets the entry being submitted
+	entry, err = store.Insert(ctx, goarpa.OutboxEntry{ID: "order-1", Status: goarpa.OutboxPending, CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxSubmitting, entry.Status)
+	assert.Equal(t, 1, entry.Attempts)
+}
@@ -0,0 +1,354 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TransactionSubmitter(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Data":[{"TransactionID":"42","TransNumber":7}]}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	store, err := goarpa.NewFileOutboxStore(path)
+	require.NoError(t, err)
+	submitter := goarpa.NewTransactionSubmitter(client, store, func(ctx context.Context) (string, error) {
+		return "token", nil
+	}, goarpa.TransactionSubmitterOptions{})
+
+	ctx := context.Background()
+	transaction := goarpa.CreateTransactionRequest{Data: goarpa.Data{
+		TransStateID: goarpa.TransStateDraft,
+		FactorTypeID: goarpa.FactorTypeSale,
+	}}
+	_, err = submitter.Enqueue(ctx, "order-1", transaction)
+	require.NoError(t, err)
+	_, err = submitter.Enqueue(ctx, "order-1", transaction)
+	require.NoError(t, err)
+
+	// the first attempt fails with a retryable error and the entry stays pending
+	submitted, err := submitter.SubmitPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, submitted)
+
+	submitted, err = submitter.SubmitPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, submitted)
+
+	submitted, err = submitter.SubmitPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, submitted)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// the entries are read back from the file
+	reloaded, err := goarpa.NewFileOutboxStore(path)
+	require.NoError(t, err)
+	entry, err := reloaded.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxSubmitted, entry.Status)
+	assert.Equal(t, 2, entry.Attempts)
+	assert.Equal(t, goarpa.TransactionID(42), entry.TransactionID)
+	assert.Equal(t, int64(7), entry.TransNumber)
+}
+
+func Test_TransactionSubmitterUnknownOutcome(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the connection is dropped after the request has been received
+		hj, ok := w.(http.Hijacker)
+		require.True(t, ok)
+		conn, _, err := hj.Hijack()
+		require.NoError(t, err)
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	store := goarpa.NewMemoryOutboxStore()
+	submitter := goarpa.NewTransactionSubmitter(client, store, func(ctx context.Context) (string, error) {
+		return "token", nil
+	}, goarpa.TransactionSubmitterOptions{})
+
+	ctx := context.Background()
+	transaction := goarpa.CreateTransactionRequest{Data: goarpa.Data{
+		TransStateID: goarpa.TransStateDraft,
+		FactorTypeID: goarpa.FactorTypeSale,
+	}}
+	_, err := submitter.Enqueue(ctx, "order-1", transaction)
+	require.NoError(t, err)
+	_, err = submitter.SubmitPending(ctx)
+	require.NoError(t, err)
+
+	entry, err := store.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxUnknown, entry.Status)
+	assert.NotEmpty(t, entry.LastError)
+}
+
+func Test_CreateTransactionAsync(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Data":[{"TransactionID":"42","TransNumber":7}]}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	store := goarpa.NewMemoryOutboxStore()
+	submitter := goarpa.NewTransactionSubmitter(client, store, func(ctx context.Context) (string, error) {
+		return "token", nil
+	}, goarpa.TransactionSubmitterOptions{RetryInterval: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transaction := goarpa.CreateTransactionRequest{Data: goarpa.Data{
+		TransStateID: goarpa.TransStateDraft,
+		FactorTypeID: goarpa.FactorTypeSale,
+	}}
+	results := submitter.CreateTransactionAsync(ctx, "order-1", transaction)
+	// the submission outlives the context of the handler
+	cancel()
+
+	result := <-results
+	require.NoError(t, result.Err)
+	assert.Equal(t, goarpa.OutboxSubmitted, result.Entry.Status)
+	assert.Equal(t, goarpa.TransactionID(42), result.Entry.TransactionID)
+	assert.Equal(t, 2, result.Entry.Attempts)
+	_, open := <-results
+	assert.False(t, open)
+
+	// a known transaction is not posted again
+	result = <-submitter.CreateTransactionAsync(context.Background(), "order-1", transaction)
+	require.NoError(t, result.Err)
+	assert.Equal(t, int64(7), result.Entry.TransNumber)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func Test_CreateTransactionAsyncFailed(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	submitter := goarpa.NewTransactionSubmitter(client, goarpa.NewMemoryOutboxStore(), func(ctx context.Context) (string, error) {
+		return "token", nil
+	}, goarpa.TransactionSubmitterOptions{})
+
+	result := <-submitter.CreateTransactionAsync(context.Background(), "order-1", goarpa.CreateTransactionRequest{Data: goarpa.Data{
+		TransStateID: goarpa.TransStateDraft,
+		FactorTypeID: goarpa.FactorTypeSale,
+	}})
+	assert.True(t, errors.Is(result.Err, goarpa.ErrTransactionNotSubmitted))
+	assert.Equal(t, goarpa.OutboxFailed, result.Entry.Status)
+}
+
+func Test_TransactionSubmitterRun(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Data":[{"TransactionID":"42","TransNumber":7}]}`))
+	}))
+	defer server.Close()
+
+	clock := goarpa.NewManualClock(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+	client := goarpa.NewClient(server.URL, goarpa.WithClock(clock))
+	store := goarpa.NewMemoryOutboxStore()
+	submitter := goarpa.NewTransactionSubmitter(client, store, func(ctx context.Context) (string, error) {
+		return "token", nil
+	}, goarpa.TransactionSubmitterOptions{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := submitter.Enqueue(ctx, "order-1", goarpa.CreateTransactionRequest{Data: goarpa.Data{
+		TransStateID: goarpa.TransStateDraft,
+		FactorTypeID: goarpa.FactorTypeSale,
+	}})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- submitter.Run(ctx, time.Minute) }()
+
+	// the first submission fails, the next one waits for the interval
+	require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+	clock.Advance(time.Minute)
+	require.Eventually(t, func() bool { return calls.Load() == 2 && clock.Timers() == 1 }, time.Second, time.Millisecond)
+
+	entry, err := store.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxSubmitted, entry.Status)
+	assert.Equal(t, clock.Now(), entry.UpdatedAt)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func Test_TransactionSubmittersSharingStore(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Data":[{"TransactionID":"42","TransNumber":7}]}`))
+	}))
+	defer server.Close()
+
+	// the submitters of two processes share the store
+	store := goarpa.NewMemoryOutboxStore()
+	token := func(ctx context.Context) (string, error) {
+		return "token", nil
+	}
+	submitters := []*goarpa.TransactionSubmitter{
+		goarpa.NewTransactionSubmitter(goarpa.NewClient(server.URL), store, token, goarpa.TransactionSubmitterOptions{}),
+		goarpa.NewTransactionSubmitter(goarpa.NewClient(server.URL), store, token, goarpa.TransactionSubmitterOptions{}),
+	}
+
+	ctx := context.Background()
+	_, err := submitters[0].Enqueue(ctx, "order-1", goarpa.CreateTransactionRequest{Data: goarpa.Data{
+		TransStateID: goarpa.TransStateDraft,
+		FactorTypeID: goarpa.FactorTypeSale,
+	}})
+	require.NoError(t, err)
+
+	var (
+		wg        sync.WaitGroup
+		submitted atomic.Int32
+	)
+	for _, submitter := range submitters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := submitter.SubmitPending(ctx)
+			assert.NoError(t, err)
+			submitted.Add(int32(n))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, int32(1), submitted.Load())
+	entry, err := store.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxSubmitted, entry.Status)
+	assert.Equal(t, 1, entry.Attempts)
+}
+
+func Test_OutboxStoreClaim(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	fileStore, err := goarpa.NewFileOutboxStore(filepath.Join(t.TempDir(), "outbox.json"))
+	require.NoError(t, err)
+
+	for name, store := range map[string]goarpa.OutboxStore{"memory": goarpa.NewMemoryOutboxStore(), "file": fileStore} {
+		_, err := store.Claim(ctx, "order-1", now)
+		assert.ErrorIs(t, err, goarpa.ErrOutboxEntryNotFound, name)
+
+		require.NoError(t, store.Save(ctx, goarpa.OutboxEntry{ID: "order-1", Status: goarpa.OutboxPending}))
+		entry, err := store.Claim(ctx, "order-1", now)
+		require.NoError(t, err, name)
+		assert.Equal(t, goarpa.OutboxSubmitting, entry.Status, name)
+		assert.Equal(t, 1, entry.Attempts, name)
+		assert.Equal(t, now, entry.UpdatedAt, name)
+
+		// the entry is claimed once
+		_, err = store.Claim(ctx, "order-1", now)
+		assert.ErrorIs(t, err, goarpa.ErrOutboxEntryNotPending, name)
+		pending, err := store.Pending(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, pending, name)
+	}
+}
+
+func Test_TransactionSubmitterRunReportsErrors(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Data":[{"TransactionID":"42","TransNumber":7}]}`))
+	}))
+	defer server.Close()
+
+	clock := goarpa.NewManualClock(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+	client := goarpa.NewClient(server.URL, goarpa.WithClock(clock))
+	store := goarpa.NewMemoryOutboxStore()
+	tokenErr := errors.New("arpa is down")
+	var tokens atomic.Int32
+	errs := make(chan error, 1)
+	submitter := goarpa.NewTransactionSubmitter(client, store, func(ctx context.Context) (string, error) {
+		if tokens.Add(1) == 1 {
+			return "", tokenErr
+		}
+		return "token", nil
+	}, goarpa.TransactionSubmitterOptions{OnError: func(err error) { errs <- err }})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := submitter.Enqueue(ctx, "order-1", goarpa.CreateTransactionRequest{Data: goarpa.Data{FactorTypeID: goarpa.FactorTypeSale}})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- submitter.Run(ctx, time.Minute) }()
+
+	// the token failure is reported and the submitter keeps running
+	assert.ErrorIs(t, <-errs, tokenErr)
+	require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(time.Minute)
+	require.Eventually(t, func() bool {
+		entry, err := store.Get(ctx, "order-1")
+		return err == nil && entry.Status == goarpa.OutboxSubmitted
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func Test_TransactionSubmitterEnqueueKeepsClaimedEntry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := goarpa.NewMemoryOutboxStore()
+	submitter := goarpa.NewTransactionSubmitter(goarpa.NewClient("http://localhost"), store, func(ctx context.Context) (string, error) {
+		return "token", nil
+	}, goarpa.TransactionSubmitterOptions{})
+
+	_, err := submitter.Enqueue(ctx, "order-1", goarpa.CreateTransactionRequest{})
+	require.NoError(t, err)
+	_, err = store.Claim(ctx, "order-1", time.Now())
+	require.NoError(t, err)
+
+	// another process enqueueing the same ID does not reset the entry to pending
+	entry, err := submitter.Enqueue(ctx, "order-1", goarpa.CreateTransactionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.OutboxSubmitting, entry.Status)
+}
@@ -0,0 +1,249 @@
+// Package poller watches Arpa for new and changed customers and transactions.
+// Arpa has no webhooks, so the list endpoints are polled with a modification date watermark
+// and the changes are emitted as typed events, or posted to an HTTP endpoint by a Webhook.
+package poller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+)
+
+// Event is a CustomerEvent or a TransactionEvent
+type Event interface {
+	// ModifiedAt returns the modification date of the record
+	ModifiedAt() time.Time
+	key() string
+}
+
+// CustomerEvent is emitted when a business is created or changed
+type CustomerEvent struct {
+	Customer goarpa.Customer
+	// Created is true if the business has not been modified since its creation
+	Created bool
+}
+
+// ModifiedAt returns the modification date of the business
+func (e CustomerEvent) ModifiedAt() time.Time {
+	return e.Customer.ModifiedAt
+}
+
+func (e CustomerEvent) key() string {
+	return fmt.Sprintf("customer:%d", e.Customer.ID)
+}
+
+// TransactionEvent is emitted when a transaction is created or changed.
+// Arpa does not return the creation date of the transactions, so both are reported alike.
+type TransactionEvent struct {
+	Transaction goarpa.Transaction
+}
+
+// ModifiedAt returns the modification date of the transaction
+func (e TransactionEvent) ModifiedAt() time.Time {
+	if e.Transaction.ModificationDate == nil {
+		return time.Time{}
+	}
+	return e.Transaction.ModificationDate.Time
+}
+
+func (e TransactionEvent) key() string {
+	return fmt.Sprintf("transaction:%d", e.Transaction.TransactionID)
+}
+
+// Options configure a Poller
+type Options struct {
+	// Interval is the time between two polls, one minute by default
+	Interval time.Duration
+	// MaxBackoff is the longest wait after consecutive failed polls, ten times the interval by default
+	MaxBackoff time.Duration
+	// Overlap is subtracted from the watermark when polling, to catch the records committed late.
+	// The records seen twice are deduplicated. One minute by default.
+	Overlap time.Duration
+	// Since is the initial watermark, the start of the poller by default. The watermarks recorded in the client
+	// by a previous poller, e.g. imported with goarpa.GoArpa.ImportState, take precedence.
+	Since time.Time
+	// Customers and Transactions select what is watched, both when none is set
+	Customers    bool
+	Transactions bool
+	// Handler receives the events. When it is nil, the events are sent to the Events channel.
+	Handler func(ctx context.Context, event Event) error
+	// OnError is called when a poll fails
+	OnError func(err error)
+	// Buffer is the capacity of the Events channel
+	Buffer int
+}
+
+// Poller polls Arpa for changes
+type Poller struct {
+	client    *goarpa.GoArpa
+	token     goarpa.TokenSource
+	options   Options
+	events    chan Event
+	seen      map[string]time.Time
+	watermark map[string]time.Time
+}
+
+// New returns a poller watching Arpa with the client
+func New(client *goarpa.GoArpa, token goarpa.TokenSource, options Options) *Poller {
+	if options.Interval <= 0 {
+		options.Interval = time.Minute
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 10 * options.Interval
+	}
+	if options.Overlap <= 0 {
+		options.Overlap = time.Minute
+	}
+	if options.Since.IsZero() {
+		options.Since = client.Clock().Now()
+	}
+	if !options.Customers && !options.Transactions {
+		options.Customers = true
+		options.Transactions = true
+	}
+
+	p := &Poller{
+		client:  client,
+		token:   token,
+		options: options,
+		seen:    make(map[string]time.Time),
+		watermark: map[string]time.Time{
+			"customer":    options.Since,
+			"transaction": options.Since,
+		},
+	}
+	for entity := range p.watermark {
+		if watermark, ok := client.Watermark(watermarkName(entity)); ok {
+			p.watermark[entity] = watermark
+		}
+	}
+	if options.Handler == nil {
+		p.events = make(chan Event, options.Buffer)
+	}
+	return p
+}
+
+// Events returns the channel of the events, nil when a handler is configured.
+// The channel is closed when Run returns.
+func (p *Poller) Events() <-chan Event {
+	return p.events
+}
+
+// Run polls Arpa until the context is done. The failed polls are reported to OnError
+// and retried with an exponential backoff.
+func (p *Poller) Run(ctx context.Context) error {
+	if p.events != nil {
+		defer close(p.events)
+	}
+
+	wait := p.options.Interval
+	for {
+		if err := p.Poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if p.options.OnError != nil {
+				p.options.OnError(err)
+			}
+			wait = min(wait*2, p.options.MaxBackoff)
+		} else {
+			wait = p.options.Interval
+		}
+
+		timer := p.client.Clock().NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Poll fetches the changes since the last poll once and emits their events
+func (p *Poller) Poll(ctx context.Context) error {
+	token, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	if p.options.Customers {
+		since := p.watermark["customer"].Add(-p.options.Overlap)
+		// the deactivations are emitted whatever the filter of the client
+		params := goarpa.GetCustomersParams{ModifiedSince: &since, Inactive: goarpa.IncludeInactive}
+		for customer, err := range p.client.IterateCustomers(ctx, token, nil, params) {
+			if err != nil {
+				return err
+			}
+			created := customer.ModifiedAt.IsZero() || !customer.ModifiedAt.After(customer.CreatedAt)
+			if err := p.emit(ctx, "customer", CustomerEvent{Customer: customer, Created: created}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.options.Transactions {
+		since := p.watermark["transaction"].Add(-p.options.Overlap)
+		params := goarpa.GetTransactionsParams{ModifiedSince: &since}
+		for transaction, err := range p.client.IterateTransactions(ctx, token, nil, params) {
+			if err != nil {
+				return err
+			}
+			if err := p.emit(ctx, "transaction", TransactionEvent{Transaction: transaction}); err != nil {
+				return err
+			}
+		}
+	}
+
+	p.prune()
+	return nil
+}
+
+// emit sends the event unless the same modification has already been emitted
+func (p *Poller) emit(ctx context.Context, entity string, event Event) error {
+	key := event.key()
+	modifiedAt := event.ModifiedAt()
+	if last, ok := p.seen[key]; ok && !modifiedAt.After(last) {
+		return nil
+	}
+
+	if p.options.Handler != nil {
+		if err := p.options.Handler(ctx, event); err != nil {
+			return err
+		}
+	} else {
+		select {
+		case p.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	p.seen[key] = modifiedAt
+	if modifiedAt.After(p.watermark[entity]) {
+		p.watermark[entity] = modifiedAt
+		p.client.SetWatermark(watermarkName(entity), modifiedAt)
+	}
+	return nil
+}
+
+// watermarkName is the name of the watermark of the entity in the client, see goarpa.GoArpa.SetWatermark
+func watermarkName(entity string) string {
+	return "poller." + entity
+}
+
+// prune forgets the records which can no more be returned by a poll
+func (p *Poller) prune() {
+	oldest := p.watermark["customer"]
+	if p.watermark["transaction"].Before(oldest) {
+		oldest = p.watermark["transaction"]
+	}
+	oldest = oldest.Add(-p.options.Overlap)
+	for key, modifiedAt := range p.seen {
+		if modifiedAt.Before(oldest) {
+			delete(p.seen, key)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package poller_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/poller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PollerDeduplicates(t *testing.T) {
+	t.Parallel()
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.Header().Set("Content-Type", "application/json")
+		if polls == 1 {
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"1","Creation_Date":"2024-03-01T10:00:00","Modification_Date":"2024-03-01T10:00:00"}],"error":null}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[
+			{"BusinessID":"1","Creation_Date":"2024-03-01T10:00:00","Modification_Date":"2024-03-01T10:00:00"},
+			{"BusinessID":"1","Creation_Date":"2024-03-01T10:00:00","Modification_Date":"2024-03-01T12:00:00"}
+		],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetCustomersEndpoint = "customers"
+
+	var events []poller.Event
+	p := poller.New(client, func(context.Context) (string, error) {
+		return "token", nil
+	}, poller.Options{
+		Customers: true,
+		Since:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Handler: func(_ context.Context, event poller.Event) error {
+			events = append(events, event)
+			return nil
+		},
+	})
+
+	require.NoError(t, p.Poll(context.Background()))
+	require.NoError(t, p.Poll(context.Background()))
+
+	// the record seen twice is emitted once
+	require.Len(t, events, 2)
+	first, ok := events[0].(poller.CustomerEvent)
+	require.True(t, ok)
+	assert.True(t, first.Created)
+	second, ok := events[1].(poller.CustomerEvent)
+	require.True(t, ok)
+	assert.False(t, second.Created)
+	assert.Equal(t, 12, second.ModifiedAt().Hour())
+
+	// the watermark is recorded in the client, to be exported with its state
+	watermark, ok := client.Watermark("poller.customer")
+	require.True(t, ok)
+	assert.Equal(t, 12, watermark.Hour())
+}
+
+func Test_PollerEmitsDeactivations(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"BusinessID":"1","InActive":"1","Creation_Date":"2024-03-01T10:00:00","Modification_Date":"2024-03-01T12:00:00"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	// the filter of the client does not hide the deactivations from the handler
+	client := goarpa.NewClient(server.URL, goarpa.WithInactiveFilter(goarpa.ExcludeInactive))
+	client.Config.GetCustomersEndpoint = "customers"
+
+	var events []poller.Event
+	p := poller.New(client, func(context.Context) (string, error) {
+		return "token", nil
+	}, poller.Options{
+		Customers: true,
+		Since:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Handler: func(_ context.Context, event poller.Event) error {
+			events = append(events, event)
+			return nil
+		},
+	})
+
+	require.NoError(t, p.Poll(context.Background()))
+	require.Len(t, events, 1)
+	event, ok := events[0].(poller.CustomerEvent)
+	require.True(t, ok)
+	assert.True(t, event.Customer.Inactive)
+}
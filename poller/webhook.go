@@ -0,0 +1,239 @@
+package poller
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/gateway"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the webhook requests, see WebhookSignature
+	SignatureHeader = "X-Arpa-Signature"
+	// TimestampHeader carries the Unix time the webhook request was signed at
+	TimestampHeader = "X-Arpa-Timestamp"
+	// EventIDHeader carries the ID of the event, the same for the retries of a delivery
+	EventIDHeader = "X-Arpa-Event"
+)
+
+// The types of the webhook payloads
+const (
+	WebhookCustomerCreated    = "customer.created"
+	WebhookCustomerUpdated    = "customer.updated"
+	WebhookTransactionChanged = "transaction.changed"
+)
+
+// WebhookPayload is the JSON body posted by a Webhook
+type WebhookPayload struct {
+	// ID identifies the change, e.g. customer:12@2024-03-01T10:00:00Z, to deduplicate the deliveries
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+	// Customer or Transaction is set by the type. The customer has the schema of the gateway.
+	Customer    *gateway.Customer   `json:"customer,omitempty"`
+	Transaction *goarpa.Transaction `json:"transaction,omitempty"`
+}
+
+// NewWebhookPayload returns the payload of the event
+func NewWebhookPayload(event Event) WebhookPayload {
+	payload := WebhookPayload{
+		ID:         event.key() + "@" + event.ModifiedAt().UTC().Format(time.RFC3339),
+		ModifiedAt: event.ModifiedAt(),
+	}
+	switch event := event.(type) {
+	case CustomerEvent:
+		payload.Type = WebhookCustomerUpdated
+		if event.Created {
+			payload.Type = WebhookCustomerCreated
+		}
+		customer := gateway.NewCustomer(event.Customer)
+		payload.Customer = &customer
+	case TransactionEvent:
+		payload.Type = WebhookTransactionChanged
+		payload.Transaction = &event.Transaction
+	}
+	return payload
+}
+
+// DeadLetter is a payload which could not be delivered, written as a line of JSON to the dead letter file
+type DeadLetter struct {
+	Payload  WebhookPayload `json:"payload"`
+	Error    string         `json:"error"`
+	Attempts int            `json:"attempts"`
+	FailedAt time.Time      `json:"failedAt"`
+}
+
+// WebhookOptions configure a Webhook
+type WebhookOptions struct {
+	// URL is the target the payloads are posted to
+	URL string
+	// Secret signs the requests when it is set, see WebhookSignature
+	Secret []byte
+	// Header is added to the requests, e.g. an authorization header
+	Header http.Header
+	// HTTPClient posts the requests, a client with a timeout of 30 seconds by default
+	HTTPClient *http.Client
+	// MaxAttempts is the number of attempts of a delivery, 5 by default
+	MaxAttempts int
+	// Backoff is the wait between the attempts, 1 second doubled up to 1 minute with full jitter by default
+	Backoff goarpa.RetryBackoff
+	// DeadLetterFile receives the payloads out of attempts or rejected by the target, see DeadLetter.
+	// Without it, the delivery fails and the event is emitted again by the next poll.
+	DeadLetterFile string
+	// Clock times the waits between the attempts, goarpa.SystemClock by default
+	Clock goarpa.Clock
+}
+
+// Webhook posts the events of a Poller to an HTTP endpoint, for the systems which cannot use the Go client.
+// Its Handle method is the Handler of the poller:
+//
+//	webhook, err := poller.NewWebhook(poller.WebhookOptions{URL: "https://crm/arpa", Secret: secret})
+//	p := poller.New(client, token, poller.Options{Handler: webhook.Handle})
+//
+// A delivery is retried on the network errors, the responses 408, 429 and 5xx. The events are delivered
+// at least once: the target should deduplicate them by the ID of the payload.
+type Webhook struct {
+	options WebhookOptions
+	// mu serializes the writes of the dead letter file
+	mu sync.Mutex
+}
+
+// NewWebhook returns a webhook posting to the URL of the options
+func NewWebhook(options WebhookOptions) (*Webhook, error) {
+	target, err := url.Parse(options.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, errors.Errorf("invalid webhook URL %q", options.URL)
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 5
+	}
+	if options.Backoff.Base <= 0 {
+		options.Backoff = goarpa.RetryBackoff{Base: time.Second, Max: time.Minute}
+	}
+	if options.Clock == nil {
+		options.Clock = goarpa.SystemClock
+	}
+	return &Webhook{options: options}, nil
+}
+
+// WebhookSignature returns the signature of a request: "sha256=" followed by the hexadecimal HMAC-SHA256
+// of the timestamp header, a dot and the body, keyed by the secret
+func WebhookSignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Handle delivers the event. It returns an error when the delivery failed without a dead letter file,
+// or when the payload could not be written to it.
+func (w *Webhook) Handle(ctx context.Context, event Event) error {
+	payload := NewWebhookPayload(event)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "could not encode webhook payload")
+	}
+
+	var attempt int
+	for attempt = 1; ; attempt++ {
+		var retryable bool
+		retryable, err = w.post(ctx, payload.ID, body)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retryable || attempt >= w.options.MaxAttempts {
+			break
+		}
+
+		timer := w.options.Clock.NewTimer(w.options.Backoff.Wait(attempt))
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+
+	err = errors.Wrapf(err, "could not deliver webhook %s after %d attempts", payload.ID, attempt)
+	if w.options.DeadLetterFile == "" {
+		return err
+	}
+	return w.deadLetter(DeadLetter{Payload: payload, Error: err.Error(), Attempts: attempt, FailedAt: w.options.Clock.Now()})
+}
+
+// post sends the body once and reports whether a failure may succeed when retried
+func (w *Webhook) post(ctx context.Context, eventID string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for key, values := range w.options.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, eventID)
+	if len(w.options.Secret) > 0 {
+		timestamp := strconv.FormatInt(w.options.Clock.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, WebhookSignature(w.options.Secret, timestamp, body))
+	}
+
+	resp, err := w.options.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	switch {
+	case resp.StatusCode < http.StatusMultipleChoices:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= http.StatusInternalServerError:
+		return true, errors.Errorf("webhook answered %s", resp.Status)
+	default:
+		return false, errors.Errorf("webhook answered %s", resp.Status)
+	}
+}
+
+// deadLetter appends the dead letter to the dead letter file
+func (w *Webhook) deadLetter(letter DeadLetter) error {
+	const errMessage = "could not write webhook dead letter"
+
+	line, err := json.Marshal(letter)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	file, err := os.OpenFile(w.options.DeadLetterFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, errMessage)
+	}
+	return errors.Wrap(file.Close(), errMessage)
+}
@@ -0,0 +1,96 @@
+package poller_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/poller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WebhookDelivers(t *testing.T) {
+	t.Parallel()
+	secret := []byte("secret")
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature := poller.WebhookSignature(secret, r.Header.Get(poller.TimestampHeader), body)
+		assert.Equal(t, signature, r.Header.Get(poller.SignatureHeader))
+		assert.Equal(t, "customer:12@2024-03-01T10:00:00Z", r.Header.Get(poller.EventIDHeader))
+
+		var payload poller.WebhookPayload
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, poller.WebhookCustomerCreated, payload.Type)
+		require.NotNil(t, payload.Customer)
+		assert.Equal(t, int64(12), payload.Customer.BusinessID)
+
+		// the first attempt fails
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	webhook, err := poller.NewWebhook(poller.WebhookOptions{
+		URL:     server.URL,
+		Secret:  secret,
+		Backoff: goarpa.RetryBackoff{Base: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	modifiedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	event := poller.CustomerEvent{Customer: goarpa.Customer{ID: 12, ModifiedAt: modifiedAt}, Created: true}
+	require.NoError(t, webhook.Handle(context.Background(), event))
+	assert.Equal(t, int32(2), attempts.Load())
+
+	_, err = poller.NewWebhook(poller.WebhookOptions{URL: "ftp://crm"})
+	assert.Error(t, err)
+}
+
+func Test_WebhookDeadLetter(t *testing.T) {
+	t.Parallel()
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		if r.URL.Path == "/rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	event := poller.TransactionEvent{Transaction: goarpa.Transaction{TransactionID: 7}}
+	ctx := context.Background()
+
+	// without a dead letter file the event fails, to be emitted again
+	webhook, err := poller.NewWebhook(poller.WebhookOptions{URL: server.URL, MaxAttempts: 3, Backoff: goarpa.RetryBackoff{Base: time.Millisecond}})
+	require.NoError(t, err)
+	assert.ErrorContains(t, webhook.Handle(ctx, event), "after 3 attempts")
+	assert.Equal(t, int32(3), attempts.Load())
+
+	// a rejected payload is not retried
+	deadLetters := filepath.Join(t.TempDir(), "dead.jsonl")
+	webhook, err = poller.NewWebhook(poller.WebhookOptions{URL: server.URL + "/rejected", DeadLetterFile: deadLetters})
+	require.NoError(t, err)
+	require.NoError(t, webhook.Handle(ctx, event))
+	assert.Equal(t, int32(4), attempts.Load())
+
+	b, err := os.ReadFile(deadLetters)
+	require.NoError(t, err)
+	var letter poller.DeadLetter
+	require.NoError(t, json.Unmarshal(b, &letter))
+	assert.Equal(t, poller.WebhookTransactionChanged, letter.Payload.Type)
+	assert.Equal(t, 1, letter.Attempts)
+	assert.Contains(t, letter.Error, "400 Bad Request")
+}
@@ -0,0 +1,64 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ItemPriceHistoryParams select the item and the period of GetItemPriceHistory
+type ItemPriceHistoryParams struct {
+	ItemID   ItemID     `json:"ItemID"`
+	FromDate *time.Time `json:"FromDate,omitempty"`
+	ToDate   *time.Time `json:"ToDate,omitempty"`
+}
+
+// ItemPriceChange is a change of the sale price of an item at a price level, see GetServiceResponse.PriceForLevel
+type ItemPriceChange struct {
+	ItemID       ItemID      `json:"ItemID"`
+	PriceLevelID StringInt64 `json:"PriceLevelID"`
+	OldPrice     Money       `json:"OldPrice"`
+	NewPrice     Money       `json:"NewPrice"`
+	ChangeDate   *CustomTime `json:"ChangeDate"`
+	// UserID is the user who changed the price
+	UserID StringInt64 `json:"Creator_UserID"`
+}
+
+// Difference returns the amount the price changed by, negative when it decreased
+func (c ItemPriceChange) Difference() Money {
+	return c.NewPrice.Sub(c.OldPrice)
+}
+
+// ItemPriceHistoryResponse is the response of GetItemPriceHistory
+type ItemPriceHistoryResponse = APIResponse[ItemPriceChange]
+
+// GetItemPriceHistory returns the changes of the prices of the item between the dates, a zero date leaving
+// the period open, ordered by date and price level.
+// It returns ErrNotSupported when the installation has no price history endpoint.
+func (g *GoArpa) GetItemPriceHistory(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, from time.Time, to time.Time) ([]ItemPriceChange, error) {
+	const errMessage = "could not get item price history"
+
+	params := ItemPriceHistoryParams{ItemID: itemID}
+	if !from.IsZero() {
+		params.FromDate = &from
+	}
+	if !to.IsZero() {
+		params.ToDate = &to
+	}
+
+	var response ItemPriceHistoryResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetItemPriceHistoryEndpoint, params, &response, errMessage); err != nil {
+		return nil, err
+	}
+
+	changes := []ItemPriceChange(response.Data)
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.ChangeDate != nil && b.ChangeDate != nil && !a.ChangeDate.Equal(b.ChangeDate.Time) {
+			return a.ChangeDate.Before(b.ChangeDate.Time)
+		}
+		return a.PriceLevelID < b.PriceLevelID
+	})
+	return changes, nil
+}
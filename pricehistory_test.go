@@ -0,0 +1,45 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetItemPriceHistory(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/prices", r.URL.Path)
+		assert.Equal(t, "7", r.URL.Query().Get("ItemID"))
+		assert.NotEmpty(t, r.URL.Query().Get("FromDate"))
+		assert.Empty(t, r.URL.Query().Get("ToDate"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[
+			{"ItemID":"7","PriceLevelID":"2","OldPrice":"1000","NewPrice":"900","ChangeDate":"2024-02-01 10:00:00"},
+			{"ItemID":"7","PriceLevelID":"1","OldPrice":"1000","NewPrice":"1200","ChangeDate":"2024-02-01 10:00:00"},
+			{"ItemID":"7","PriceLevelID":"1","OldPrice":"800","NewPrice":"1000","ChangeDate":"2024-01-05 09:00:00"}
+		],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	ctx := context.Background()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := client.GetItemPriceHistory(ctx, "token", nil, 7, from, time.Time{})
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+
+	client.Config.GetItemPriceHistoryEndpoint = "prices"
+	changes, err := client.GetItemPriceHistory(ctx, "token", nil, 7, from, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, "200", changes[0].Difference().String())
+	assert.Equal(t, goarpa.StringInt64(1), changes[1].PriceLevelID)
+	assert.Equal(t, "-100", changes[2].Difference().String())
+}
@@ -0,0 +1,174 @@
+package goarpa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// InstallationProfile holds the transaction defaults which differ between Arpa installations.
+// The zero fields have no default.
+type InstallationProfile struct {
+	DocAliasID   int64
+	TransStateID TransState
+	SettlementID int64
+	DepartmentID int64
+	WarehouseID  int64
+}
+
+// WithInstallationProfile fills the defaults of the profile into the transactions of the client,
+// see NewTransactionRequest. Call ValidateInstallationProfile at startup to check it against the installation.
+func WithInstallationProfile(profile InstallationProfile) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.profile = profile
+	}
+}
+
+// InstallationProfile returns the installation profile of the client
+func (g *GoArpa) InstallationProfile() InstallationProfile {
+	return g.profile
+}
+
+// NewTransactionRequest returns a transaction of the business with the defaults of the installation profile
+func (g *GoArpa) NewTransactionRequest(businessID BusinessID, factorType FactorType, items ...TransactionItem) CreateTransactionRequest {
+	transaction := CreateTransactionRequest{
+		Data:  Data{BusinessID: businessID, FactorTypeID: factorType},
+		Items: items,
+	}
+	g.profile.apply(&transaction.Data)
+	return transaction
+}
+
+// apply fills the defaults into the fields which are not set
+func (p InstallationProfile) apply(data *Data) {
+	if data.DocAliasID == 0 {
+		data.DocAliasID = p.DocAliasID
+	}
+	if data.TransStateID == 0 {
+		data.TransStateID = p.TransStateID
+	}
+	if data.SettlementID == 0 {
+		data.SettlementID = p.SettlementID
+	}
+	if data.DepartmentID == 0 {
+		data.DepartmentID = p.DepartmentID
+	}
+	if data.WarehouseID == 0 {
+		data.WarehouseID = p.WarehouseID
+	}
+}
+
+// ValidateInstallationProfile checks that the IDs of the installation profile exist in the lookups of the installation.
+// The lookups whose endpoint is not configured are skipped.
+func (g *GoArpa) ValidateInstallationProfile(ctx context.Context, accessToken string, cookie []*http.Cookie) error {
+	const errMessage = "invalid installation profile"
+
+	if g.profile.TransStateID != 0 && !g.profile.TransStateID.Valid() {
+		return errors.Wrap(fmt.Errorf("unknown transaction state %d", g.profile.TransStateID), errMessage)
+	}
+
+	var problems []string
+	check := func(name string, id int64, ids []int64, err error) error {
+		if id == 0 || errors.Is(err, ErrNotSupported) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, known := range ids {
+			if known == id {
+				return nil
+			}
+		}
+		problems = append(problems, fmt.Sprintf("unknown %s %d", name, id))
+		return nil
+	}
+
+	if g.profile.DocAliasID != 0 {
+		result, err := g.GetDocAliases(ctx, accessToken, cookie)
+		var ids []int64
+		if err == nil {
+			for _, docAlias := range result.Data {
+				ids = append(ids, docAlias.ID.Int64())
+			}
+		}
+		if err := check("document alias", g.profile.DocAliasID, ids, err); err != nil {
+			return errors.Wrap(err, errMessage)
+		}
+	}
+	if g.profile.SettlementID != 0 {
+		result, err := g.GetSettlements(ctx, accessToken, cookie)
+		var ids []int64
+		if err == nil {
+			for _, settlement := range result.Data {
+				ids = append(ids, settlement.ID.Int64())
+			}
+		}
+		if err := check("settlement", g.profile.SettlementID, ids, err); err != nil {
+			return errors.Wrap(err, errMessage)
+		}
+	}
+	if g.profile.DepartmentID != 0 {
+		result, err := g.GetDepartments(ctx, accessToken, cookie)
+		var ids []int64
+		if err == nil {
+			for _, department := range result.Data {
+				ids = append(ids, department.ID.Int64())
+			}
+		}
+		if err := check("department", g.profile.DepartmentID, ids, err); err != nil {
+			return errors.Wrap(err, errMessage)
+		}
+	}
+
+	if g.profile.WarehouseID != 0 {
+		result, err := g.GetWarehouses(ctx, accessToken, cookie)
+		var ids []int64
+		if err == nil {
+			for _, warehouse := range result.Data {
+				ids = append(ids, warehouse.ID.Int64())
+			}
+		}
+		if err := check("warehouse", g.profile.WarehouseID, ids, err); err != nil {
+			return errors.Wrap(err, errMessage)
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Wrap(errors.New(strings.Join(problems, ", ")), errMessage)
+	}
+	return nil
+}
+
+// ValidateTransactionWarehouses checks that the warehouses of the transaction and of its lines exist,
+// before Arpa rejects the transaction or takes the stock from another warehouse.
+func (g *GoArpa) ValidateTransactionWarehouses(ctx context.Context, accessToken string, cookie []*http.Cookie, transaction CreateTransactionRequest) error {
+	const errMessage = "invalid transaction warehouses"
+
+	warehouses, err := g.GetWarehouses(ctx, accessToken, cookie)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+	known := make(map[int64]bool, len(warehouses.Data))
+	for _, warehouse := range warehouses.Data {
+		known[warehouse.ID.Int64()] = true
+	}
+
+	var problems []string
+	if id := transaction.Data.WarehouseID; id != 0 && !known[id] {
+		problems = append(problems, fmt.Sprintf("unknown warehouse %d of the transaction", id))
+	}
+	for i, item := range transaction.Items {
+		if id := item.WarehouseID; id != 0 && !known[id] {
+			problems = append(problems, fmt.Sprintf("unknown warehouse %d of line %d", id, i+1))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Wrap(errors.New(strings.Join(problems, ", ")), errMessage)
+	}
+	return nil
+}
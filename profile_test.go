@@ -0,0 +1,100 @@
+package goarpa_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InstallationProfile(t *testing.T) {
+	t.Parallel()
+	var sent goarpa.CreateTransactionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/docaliases":
+			_, _ = w.Write([]byte(`{"data":[{"DocAliasID":"1","DocAliasName":"Sales"},{"DocAliasID":"5","DocAliasName":"POS"}]}`))
+		case "/settlements":
+			_, _ = w.Write([]byte(`{"data":[{"SettlementID":"1","SettlementName":"Cash"}]}`))
+		default:
+			_ = json.NewDecoder(r.Body).Decode(&sent)
+			_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+		}
+	}))
+	defer server.Close()
+
+	profile := goarpa.InstallationProfile{DocAliasID: 5, TransStateID: goarpa.TransStateFinal, SettlementID: 2, DepartmentID: 7}
+	client := goarpa.NewClient(server.URL, goarpa.WithInstallationProfile(profile))
+	client.Config.GetDocAliasesEndpoint = "docaliases"
+	client.Config.GetSettlementsEndpoint = "settlements"
+	ctx := context.Background()
+
+	transaction := client.NewTransactionRequest(42, goarpa.FactorTypeSale, goarpa.TransactionItem{ItemID: 1, Qty: 1})
+	assert.Equal(t, int64(5), transaction.Data.DocAliasID)
+	assert.Equal(t, goarpa.TransStateFinal, transaction.Data.TransStateID)
+
+	// the fields set by the caller are kept
+	_, err := client.CreateTransaction(ctx, "token", goarpa.CreateTransactionRequest{
+		Data: goarpa.Data{BusinessID: 42, FactorTypeID: goarpa.FactorTypeSale, DepartmentID: 3},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), sent.Data.DocAliasID)
+	assert.Equal(t, int64(2), sent.Data.SettlementID)
+	assert.Equal(t, int64(3), sent.Data.DepartmentID)
+
+	// the settlement does not exist, the departments cannot be checked
+	err = client.ValidateInstallationProfile(ctx, "token", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown settlement 2")
+	assert.NotContains(t, err.Error(), "document alias")
+
+	valid := goarpa.NewClient(server.URL, goarpa.WithInstallationProfile(goarpa.InstallationProfile{DocAliasID: 1, SettlementID: 1}))
+	valid.Config.GetDocAliasesEndpoint = "docaliases"
+	valid.Config.GetSettlementsEndpoint = "settlements"
+	assert.NoError(t, valid.ValidateInstallationProfile(ctx, "token", nil))
+}
+
+func Test_TransactionWarehouses(t *testing.T) {
+	t.Parallel()
+	var sent goarpa.CreateTransactionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/warehouses" {
+			_, _ = w.Write([]byte(`{"data":[{"StockID":"1","StockName":"Main"},{"StockID":"2","StockName":"Shop"}]}`))
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithInstallationProfile(goarpa.InstallationProfile{TransStateID: goarpa.TransStateFinal, WarehouseID: 1}))
+	client.Config.GetWarehousesEndpoint = "warehouses"
+	ctx := context.Background()
+
+	transaction := client.NewTransactionRequest(42, goarpa.FactorTypeSale,
+		goarpa.TransactionItem{ItemID: 1, Qty: 1},
+		goarpa.TransactionItem{ItemID: 2, Qty: 1, WarehouseID: 2},
+	)
+	assert.Equal(t, int64(1), transaction.Items[0].Warehouse(transaction.Data))
+	assert.Equal(t, int64(2), transaction.Items[1].Warehouse(transaction.Data))
+	require.NoError(t, client.ValidateTransactionWarehouses(ctx, "token", nil, transaction))
+	require.NoError(t, client.ValidateInstallationProfile(ctx, "token", nil))
+
+	_, err := client.CreateTransaction(ctx, "token", transaction)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), sent.Data.WarehouseID)
+	assert.Equal(t, int64(0), sent.Items[0].WarehouseID)
+	assert.Equal(t, int64(2), sent.Items[1].WarehouseID)
+
+	transaction.Items[0].WarehouseID = 9
+	err = client.ValidateTransactionWarehouses(ctx, "token", nil, transaction)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown warehouse 9 of line 1")
+}
@@ -0,0 +1,136 @@
+// Package reconcile compares the transactions expected by a local system, e.g. the invoices of a shop,
+// with the transactions registered in Arpa and reports the differences.
+package reconcile
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+)
+
+// Expected is a transaction expected in Arpa. It is matched by TransactionID when set, by TransNumber otherwise.
+type Expected struct {
+	// Reference is the local identifier of the transaction, reported back as is
+	Reference     string
+	TransactionID goarpa.TransactionID
+	TransNumber   int64
+	Amount        goarpa.Money
+	Date          time.Time
+}
+
+// AmountMismatch is a transaction found in Arpa with a different amount
+type AmountMismatch struct {
+	Expected   Expected
+	Actual     goarpa.Transaction
+	Difference goarpa.Money
+}
+
+// Report is the difference between the expected transactions and Arpa
+type Report struct {
+	From    time.Time
+	To      time.Time
+	Matched int
+	// Missing are the expected transactions not found in Arpa
+	Missing []Expected
+	// AmountMismatches are the transactions whose amount differs
+	AmountMismatches []AmountMismatch
+	// Extra are the transactions of Arpa in the range which were not expected
+	Extra []goarpa.Transaction
+}
+
+// IsClean returns true if Arpa matches the expected transactions
+func (r Report) IsClean() bool {
+	return len(r.Missing) == 0 && len(r.AmountMismatches) == 0 && len(r.Extra) == 0
+}
+
+// Options configure the reconciliation
+type Options struct {
+	// From and To bound the dates pulled from Arpa, the range of the expected dates by default
+	From time.Time
+	To   time.Time
+	// BusinessID restricts the transactions to a business
+	BusinessID *goarpa.BusinessID
+	// Tolerance is the accepted absolute amount difference, e.g. for rounding
+	Tolerance goarpa.Money
+}
+
+// Run pulls the transactions of the range from Arpa and compares them with the expected ones
+func Run(ctx context.Context, client *goarpa.GoArpa, accessToken string, cookie []*http.Cookie, expected []Expected, options Options) (*Report, error) {
+	from, to := options.From, options.To
+	for _, transaction := range expected {
+		if from.IsZero() || transaction.Date.Before(from) {
+			from = transaction.Date
+		}
+		if to.IsZero() || transaction.Date.After(to) {
+			to = transaction.Date
+		}
+	}
+
+	params := goarpa.GetTransactionsParams{BusinessID: options.BusinessID}
+	if !from.IsZero() {
+		params.FromDate = goarpa.ToPtr(from)
+	}
+	if !to.IsZero() {
+		params.ToDate = goarpa.ToPtr(to)
+	}
+
+	var actual []goarpa.Transaction
+	for transaction, err := range client.IterateTransactions(ctx, accessToken, cookie, params) {
+		if err != nil {
+			return nil, err
+		}
+		actual = append(actual, transaction)
+	}
+
+	report := Compare(expected, actual, options.Tolerance)
+	report.From = from
+	report.To = to
+	return report, nil
+}
+
+// Compare compares the expected transactions with the transactions pulled from Arpa
+func Compare(expected []Expected, actual []goarpa.Transaction, tolerance goarpa.Money) *Report {
+	byID := make(map[goarpa.TransactionID]int, len(actual))
+	byNumber := make(map[int64]int, len(actual))
+	for i, transaction := range actual {
+		byID[transaction.TransactionID] = i
+		byNumber[int64(transaction.TransNumber)] = i
+	}
+
+	report := &Report{}
+	matched := make(map[int]bool, len(expected))
+	for _, e := range expected {
+		i, ok := -1, false
+		switch {
+		case e.TransactionID != 0:
+			i, ok = byID[e.TransactionID]
+		case e.TransNumber != 0:
+			i, ok = byNumber[e.TransNumber]
+		}
+		if !ok || matched[i] {
+			report.Missing = append(report.Missing, e)
+			continue
+		}
+		matched[i] = true
+
+		difference := actual[i].TotalAmount.Sub(e.Amount)
+		if difference.Abs().GreaterThan(tolerance.Abs()) {
+			report.AmountMismatches = append(report.AmountMismatches, AmountMismatch{
+				Expected:   e,
+				Actual:     actual[i],
+				Difference: difference,
+			})
+			continue
+		}
+		report.Matched++
+	}
+
+	for i, transaction := range actual {
+		if !matched[i] {
+			report.Extra = append(report.Extra, transaction)
+		}
+	}
+	return report
+}
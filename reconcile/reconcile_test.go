@@ -0,0 +1,40 @@
+package reconcile_test
+
+import (
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/reconcile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Compare(t *testing.T) {
+	t.Parallel()
+	expected := []reconcile.Expected{
+		{Reference: "a", TransactionID: 1, Amount: goarpa.NewMoney(1000)},
+		{Reference: "b", TransNumber: 20, Amount: goarpa.NewMoney(2000)},
+		{Reference: "c", TransactionID: 3, Amount: goarpa.NewMoney(3000)},
+		{Reference: "d", TransactionID: 4, Amount: goarpa.NewMoneyFromFloat(4000.4)},
+	}
+	actual := []goarpa.Transaction{
+		{TransactionID: 1, TransNumber: 10, TotalAmount: goarpa.NewMoney(1000)},
+		{TransactionID: 2, TransNumber: 20, TotalAmount: goarpa.NewMoney(2500)},
+		{TransactionID: 4, TransNumber: 40, TotalAmount: goarpa.NewMoney(4000)},
+		{TransactionID: 5, TransNumber: 50, TotalAmount: goarpa.NewMoney(5000)},
+	}
+
+	report := reconcile.Compare(expected, actual, goarpa.NewMoneyFromFloat(0.5))
+	assert.False(t, report.IsClean())
+	assert.Equal(t, 2, report.Matched)
+
+	require.Len(t, report.Missing, 1)
+	assert.Equal(t, "c", report.Missing[0].Reference)
+
+	require.Len(t, report.AmountMismatches, 1)
+	assert.Equal(t, "b", report.AmountMismatches[0].Expected.Reference)
+	assert.Equal(t, "500", report.AmountMismatches[0].Difference.String())
+
+	require.Len(t, report.Extra, 1)
+	assert.Equal(t, goarpa.TransactionID(5), report.Extra[0].TransactionID)
+}
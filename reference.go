@@ -0,0 +1,78 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Arpa has no field for the identifier of a transaction in another system, e.g. the order ID of a shop.
+// It is stored at the start of the description of the transaction between the following markers.
+const (
+	referencePrefix = "[ref:"
+	referenceSuffix = "]"
+)
+
+// SetReference stores the external reference, e.g. the order ID of a shop, in the description of the transaction,
+// so that the transaction can be found with GetTransactionByReference. It replaces the previous reference.
+func (d *Data) SetReference(reference string) error {
+	if reference == "" || strings.ContainsAny(reference, referenceSuffix+"\n") {
+		return errors.Errorf("invalid reference %q", reference)
+	}
+	_, description := splitReference(d.Description)
+	d.Description = referencePrefix + reference + referenceSuffix
+	if description != "" {
+		d.Description += " " + description
+	}
+	return nil
+}
+
+// Reference returns the external reference stored by Data.SetReference and false when the transaction has none
+func (t Transaction) Reference() (string, bool) {
+	reference, _ := splitReference(string(t.Description))
+	return reference, reference != ""
+}
+
+// splitReference splits a description into its reference, empty if it has none, and the rest of the description
+func splitReference(description string) (string, string) {
+	rest, ok := strings.CutPrefix(description, referencePrefix)
+	if !ok {
+		return "", description
+	}
+	reference, rest, ok := strings.Cut(rest, referenceSuffix)
+	if !ok || reference == "" {
+		return "", description
+	}
+	return reference, strings.TrimPrefix(rest, " ")
+}
+
+// GetTransactionByReference returns the transaction with the external reference of Data.SetReference.
+// The transactions are listed with the params, whose dates should narrow the search since Arpa cannot
+// filter by reference. If a retried post left several transactions with the reference, the first created
+// is returned. The error matches ErrTransactionNotFound when there is none.
+func (g *GoArpa) GetTransactionByReference(ctx context.Context, accessToken string, cookie []*http.Cookie, reference string, params GetTransactionsParams) (*Transaction, error) {
+	return getTransactionByReference(ctx, g, accessToken, cookie, reference, params)
+}
+
+func getTransactionByReference(ctx context.Context, client GoArpaIface, accessToken string, cookie []*http.Cookie, reference string, params GetTransactionsParams) (*Transaction, error) {
+	const errMessage = "could not get transaction by reference"
+
+	var found *Transaction
+	for transaction, err := range client.IterateTransactions(ctx, accessToken, cookie, params) {
+		if err != nil {
+			return nil, errors.Wrap(err, errMessage)
+		}
+		if actual, ok := transaction.Reference(); !ok || actual != reference {
+			continue
+		}
+		if found == nil || transaction.TransactionID < found.TransactionID {
+			found = &transaction
+		}
+	}
+	if found == nil {
+		return nil, errors.Wrapf(ErrTransactionNotFound, "%s: %s", errMessage, reference)
+	}
+	return found, nil
+}
@@ -0,0 +1,59 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SetReference(t *testing.T) {
+	t.Parallel()
+	data := goarpa.Data{Description: "paid online"}
+	require.NoError(t, data.SetReference("ORD-1"))
+	assert.Equal(t, "[ref:ORD-1] paid online", data.Description)
+	require.NoError(t, data.SetReference("ORD-2"))
+	assert.Equal(t, "[ref:ORD-2] paid online", data.Description)
+
+	reference, ok := goarpa.Transaction{Description: goarpa.EnforcedString(data.Description)}.Reference()
+	assert.True(t, ok)
+	assert.Equal(t, "ORD-2", reference)
+	_, ok = goarpa.Transaction{Description: "[ref: paid"}.Reference()
+	assert.False(t, ok)
+
+	assert.Error(t, data.SetReference(""))
+	assert.Error(t, data.SetReference("ORD]1"))
+}
+
+func Test_GetTransactionByReference(t *testing.T) {
+	t.Parallel()
+	simulated := goarpa.NewSimulatedClient()
+	pen := simulated.AddItem(goarpa.GetServiceResponse{ItemCode: "PEN", ItemName: "Pen", SalePrice: goarpa.NewMoney(1000)})
+	ctx := context.Background()
+
+	customer, err := simulated.CreateCustomer(ctx, goarpa.SimulatedToken, nil, goarpa.CreateCustomerRequest{BusName: "Ali"})
+	require.NoError(t, err)
+	sale := func(reference string) goarpa.TransactionID {
+		transaction := goarpa.CreateTransactionRequest{
+			Data:  goarpa.Data{BusinessID: customer.Data.BusinessID, TransStateID: goarpa.TransStateFinal, FactorTypeID: goarpa.FactorTypeSale},
+			Items: []goarpa.TransactionItem{{ItemID: pen, Qty: 1}},
+		}
+		require.NoError(t, transaction.Data.SetReference(reference))
+		created, err := simulated.CreateTransaction(ctx, goarpa.SimulatedToken, transaction)
+		require.NoError(t, err)
+		return created.Data[0].TransactionID
+	}
+	sale("ORD-1")
+	first := sale("ORD-2")
+	sale("ORD-2")
+
+	transaction, err := simulated.GetTransactionByReference(ctx, goarpa.SimulatedToken, nil, "ORD-2", goarpa.GetTransactionsParams{})
+	require.NoError(t, err)
+	assert.Equal(t, first, transaction.TransactionID)
+
+	_, err = simulated.GetTransactionByReference(ctx, goarpa.SimulatedToken, nil, "ORD-3", goarpa.GetTransactionsParams{})
+	assert.True(t, errors.Is(err, goarpa.ErrTransactionNotFound))
+}
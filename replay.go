@@ -0,0 +1,280 @@
+package goarpa
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ReplayEntry is a recorded call to replay against another environment, see ReadAuditLog and ReadDryRunLog
+type ReplayEntry struct {
+	// Operation is the name of the client method, it is empty for the dry run entries
+	Operation string
+	Method    string
+	// Endpoint is the path of the call relative to the base path of the client
+	Endpoint string
+	Body     json.RawMessage
+	// ResponseIDs are the identifiers returned by the recorded call, they map the IDs of the source
+	// environment to the IDs returned by the replayed call, see ReplayMapping
+	ResponseIDs map[string]string
+}
+
+// ReadAuditLog reads the entries of the audit records written as JSON lines by a FileAuditSink.
+// The records must contain the request bodies, see WithAuditBodies. The failed calls are not replayed.
+func ReadAuditLog(r io.Reader) ([]ReplayEntry, error) {
+	const errMessage = "could not read audit log"
+
+	var entries []ReplayEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, errors.Wrapf(err, "%s: line %d", errMessage, line)
+		}
+		if record.Error != "" {
+			continue
+		}
+		if len(record.Body) == 0 {
+			return nil, errors.Errorf("%s: line %d has no body, see WithAuditBodies", errMessage, line)
+		}
+		entries = append(entries, ReplayEntry{
+			Operation:   record.Operation,
+			Method:      record.Method,
+			Endpoint:    record.Endpoint,
+			Body:        record.Body,
+			ResponseIDs: record.ResponseIDs,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+	return entries, nil
+}
+
+// ReadDryRunLog reads the entries of the requests logged by a client in dry run, see WithDryRun.
+// The other lines of the log are ignored. basePath is the base path of the client which logged them,
+// it is trimmed from the URLs.
+func ReadDryRunLog(r io.Reader, basePath string) ([]ReplayEntry, error) {
+	const errMessage = "could not read dry run log"
+	const prefix = "goarpa: dry run: "
+
+	basePath = strings.TrimRight(basePath, urlSeparator)
+	var entries []ReplayEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		_, request, ok := strings.Cut(scanner.Text(), prefix)
+		if !ok {
+			continue
+		}
+		method, rest, _ := strings.Cut(request, " ")
+		url, rest, _ := strings.Cut(rest, " query=")
+		_, body, ok := strings.Cut(rest, " body=")
+		if !ok || !json.Valid([]byte(body)) {
+			return nil, errors.Errorf("%s: line %d has no JSON body", errMessage, line)
+		}
+		entries = append(entries, ReplayEntry{
+			Method:   method,
+			Endpoint: strings.TrimPrefix(url, basePath),
+			Body:     json.RawMessage(body),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+	return entries, nil
+}
+
+// ReplayMapping maps the IDs of the source environment to the IDs of the target environment by the name
+// of their field, e.g. "BusinessID", compared case-insensitively. It is filled with the IDs returned
+// by the replayed calls and may be seeded with the IDs which are known to differ. It is safe for concurrent use.
+type ReplayMapping struct {
+	mu  sync.RWMutex
+	ids map[string]map[string]string
+}
+
+// NewReplayMapping returns an empty mapping
+func NewReplayMapping() *ReplayMapping {
+	return &ReplayMapping{ids: make(map[string]map[string]string)}
+}
+
+// Set maps the source ID of the field to the target ID
+func (m *ReplayMapping) Set(field string, source string, target string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	field = strings.ToLower(field)
+	if m.ids[field] == nil {
+		m.ids[field] = make(map[string]string)
+	}
+	m.ids[field][source] = target
+}
+
+// Get returns the target ID of the source ID of the field
+func (m *ReplayMapping) Get(field string, source string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	target, ok := m.ids[strings.ToLower(field)][source]
+	return target, ok
+}
+
+// rewrite replaces the mapped IDs of the fields of the JSON value, the numbers stay numbers
+func (m *ReplayMapping) rewrite(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for field, v := range value {
+			switch v := v.(type) {
+			case json.Number:
+				if target, ok := m.Get(field, v.String()); ok {
+					value[field] = json.Number(target)
+				}
+			case string:
+				if target, ok := m.Get(field, v); ok {
+					value[field] = target
+				}
+			default:
+				value[field] = m.rewrite(v)
+			}
+		}
+	case []any:
+		for i, v := range value {
+			value[i] = m.rewrite(v)
+		}
+	}
+	return value
+}
+
+// ReplayOptions configure Replay
+type ReplayOptions struct {
+	// Mapping maps the IDs of the bodies, a new mapping by default
+	Mapping *ReplayMapping
+	// Rewrite is called with every entry once its IDs are mapped, e.g. to change the fields which cannot be
+	// mapped by name. The entry is skipped when it returns ErrSkipReplay.
+	Rewrite func(entry *ReplayEntry, mapping *ReplayMapping) error
+	// ContinueOnError replays the next entries after a failed call, by default the replay stops
+	// since the next entries may depend on the IDs of the failed one
+	ContinueOnError bool
+}
+
+// ErrSkipReplay is returned by ReplayOptions.Rewrite to skip an entry
+var ErrSkipReplay = errors.New("skip replay")
+
+// ReplayResult is the outcome of the replay of an entry
+type ReplayResult struct {
+	Entry   ReplayEntry
+	Skipped bool
+	// ResponseIDs are the identifiers returned by the replayed call
+	ResponseIDs map[string]string
+	Err         error
+}
+
+// Replay sends the recorded calls to the environment of the client, in order, e.g. the calls recorded on
+// a staging server to production at cutover. The IDs of the bodies are mapped with the mapping, which learns
+// the IDs returned by the replayed calls, so that a transaction of a replayed business refers to its new ID.
+// The calls are audited and logged in dry run like the other mutating calls.
+func (g *GoArpa) Replay(ctx context.Context, accessToken string, cookie []*http.Cookie, entries []ReplayEntry, options ReplayOptions) ([]ReplayResult, error) {
+	mapping := options.Mapping
+	if mapping == nil {
+		mapping = NewReplayMapping()
+	}
+
+	results := make([]ReplayResult, 0, len(entries))
+	for i, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := g.replay(ctx, accessToken, cookie, entry, mapping, options.Rewrite)
+		results = append(results, result)
+		if result.Err != nil && !options.ContinueOnError {
+			return results, errors.Wrapf(result.Err, "could not replay entry %d", i+1)
+		}
+	}
+	return results, nil
+}
+
+func (g *GoArpa) replay(ctx context.Context, accessToken string, cookie []*http.Cookie, entry ReplayEntry, mapping *ReplayMapping, rewrite func(*ReplayEntry, *ReplayMapping) error) ReplayResult {
+	errMessage := fmt.Sprintf("could not replay %s %s", entry.Method, entry.Endpoint)
+
+	decoder := json.NewDecoder(bytes.NewReader(entry.Body))
+	decoder.UseNumber()
+	var body any
+	if err := decoder.Decode(&body); err != nil {
+		return ReplayResult{Entry: entry, Err: errors.Wrap(err, errMessage)}
+	}
+	mapped, err := json.Marshal(mapping.rewrite(body))
+	if err != nil {
+		return ReplayResult{Entry: entry, Err: errors.Wrap(err, errMessage)}
+	}
+	entry.Body = mapped
+	if rewrite != nil {
+		if err := rewrite(&entry, mapping); errors.Is(err, ErrSkipReplay) {
+			return ReplayResult{Entry: entry, Skipped: true}
+		} else if err != nil {
+			return ReplayResult{Entry: entry, Err: errors.Wrap(err, errMessage)}
+		}
+	}
+
+	var response APIResponse[map[string]json.RawMessage]
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody([]byte(entry.Body)).
+		SetResult(&response)
+	url := g.url(entry.Endpoint)
+
+	if g.dryRun {
+		logDryRun(req, entry.Method, url)
+		return ReplayResult{Entry: entry}
+	}
+
+	resp, err := req.Execute(entry.Method, url)
+	ids := func() map[string]string { return replayResponseIDs(&response, entry.ResponseIDs) }
+	g.audit(ctx, accessToken, entry.Operation, url, []byte(entry.Body), resp, err, response.Error, ids)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return ReplayResult{Entry: entry, Err: err}
+	}
+	if err := g.checkForArpaError(resp, response.Error, errMessage); err != nil {
+		return ReplayResult{Entry: entry, Err: err}
+	}
+
+	result := ReplayResult{Entry: entry, ResponseIDs: ids()}
+	for field, source := range entry.ResponseIDs {
+		if target, ok := result.ResponseIDs[field]; ok {
+			mapping.Set(field, source, target)
+		}
+	}
+	return result
+}
+
+// replayResponseIDs returns the identifiers of the first datum of the response which were recorded
+func replayResponseIDs(response *APIResponse[map[string]json.RawMessage], recorded map[string]string) map[string]string {
+	datum, ok := response.First()
+	if !ok {
+		return nil
+	}
+	ids := make(map[string]string)
+	for name, raw := range datum {
+		for field := range recorded {
+			if !strings.EqualFold(name, field) {
+				continue
+			}
+			var value any
+			decoder := json.NewDecoder(bytes.NewReader(raw))
+			decoder.UseNumber()
+			if decoder.Decode(&value) == nil {
+				ids[field] = fmt.Sprint(value)
+			}
+		}
+	}
+	return ids
+}
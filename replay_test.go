@@ -0,0 +1,96 @@
+package goarpa_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplayServer answers the customers with the business ID and the transactions with the transaction ID
+func newReplayServer(businessID string, transactionID string, transactions *[]goarpa.CreateTransactionRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "PostBusiness") {
+			_, _ = w.Write([]byte(`{"data":{"BusinessID":"` + businessID + `","BusinessCode":"1001"}}`))
+			return
+		}
+		var transaction goarpa.CreateTransactionRequest
+		_ = json.NewDecoder(r.Body).Decode(&transaction)
+		*transactions = append(*transactions, transaction)
+		_, _ = w.Write([]byte(`{"data":[{"TransactionID":"` + transactionID + `","TransNumber":9}]}`))
+	}))
+}
+
+func Test_Replay(t *testing.T) {
+	t.Parallel()
+	var staged, replayed []goarpa.CreateTransactionRequest
+	staging := newReplayServer("7", "42", &staged)
+	defer staging.Close()
+	production := newReplayServer("70", "420", &replayed)
+	defer production.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := goarpa.NewFileAuditSink(path)
+	require.NoError(t, err)
+	source := goarpa.NewClient(staging.URL, goarpa.WithAuditSink(sink, nil), goarpa.WithAuditBodies())
+	ctx := context.Background()
+
+	customer, err := source.CreateCustomer(ctx, "token", nil, goarpa.CreateCustomerRequest{BusName: "Ali"})
+	require.NoError(t, err)
+	_, err = source.CreateTransaction(ctx, "token", goarpa.CreateTransactionRequest{
+		Data:  goarpa.Data{BusinessID: customer.Data.BusinessID, DepartmentID: 3, TransStateID: goarpa.TransStateDraft, FactorTypeID: goarpa.FactorTypeSale},
+		Items: []goarpa.TransactionItem{{ItemID: 5, Qty: 1}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	entries, err := goarpa.ReadAuditLog(file)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// the departments differ between the environments, the items are the same
+	mapping := goarpa.NewReplayMapping()
+	mapping.Set("DepartmentID", "3", "4")
+	target := goarpa.NewClient(production.URL)
+	results, err := target.Replay(ctx, "token", nil, entries, goarpa.ReplayOptions{Mapping: mapping})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, map[string]string{"BusinessID": "70", "BusinessCode": "1001"}, results[0].ResponseIDs)
+	assert.Equal(t, "420", results[1].ResponseIDs["TransactionID"])
+
+	require.Len(t, replayed, 1)
+	assert.Equal(t, goarpa.BusinessID(70), replayed[0].Data.BusinessID)
+	assert.Equal(t, int64(4), replayed[0].Data.DepartmentID)
+	assert.Equal(t, goarpa.ItemID(5), replayed[0].Items[0].ItemID)
+	transactionID, ok := mapping.Get("transactionid", "42")
+	assert.True(t, ok)
+	assert.Equal(t, "420", transactionID)
+}
+
+func Test_ReadDryRunLog(t *testing.T) {
+	t.Parallel()
+	log := `2024/01/02 10:00:00 goarpa: dry run: POST http://staging/arpa/serv/api/NewTransaction query=map[] header=map[Authorization:[<redacted>]] body={"Data":{"BusinessID":7}}
+2024/01/02 10:00:01 unrelated line
+`
+	entries, err := goarpa.ReadDryRunLog(strings.NewReader(log), "http://staging/arpa/")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, http.MethodPost, entries[0].Method)
+	assert.Equal(t, "/serv/api/NewTransaction", entries[0].Endpoint)
+	assert.JSONEq(t, `{"Data":{"BusinessID":7}}`, string(entries[0].Body))
+
+	_, err = goarpa.ReadDryRunLog(strings.NewReader("goarpa: dry run: POST http://staging/x query=map[] header=map[] body={"), "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,191 @@
+package goarpa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2/shared/constant"
+	"github.com/pkg/errors"
+)
+
+// ReportJobStatus is the state of an asynchronous report
+type ReportJobStatus string
+
+const (
+	ReportJobQueued  ReportJobStatus = "queued"
+	ReportJobRunning ReportJobStatus = "running"
+	ReportJobReady   ReportJobStatus = "ready"
+	ReportJobFailed  ReportJobStatus = "failed"
+)
+
+// maxReportPollInterval caps the backoff of WaitForReport
+const maxReportPollInterval = time.Minute
+
+// ErrReportFailed is matched by the error WaitForReport returns when Arpa could not generate the report
+var ErrReportFailed = errors.New("report failed")
+
+// ReportJobRequest asks Arpa to generate a report asynchronously
+type ReportJobRequest struct {
+	ReportName string            `json:"ReportName"`
+	Params     map[string]string `json:"Params,omitempty"`
+}
+
+// ReportJob is an asynchronous report
+type ReportJob struct {
+	JobID    string          `json:"JobID"`
+	Status   ReportJobStatus `json:"Status"`
+	Message  string          `json:"Message,omitempty"`
+	RowCount int             `json:"RowCount,omitempty"`
+}
+
+// Done reports whether the job is ready or failed
+func (j ReportJob) Done() bool {
+	return j.Status == ReportJobReady || j.Status == ReportJobFailed
+}
+
+// ReportJobResponse is the response of SubmitReportJob and GetReportJob
+type ReportJobResponse = APIResponse[ReportJob]
+
+// ReportJobParams select the job of GetReportJob, GetReportRows and OpenReport
+type ReportJobParams struct {
+	JobID string `json:"JobID"`
+}
+
+// SubmitReportJob asks Arpa to generate the report and returns its job, see WaitForReport.
+// In dry-run mode nothing is submitted and an empty job is returned.
+func (g *GoArpa) SubmitReportJob(ctx context.Context, accessToken string, cookie []*http.Cookie, request ReportJobRequest) (*ReportJob, error) {
+	const errMessage = "could not submit report job"
+
+	url, err := g.endpointURL(g.config().SubmitReportJobEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	body, err := marshalBody(request)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var response ReportJobResponse
+
+	req := g.GetRequestWithBearerAuthWithCookie(withReportTimeout(ctx), accessToken, cookie).
+		SetBody(body).
+		SetResult(&response)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &ReportJob{}, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "SubmitReportJob", url, body, resp, err, response.Error, func() map[string]string {
+		job, ok := response.First()
+		if !ok {
+			return nil
+		}
+		return map[string]string{"JobID": job.JobID}
+	})
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, response.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	job, ok := response.First()
+	if !ok {
+		return nil, errors.Wrap(errors.New("no job in response"), errMessage)
+	}
+	return &job, nil
+}
+
+// GetReportJob returns the current state of the job
+func (g *GoArpa) GetReportJob(ctx context.Context, accessToken string, cookie []*http.Cookie, jobID string) (*ReportJob, error) {
+	const errMessage = "could not get report job"
+
+	var response ReportJobResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetReportJobEndpoint, ReportJobParams{JobID: jobID}, &response, errMessage); err != nil {
+		return nil, err
+	}
+
+	job, ok := response.First()
+	if !ok {
+		return nil, errors.Wrap(errors.New("no job in response"), errMessage)
+	}
+	return &job, nil
+}
+
+// WaitForReport polls the job until it is ready or the context is done.
+// The interval between the polls starts at pollInterval and doubles up to a minute.
+// The error matches ErrReportFailed when Arpa could not generate the report.
+func (g *GoArpa) WaitForReport(ctx context.Context, accessToken string, cookie []*http.Cookie, jobID string, pollInterval time.Duration) (*ReportJob, error) {
+	return waitForReport(ctx, g.Clock(), g, accessToken, cookie, jobID, pollInterval)
+}
+
+func waitForReport(ctx context.Context, clock Clock, client GoArpaIface, accessToken string, cookie []*http.Cookie, jobID string, pollInterval time.Duration) (*ReportJob, error) {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	for {
+		job, err := client.GetReportJob(ctx, accessToken, cookie, jobID)
+		if err != nil {
+			return nil, err
+		}
+		switch job.Status {
+		case ReportJobReady:
+			return job, nil
+		case ReportJobFailed:
+			return job, fmt.Errorf("%w: job %s: %s", ErrReportFailed, jobID, job.Message)
+		}
+
+		timer := clock.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return job, ctx.Err()
+		case <-timer.C():
+		}
+		pollInterval = min(2*pollInterval, maxReportPollInterval)
+	}
+}
+
+// GetReportRows returns the rows of a ready report decoded as T
+func GetReportRows[T any](ctx context.Context, g *GoArpa, accessToken string, cookie []*http.Cookie, jobID string) ([]T, error) {
+	const errMessage = "could not get report rows"
+
+	var response APIResponse[T]
+	if err := g.getList(withReportTimeout(ctx), accessToken, cookie, g.config().GetReportResultEndpoint, ReportJobParams{JobID: jobID}, &response, errMessage); err != nil {
+		return nil, err
+	}
+	return []T(response.Data), nil
+}
+
+// OpenReport returns the content of a ready report, e.g. an exported spreadsheet, without buffering it.
+// The caller must close the returned reader.
+func (g *GoArpa) OpenReport(ctx context.Context, accessToken string, cookie []*http.Cookie, jobID string) (io.ReadCloser, error) {
+	const errMessage = "could not open report"
+
+	url, err := g.endpointURL(g.config().GetReportResultEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	resp, err := g.GetRequestWithBearerAuthWithCookie(context.WithValue(withReportTimeout(ctx), streamingContextKey, true), accessToken, cookie).
+		SetQueryParam(string(constant.JobIDKey), jobID).
+		SetDoNotParseResponse(true).
+		Get(url)
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		if resp != nil && resp.RawBody() != nil {
+			resp.RawBody().Close()
+		}
+		return nil, err
+	}
+	return resp.RawBody(), nil
+}
@@ -0,0 +1,103 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WaitForReport(t *testing.T) {
+	t.Parallel()
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/reports":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":{"JobID":"j1","Status":"queued"}}`))
+		case "/reports/status":
+			assert.Equal(t, "j1", r.URL.Query().Get("JobID"))
+			w.Header().Set("Content-Type", "application/json")
+			if polls.Add(1) < 3 {
+				_, _ = w.Write([]byte(`{"data":{"JobID":"j1","Status":"running"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"JobID":"j1","Status":"ready","RowCount":2}}`))
+		case "/reports/result":
+			if r.Header.Get("Accept") == "text/csv" {
+				_, _ = w.Write([]byte("code,qty\nPEN,2\n"))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":[{"code":"PEN","qty":2},{"code":"INK","qty":5}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.SubmitReportJobEndpoint = "reports"
+	client.Config.GetReportJobEndpoint = "reports/status"
+	client.Config.GetReportResultEndpoint = "reports/result"
+	ctx := context.Background()
+
+	job, err := client.SubmitReportJob(ctx, "token", nil, goarpa.ReportJobRequest{ReportName: "stock"})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.ReportJobQueued, job.Status)
+
+	job, err = client.WaitForReport(ctx, "token", nil, job.JobID, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.ReportJobReady, job.Status)
+	assert.Equal(t, int32(3), polls.Load())
+
+	type stockRow struct {
+		Code string `json:"code"`
+		Qty  int    `json:"qty"`
+	}
+	rows, err := goarpa.GetReportRows[stockRow](ctx, client, "token", nil, job.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, []stockRow{{"PEN", 2}, {"INK", 5}}, rows)
+
+	client.RestyClient().SetHeader("Accept", "text/csv")
+	body, err := client.OpenReport(ctx, "token", nil, job.JobID)
+	require.NoError(t, err)
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "code,qty\nPEN,2\n", string(content))
+}
+
+func Test_WaitForReportFailedAndCanceled(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("JobID") == "failed" {
+			_, _ = w.Write([]byte(`{"data":{"JobID":"failed","Status":"failed","Message":"fiscal year is closed"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"JobID":"slow","Status":"running"}}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetReportJobEndpoint = "reports/status"
+
+	_, err := client.WaitForReport(context.Background(), "token", nil, "failed", time.Millisecond)
+	require.True(t, errors.Is(err, goarpa.ErrReportFailed))
+	assert.Contains(t, err.Error(), "fiscal year is closed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.WaitForReport(ctx, "token", nil, "slow", time.Millisecond)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	_, err = goarpa.NewClient(server.URL).SubmitReportJob(context.Background(), "token", nil, goarpa.ReportJobRequest{ReportName: "stock"})
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+}
@@ -0,0 +1,120 @@
+package goarpa
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ReserveStockRequest holds a quantity of an item, e.g. during the payment of an online checkout
+type ReserveStockRequest struct {
+	ItemID    ItemID  `json:"ItemID"`
+	Qty       float64 `json:"Qty"`
+	Reference string  `json:"Reference,omitempty"`
+}
+
+// StockReservation is a quantity of an item held by ReserveStock until it is released or sold
+type StockReservation struct {
+	ReservationID string      `json:"ReservationID"`
+	ItemID        ItemID      `json:"ItemID"`
+	Qty           float64     `json:"Qty"`
+	Reference     string      `json:"Reference,omitempty"`
+	ExpireDate    *CustomTime `json:"ExpireDate,omitempty"`
+}
+
+// StockReservationResponse is the response of ReserveStock and ReleaseReservation
+type StockReservationResponse = APIResponse[StockReservation]
+
+// ReleaseReservationRequest releases a reservation of ReserveStock
+type ReleaseReservationRequest struct {
+	ReservationID string `json:"ReservationID"`
+}
+
+// ReserveStock holds the quantity of the item, so that it is not sold to another customer during the payment.
+// The reference, e.g. the order number, is stored with the reservation.
+// It returns ErrNotSupported when the installation has no reservation endpoint.
+func (g *GoArpa) ReserveStock(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, qty float64, reference string) (*StockReservation, error) {
+	const errMessage = "could not reserve stock"
+
+	url, err := g.endpointURL(g.config().ReserveStockEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+	if qty <= 0 {
+		return nil, errors.Wrap(errors.Errorf("invalid quantity %v", qty), errMessage)
+	}
+
+	body, err := marshalBody(ReserveStockRequest{ItemID: itemID, Qty: qty, Reference: reference})
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	var response StockReservationResponse
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody(body).
+		SetResult(&response)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return &StockReservation{ItemID: itemID, Qty: qty, Reference: reference}, nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "ReserveStock", url, body, resp, err, response.Error, func() map[string]string {
+		return map[string]string{"ItemID": itemID.String(), "Reference": reference}
+	})
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return nil, err
+	}
+
+	if err := g.checkForArpaError(resp, response.Error, errMessage); err != nil {
+		return nil, err
+	}
+
+	reservation, ok := response.First()
+	if !ok {
+		return nil, errors.Wrap(errors.New("no reservation in response"), errMessage)
+	}
+	return &reservation, nil
+}
+
+// ReleaseReservation returns the quantity held by the reservation to the stock, e.g. when the payment failed.
+// It returns ErrNotSupported when the installation has no reservation endpoint.
+func (g *GoArpa) ReleaseReservation(ctx context.Context, accessToken string, cookie []*http.Cookie, reservationID string) error {
+	const errMessage = "could not release reservation"
+
+	url, err := g.endpointURL(g.config().ReleaseReservationEndpoint)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+
+	body, err := marshalBody(ReleaseReservationRequest{ReservationID: reservationID})
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+
+	var response StockReservationResponse
+
+	req := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		SetBody(body).
+		SetResult(&response)
+
+	if g.dryRun {
+		logDryRun(req, http.MethodPost, url)
+		return nil
+	}
+
+	resp, err := req.Post(url)
+	g.audit(ctx, accessToken, "ReleaseReservation", url, body, resp, err, response.Error, func() map[string]string {
+		return map[string]string{"ReservationID": reservationID}
+	})
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return err
+	}
+
+	return g.checkForArpaError(resp, response.Error, errMessage)
+}
@@ -0,0 +1,66 @@
+package goarpa_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StockReservation(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var request map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if strings.HasSuffix(r.URL.Path, "/reserve") {
+			assert.Equal(t, map[string]interface{}{"ItemID": float64(7), "Qty": float64(2), "Reference": "order-1"}, request)
+			_, _ = w.Write([]byte(`{"data":[{"ReservationID":"R-1","ItemID":"7","Qty":2,"Reference":"order-1"}],"error":null}`))
+			return
+		}
+		assert.Equal(t, map[string]interface{}{"ReservationID": "R-1"}, request)
+		_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	ctx := context.Background()
+	_, err := client.ReserveStock(ctx, "token", nil, 7, 2, "order-1")
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+	assert.True(t, errors.Is(client.ReleaseReservation(ctx, "token", nil, "R-1"), goarpa.ErrNotSupported))
+
+	client.Config.ReserveStockEndpoint = "reserve"
+	client.Config.ReleaseReservationEndpoint = "release"
+	reservation, err := client.ReserveStock(ctx, "token", nil, 7, 2, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, "R-1", reservation.ReservationID)
+	assert.Equal(t, goarpa.ItemID(7), reservation.ItemID)
+	require.NoError(t, client.ReleaseReservation(ctx, "token", nil, reservation.ReservationID))
+}
+
+func Test_SimulatedStockReservation(t *testing.T) {
+	t.Parallel()
+	simulated := goarpa.NewSimulatedClient()
+	pen := simulated.AddItem(goarpa.GetServiceResponse{ItemCode: "PEN", Qty: "5"})
+	ctx := context.Background()
+
+	reservation, err := simulated.ReserveStock(ctx, goarpa.SimulatedToken, nil, pen, 4, "order-1")
+	require.NoError(t, err)
+	stock, _ := simulated.Stock(pen)
+	assert.Equal(t, 1.0, stock)
+
+	_, err = simulated.ReserveStock(ctx, goarpa.SimulatedToken, nil, pen, 2, "order-2")
+	assert.ErrorContains(t, err, "insufficient stock")
+
+	require.NoError(t, simulated.ReleaseReservation(ctx, goarpa.SimulatedToken, nil, reservation.ReservationID))
+	stock, _ = simulated.Stock(pen)
+	assert.Equal(t, 5.0, stock)
+	assert.Error(t, simulated.ReleaseReservation(ctx, goarpa.SimulatedToken, nil, reservation.ReservationID))
+}
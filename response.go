@@ -0,0 +1,48 @@
+package goarpa
+
+import (
+	"encoding/json"
+)
+
+// APIResponse is the envelope Arpa wraps the results in.
+// Data is decoded from either a JSON array or a single JSON object.
+type APIResponse[T any] struct {
+	Data  ObjectOrArray[T] `json:"data"`
+	Error *ArpaError       `json:"error"`
+}
+
+// WithLenientEnvelope restores the former behavior of ignoring the error field of the responses
+// answered with a successful HTTP status. By default such a response fails the call with an *APIError
+// carrying the Arpa message, e.g. "invalid session", instead of returning empty data.
+func WithLenientEnvelope() func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.lenientEnvelope = true
+	}
+}
+
+// envelope is implemented by the response envelopes
+type envelope interface {
+	envelopeError() *ArpaError
+}
+
+func (r *APIResponse[T]) envelopeError() *ArpaError {
+	return r.Error
+}
+
+// First returns the first item of the data and false if there is none
+func (r *APIResponse[T]) First() (T, bool) {
+	if r == nil || len(r.Data) == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.Data[0], true
+}
+
+// DecodeAPIResponse decodes a raw Arpa response body
+func DecodeAPIResponse[T any](body []byte) (*APIResponse[T], error) {
+	var response APIResponse[T]
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,104 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DecodeAPIResponse(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]int{
+		`{"data":[{"TransactionID":1},{"TransactionID":2}],"error":null}`: 2,
+		`{"data":{"TransactionID":1},"error":null}`:                       1,
+		`{"data":null,"error":"invalid session"}`:                         0,
+		`{"data":[],"error":null}`:                                        0,
+	}
+	for body, count := range testCases {
+		response, err := goarpa.DecodeAPIResponse[goarpa.Datum]([]byte(body))
+		require.NoError(t, err, body)
+		assert.Len(t, response.Data, count, body)
+	}
+
+	response, err := goarpa.DecodeAPIResponse[goarpa.Datum]([]byte(`{"data":{"TransactionID":7}}`))
+	require.NoError(t, err)
+	first, ok := response.First()
+	assert.True(t, ok)
+	assert.Equal(t, goarpa.TransactionID(7), first.TransactionID)
+}
+
+func Test_ArpaError(t *testing.T) {
+	t.Parallel()
+	response, err := goarpa.DecodeAPIResponse[goarpa.Datum2]([]byte(`{"data":null,"error":"invalid session"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "invalid session", response.Error.Message)
+
+	response, err = goarpa.DecodeAPIResponse[goarpa.Datum2]([]byte(`{"data":null,"error":{"Code":12,"Message":"نشست نامعتبر","MessageEn":"invalid session"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "12", response.Error.Code)
+	assert.Equal(t, "نشست نامعتبر", response.Error.Message)
+	assert.Equal(t, "invalid session", response.Error.MessageEn)
+
+	response, err = goarpa.DecodeAPIResponse[goarpa.Datum2]([]byte(`{"data":[],"error":null}`))
+	require.NoError(t, err)
+	assert.False(t, response.Error.NotEmpty())
+}
+
+func Test_EnvelopeErrorFailsTheCall(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":null,"error":"fiscal year is closed"}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	_, err := client.GetCustomerByBusinessCode(context.Background(), "token", []*http.Cookie{{Name: "session", Value: "1"}}, "127013")
+	require.Error(t, err)
+
+	var apiErr *goarpa.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, goarpa.APIErrTypeArpa, apiErr.Type)
+	assert.Equal(t, "could not get customer info: fiscal year is closed", apiErr.Message)
+
+	_, _, err = client.GetAdminToken(context.Background(), "user", "pass")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "could not get token: fiscal year is closed", apiErr.Message)
+
+	_, err = client.CreateService(context.Background(), "token", goarpa.CreateServiceRequest{ServiceName: "Repair"})
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, goarpa.APIErrTypeArpa, apiErr.Type)
+
+	// the lenient client returns the empty data as before
+	lenient := goarpa.NewClient(server.URL, goarpa.WithLenientEnvelope())
+	response, err := lenient.GetCustomerByMobile(context.Background(), "token", nil, "09120000000")
+	require.NoError(t, err)
+	assert.Empty(t, response.Data)
+}
+
+func Test_SchemaDriftDetection(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"BusinessID":"1","BusinessName":"Test","Creation_Date":"2024-03-20 10:30:00","LoyaltyPoints":"12"},"error":null}`))
+	}))
+	defer server.Close()
+
+	var drifts []goarpa.SchemaDrift
+	client := goarpa.NewClient(server.URL, goarpa.WithSchemaDriftHandler(func(drift goarpa.SchemaDrift) {
+		drifts = append(drifts, drift)
+	}))
+	response, err := client.GetCustomerByBusinessCode(context.Background(), "token", []*http.Cookie{{Name: "session", Value: "1"}}, "1")
+	require.NoError(t, err)
+	require.Len(t, response.Data, 1)
+
+	require.Len(t, drifts, 1)
+	assert.Equal(t, goarpa.SchemaDriftUnknownField, drifts[0].Kind)
+	assert.Equal(t, "$.data.LoyaltyPoints", drifts[0].Path)
+}
@@ -0,0 +1,319 @@
+package goarpa
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var retryStateContextKey = &contextKey{"retryState"}
+
+// RetryInfo describes a single retry performed by the client
+type RetryInfo struct {
+	Method     string
+	URL        string
+	Attempt    int
+	StatusCode int
+	Err        error
+}
+
+// RetryHook is called every time a request is going to be retried
+type RetryHook func(info RetryInfo)
+
+// WithRetryHook registers a hook which is fired on every retry
+func WithRetryHook(hook RetryHook) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.retryHooks = append(g.retryHooks, hook)
+	}
+}
+
+// RetryPolicy decides whether the request may be retried by the retry settings of the resty client
+type RetryPolicy func(method string, url string) bool
+
+// DefaultRetryPolicy retries the reads only: a mutating call which failed may still have reached Arpa
+// and would be posted twice
+func DefaultRetryPolicy(method string, _ string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// WithRetryPolicy sets the requests retried by the client, DefaultRetryPolicy by default.
+// The policy is overridden per call by WithCallRetry.
+func WithRetryPolicy(policy RetryPolicy) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.retryPolicy = policy
+	}
+}
+
+// WithIdempotencyKeys sends a random Idempotency-Key header, kept across the retries, with every mutating request
+// so that the server can drop the duplicates. The mutating requests are then retried like the reads.
+// The key of a request without an outcome, e.g. after a timeout, is reused once when the same request is sent again
+// within IdempotencyKeyTTL, including by a client which imported the state of this one, see ExportState.
+// The requests in flight at the same time never share a key.
+func WithIdempotencyKeys() func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.idempotencyKeys = true
+	}
+}
+
+var callRetryContextKey = &contextKey{"callRetry"}
+
+// WithCallRetry enables or disables the retries of the calls made with the context, whatever the retry policy
+func WithCallRetry(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, callRetryContextKey, enabled)
+}
+
+// errRetryDisabled is the cause of the end of the context of a request which must not be retried
+var errRetryDisabled = errors.New("retry disabled")
+
+// retryState keeps track of the retries of a single request
+type retryState struct {
+	mu      sync.Mutex
+	wait    time.Duration
+	retryAt time.Time
+	lastErr error
+	// start is the time of the first attempt, see ClientStats
+	start time.Time
+	// idempotencyKey is the key of the mutating request, settled when the request ends
+	idempotencyKey string
+	// stop ends the context of a request which must not be retried after its first attempt,
+	// resty does not retry a request whose context is done
+	stop context.CancelCauseFunc
+}
+
+// stopFunc returns the function ending the request after its attempt, nil if it may be retried
+func (s *retryState) stopFunc() context.CancelCauseFunc {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stop
+}
+
+// retryContext carries the retry state of a request, allocating the context and the state at once
+type retryContext struct {
+	context.Context
+	state retryState
+}
+
+func (c *retryContext) Value(key interface{}) interface{} {
+	if key == retryStateContextKey {
+		return &c.state
+	}
+	return c.Context.Value(key)
+}
+
+func withRetryState(ctx context.Context) context.Context {
+	return &retryContext{Context: ctx}
+}
+
+func retryStateFromRequest(req *resty.Request) *retryState {
+	if req == nil {
+		return nil
+	}
+	state, _ := req.Context().Value(retryStateContextKey).(*retryState)
+	return state
+}
+
+func (g *GoArpa) beforeRequest(_ *resty.Client, req *resty.Request) error {
+	state := retryStateFromRequest(req)
+	if state == nil {
+		return nil
+	}
+
+	if req.Attempt <= 1 {
+		g.applyRetryPolicy(req, state)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	now := g.Clock().Now()
+	if state.start.IsZero() {
+		state.start = now
+	}
+	if !state.retryAt.IsZero() {
+		state.wait += now.Sub(state.retryAt)
+		state.retryAt = time.Time{}
+	}
+	return nil
+}
+
+// applyRetryPolicy sends the idempotency key of a mutating request and decides whether it is retried
+func (g *GoArpa) applyRetryPolicy(req *resty.Request, state *retryState) {
+	mutating := !DefaultRetryPolicy(req.Method, req.URL)
+	if mutating && g.idempotencyKeys && req.Header.Get("Idempotency-Key") == "" {
+		key := g.state.idempotencyKey(requestFingerprint(req), g.Clock().Now())
+		req.SetHeader("Idempotency-Key", key)
+		state.mu.Lock()
+		state.idempotencyKey = key
+		state.mu.Unlock()
+	}
+
+	var retry bool
+	switch enabled, ok := req.Context().Value(callRetryContextKey).(bool); {
+	case ok:
+		retry = enabled
+	case g.retryPolicy != nil:
+		retry = g.retryPolicy(req.Method, req.URL)
+	default:
+		retry = !mutating || g.idempotencyKeys
+	}
+	if !retry {
+		ctx, stop := context.WithCancelCause(req.Context())
+		req.SetContext(ctx)
+		state.mu.Lock()
+		state.stop = stop
+		state.mu.Unlock()
+	}
+}
+
+// singleAttemptTransport ends the context of a request which must not be retried once its attempt is over:
+// when the body of the response is closed, or on a transport error. The redirects are followed first,
+// their last response ends the request in stopAfterResponse.
+type singleAttemptTransport struct {
+	next http.RoundTripper
+}
+
+// wrapSingleAttemptTransport installs the end of the requests which must not be retried, inside the charset conversion
+func wrapSingleAttemptTransport(restyClient *resty.Client) {
+	charset, ok := restyClient.GetClient().Transport.(*charsetTransport)
+	if !ok {
+		return
+	}
+	if _, ok := charset.next.(*singleAttemptTransport); ok {
+		return
+	}
+	charset.next = &singleAttemptTransport{next: charset.next}
+}
+
+func (t *singleAttemptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	state, _ := req.Context().Value(retryStateContextKey).(*retryState)
+	stop := state.stopFunc()
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case stop == nil:
+	case err != nil || resp.Body == nil:
+		stop(errRetryDisabled)
+	case resp.StatusCode < http.StatusMultipleChoices || resp.StatusCode >= http.StatusBadRequest || resp.Header.Get("Location") == "":
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: func() { stop(errRetryDisabled) }}
+	}
+	return resp, err
+}
+
+// stopAfterResponse ends the context of a request which must not be retried, e.g. after a redirect
+// which was not followed
+func (g *GoArpa) stopAfterResponse(_ *resty.Client, resp *resty.Response) error {
+	if stop := retryStateFromRequest(resp.Request).stopFunc(); stop != nil {
+		stop(errRetryDisabled)
+	}
+	return nil
+}
+
+// onRequestSuccess ends a request answered by Arpa. Its outcome is unknown when the server failed:
+// the same request sent again, e.g. by a restarted worker, reuses its idempotency key.
+func (g *GoArpa) onRequestSuccess(_ *resty.Client, resp *resty.Response) {
+	g.endRequest(resp.Request, resp.StatusCode() < http.StatusInternalServerError)
+}
+
+// onRequestError ends a request which failed, its outcome is unknown unless Arpa answered it with a client error
+func (g *GoArpa) onRequestError(req *resty.Request, err error) {
+	var respErr *resty.ResponseError
+	answered := errors.As(err, &respErr) && respErr.Response != nil &&
+		respErr.Response.StatusCode() != 0 && respErr.Response.StatusCode() < http.StatusInternalServerError
+	g.endRequest(req, answered)
+}
+
+// endRequest releases the context of a request which was not retried and settles its idempotency key
+func (g *GoArpa) endRequest(req *resty.Request, outcome bool) {
+	state := retryStateFromRequest(req)
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	stop, key := state.stop, state.idempotencyKey
+	state.mu.Unlock()
+	if stop != nil {
+		stop(errRetryDisabled)
+	}
+	if key != "" {
+		g.state.settleIdempotencyKey(key, outcome)
+	}
+}
+
+func newIdempotencyKey() string {
+	var key [16]byte
+	_, _ = rand.Read(key[:])
+	return hex.EncodeToString(key[:])
+}
+
+// onRetry records the failed attempt and fires the retry hooks. Resty also calls it after the last attempt,
+// which is not retried: the hooks are not fired for it.
+func (g *GoArpa) onRetry(resp *resty.Response, err error) {
+	info := RetryInfo{Err: err}
+	if resp != nil && resp.Request != nil {
+		info.Method = resp.Request.Method
+		info.URL = resp.Request.URL
+		info.Attempt = resp.Request.Attempt
+		info.StatusCode = resp.StatusCode()
+
+		if info.Err == nil && resp.IsError() {
+			info.Err = errors.New(resp.Status())
+		}
+
+		if state := retryStateFromRequest(resp.Request); state != nil {
+			state.mu.Lock()
+			state.retryAt = g.Clock().Now()
+			state.lastErr = info.Err
+			state.mu.Unlock()
+		}
+	}
+
+	if info.Attempt > g.RestyClient().RetryCount {
+		return
+	}
+	for _, hook := range g.retryHooks {
+		hook(info)
+	}
+}
+
+// isContextError reports whether the error is the error of a done context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// retryCanceledError is returned when the context is done while waiting to retry a call,
+// it matches the error of the context and tells the error of the last attempt
+func retryCanceledError(ctx context.Context, lastErr error, attempts int) *APIError {
+	return &APIError{
+		Message:  fmt.Sprintf("%s while waiting to retry: %s", ctx.Err(), lastErr),
+		Type:     APIErrTypeUnknown,
+		Attempts: attempts,
+		LastErr:  ctx.Err(),
+	}
+}
+
+// withRetryInfo adds the retry details of the response to the API error.
+// The error of a context done during a retry wait is kept, see isContextError.
+func withRetryInfo(apiErr *APIError, resp *resty.Response) *APIError {
+	if resp == nil || resp.Request == nil {
+		return apiErr
+	}
+
+	apiErr.Attempts = resp.Request.Attempt
+	if state := retryStateFromRequest(resp.Request); state != nil {
+		state.mu.Lock()
+		apiErr.RetryWait = state.wait
+		if state.lastErr != nil && !isContextError(apiErr.LastErr) {
+			apiErr.LastErr = state.lastErr
+		}
+		state.mu.Unlock()
+	}
+	return apiErr
+}
@@ -0,0 +1,192 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RetryInfoInAPIError(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var retries []goarpa.RetryInfo
+	client := goarpa.NewClient(server.URL, goarpa.WithRetryHook(func(info goarpa.RetryInfo) {
+		retries = append(retries, info)
+	}))
+	client.RestyClient().
+		SetRetryCount(2).
+		SetRetryWaitTime(10 * time.Millisecond).
+		SetRetryMaxWaitTime(20 * time.Millisecond).
+		AddRetryCondition(func(r *resty.Response, err error) bool {
+			return r != nil && r.StatusCode() == http.StatusBadGateway
+		})
+
+	_, _, err := client.GetAdminToken(context.Background(), "user", "pass")
+	require.Error(t, err)
+
+	var apiErr *goarpa.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.Code)
+	assert.Equal(t, 3, apiErr.Attempts)
+	assert.Greater(t, apiErr.RetryWait, time.Duration(0))
+	assert.Error(t, apiErr.LastErr)
+	// the last attempt is not retried
+	require.Len(t, retries, 2)
+	assert.Equal(t, 1, retries[0].Attempt)
+	assert.Equal(t, 2, retries[1].Attempt)
+	assert.Equal(t, http.StatusBadGateway, retries[0].StatusCode)
+}
+
+func Test_RetryPolicy(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.Method]++
+		if key := r.Header.Get("Idempotency-Key"); key != "" {
+			keys = append(keys, key)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	newClient := func(options ...func(*goarpa.GoArpa)) *goarpa.GoArpa {
+		client := goarpa.NewClient(server.URL, options...)
+		client.RestyClient().
+			SetRetryCount(2).
+			SetRetryWaitTime(time.Millisecond).
+			AddRetryCondition(func(r *resty.Response, err error) bool {
+				return r != nil && r.StatusCode() == http.StatusBadGateway
+			})
+		return client
+	}
+	count := func(method string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		n := calls[method]
+		calls[method] = 0
+		return n
+	}
+	ctx := context.Background()
+	request := goarpa.CreateServiceRequest{ServiceName: "Repair"}
+
+	// the reads are retried, the mutating calls are not
+	client := newClient()
+	_, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.Error(t, err)
+	assert.Equal(t, 3, count(http.MethodGet))
+	_, err = client.CreateService(ctx, "token", request)
+	var apiErr *goarpa.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.Code)
+	assert.Equal(t, 1, apiErr.Attempts)
+	assert.Equal(t, 1, count(http.MethodPost))
+
+	// per call
+	_, err = client.CreateService(goarpa.WithCallRetry(ctx, true), "token", request)
+	require.Error(t, err)
+	assert.Equal(t, 3, count(http.MethodPost))
+	_, err = client.GetCustomerByMobile(goarpa.WithCallRetry(ctx, false), "token", nil, "09120000000")
+	require.Error(t, err)
+	assert.Equal(t, 1, count(http.MethodGet))
+
+	// per policy
+	client = newClient(goarpa.WithRetryPolicy(func(method string, url string) bool { return false }))
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	require.Error(t, err)
+	assert.Equal(t, 1, count(http.MethodGet))
+
+	// with idempotency keys, the key is the same for every attempt
+	client = newClient(goarpa.WithIdempotencyKeys())
+	_, err = client.CreateService(ctx, "token", request)
+	require.Error(t, err)
+	assert.Equal(t, 3, count(http.MethodPost))
+	require.Len(t, keys, 3)
+	assert.Equal(t, keys[0], keys[2])
+}
+
+func Test_RetryPolicyTransportError(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.RestyClient().
+		SetRetryCount(2).
+		SetRetryWaitTime(time.Millisecond).
+		AddRetryCondition(func(r *resty.Response, err error) bool {
+			return err != nil
+		})
+
+	// the mutating call which may have reached Arpa is not retried, its error is the error of the transport
+	_, err := client.CreateService(context.Background(), "token", goarpa.CreateServiceRequest{ServiceName: "Repair"})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, int32(1), calls.Swap(0))
+
+	_, err = client.GetCustomerByMobile(context.Background(), "token", nil, "09120000000")
+	require.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func Test_RetryWaitCanceled(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.RestyClient().
+		SetRetryCount(3).
+		SetRetryWaitTime(10 * time.Second).
+		SetRetryMaxWaitTime(10 * time.Second).
+		AddRetryCondition(func(r *resty.Response, err error) bool {
+			return r != nil && r.StatusCode() == http.StatusBadGateway
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	var apiErr *goarpa.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 1, apiErr.Attempts)
+
+	// the waits of the bulk calls are canceled as well
+	bulk := goarpa.NewBulk(func(ctx context.Context, mobile string) (*goarpa.GetCustomerResponse, error) {
+		return nil, &goarpa.APIError{Code: http.StatusBadGateway, Message: "502 Bad Gateway"}
+	}, goarpa.BulkOptions{Retries: 3, RetryWait: 10 * time.Second})
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start = time.Now()
+	_, err = bulk.Execute(ctx, []string{"09120000000"})
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
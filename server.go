@@ -0,0 +1,177 @@
+package goarpa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotSupportedByServer is matched by the errors of the operations which the detected Arpa server
+// does not support, see GetServerInfo. The errors also match ErrNotSupported.
+var ErrNotSupportedByServer = errors.New("operation is not supported by the server")
+
+// ServerInfo is the version and the enabled modules of an Arpa server
+type ServerInfo struct {
+	Version string   `json:"Version"`
+	Modules []string `json:"Modules"`
+}
+
+// ServerInfoResponse is the response of GetServerInfo
+type ServerInfoResponse = APIResponse[ServerInfo]
+
+// HasModule reports whether the module is enabled, the names are compared case insensitively
+func (s ServerInfo) HasModule(module string) bool {
+	for _, enabled := range s.Modules {
+		if strings.EqualFold(enabled, module) {
+			return true
+		}
+	}
+	return false
+}
+
+// AtLeast reports whether the version of the server is the given version or a newer one, e.g. AtLeast("4.2")
+func (s ServerInfo) AtLeast(version string) bool {
+	return compareVersions(s.Version, version) >= 0
+}
+
+// Capability is what an operation requires from the server, the zero fields require nothing
+type Capability struct {
+	// MinVersion is the first version of Arpa with the operation
+	MinVersion string
+	// Module is the module of Arpa the operation belongs to
+	Module string
+}
+
+// DefaultCapabilities are the requirements of the operations, by method name, checked once the server is known
+var DefaultCapabilities = map[string]Capability{
+	"OpenShift":       {Module: "POS"},
+	"GetShiftSummary": {Module: "POS"},
+	"CloseShift":      {Module: "POS"},
+}
+
+// WithCapabilities replaces DefaultCapabilities, e.g. to add the minimum versions of an installation
+func WithCapabilities(capabilities map[string]Capability) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.capabilities = capabilities
+	}
+}
+
+// ServerCapabilityError is returned instead of calling an operation which the detected server does not support
+type ServerCapabilityError struct {
+	Operation  string
+	Version    string
+	Capability Capability
+}
+
+// Error stringifies the ServerCapabilityError
+func (e *ServerCapabilityError) Error() string {
+	if e.Capability.Module != "" {
+		return fmt.Sprintf("%s: %s requires the %s module, Arpa %s", ErrNotSupportedByServer, e.Operation, e.Capability.Module, e.Version)
+	}
+	return fmt.Sprintf("%s: %s requires Arpa %s, the server is %s", ErrNotSupportedByServer, e.Operation, e.Capability.MinVersion, e.Version)
+}
+
+// Is allows matching the error with ErrNotSupportedByServer and ErrNotSupported
+func (e *ServerCapabilityError) Is(target error) bool {
+	return target == ErrNotSupportedByServer || target == ErrNotSupported
+}
+
+// GetServerInfo returns the version and the enabled modules of the server.
+// The client remembers them: afterwards the operations the server does not support fail
+// with ErrNotSupportedByServer instead of calling it, see DefaultCapabilities.
+func (g *GoArpa) GetServerInfo(ctx context.Context, accessToken string, cookie []*http.Cookie) (*ServerInfo, error) {
+	const errMessage = "could not get server info"
+
+	var response ServerInfoResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetServerInfoEndpoint, nil, &response, errMessage); err != nil {
+		return nil, err
+	}
+
+	info, ok := response.First()
+	if !ok {
+		return nil, errors.Wrap(errors.New("no server info in response"), errMessage)
+	}
+	g.server.Store(g.detectServer(info, g.config()))
+	return &info, nil
+}
+
+// detectedServer is the server detected by GetServerInfo
+type detectedServer struct {
+	info ServerInfo
+	// unsupported are the errors of the operations the server does not support, by endpoint
+	unsupported map[string]*ServerCapabilityError
+}
+
+// detectServer maps the endpoints of the config to the operations which the server does not support
+func (g *GoArpa) detectServer(info ServerInfo, config ClientConfig) *detectedServer {
+	capabilities := g.capabilities
+	if capabilities == nil {
+		capabilities = DefaultCapabilities
+	}
+
+	server := &detectedServer{info: info, unsupported: make(map[string]*ServerCapabilityError)}
+	endpoints := reflect.ValueOf(config)
+	for operation, capability := range capabilities {
+		field := endpoints.FieldByName(operation + "Endpoint")
+		if !field.IsValid() || field.String() == "" {
+			continue
+		}
+		if (capability.Module != "" && !info.HasModule(capability.Module)) ||
+			(capability.MinVersion != "" && !info.AtLeast(capability.MinVersion)) {
+			server.unsupported[field.String()] = &ServerCapabilityError{Operation: operation, Version: info.Version, Capability: capability}
+		}
+	}
+	return server
+}
+
+// checkCapability returns a *ServerCapabilityError if the endpoint belongs to an operation
+// which the detected server does not support. Nothing is checked until the server is known.
+func (g *GoArpa) checkCapability(endpoint string) error {
+	server := g.server.Load()
+	if server == nil {
+		return nil
+	}
+	if err, ok := server.unsupported[endpoint]; ok {
+		copied := *err
+		return &copied
+	}
+	return nil
+}
+
+// compareVersions compares dotted versions part by part, numerically when both parts are numbers
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(defaultString(x, "0"))
+		yn, yerr := strconv.Atoi(defaultString(y, "0"))
+		switch {
+		case xerr == nil && yerr == nil && xn != yn:
+			if xn < yn {
+				return -1
+			}
+			return 1
+		case (xerr != nil || yerr != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,74 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServerCapabilities(t *testing.T) {
+	t.Parallel()
+	var shifts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/info") {
+			_, _ = w.Write([]byte(`{"data":[{"Version":"4.1.3","Modules":["Accounting","Inventory"]}],"error":null}`))
+			return
+		}
+		shifts.Add(1)
+		_, _ = w.Write([]byte(`{"data":[{"ShiftID":"1"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithCapabilities(map[string]goarpa.Capability{
+		"OpenShift":    {Module: "POS"},
+		"ReserveStock": {MinVersion: "4.2"},
+	}))
+	client.Config.GetServerInfoEndpoint = "info"
+	client.Config.OpenShiftEndpoint = "shift"
+	client.Config.ReserveStockEndpoint = "reserve"
+	ctx := context.Background()
+
+	// nothing is gated until the server is known
+	_, err := client.OpenShift(ctx, "token", nil, goarpa.OpenShiftRequest{})
+	require.NoError(t, err)
+
+	info, err := client.GetServerInfo(ctx, "token", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "4.1.3", info.Version)
+	assert.True(t, info.HasModule("inventory"))
+
+	_, err = client.OpenShift(ctx, "token", nil, goarpa.OpenShiftRequest{})
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupportedByServer))
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+	assert.ErrorContains(t, err, "OpenShift requires the POS module, Arpa 4.1.3")
+
+	_, err = client.ReserveStock(ctx, "token", nil, 7, 1, "")
+	assert.ErrorContains(t, err, "ReserveStock requires Arpa 4.2, the server is 4.1.3")
+	assert.Equal(t, int32(1), shifts.Load())
+
+	// the operations are still gated after their endpoints change
+	client.UpdateConfig(func(config *goarpa.ClientConfig) {
+		config.OpenShiftEndpoint = "pos/shift"
+	})
+	_, err = client.OpenShift(ctx, "token", nil, goarpa.OpenShiftRequest{})
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupportedByServer))
+	assert.Equal(t, int32(1), shifts.Load())
+}
+
+func Test_ServerInfoAtLeast(t *testing.T) {
+	t.Parallel()
+	info := goarpa.ServerInfo{Version: "4.10"}
+	assert.True(t, info.AtLeast("4.2"))
+	assert.True(t, info.AtLeast("4.10.0"))
+	assert.False(t, info.AtLeast("4.10.1"))
+	assert.False(t, info.AtLeast("5"))
+}
@@ -0,0 +1,129 @@
+package goarpa
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// sessionExpiredMessages are the parts of the messages Arpa answers the calls of an expired session with,
+// they are compared case-insensitively
+var sessionExpiredMessages = []string{
+	"invalid session",
+	"session expired",
+	"session has expired",
+	"token expired",
+	"token has expired",
+	"نشست نامعتبر",
+	"نشست منقضی",
+}
+
+// isSessionExpiredMessage reports whether the envelope error tells that the session expired
+func isSessionExpiredMessage(arpaErr *ArpaError) bool {
+	if !arpaErr.NotEmpty() {
+		return false
+	}
+	for _, message := range []string{arpaErr.Message, arpaErr.MessageEn} {
+		message = strings.ToLower(message)
+		for _, expired := range sessionExpiredMessages {
+			if strings.Contains(message, expired) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isLoginRedirect reports whether Arpa redirected the request to its login page, which it does instead
+// of answering 401 when the cookies of the session expired. The redirect is either followed by the client,
+// the response being the login page, or returned as is when the redirects are disabled.
+func isLoginRedirect(resp *resty.Response) bool {
+	raw := resp.RawResponse
+	if raw == nil {
+		return false
+	}
+	if raw.StatusCode >= 300 && raw.StatusCode < 400 {
+		return isLoginPath(raw.Header.Get("Location"))
+	}
+	// the request of the response was made to follow a redirect
+	return raw.Request != nil && raw.Request.Response != nil && isLoginPath(raw.Request.URL.Path)
+}
+
+func isLoginPath(path string) bool {
+	path = strings.ToLower(path)
+	return strings.Contains(path, "login") || strings.Contains(path, "signin")
+}
+
+// managedSession returns the token manager whose session has the access token, nil for the other tokens
+func (g *GoArpa) managedSession(token string) *TokenManager {
+	if token == "" {
+		return nil
+	}
+	manager, ok := g.managedSessions.Load(token)
+	if !ok {
+		return nil
+	}
+	return manager.(*TokenManager)
+}
+
+// applyManagedSession sends the current token and cookies of the managed session of the request,
+// which Arpa may have rotated since the caller got them, e.g. in the middle of a batch
+func (g *GoArpa) applyManagedSession(_ *resty.Client, req *resty.Request) error {
+	manager := g.managedSession(req.Token)
+	if manager == nil {
+		return nil
+	}
+	token, cookies := manager.current()
+	if token != "" {
+		req.Token = token
+	}
+	req.Cookies = mergeCookies(req.Cookies, cookies)
+	return nil
+}
+
+// captureManagedSession updates the managed session of the request with the cookies set by the response
+// and with the token of the TokenHeader of its manager
+func (g *GoArpa) captureManagedSession(_ *resty.Client, resp *resty.Response) error {
+	manager := g.managedSession(resp.Request.Token)
+	if manager == nil {
+		return nil
+	}
+	var token string
+	if manager.options.TokenHeader != "" {
+		token = strings.Trim(strings.TrimSpace(resp.Header().Get(manager.options.TokenHeader)), `"`)
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 || token != "" {
+		manager.roll(resp.Request.Token, token, cookies)
+	}
+	return nil
+}
+
+// mergeCookies returns the cookies with the updates replacing the cookies of the same name
+// and the updates which expire removing them
+func mergeCookies(cookies []*http.Cookie, updates []*http.Cookie) []*http.Cookie {
+	if len(updates) == 0 {
+		return cookies
+	}
+	merged := make([]*http.Cookie, 0, len(cookies)+len(updates))
+	for _, cookie := range cookies {
+		if !hasCookie(updates, cookie.Name) {
+			merged = append(merged, cookie)
+		}
+	}
+	for _, cookie := range updates {
+		if cookie.MaxAge >= 0 && cookie.Value != "" {
+			merged = append(merged, cookie)
+		}
+	}
+	return merged
+}
+
+func hasCookie(cookies []*http.Cookie, name string) bool {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SessionExpired(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Account/Login":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><form>login</form></html>"))
+		case "/redirect":
+			http.Redirect(w, r, "/Account/Login?ReturnUrl=%2Fserv", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":null,"error":{"Code":"12","Message":"نشست نامعتبر است","MessageEn":"Invalid session"}}`))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	client := goarpa.NewClient(server.URL)
+	_, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	assert.True(t, errors.Is(err, goarpa.ErrSessionExpired), err)
+	var apiErr *goarpa.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, goarpa.APIErrTypeSessionExpired, apiErr.Type)
+	assert.Equal(t, "12", apiErr.Arpa.Code)
+
+	// the redirect to the login page is followed
+	client.Config.GetCustomerEndpoint = "redirect"
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	assert.True(t, errors.Is(err, goarpa.ErrSessionExpired), err)
+
+	// or returned as is
+	client.SetRestyClient(resty.New().SetRedirectPolicy(resty.NoRedirectPolicy()))
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	assert.True(t, errors.Is(err, goarpa.ErrSessionExpired), err)
+
+	// the other envelope errors are not session errors
+	assert.False(t, errors.Is(&goarpa.APIError{Type: goarpa.APIErrTypeArpa}, goarpa.ErrSessionExpired))
+}
@@ -0,0 +1,242 @@
+package goarpa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SessionSource returns a valid access token with the cookies of its session, it is implemented by *TokenManager
+type SessionSource interface {
+	Session(ctx context.Context) (string, []*http.Cookie, error)
+}
+
+// SetupOptions configure ValidateSetup
+type SetupOptions struct {
+	// BusinessID is the business of the draft transaction, the first business read by default
+	BusinessID BusinessID
+	// Items are the lines of the draft transaction, the transaction is not posted when there are none
+	Items []TransactionItem
+}
+
+// SetupCheckStatus is the outcome of a check of ValidateSetup
+type SetupCheckStatus string
+
+const (
+	// SetupCheckPassed is a check which succeeded
+	SetupCheckPassed SetupCheckStatus = "passed"
+	// SetupCheckFailed is a check which failed, the installation is not ready
+	SetupCheckFailed SetupCheckStatus = "failed"
+	// SetupCheckSkipped is a check which could not run, e.g. because its endpoint is not configured
+	SetupCheckSkipped SetupCheckStatus = "skipped"
+)
+
+// SetupCheck is a check of ValidateSetup
+type SetupCheck struct {
+	Name   string           `json:"name"`
+	Status SetupCheckStatus `json:"status"`
+	// Detail tells why the check failed or was skipped
+	Detail string `json:"detail,omitempty"`
+}
+
+// SetupReport is the readiness of an installation, see ValidateSetup
+type SetupReport struct {
+	Checks []SetupCheck `json:"checks"`
+}
+
+// Ready reports whether no check failed
+func (r *SetupReport) Ready() bool {
+	return r.Err() == nil
+}
+
+// Err returns an error listing the failed checks, nil when the installation is ready
+func (r *SetupReport) Err() error {
+	var failed []string
+	for _, check := range r.Checks {
+		if check.Status == SetupCheckFailed {
+			failed = append(failed, check.Name+": "+check.Detail)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.New("setup is not ready: " + strings.Join(failed, ", "))
+}
+
+// String returns a line per check
+func (r *SetupReport) String() string {
+	var res strings.Builder
+	for _, check := range r.Checks {
+		fmt.Fprintf(&res, "%-8s %s", check.Status, check.Name)
+		if check.Detail != "" {
+			fmt.Fprintf(&res, ": %s", check.Detail)
+		}
+		res.WriteString("\n")
+	}
+	return res.String()
+}
+
+func (r *SetupReport) add(name string, status SetupCheckStatus, detail string) {
+	r.Checks = append(r.Checks, SetupCheck{Name: name, Status: status, Detail: detail})
+}
+
+// addResult adds a check passed when err is nil, skipped when its endpoint is not configured
+func (r *SetupReport) addResult(name string, err error) {
+	switch {
+	case err == nil:
+		r.add(name, SetupCheckPassed, "")
+	case errors.Is(err, ErrNotSupported):
+		r.add(name, SetupCheckSkipped, err.Error())
+	default:
+		r.add(name, SetupCheckFailed, err.Error())
+	}
+}
+
+// ValidateSetup checks that a new deployment is ready: the service account logs in, the configured endpoints
+// respond, the account can read the businesses and post a draft transaction, which is voided afterwards,
+// and the document aliases and the settlements exist, including those of the installation profile.
+// The checks which cannot run are skipped, e.g. the draft transaction when VoidTransactionEndpoint is not
+// configured. The report is returned even when checks failed, see SetupReport.Err.
+func (g *GoArpa) ValidateSetup(ctx context.Context, session SessionSource, options SetupOptions) *SetupReport {
+	report := &SetupReport{}
+
+	accessToken, cookie, err := session.Session(ctx)
+	report.addResult("login", err)
+	if err != nil {
+		return report
+	}
+
+	for _, endpoint := range g.configuredEndpoints() {
+		report.addResult("endpoint "+endpoint.endpoint, g.probeEndpoint(ctx, accessToken, cookie, endpoint))
+	}
+
+	businessID, err := g.setupReadCustomers(ctx, accessToken, cookie)
+	report.addResult("read customers", err)
+	if options.BusinessID != 0 {
+		businessID = options.BusinessID
+	}
+	g.setupDraftTransaction(ctx, report, accessToken, cookie, businessID, options.Items)
+
+	docAliases, err := g.GetDocAliases(ctx, accessToken, cookie)
+	if err == nil && len(docAliases.Data) == 0 {
+		err = errors.New("no document alias is defined")
+	}
+	report.addResult("document aliases", err)
+
+	settlements, err := g.GetSettlements(ctx, accessToken, cookie)
+	if err == nil && len(settlements.Data) == 0 {
+		err = errors.New("no settlement is defined")
+	}
+	report.addResult("settlements", err)
+
+	if g.profile == (InstallationProfile{}) {
+		report.add("installation profile", SetupCheckSkipped, "no installation profile")
+	} else {
+		report.addResult("installation profile", g.ValidateInstallationProfile(ctx, accessToken, cookie))
+	}
+	return report
+}
+
+// configuredEndpoint is an endpoint of the client and whether it only reads
+type configuredEndpoint struct {
+	endpoint string
+	read     bool
+}
+
+// configuredEndpoints returns the configured endpoints of the client but the login, once each in the order of ClientConfig.
+// The endpoints of the Get operations are the reads.
+func (g *GoArpa) configuredEndpoints() []configuredEndpoint {
+	config := reflect.ValueOf(g.config())
+	var endpoints []configuredEndpoint
+	seen := make(map[string]bool)
+	for _, field := range reflect.VisibleFields(config.Type()) {
+		if field.Anonymous || field.Type.Kind() != reflect.String || field.Name == "GetServiceTokenEndpoint" {
+			continue
+		}
+		if endpoint := config.FieldByIndex(field.Index).String(); endpoint != "" && !seen[endpoint] {
+			seen[endpoint] = true
+			endpoints = append(endpoints, configuredEndpoint{endpoint: endpoint, read: strings.HasPrefix(field.Name, "Get")})
+		}
+	}
+	return endpoints
+}
+
+// probeEndpoint checks that the endpoint exists. The reads are probed with a GET without params, the other
+// endpoints with an OPTIONS which changes nothing. Only a missing endpoint or a failed call fails the probe,
+// e.g. an endpoint answering 405 exists.
+func (g *GoArpa) probeEndpoint(ctx context.Context, accessToken string, cookie []*http.Cookie, endpoint configuredEndpoint) error {
+	method := http.MethodOptions
+	if endpoint.read {
+		method = http.MethodGet
+	}
+	resp, err := g.GetRequestWithBearerAuthWithCookie(ctx, accessToken, cookie).
+		Execute(method, g.url(endpoint.endpoint))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return errors.New("not found")
+	}
+	if isLoginRedirect(resp) {
+		return ErrSessionExpired
+	}
+	return nil
+}
+
+// setupReadCustomers reads a business, with GetCustomers when it is configured, and returns its ID
+func (g *GoArpa) setupReadCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie) (BusinessID, error) {
+	var customers *GetCustomerResponse
+	var err error
+	if g.config().GetCustomersEndpoint != "" {
+		customers, err = g.GetCustomers(ctx, accessToken, cookie, GetCustomersParams{ListParams: ListParams{Page: 1, PageSize: 1}})
+	} else {
+		// any number, the lookup is only made to check the permission
+		customers, err = g.GetCustomerByMobile(ctx, accessToken, cookie, "09120000000")
+	}
+	if err != nil || len(customers.Data) == 0 {
+		return 0, err
+	}
+	return customers.Data[0].BusinessID, nil
+}
+
+// setupDraftTransaction posts a draft sale transaction and voids it
+func (g *GoArpa) setupDraftTransaction(ctx context.Context, report *SetupReport, accessToken string, cookie []*http.Cookie, businessID BusinessID, items []TransactionItem) {
+	const name = "post draft transaction"
+
+	switch {
+	case g.config().VoidTransactionEndpoint == "":
+		report.add(name, SetupCheckSkipped, "VoidTransactionEndpoint is not configured, the draft could not be voided")
+		return
+	case len(items) == 0:
+		report.add(name, SetupCheckSkipped, "no items, see SetupOptions.Items")
+		return
+	case businessID == 0:
+		report.add(name, SetupCheckSkipped, "no business, see SetupOptions.BusinessID")
+		return
+	case g.dryRun:
+		report.add(name, SetupCheckSkipped, "dry run")
+		return
+	}
+
+	transaction := g.NewTransactionRequest(businessID, FactorTypeSale, items...)
+	transaction.Data.TransStateID = TransStateDraft
+	transaction.Data.Description = "goarpa setup check"
+	created, err := g.CreateTransaction(ctx, accessToken, transaction)
+	if err == nil && len(created.Data) == 0 {
+		err = errors.New("no transaction was returned")
+	}
+	report.addResult(name, err)
+	if err != nil {
+		return
+	}
+
+	_, err = g.VoidTransaction(ctx, accessToken, cookie, VoidTransactionRequest{TransactionID: created.Data[0].TransactionID})
+	if err != nil {
+		err = errors.Wrapf(err, "transaction %d is left as a draft", created.Data[0].TransactionID)
+	}
+	report.addResult("void draft transaction", err)
+}
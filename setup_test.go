@@ -0,0 +1,98 @@
+package goarpa_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidateSetup(t *testing.T) {
+	t.Parallel()
+	var posted goarpa.CreateTransactionRequest
+	var voided goarpa.VoidTransactionRequest
+	var mu sync.Mutex
+	methods := make(map[string][]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods[r.URL.Path] = append(methods[r.URL.Path], r.Method)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/serv/token/GetServiceToken":
+			_, _ = w.Write([]byte(`"token"`))
+		case "/serv/api/NewTransaction":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&posted)
+			_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+		case "/void":
+			if r.Method == http.MethodPost {
+				_ = json.NewDecoder(r.Body).Decode(&voided)
+			}
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		case "/customers":
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"7","BusinessCode":"1001"}]}`))
+		case "/docaliases":
+			_, _ = w.Write([]byte(`{"data":[{"DocAliasID":"1","DocAliasName":"Sales"}]}`))
+		case "/settlements":
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		case "/serv/api/PostBusiness", "/serv/api/PostService", "/serv/api/GetBusiness", "/serv/api/GetItem":
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetCustomersEndpoint = "customers"
+	client.Config.VoidTransactionEndpoint = "void"
+	client.Config.GetDocAliasesEndpoint = "docaliases"
+	client.Config.GetSettlementsEndpoint = "settlements"
+	client.Config.GetItemsEndpoint = "items"
+	session := goarpa.NewTokenManager(client, "admin", "secret", goarpa.TokenManagerOptions{})
+
+	report := client.ValidateSetup(context.Background(), session, goarpa.SetupOptions{
+		Items: []goarpa.TransactionItem{{ItemID: 1, Qty: 1}},
+	})
+	statuses := make(map[string]goarpa.SetupCheckStatus)
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	assert.Equal(t, goarpa.SetupCheckPassed, statuses["login"])
+	assert.Equal(t, goarpa.SetupCheckPassed, statuses["endpoint serv/api/NewTransaction"])
+	assert.Equal(t, goarpa.SetupCheckFailed, statuses["endpoint items"])
+	assert.Equal(t, goarpa.SetupCheckPassed, statuses["read customers"])
+	assert.Equal(t, goarpa.SetupCheckPassed, statuses["post draft transaction"])
+	assert.Equal(t, goarpa.SetupCheckPassed, statuses["void draft transaction"])
+	assert.Equal(t, goarpa.SetupCheckPassed, statuses["document aliases"])
+	assert.Equal(t, goarpa.SetupCheckFailed, statuses["settlements"])
+	assert.Equal(t, goarpa.SetupCheckSkipped, statuses["installation profile"])
+
+	// the draft is posted to the first business and voided
+	assert.Equal(t, goarpa.BusinessID(7), posted.Data.BusinessID)
+	assert.Equal(t, goarpa.TransStateDraft, posted.Data.TransStateID)
+	assert.Equal(t, goarpa.TransactionID(42), voided.TransactionID)
+
+	assert.False(t, report.Ready())
+	require.Error(t, report.Err())
+	assert.Contains(t, report.Err().Error(), "settlements: no settlement is defined")
+	assert.Contains(t, report.String(), "failed   endpoint items: not found")
+
+	// the endpoints which change data are only probed with OPTIONS
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{http.MethodOptions, http.MethodPost}, methods["/serv/api/NewTransaction"])
+	assert.Equal(t, []string{http.MethodOptions, http.MethodPost}, methods["/void"])
+	assert.Equal(t, []string{http.MethodOptions}, methods["/serv/api/PostBusiness"])
+	assert.Equal(t, http.MethodGet, methods["/docaliases"][0])
+}
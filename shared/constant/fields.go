@@ -1,7 +1,88 @@
+// Package constant holds the names of the query parameters and of the body fields of the Arpa API.
+// The installations with customized parameter names are served by renaming them per client,
+// see goarpa.WithQueryKeyNames and goarpa.WithFieldKeyNames.
 package constant
 
+// QueryKey is the name of a query parameter
+type QueryKey string
+
+// String returns the name of the query parameter
+func (k QueryKey) String() string {
+	return string(k)
+}
+
+// FieldKey is the name of a field of a request body
+type FieldKey string
+
+// String returns the name of the field
+func (k FieldKey) String() string {
+	return string(k)
+}
+
+// Keys of the lookups
+const (
+	MobileKey          QueryKey = "MobileNo"
+	BusinessCodeKey    QueryKey = "BusinessCode"
+	ItemCodeKey        QueryKey = "ItemCode"
+	BusinessIDQueryKey QueryKey = "BusinessID"
+	NationalCodeKey    QueryKey = "NationalCode"
+)
+
+// CultureKey is the parameter of the culture of the messages and labels, see goarpa.WithLocale
+const CultureKey QueryKey = "culture"
+
+// Keys of the list and report parameters
+const (
+	PageNumberKey    QueryKey = "PageNumber"
+	PageSizeKey      QueryKey = "PageSize"
+	ModifiedSinceKey QueryKey = "ModifiedSince"
+	FromDateKey      QueryKey = "FromDate"
+	ToDateKey        QueryKey = "ToDate"
+	AsOfKey          QueryKey = "AsOf"
+	FormatKey        QueryKey = "Format"
+	ItemIDQueryKey   QueryKey = "ItemID"
+	StockIDQueryKey  QueryKey = "StockID"
+	ProvinceIDKey    QueryKey = "ProvinceID"
+	RepresentorIDKey QueryKey = "RepresentorID"
+	JobIDKey         QueryKey = "JobID"
+	ShiftIDKey       QueryKey = "ShiftID"
+)
+
+// Keys of the request bodies
+const (
+	BusinessIDKey  FieldKey = "BusinessId"
+	TaxExemptField FieldKey = "TaxExempt"
+)
+
+// Keys of the customer fields, e.g. of goarpa.CustomerChanges
+const (
+	BusNameField            FieldKey = "BusName"
+	ProvinceIDField         FieldKey = "ProvinceId"
+	CityIDField             FieldKey = "CityId"
+	EmailField              FieldKey = "Email"
+	MobileField             FieldKey = "Mobile"
+	PhoneNoField            FieldKey = "PhoneNo"
+	NameField               FieldKey = "Name"
+	FamilyField             FieldKey = "Family"
+	NationalCodeField       FieldKey = "NationalCode"
+	BirthDateField          FieldKey = "BirthDate"
+	SexualityField          FieldKey = "Sexuality"
+	RealOrFinancialField    FieldKey = "RealOrFinancial"
+	AddressField            FieldKey = "Address"
+	FinCodeField            FieldKey = "FinCode"
+	IDNoField               FieldKey = "IDNo"
+	RegisterNumberField     FieldKey = "RegisterNumber"
+	BusinessCategoryIDField FieldKey = "BusinessCategoryId"
+)
+
+// Keys of the transaction item lines
 const (
-	MobileKey       = "MobileNo"
-	BusinessCodeKey = "BusinessCode"
-	ItemCodeKey     = "ItemCode"
+	ItemIDKey          FieldKey = "ItemID"
+	QtyKey             FieldKey = "Qty"
+	PriceKey           FieldKey = "Price"
+	DiscountAmountKey  FieldKey = "DiscountAmount"
+	DiscountPercentKey FieldKey = "DiscountPercent"
+	CalcTaxAndTollKey  FieldKey = "CalcTaxAndToll"
+	FreeQtyKey         FieldKey = "FreeQty"
+	StockIDKey         FieldKey = "StockID"
 )
@@ -0,0 +1,962 @@
+package goarpa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// SimulatedToken is the access token returned by SimulatedClient.GetAdminToken
+const SimulatedToken = "simulated-token"
+
+// SimulatedClient implements GoArpaIface in memory, for demos, local development and tests
+// which have no access to an Arpa server. It mimics the behaviors of Arpa the integrations rely on:
+//   - a business with the mobile or the national code of an existing one is reported as existing
+//   - the transactions get incrementing transaction ids and numbers
+//   - the sale invoices decrement the stock of the items, the returns and purchases adjust it back
+//   - the sale invoices are added to the balance of the business
+//   - the stock reservations take the quantity out of the stock until they are released
+//
+// It is safe for concurrent use.
+type SimulatedClient struct {
+	restyClient *resty.Client
+
+	mu                sync.Mutex
+	customers         []Datum2
+	items             []GetServiceResponse
+	transactions      []Transaction
+	balances          map[BusinessID]Money
+	attributes        map[BusinessID]CustomerAttributes
+	reservations      map[string]StockReservation
+	nextBusinessID    BusinessID
+	nextItemID        ItemID
+	nextTransactionID TransactionID
+	nextTransNumber   int64
+	nextReservationID int64
+}
+
+var _ GoArpaIface = (*SimulatedClient)(nil)
+
+// NewSimulatedClient returns an empty simulated Arpa
+func NewSimulatedClient() *SimulatedClient {
+	return &SimulatedClient{
+		restyClient:       resty.New(),
+		balances:          make(map[BusinessID]Money),
+		attributes:        make(map[BusinessID]CustomerAttributes),
+		reservations:      make(map[string]StockReservation),
+		nextBusinessID:    1,
+		nextItemID:        1,
+		nextTransactionID: 1,
+		nextTransNumber:   1,
+	}
+}
+
+// AddItem adds an item to the simulated Arpa and returns its ID. A zero ItemID is assigned automatically.
+// The stock of the item is tracked when its Qty is set.
+func (s *SimulatedClient) AddItem(item GetServiceResponse) ItemID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if item.ItemID == 0 {
+		item.ItemID = s.nextItemID
+	}
+	if item.ItemID >= s.nextItemID {
+		s.nextItemID = item.ItemID + 1
+	}
+	if item.IsActive == "" {
+		item.IsActive = "1"
+	}
+	if item.CreationDate.IsZero() {
+		item.CreationDate = CustomTime{Time: time.Now()}
+	}
+	s.items = append(s.items, item)
+	return item.ItemID
+}
+
+// Stock returns the quantity in stock of the item and false if the item is unknown or its stock is not tracked
+func (s *SimulatedClient) Stock(itemID ItemID) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := s.item(itemID)
+	if item == nil {
+		return 0, false
+	}
+	return item.Stock()
+}
+
+// RestyClient returns the resty client, which is never used by the simulation
+func (s *SimulatedClient) RestyClient() *resty.Client {
+	return s.restyClient
+}
+
+// SetRestyClient overwrites the resty client, which is never used by the simulation
+func (s *SimulatedClient) SetRestyClient(restyClient *resty.Client) {
+	s.restyClient = restyClient
+}
+
+// GetAdminToken returns SimulatedToken for any credentials
+func (s *SimulatedClient) GetAdminToken(ctx context.Context, username string, password string) (string, []*http.Cookie, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+	if username == "" {
+		return "", nil, &APIError{Code: http.StatusUnauthorized, Message: "could not get token: 401 Unauthorized", Type: APIErrTypeUnknown}
+	}
+	return SimulatedToken, nil, nil
+}
+
+// CreateCustomer creates a business, or reports the existing one with a *CustomerExistsError
+func (s *SimulatedClient) CreateCustomer(ctx context.Context, accessToken string, cookie []*http.Cookie, customer CreateCustomerRequest) (*RetCustomerResponse, error) {
+	const errMessage = "could not create customer"
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if customer.BusName == "" {
+		return nil, simulatedArpaError(errMessage, "BusName is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.customers {
+		if (!NilOrEmpty(customer.Mobile) && existing.Mobile == *customer.Mobile) ||
+			(customer.NationalCode != nil && *customer.NationalCode != "" && existing.NationalCode == string(*customer.NationalCode)) {
+			response := &RetCustomerResponse{Data: CreateCustomerResponse{
+				BusinessID:   existing.BusinessID,
+				BusinessCode: EnforcedString(existing.BusinessCode),
+				Existed:      true,
+			}}
+			return response, &CustomerExistsError{BusinessID: existing.BusinessID, BusinessCode: existing.BusinessCode}
+		}
+	}
+
+	now := &CustomTime{Time: time.Now()}
+	datum := Datum2{
+		RowNumber:        StringInt64(len(s.customers) + 1),
+		BusinessID:       s.nextBusinessID,
+		BusinessCode:     strconv.FormatInt(1000+s.nextBusinessID.Int64(), 10),
+		IsCustomer:       true,
+		CreationDate:     now,
+		ModificationDate: now,
+	}
+	s.nextBusinessID++
+	applyCustomerRequest(&datum, customer)
+	s.customers = append(s.customers, datum)
+
+	return &RetCustomerResponse{Data: CreateCustomerResponse{
+		BusinessID:   datum.BusinessID,
+		BusinessCode: EnforcedString(datum.BusinessCode),
+	}}, nil
+}
+
+// UpdateCustomerPartial updates the changed fields of a business
+func (s *SimulatedClient) UpdateCustomerPartial(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, changes CustomerChanges) (*RetCustomerResponse, error) {
+	const errMessage = "could not update customer"
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+	var request CreateCustomerRequest
+	if err := json.Unmarshal(b, &request); err != nil {
+		return nil, errors.Wrap(err, errMessage)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	datum := s.customer(businessID)
+	if datum == nil {
+		return nil, simulatedArpaError(errMessage, "business not found")
+	}
+	applyCustomerRequest(datum, request)
+	datum.ModificationDate = &CustomTime{Time: time.Now()}
+
+	return &RetCustomerResponse{Data: CreateCustomerResponse{
+		BusinessID:   datum.BusinessID,
+		BusinessCode: EnforcedString(datum.BusinessCode),
+	}}, nil
+}
+
+// SetCustomerTaxExempt exempts the business from the tax and toll or charges them again
+func (s *SimulatedClient) SetCustomerTaxExempt(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, exempt bool) error {
+	return setCustomerTaxExempt(ctx, s, accessToken, cookie, businessID, exempt)
+}
+
+// EnsureCustomer returns the business matching the customer or creates it, see GoArpa.EnsureCustomer
+func (s *SimulatedClient) EnsureCustomer(ctx context.Context, accessToken string, cookie []*http.Cookie, customer CreateCustomerRequest) (*EnsureCustomerResult, error) {
+	return ensureCustomer(ctx, s, accessToken, cookie, customer)
+}
+
+// GetCustomerByMobile returns the businesses with the mobile number
+func (s *SimulatedClient) GetCustomerByMobile(ctx context.Context, accessToken string, cookie []*http.Cookie, mobile string) (*GetCustomerResponse, error) {
+	return s.findCustomers(ctx, func(datum Datum2) bool {
+		return datum.Mobile == mobile
+	})
+}
+
+// GetCustomersByMobiles looks the mobile numbers up with GetCustomerByMobile
+func (s *SimulatedClient) GetCustomersByMobiles(ctx context.Context, session SessionSource, mobiles []string) (*MobileLookupResult, error) {
+	return customersByMobiles(ctx, s, session, mobiles, DefaultLookupConcurrency)
+}
+
+// GetCustomerByBusinessCode returns the business with the code
+func (s *SimulatedClient) GetCustomerByBusinessCode(ctx context.Context, accessToken string, cookie []*http.Cookie, businessCode string) (*GetCustomerResponse, error) {
+	return s.findCustomers(ctx, func(datum Datum2) bool {
+		return datum.BusinessCode == businessCode
+	})
+}
+
+// GetCustomerByID returns the business with the ID
+func (s *SimulatedClient) GetCustomerByID(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID) (*GetCustomerResponse, error) {
+	return s.findCustomers(ctx, func(datum Datum2) bool {
+		return datum.BusinessID == businessID
+	})
+}
+
+// GetCustomerByNationalCode returns the businesses with the national code
+func (s *SimulatedClient) GetCustomerByNationalCode(ctx context.Context, accessToken string, cookie []*http.Cookie, nationalCode string) (*GetCustomerResponse, error) {
+	return s.findCustomers(ctx, func(datum Datum2) bool {
+		return datum.NationalCode == nationalCode
+	})
+}
+
+// GetCustomerBalance returns the sum of the sale invoices of the business minus its returns
+func (s *SimulatedClient) GetCustomerBalance(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID) (*CustomerBalance, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &CustomerBalance{BusinessID: businessID, Balance: s.balances[businessID]}, nil
+}
+
+// CheckCustomerCredit decides whether the amount can be sold on credit to the business, see GoArpa.CheckCustomerCredit
+func (s *SimulatedClient) CheckCustomerCredit(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, amount Money) (*CreditCheckResult, error) {
+	return checkCustomerCredit(ctx, s, accessToken, cookie, businessID, amount)
+}
+
+// GetBusinessID returns the ID of the business with the code, see GoArpa.GetBusinessID
+func (s *SimulatedClient) GetBusinessID(ctx context.Context, accessToken string, cookie []*http.Cookie, businessCode string) (BusinessID, error) {
+	return getBusinessID(ctx, s, accessToken, cookie, businessCode)
+}
+
+// GetCustomerAttributes returns the extended attributes of the business
+func (s *SimulatedClient) GetCustomerAttributes(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID) (CustomerAttributes, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.customer(businessID) == nil {
+		return nil, simulatedArpaError("could not get customer attributes", "business not found")
+	}
+	attributes := make(CustomerAttributes, len(s.attributes[businessID]))
+	for name, value := range s.attributes[businessID] {
+		attributes[name] = value
+	}
+	return attributes, nil
+}
+
+// SetCustomerAttributes writes the extended attributes of the business, its other attributes are kept
+func (s *SimulatedClient) SetCustomerAttributes(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, attributes CustomerAttributes) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.customer(businessID) == nil {
+		return simulatedArpaError("could not set customer attributes", "business not found")
+	}
+	if s.attributes[businessID] == nil {
+		s.attributes[businessID] = make(CustomerAttributes, len(attributes))
+	}
+	for name, value := range attributes {
+		s.attributes[businessID][name] = value
+	}
+	return nil
+}
+
+// GetCustomerTransactionsAging computes the aging of the businesses from their balances and sale invoices
+func (s *SimulatedClient) GetCustomerTransactionsAging(ctx context.Context, accessToken string, cookie []*http.Cookie, params AgingParams) ([]CustomerAging, error) {
+	return customerTransactionsAging(ctx, s, accessToken, cookie, params)
+}
+
+// GetCustomers returns a page of businesses
+func (s *SimulatedClient) GetCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams) (*GetCustomerResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var customers []Datum2
+	for _, datum := range s.customers {
+		if (params.ModifiedSince == nil || modifiedAfter(datum.ModificationDate, *params.ModifiedSince)) &&
+			params.Inactive.keep(!datum.IsActive()) {
+			customers = append(customers, datum)
+		}
+	}
+	return &GetCustomerResponse{Data: page(customers, params.ListParams)}, nil
+}
+
+// IterateCustomers iterates over all the businesses
+func (s *SimulatedClient) IterateCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams) iter.Seq2[Customer, error] {
+	return iteratePages(ctx, params.ListParams, func(ctx context.Context, paging ListParams) ([]Customer, error) {
+		params := params
+		params.ListParams = paging
+		return s.customersPage(ctx, params)
+	})
+}
+
+// GetCustomersStream calls fn with every business
+func (s *SimulatedClient) GetCustomersStream(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams, fn func(Customer) error) error {
+	for customer, err := range s.IterateCustomers(ctx, accessToken, cookie, params) {
+		if err != nil {
+			return err
+		}
+		if err := fn(customer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportCustomers streams all the businesses into the returned channel
+func (s *SimulatedClient) ExportCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams, concurrency int) <-chan ExportResult[Customer] {
+	return exportPages(ctx, params.ListParams, concurrency, func(ctx context.Context, paging ListParams) ([]Customer, error) {
+		params := params
+		params.ListParams = paging
+		return s.customersPage(ctx, params)
+	})
+}
+
+// CreateCustomers creates many businesses with a bulk executor
+func (s *SimulatedClient) CreateCustomers(ctx context.Context, accessToken string, cookie []*http.Cookie, customers []CreateCustomerRequest, options BulkOptions) ([]*RetCustomerResponse, error) {
+	bulk := NewBulk(func(ctx context.Context, customer CreateCustomerRequest) (*RetCustomerResponse, error) {
+		return s.CreateCustomer(ctx, accessToken, cookie, customer)
+	}, options)
+	return bulk.Execute(ctx, customers)
+}
+
+// ImportCustomersCSV creates the businesses of the CSV rows which do not exist yet
+func (s *SimulatedClient) ImportCustomersCSV(ctx context.Context, accessToken string, cookie []*http.Cookie, r io.Reader, mapping CSVMapping) (*CSVImportResult, error) {
+	return importCSV(ctx, r, mapping, func(ctx context.Context, customer Customer) error {
+		request, err := customer.ToCreateCustomerRequest()
+		if err != nil {
+			return err
+		}
+		_, err = s.EnsureCustomer(ctx, accessToken, cookie, request)
+		return err
+	})
+}
+
+// ExportCustomersCSV writes the businesses as CSV
+func (s *SimulatedClient) ExportCustomersCSV(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams, w io.Writer, mapping CSVMapping) error {
+	return exportCSV(w, mapping, s.IterateCustomers(ctx, accessToken, cookie, params))
+}
+
+// CreateTransaction creates a transaction, adjusting the stock of its items and the balance of the business
+func (s *SimulatedClient) CreateTransaction(ctx context.Context, accessToken string, transaction CreateTransactionRequest) (*CreateTransactionResponse, error) {
+	const errMessage = "could not create transaction"
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(transaction.Items) == 0 {
+		return nil, simulatedArpaError(errMessage, "transaction has no items")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.customer(transaction.Data.BusinessID) == nil {
+		return nil, simulatedArpaError(errMessage, fmt.Sprintf("business %d not found", transaction.Data.BusinessID))
+	}
+
+	// the stock sign of the invoice type, sales take items out of the stock
+	sign := 0.0
+	switch transaction.Data.FactorTypeID {
+	case FactorTypeSale, FactorTypePurchaseReturn:
+		sign = -1
+	case FactorTypeSaleReturn, FactorTypePurchase:
+		sign = 1
+	}
+
+	stock := make(map[ItemID]float64)
+	total := Money{}
+	for _, line := range transaction.Items {
+		item := s.item(line.ItemID)
+		if item == nil {
+			return nil, simulatedArpaError(errMessage, fmt.Sprintf("item %d not found", line.ItemID))
+		}
+		if item.Qty != "" {
+			qty, ok := stock[line.ItemID]
+			if !ok {
+				qty, _ = strconv.ParseFloat(string(item.Qty), 64)
+			}
+			qty += sign * (line.Qty + line.FreeQty)
+			if qty < 0 {
+				return nil, simulatedArpaError(errMessage, fmt.Sprintf("insufficient stock of item %d", line.ItemID))
+			}
+			stock[line.ItemID] = qty
+		}
+
+		price := line.Price
+		if price.IsZero() {
+			price = item.SalePrice
+		}
+		amount := Money{price.Mul(decimal.NewFromFloat(line.Qty))}
+		amount = amount.Sub(line.DiscountAmount).Sub(amount.Percent(line.DiscountPercent))
+		total = total.Add(amount)
+	}
+	total = total.Sub(transaction.Data.TransDiscountAmount).Sub(total.Percent(transaction.Data.TransDiscountPercent))
+	for _, addSub := range transaction.AddSub {
+		total = total.Add(addSub.TASAmount)
+	}
+
+	for itemID, qty := range stock {
+		s.item(itemID).Qty = EnforcedString(strconv.FormatFloat(qty, 'f', -1, 64))
+	}
+	switch transaction.Data.FactorTypeID {
+	case FactorTypeSale:
+		s.balances[transaction.Data.BusinessID] = s.balances[transaction.Data.BusinessID].Add(total)
+	case FactorTypeSaleReturn:
+		s.balances[transaction.Data.BusinessID] = s.balances[transaction.Data.BusinessID].Sub(total)
+	}
+
+	now := &CustomTime{Time: time.Now()}
+	created := Transaction{
+		TransactionID:    s.nextTransactionID,
+		TransNumber:      EnforcedInt(s.nextTransNumber),
+		BusinessID:       transaction.Data.BusinessID,
+		TransDate:        now,
+		TransStateID:     transaction.Data.TransStateID,
+		FactorTypeID:     transaction.Data.FactorTypeID,
+		TotalAmount:      total,
+		Description:      EnforcedString(transaction.Data.Description),
+		ModificationDate: now,
+	}
+	s.nextTransactionID++
+	s.nextTransNumber++
+	s.transactions = append(s.transactions, created)
+
+	return &CreateTransactionResponse{Data: []Datum{{
+		TransactionID: created.TransactionID,
+		TransNumber:   int64(created.TransNumber),
+	}}}, nil
+}
+
+// CreateDraftTransaction creates the transaction as a draft
+func (s *SimulatedClient) CreateDraftTransaction(ctx context.Context, accessToken string, transaction CreateTransactionRequest) (*CreateTransactionResponse, error) {
+	return createDraftTransaction(ctx, s, accessToken, transaction)
+}
+
+// FinalizeTransaction moves a draft transaction to the final state
+func (s *SimulatedClient) FinalizeTransaction(ctx context.Context, accessToken string, cookie []*http.Cookie, transactionID TransactionID) (*CreateTransactionResponse, error) {
+	const errMessage = "could not finalize transaction"
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.transactions {
+		transaction := &s.transactions[i]
+		if transaction.TransactionID != transactionID {
+			continue
+		}
+		if transaction.TransStateID != TransStateDraft {
+			return nil, simulatedArpaError(errMessage, fmt.Sprintf("transaction %d is not a draft", transactionID))
+		}
+		transaction.TransStateID = TransStateFinal
+		transaction.ModificationDate = &CustomTime{Time: time.Now()}
+		return &CreateTransactionResponse{Data: []Datum{{
+			TransactionID: transaction.TransactionID,
+			TransNumber:   int64(transaction.TransNumber),
+		}}}, nil
+	}
+	return nil, simulatedArpaError(errMessage, fmt.Sprintf("transaction %d not found", transactionID))
+}
+
+// GetTransactionByReference returns the transaction with the external reference
+func (s *SimulatedClient) GetTransactionByReference(ctx context.Context, accessToken string, cookie []*http.Cookie, reference string, params GetTransactionsParams) (*Transaction, error) {
+	return getTransactionByReference(ctx, s, accessToken, cookie, reference, params)
+}
+
+// CreateTransactions creates many transactions with a bulk executor
+func (s *SimulatedClient) CreateTransactions(ctx context.Context, accessToken string, transactions []CreateTransactionRequest, options BulkOptions) ([]*CreateTransactionResponse, error) {
+	bulk := NewBulk(func(ctx context.Context, transaction CreateTransactionRequest) (*CreateTransactionResponse, error) {
+		return s.CreateTransaction(ctx, accessToken, transaction)
+	}, options)
+	return bulk.Execute(ctx, transactions)
+}
+
+// GetTransactions returns a page of transactions
+func (s *SimulatedClient) GetTransactions(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams) (*GetTransactionsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var transactions []Transaction
+	for _, transaction := range s.transactions {
+		switch {
+		case params.BusinessID != nil && transaction.BusinessID != *params.BusinessID,
+			params.FromDate != nil && transaction.TransDate.Before(*params.FromDate),
+			params.ToDate != nil && transaction.TransDate.After(*params.ToDate),
+			params.ModifiedSince != nil && !modifiedAfter(transaction.ModificationDate, *params.ModifiedSince):
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+	return &GetTransactionsResponse{Data: page(transactions, params.ListParams)}, nil
+}
+
+// IterateTransactions iterates over all the transactions
+func (s *SimulatedClient) IterateTransactions(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams) iter.Seq2[Transaction, error] {
+	return iteratePages(ctx, params.ListParams, func(ctx context.Context, paging ListParams) ([]Transaction, error) {
+		params := params
+		params.ListParams = paging
+		result, err := s.GetTransactions(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+}
+
+// GetTransactionsStream calls fn with every transaction
+func (s *SimulatedClient) GetTransactionsStream(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams, fn func(Transaction) error) error {
+	for transaction, err := range s.IterateTransactions(ctx, accessToken, cookie, params) {
+		if err != nil {
+			return err
+		}
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportTransactions streams all the transactions into the returned channel
+func (s *SimulatedClient) ExportTransactions(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams, concurrency int) <-chan ExportResult[Transaction] {
+	return exportPages(ctx, params.ListParams, concurrency, func(ctx context.Context, paging ListParams) ([]Transaction, error) {
+		params := params
+		params.ListParams = paging
+		result, err := s.GetTransactions(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+}
+
+// CreateService creates a service, whose stock is not tracked
+func (s *SimulatedClient) CreateService(ctx context.Context, accessToken string, service CreateServiceRequest) (*CreateServiceResponse, error) {
+	const errMessage = "could not create service"
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if service.ServiceName == "" {
+		return nil, simulatedArpaError(errMessage, "ServiceName is required")
+	}
+
+	s.AddItem(GetServiceResponse{
+		ItemCode:       service.ServiceCode,
+		ItemName:       service.ServiceName,
+		IAGroupID:      StringInt64(service.IAGroupID),
+		ItemCategoryID: StringInt64(service.ItemCategoryID),
+	})
+	return &CreateServiceResponse{ServiceName: service.ServiceName, ItemCategoryID: service.ItemCategoryID}, nil
+}
+
+// GetServiceByItemCode returns the item with the code
+func (s *SimulatedClient) GetServiceByItemCode(ctx context.Context, accessToken string, cookie []*http.Cookie, itemCode string) (*RetServiceResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &RetServiceResponse{Data: []GetServiceResponse{}}
+	for _, item := range s.items {
+		if item.ItemCode == itemCode {
+			result.Data = append(result.Data, item)
+		}
+	}
+	return result, nil
+}
+
+// GetItems returns a page of items
+func (s *SimulatedClient) GetItems(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams) (*RetServiceResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []GetServiceResponse
+	for _, item := range s.items {
+		modified := item.ModificationDate
+		if modified == nil {
+			modified = &item.CreationDate
+		}
+		if params.ModifiedSince == nil || modifiedAfter(modified, *params.ModifiedSince) {
+			items = append(items, item)
+		}
+	}
+	return &RetServiceResponse{Data: page(items, params.ListParams)}, nil
+}
+
+// IterateItems iterates over all the items
+func (s *SimulatedClient) IterateItems(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams) iter.Seq2[GetServiceResponse, error] {
+	return iteratePages(ctx, params.ListParams, func(ctx context.Context, paging ListParams) ([]GetServiceResponse, error) {
+		params := params
+		params.ListParams = paging
+		result, err := s.GetItems(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+}
+
+// ExportItems streams all the items into the returned channel
+func (s *SimulatedClient) ExportItems(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams, concurrency int) <-chan ExportResult[GetServiceResponse] {
+	return exportPages(ctx, params.ListParams, concurrency, func(ctx context.Context, paging ListParams) ([]GetServiceResponse, error) {
+		params := params
+		params.ListParams = paging
+		result, err := s.GetItems(ctx, accessToken, cookie, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+}
+
+// ImportItemsCSV creates the services of the CSV rows
+func (s *SimulatedClient) ImportItemsCSV(ctx context.Context, accessToken string, r io.Reader, mapping CSVMapping) (*CSVImportResult, error) {
+	return importCSV(ctx, r, mapping, func(ctx context.Context, service CreateServiceRequest) error {
+		_, err := s.CreateService(ctx, accessToken, service)
+		return err
+	})
+}
+
+// ExportItemsCSV writes the items as CSV
+func (s *SimulatedClient) ExportItemsCSV(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetItemsParams, w io.Writer, mapping CSVMapping) error {
+	return exportCSV(w, mapping, s.IterateItems(ctx, accessToken, cookie, params))
+}
+
+// GetItemAvailability returns the stock of the item in the default warehouse, whose ID is zero,
+// since the simulation has a single warehouse. The stock of the items which are not tracked is zero.
+func (s *SimulatedClient) GetItemAvailability(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, options ItemAvailabilityOptions) (*ItemAvailability, error) {
+	const errMessage = "could not get item availability"
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := s.item(itemID)
+	if item == nil {
+		return nil, simulatedArpaError(errMessage, fmt.Sprintf("item %d not found", itemID))
+	}
+	qty, _ := item.Stock()
+	stocks := []WarehouseStock{{Qty: EnforcedFloat(qty)}}
+	return newItemAvailability(itemID, stocks, options.Warehouses), nil
+}
+
+// ReserveStock takes the quantity out of the stock of the item until the reservation is released
+func (s *SimulatedClient) ReserveStock(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, qty float64, reference string) (*StockReservation, error) {
+	const errMessage = "could not reserve stock"
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if qty <= 0 {
+		return nil, errors.Wrap(errors.Errorf("invalid quantity %v", qty), errMessage)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := s.item(itemID)
+	if item == nil {
+		return nil, simulatedArpaError(errMessage, fmt.Sprintf("item %d not found", itemID))
+	}
+	if item.Qty != "" {
+		stock, _ := strconv.ParseFloat(string(item.Qty), 64)
+		if stock < qty {
+			return nil, simulatedArpaError(errMessage, fmt.Sprintf("insufficient stock of item %d", itemID))
+		}
+		item.Qty = EnforcedString(strconv.FormatFloat(stock-qty, 'f', -1, 64))
+	}
+
+	reservation := StockReservation{
+		ReservationID: strconv.FormatInt(s.nextReservationID+1, 10),
+		ItemID:        itemID,
+		Qty:           qty,
+		Reference:     reference,
+	}
+	s.nextReservationID++
+	s.reservations[reservation.ReservationID] = reservation
+	return &reservation, nil
+}
+
+// ReleaseReservation returns the quantity held by the reservation to the stock of the item
+func (s *SimulatedClient) ReleaseReservation(ctx context.Context, accessToken string, cookie []*http.Cookie, reservationID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservation, ok := s.reservations[reservationID]
+	if !ok {
+		return simulatedArpaError("could not release reservation", "reservation not found")
+	}
+	delete(s.reservations, reservationID)
+	if item := s.item(reservation.ItemID); item != nil && item.Qty != "" {
+		stock, _ := strconv.ParseFloat(string(item.Qty), 64)
+		item.Qty = EnforcedString(strconv.FormatFloat(stock+reservation.Qty, 'f', -1, 64))
+	}
+	return nil
+}
+
+// GetReceipts is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) GetReceipts(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) (*GetCashMovementsResponse, error) {
+	return nil, errors.Wrap(ErrNotSupported, "could not get receipts")
+}
+
+// IterateReceipts is not simulated and yields ErrNotSupported
+func (s *SimulatedClient) IterateReceipts(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) iter.Seq2[CashMovement, error] {
+	return iterateCashMovements(ctx, params, func(ctx context.Context, params GetCashMovementsParams) (*GetCashMovementsResponse, error) {
+		return s.GetReceipts(ctx, accessToken, cookie, params)
+	})
+}
+
+// GetPayments is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) GetPayments(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) (*GetCashMovementsResponse, error) {
+	return nil, errors.Wrap(ErrNotSupported, "could not get payments")
+}
+
+// IteratePayments is not simulated and yields ErrNotSupported
+func (s *SimulatedClient) IteratePayments(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) iter.Seq2[CashMovement, error] {
+	return iterateCashMovements(ctx, params, func(ctx context.Context, params GetCashMovementsParams) (*GetCashMovementsResponse, error) {
+		return s.GetPayments(ctx, accessToken, cookie, params)
+	})
+}
+
+// GetItemPriceHistory is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) GetItemPriceHistory(ctx context.Context, accessToken string, cookie []*http.Cookie, itemID ItemID, from time.Time, to time.Time) ([]ItemPriceChange, error) {
+	return nil, errors.Wrap(ErrNotSupported, "could not get item price history")
+}
+
+// GetRepresentorSales is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) GetRepresentorSales(ctx context.Context, accessToken string, cookie []*http.Cookie, params RepresentorSalesParams) ([]RepresentorSales, error) {
+	return nil, errors.Wrap(ErrNotSupported, "could not get representor sales")
+}
+
+// GetProvinces returns the provinces of EmbeddedGeoDataset
+func (s *SimulatedClient) GetProvinces(ctx context.Context, accessToken string, cookie []*http.Cookie) ([]Province, error) {
+	return EmbeddedGeoDataset().Provinces, nil
+}
+
+// GetCities returns the cities of EmbeddedGeoDataset
+func (s *SimulatedClient) GetCities(ctx context.Context, accessToken string, cookie []*http.Cookie, provinceID int64) ([]City, error) {
+	dataset := EmbeddedGeoDataset()
+	return dataset.CitiesOf(provinceID), nil
+}
+
+// RefreshGeoDataset is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) RefreshGeoDataset(ctx context.Context, accessToken string, cookie []*http.Cookie) (*GeoChanges, error) {
+	return nil, errors.Wrap(ErrNotSupported, "could not refresh geo dataset")
+}
+
+// GetServerInfo is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) GetServerInfo(ctx context.Context, accessToken string, cookie []*http.Cookie) (*ServerInfo, error) {
+	return nil, errors.Wrap(ErrNotSupported, "could not get server info")
+}
+
+// SubmitReportJob is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) SubmitReportJob(ctx context.Context, accessToken string, cookie []*http.Cookie, request ReportJobRequest) (*ReportJob, error) {
+	return nil, errors.Wrap(ErrNotSupported, "could not submit report job")
+}
+
+// GetReportJob is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) GetReportJob(ctx context.Context, accessToken string, cookie []*http.Cookie, jobID string) (*ReportJob, error) {
+	return nil, errors.Wrap(ErrNotSupported, "could not get report job")
+}
+
+// WaitForReport polls the report job, see GoArpa.WaitForReport
+func (s *SimulatedClient) WaitForReport(ctx context.Context, accessToken string, cookie []*http.Cookie, jobID string, pollInterval time.Duration) (*ReportJob, error) {
+	return waitForReport(ctx, SystemClock, s, accessToken, cookie, jobID, pollInterval)
+}
+
+// OpenReport is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) OpenReport(ctx context.Context, accessToken string, cookie []*http.Cookie, jobID string) (io.ReadCloser, error) {
+	return nil, errors.Wrap(ErrNotSupported, "could not open report")
+}
+
+// DownloadCustomerStatement is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) DownloadCustomerStatement(ctx context.Context, accessToken string, cookie []*http.Cookie, params CustomerStatementParams, w io.Writer) (int64, error) {
+	return 0, errors.Wrap(ErrNotSupported, "could not download customer statement")
+}
+
+// DownloadFile is not simulated and returns ErrNotSupported
+func (s *SimulatedClient) DownloadFile(ctx context.Context, accessToken string, cookie []*http.Cookie, endpoint string, params interface{}, w io.Writer) (int64, error) {
+	return 0, errors.Wrap(ErrNotSupported, "could not download file")
+}
+
+func (s *SimulatedClient) findCustomers(ctx context.Context, match func(Datum2) bool) (*GetCustomerResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &GetCustomerResponse{Data: []Datum2{}}
+	for _, datum := range s.customers {
+		if match(datum) {
+			result.Data = append(result.Data, datum)
+		}
+	}
+	return result, nil
+}
+
+func (s *SimulatedClient) customersPage(ctx context.Context, params GetCustomersParams) ([]Customer, error) {
+	result, err := s.GetCustomers(ctx, SimulatedToken, nil, params)
+	if err != nil {
+		return nil, err
+	}
+	customers := make([]Customer, 0, len(result.Data))
+	for _, datum := range result.Data {
+		customers = append(customers, datum.ToCustomer())
+	}
+	return customers, nil
+}
+
+// customer returns the business with the ID, the lock must be held
+func (s *SimulatedClient) customer(businessID BusinessID) *Datum2 {
+	for i := range s.customers {
+		if s.customers[i].BusinessID == businessID {
+			return &s.customers[i]
+		}
+	}
+	return nil
+}
+
+// item returns the item with the ID, the lock must be held
+func (s *SimulatedClient) item(itemID ItemID) *GetServiceResponse {
+	for i := range s.items {
+		if s.items[i].ItemID == itemID {
+			return &s.items[i]
+		}
+	}
+	return nil
+}
+
+// applyCustomerRequest sets the fields of the request which are not nil
+func applyCustomerRequest(datum *Datum2, request CreateCustomerRequest) {
+	if request.BusName != "" {
+		datum.BusinessName = request.BusName
+	}
+	setString := func(target *string, value *string) {
+		if value != nil {
+			*target = *value
+		}
+	}
+	setInt := func(target *StringInt64, value *int64) {
+		if value != nil {
+			*target = StringInt64(*value)
+		}
+	}
+	setString(&datum.Email, request.Email)
+	setString(&datum.Mobile, request.Mobile)
+	setString(&datum.PhoneNo, request.PhoneNo)
+	setString(&datum.Name, request.Name)
+	setString(&datum.Family, request.Family)
+	setString(&datum.Address, request.Address)
+	setInt(&datum.ProvinceID, request.ProvinceID)
+	setInt(&datum.CityID, request.CityID)
+	setInt(&datum.BusinessCategoryID, request.BusinessCategoryID)
+	if request.NationalCode != nil {
+		datum.NationalCode = string(*request.NationalCode)
+	}
+	if request.FinCode != nil {
+		datum.FinCode = string(*request.FinCode)
+	}
+	if request.IDNo != nil {
+		datum.IDNo = string(*request.IDNo)
+	}
+	if request.RegisterNumber != nil {
+		datum.RegisterNumber = strconv.FormatInt(*request.RegisterNumber, 10)
+	}
+	if request.Sexuality != nil {
+		datum.Sexuality = strconv.FormatInt(int64(*request.Sexuality), 10)
+	}
+	if request.RealOrFinancial != nil {
+		datum.RealOrFinancial = strconv.FormatInt(int64(*request.RealOrFinancial), 10)
+	}
+	if request.TaxExempt != nil {
+		datum.TaxExempt = StringBool(*request.TaxExempt)
+	}
+}
+
+// page returns the page of the items, sorted as they were added
+func page[T any](items []T, paging ListParams) []T {
+	paging = paging.normalize()
+	start := (paging.Page - 1) * paging.PageSize
+	if start >= len(items) {
+		return []T{}
+	}
+	end := min(start+paging.PageSize, len(items))
+	return append([]T{}, items[start:end]...)
+}
+
+func modifiedAfter(modified *CustomTime, since time.Time) bool {
+	return modified != nil && !modified.Before(since)
+}
+
+func simulatedArpaError(errMessage string, message string) error {
+	arpaErr := &ArpaError{Message: message}
+	return &APIError{
+		Code:    http.StatusOK,
+		Message: fmt.Sprintf("%s: %s", errMessage, arpaErr),
+		Type:    APIErrTypeArpa,
+		Arpa:    arpaErr,
+	}
+}
@@ -0,0 +1,107 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SimulatedClientCustomers(t *testing.T) {
+	t.Parallel()
+	var client goarpa.GoArpaIface = goarpa.NewSimulatedClient()
+	ctx := context.Background()
+
+	token, _, err := client.GetAdminToken(ctx, "user", "pass")
+	require.NoError(t, err)
+
+	created, err := client.CreateCustomer(ctx, token, nil, goarpa.CreateCustomerRequest{BusName: "Ali", Mobile: goarpa.StringP("09120000000")})
+	require.NoError(t, err)
+	assert.False(t, bool(created.Data.Existed))
+
+	existed, err := client.CreateCustomer(ctx, token, nil, goarpa.CreateCustomerRequest{BusName: "Ali R.", Mobile: goarpa.StringP("09120000000")})
+	assert.True(t, errors.Is(err, goarpa.ErrCustomerAlreadyExists))
+	assert.True(t, bool(existed.Data.Existed))
+	assert.Equal(t, created.Data.BusinessID, existed.Data.BusinessID)
+
+	ensured, err := client.EnsureCustomer(ctx, token, nil, goarpa.CreateCustomerRequest{BusName: "Ali", Mobile: goarpa.StringP("09120000000")})
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.CustomerFound, ensured.Path)
+
+	changes, err := goarpa.DiffCustomers(goarpa.Customer{Name: "Ali"}, goarpa.Customer{Name: "Ali", Email: "ali@example.com"})
+	require.NoError(t, err)
+	_, err = client.UpdateCustomerPartial(ctx, token, nil, created.Data.BusinessID, changes)
+	require.NoError(t, err)
+
+	found, err := client.GetCustomerByBusinessCode(ctx, token, nil, string(created.Data.BusinessCode))
+	require.NoError(t, err)
+	customer, ok := found.First()
+	require.True(t, ok)
+	assert.Equal(t, "ali@example.com", customer.Email)
+	assert.Equal(t, "09120000000", customer.Mobile)
+
+	businessID, err := client.GetBusinessID(ctx, token, nil, string(created.Data.BusinessCode))
+	require.NoError(t, err)
+	assert.Equal(t, created.Data.BusinessID, businessID)
+	_, err = client.GetBusinessID(ctx, token, nil, "missing")
+	assert.True(t, errors.Is(err, goarpa.ErrCustomerNotFound))
+
+	require.NoError(t, client.SetCustomerAttributes(ctx, token, nil, businessID, goarpa.CustomerAttributes{"segment": "vip"}))
+	require.NoError(t, client.SetCustomerAttributes(ctx, token, nil, businessID, goarpa.CustomerAttributes{"source": "web"}))
+	attributes, err := client.GetCustomerAttributes(ctx, token, nil, businessID)
+	require.NoError(t, err)
+	assert.Equal(t, goarpa.CustomerAttributes{"segment": "vip", "source": "web"}, attributes)
+
+	var names []string
+	for customer, err := range client.IterateCustomers(ctx, token, nil, goarpa.GetCustomersParams{ListParams: goarpa.ListParams{PageSize: 1}}) {
+		require.NoError(t, err)
+		names = append(names, customer.Name)
+	}
+	assert.Equal(t, []string{"Ali"}, names)
+}
+
+func Test_SimulatedClientTransactions(t *testing.T) {
+	t.Parallel()
+	simulated := goarpa.NewSimulatedClient()
+	pen := simulated.AddItem(goarpa.GetServiceResponse{ItemCode: "PEN", ItemName: "Pen", SalePrice: goarpa.NewMoney(1000), Qty: "5"})
+	ctx := context.Background()
+
+	created, err := simulated.CreateCustomer(ctx, goarpa.SimulatedToken, nil, goarpa.CreateCustomerRequest{BusName: "Ali"})
+	require.NoError(t, err)
+	businessID := created.Data.BusinessID
+
+	sale := func(qty float64) (*goarpa.CreateTransactionResponse, error) {
+		return simulated.CreateTransaction(ctx, goarpa.SimulatedToken, goarpa.CreateTransactionRequest{
+			Data:  goarpa.Data{BusinessID: businessID, TransStateID: goarpa.TransStateFinal, FactorTypeID: goarpa.FactorTypeSale},
+			Items: []goarpa.TransactionItem{{ItemID: pen, Qty: qty}},
+		})
+	}
+
+	first, err := sale(2)
+	require.NoError(t, err)
+	second, err := sale(3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first.Data[0].TransNumber)
+	assert.Equal(t, int64(2), second.Data[0].TransNumber)
+
+	stock, ok := simulated.Stock(pen)
+	require.True(t, ok)
+	assert.Equal(t, 0.0, stock)
+
+	_, err = sale(1)
+	var apiErr *goarpa.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, goarpa.APIErrTypeArpa, apiErr.Type)
+
+	balance, err := simulated.GetCustomerBalance(ctx, goarpa.SimulatedToken, nil, businessID)
+	require.NoError(t, err)
+	assert.Equal(t, "5000", balance.Balance.String())
+
+	transactions, err := simulated.GetTransactions(ctx, goarpa.SimulatedToken, nil, goarpa.GetTransactionsParams{BusinessID: &businessID})
+	require.NoError(t, err)
+	require.Len(t, transactions.Data, 2)
+	assert.Equal(t, "2000", transactions.Data[0].TotalAmount.String())
+}
@@ -0,0 +1,298 @@
+package goarpa
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// ClientStateVersion is the version of the ClientState exported by the client
+const ClientStateVersion = 1
+
+// IdempotencyKeyTTL is how long the idempotency key of a request without an outcome is kept, see WithIdempotencyKeys
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// ClientState is the state a client keeps in memory, exported by ExportState to be imported by the client
+// of a restarted worker with ImportState. It holds the access tokens and the cookies of the sessions:
+// it must be stored as safely as the credentials.
+type ClientState struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	// Sessions are the sessions of the TokenManagers of the client which did not expire
+	Sessions []SessionState `json:"sessions,omitempty"`
+	// Watermarks are the watermarks of the client by name, see SetWatermark, e.g. those of the pollers.
+	// The watermarks of an arpasync.Syncer are kept by its store.
+	Watermarks map[string]time.Time `json:"watermarks,omitempty"`
+	// IdempotencyKeys are the keys of the mutating requests whose outcome is unknown, see WithIdempotencyKeys
+	IdempotencyKeys []IdempotencyKeyState `json:"idempotencyKeys,omitempty"`
+}
+
+// SessionState is the session of a TokenManager
+type SessionState struct {
+	Username  string         `json:"username"`
+	Token     string         `json:"token"`
+	Cookies   []*http.Cookie `json:"cookies,omitempty"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+}
+
+// IdempotencyKeyState is the idempotency key of a mutating request whose outcome is unknown
+type IdempotencyKeyState struct {
+	// Fingerprint identifies the request by its method, its URL and its body
+	Fingerprint string    `json:"fingerprint"`
+	Key         string    `json:"key"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ExportState returns the state of the client, see ClientState
+func (g *GoArpa) ExportState() ClientState {
+	now := g.Clock().Now()
+	state := ClientState{
+		Version:         ClientStateVersion,
+		ExportedAt:      now,
+		Watermarks:      g.state.watermarksCopy(),
+		IdempotencyKeys: g.state.idempotencyKeys(now),
+	}
+	exported := make(map[*TokenManager]bool)
+	g.managedSessions.Range(func(_, value any) bool {
+		manager := value.(*TokenManager)
+		if exported[manager] {
+			return true
+		}
+		exported[manager] = true
+		if session, ok := manager.export(now); ok {
+			state.Sessions = append(state.Sessions, session)
+		}
+		return true
+	})
+	return state
+}
+
+// ImportState restores the state exported by a client of the same server, e.g. before the restart of the worker.
+// The sessions are adopted by the TokenManagers of their usernames instead of logging in again, the watermarks
+// and the idempotency keys are merged with those of the client. The expired sessions and keys are ignored.
+func (g *GoArpa) ImportState(state ClientState) error {
+	if state.Version != ClientStateVersion {
+		return errors.Errorf("could not import client state: unsupported version %d", state.Version)
+	}
+	now := g.Clock().Now()
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	g.state.init()
+	for _, session := range state.Sessions {
+		if session.Token != "" && now.Before(session.ExpiresAt) {
+			g.state.sessions[session.Username] = session
+		}
+	}
+	for name, watermark := range state.Watermarks {
+		if watermark.After(g.state.watermarks[name]) {
+			g.state.watermarks[name] = watermark
+		}
+	}
+	for _, key := range state.IdempotencyKeys {
+		if now.Sub(key.CreatedAt) < IdempotencyKeyTTL && !g.state.hasIdempotencyKey(key) {
+			g.state.unknown[key.Fingerprint] = append(g.state.unknown[key.Fingerprint], key)
+		}
+	}
+	return nil
+}
+
+// Watermark returns the watermark of the name, e.g. the modification date of the last record polled,
+// and false when it is unknown
+func (g *GoArpa) Watermark(name string) (time.Time, bool) {
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	watermark, ok := g.state.watermarks[name]
+	return watermark, ok
+}
+
+// SetWatermark records the watermark of the name, to be exported with the state of the client
+func (g *GoArpa) SetWatermark(name string, watermark time.Time) {
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	g.state.init()
+	g.state.watermarks[name] = watermark
+}
+
+// clientState is the state of a client which is not held by other objects
+type clientState struct {
+	mu         sync.Mutex
+	watermarks map[string]time.Time
+	// sessions are the imported sessions by username, until their TokenManager adopts them
+	sessions map[string]SessionState
+	// unknown are the idempotency keys of the requests whose outcome is unknown by fingerprint,
+	// each is reused once by a request sent again
+	unknown map[string][]IdempotencyKeyState
+	// inflight are the idempotency keys of the requests being sent by key
+	inflight map[string]IdempotencyKeyState
+}
+
+// init allocates the maps, the lock must be held
+func (s *clientState) init() {
+	if s.watermarks == nil {
+		s.watermarks = make(map[string]time.Time)
+		s.sessions = make(map[string]SessionState)
+		s.unknown = make(map[string][]IdempotencyKeyState)
+		s.inflight = make(map[string]IdempotencyKeyState)
+	}
+}
+
+func (s *clientState) watermarksCopy() map[string]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.watermarks) == 0 {
+		return nil
+	}
+	watermarks := make(map[string]time.Time, len(s.watermarks))
+	for name, watermark := range s.watermarks {
+		watermarks[name] = watermark
+	}
+	return watermarks
+}
+
+// takeSession removes and returns the imported session of the username
+func (s *clientState) takeSession(username string) (SessionState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[username]
+	delete(s.sessions, username)
+	return session, ok
+}
+
+// idempotencyKey returns the key of a request sent with the fingerprint: the key of a previous request
+// whose outcome is unknown if any, a new key otherwise. The requests in flight never share a key,
+// two identical requests sent at the same time are two transactions.
+func (s *clientState) idempotencyKey(fingerprint string, now time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.init()
+	for pending, keys := range s.unknown {
+		live := keys[:0]
+		for _, key := range keys {
+			if now.Sub(key.CreatedAt) < IdempotencyKeyTTL {
+				live = append(live, key)
+			}
+		}
+		if len(live) == 0 {
+			delete(s.unknown, pending)
+		} else {
+			s.unknown[pending] = live
+		}
+	}
+
+	var key IdempotencyKeyState
+	if keys := s.unknown[fingerprint]; len(keys) > 0 {
+		key = keys[0]
+		if len(keys) == 1 {
+			delete(s.unknown, fingerprint)
+		} else {
+			s.unknown[fingerprint] = keys[1:]
+		}
+	} else {
+		key = IdempotencyKeyState{Fingerprint: fingerprint, Key: newIdempotencyKey(), CreatedAt: now}
+	}
+	s.inflight[key.Key] = key
+	return key.Key
+}
+
+// settleIdempotencyKey ends the request sent with the key: the key is forgotten when the request got an outcome,
+// it is kept to be reused by the same request sent again otherwise
+func (s *clientState) settleIdempotencyKey(key string, outcome bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending, ok := s.inflight[key]
+	if !ok {
+		return
+	}
+	delete(s.inflight, key)
+	if !outcome {
+		s.unknown[pending.Fingerprint] = append(s.unknown[pending.Fingerprint], pending)
+	}
+}
+
+// hasIdempotencyKey reports whether the key is known, the lock must be held
+func (s *clientState) hasIdempotencyKey(key IdempotencyKeyState) bool {
+	if _, ok := s.inflight[key.Key]; ok {
+		return true
+	}
+	for _, unknown := range s.unknown[key.Fingerprint] {
+		if unknown.Key == key.Key {
+			return true
+		}
+	}
+	return false
+}
+
+// idempotencyKeys returns the keys whose outcome is unknown, the requests in flight included
+func (s *clientState) idempotencyKeys(now time.Time) []IdempotencyKeyState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []IdempotencyKeyState
+	add := func(key IdempotencyKeyState) {
+		if now.Sub(key.CreatedAt) < IdempotencyKeyTTL {
+			keys = append(keys, key)
+		}
+	}
+	for _, unknown := range s.unknown {
+		for _, key := range unknown {
+			add(key)
+		}
+	}
+	for _, key := range s.inflight {
+		add(key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// requestFingerprint identifies a request by its method, its URL, its query, its credentials and its body
+func requestFingerprint(req *resty.Request) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s?%s\n", req.Method, req.URL, req.QueryParam.Encode())
+	fmt.Fprintf(hash, "%s\n%s\n", req.Token, req.Header.Get("Authorization"))
+	switch body := req.Body.(type) {
+	case nil:
+	case []byte:
+		hash.Write(body)
+	case string:
+		hash.Write([]byte(body))
+	default:
+		b, _ := json.Marshal(body)
+		hash.Write(b)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// export returns the session of the manager unless it has none or it expired
+func (m *TokenManager) export(now time.Time) (SessionState, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.token == "" || !now.Before(m.expiresAt) {
+		return SessionState{}, false
+	}
+	return SessionState{Username: m.username, Token: m.token, Cookies: m.cookies, ExpiresAt: m.expiresAt}, true
+}
+
+// adoptImportedSession takes the session of the username imported by the client, the lock must be held
+func (m *TokenManager) adoptImportedSession() bool {
+	session, ok := m.client.state.takeSession(m.username)
+	if !ok || !m.client.Clock().Now().Before(session.ExpiresAt.Add(-m.options.RefreshBefore)) {
+		return false
+	}
+	m.unregister()
+	m.token = session.Token
+	m.cookies = session.Cookies
+	m.expiresAt = session.ExpiresAt
+	m.register(m.token)
+	m.client.stats.trackSession(m, m.expiresAt)
+	return true
+}
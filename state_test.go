@@ -0,0 +1,202 @@
+package goarpa_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restart exports the state of the client and imports it in a new client, through JSON
+func restart(t *testing.T, client *goarpa.GoArpa, basePath string, options ...func(*goarpa.GoArpa)) *goarpa.GoArpa {
+	b, err := json.Marshal(client.ExportState())
+	require.NoError(t, err)
+	var state goarpa.ClientState
+	require.NoError(t, json.Unmarshal(b, &state))
+	restarted := goarpa.NewClient(basePath, options...)
+	require.NoError(t, restarted.ImportState(state))
+	return restarted
+}
+
+func Test_ExportStateSessions(t *testing.T) {
+	t.Parallel()
+	var logins atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		logins.Add(1)
+		http.SetCookie(w, &http.Cookie{Name: "ASP.NET_SessionId", Value: "s1"})
+		_, _ = w.Write([]byte(`"token-1"`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	ctx := context.Background()
+	exported, err := goarpa.NewTokenManager(client, "admin", "secret", goarpa.TokenManagerOptions{}).Token(ctx)
+	require.NoError(t, err)
+
+	restarted := restart(t, client, server.URL)
+	token, cookies, err := goarpa.NewTokenManager(restarted, "admin", "secret", goarpa.TokenManagerOptions{}).Session(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, exported, token)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "s1", cookies[0].Value)
+	assert.Equal(t, int32(1), logins.Load())
+	assert.Equal(t, 1, restarted.Stats().ActiveSessions)
+
+	// the session of another user is not adopted
+	_, err = goarpa.NewTokenManager(restart(t, client, server.URL), "other", "secret", goarpa.TokenManagerOptions{}).Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), logins.Load())
+}
+
+func Test_ExportStateIdempotencyKeys(t *testing.T) {
+	t.Parallel()
+	var (
+		mu   sync.Mutex
+		keys []string
+		down = true
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		w.Header().Set("Content-Type", "application/json")
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithIdempotencyKeys())
+	ctx := context.Background()
+	transaction := goarpa.CreateTransactionRequest{Data: goarpa.Data{BusinessID: 7, FactorTypeID: goarpa.FactorTypeSale, TransStateID: goarpa.TransStateFinal}}
+
+	_, err := client.CreateTransaction(ctx, "token", transaction)
+	require.Error(t, err)
+	require.Len(t, client.ExportState().IdempotencyKeys, 1)
+
+	// the restarted worker sends the transaction again with the same key
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	restarted := restart(t, client, server.URL, goarpa.WithIdempotencyKeys())
+	_, err = restarted.CreateTransaction(ctx, "token", transaction)
+	require.NoError(t, err)
+	assert.Empty(t, restarted.ExportState().IdempotencyKeys)
+
+	// a transaction sent again after its outcome is a new transaction
+	_, err = restarted.CreateTransaction(ctx, "token", transaction)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+	assert.NotEqual(t, keys[1], keys[2])
+}
+
+func Test_IdempotencyKeysConcurrentRequests(t *testing.T) {
+	t.Parallel()
+	var (
+		mu   sync.Mutex
+		keys = make(map[string]bool)
+	)
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys[r.Header.Get("Idempotency-Key")] = true
+		mu.Unlock()
+		arrived <- struct{}{}
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"TransactionID":"42","TransNumber":9}]}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithIdempotencyKeys())
+	ctx := context.Background()
+	// two identical sales to a walk-in customer are two transactions
+	transaction := goarpa.CreateTransactionRequest{Data: goarpa.Data{BusinessID: 7, FactorTypeID: goarpa.FactorTypeSale, TransStateID: goarpa.TransStateFinal}}
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.CreateTransaction(ctx, "token", transaction)
+			assert.NoError(t, err)
+		}()
+	}
+	<-arrived
+	<-arrived
+	assert.Len(t, client.ExportState().IdempotencyKeys, 2)
+	close(release)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, keys, 2)
+	assert.Empty(t, client.ExportState().IdempotencyKeys)
+}
+
+func Test_IdempotencyKeysIdentity(t *testing.T) {
+	t.Parallel()
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithIdempotencyKeys())
+	ctx := context.Background()
+	transaction := goarpa.CreateTransactionRequest{Data: goarpa.Data{BusinessID: 7, FactorTypeID: goarpa.FactorTypeSale, TransStateID: goarpa.TransStateFinal}}
+
+	// the same transaction of another caller is another request
+	_, err := client.CreateTransaction(ctx, "token-1", transaction)
+	require.Error(t, err)
+	_, err = client.CreateTransaction(ctx, "token-2", transaction)
+	require.Error(t, err)
+	// the request sent again by the first caller reuses its key
+	_, err = client.CreateTransaction(ctx, "token-1", transaction)
+	require.Error(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, keys, 3)
+	assert.NotEqual(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], keys[2])
+}
+
+func Test_ExportStateWatermarks(t *testing.T) {
+	t.Parallel()
+	client := goarpa.NewClient("http://localhost")
+	since := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	client.SetWatermark("poller.customer", since)
+
+	restarted := restart(t, client, "http://localhost")
+	watermark, ok := restarted.Watermark("poller.customer")
+	require.True(t, ok)
+	assert.True(t, since.Equal(watermark))
+	_, ok = restarted.Watermark("poller.transaction")
+	assert.False(t, ok)
+
+	assert.ErrorContains(t, restarted.ImportState(goarpa.ClientState{Version: 99}), "unsupported version 99")
+}
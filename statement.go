@@ -0,0 +1,47 @@
+package goarpa
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StatementFormat is the document format of a customer statement
+type StatementFormat string
+
+const (
+	StatementFormatPDF  StatementFormat = "pdf"
+	StatementFormatXLSX StatementFormat = "xlsx"
+)
+
+// CustomerStatementParams select the business, the period and the format of DownloadCustomerStatement
+type CustomerStatementParams struct {
+	BusinessID BusinessID `json:"BusinessID"`
+	FromDate   *time.Time `json:"FromDate,omitempty"`
+	ToDate     *time.Time `json:"ToDate,omitempty"`
+	// Format is StatementFormatPDF by default
+	Format StatementFormat `json:"Format,omitempty"`
+}
+
+// DownloadCustomerStatement writes the account statement of the business formatted by Arpa into w,
+// e.g. the official statement a customer asks for, and returns the number of bytes written, see DownloadFile.
+// It returns ErrNotSupported when the installation has no statement endpoint.
+func (g *GoArpa) DownloadCustomerStatement(ctx context.Context, accessToken string, cookie []*http.Cookie, params CustomerStatementParams, w io.Writer) (int64, error) {
+	const errMessage = "could not download customer statement"
+
+	if params.BusinessID == 0 {
+		return 0, errors.Wrap(errors.New("missing business ID"), errMessage)
+	}
+	if params.FromDate != nil && params.ToDate != nil && params.ToDate.Before(*params.FromDate) {
+		return 0, errors.Wrap(errors.New("the period ends before it starts"), errMessage)
+	}
+	if params.Format == "" {
+		params.Format = StatementFormatPDF
+	}
+
+	n, err := g.DownloadFile(ctx, accessToken, cookie, g.config().GetCustomerStatementEndpoint, params, w)
+	return n, errors.Wrap(err, errMessage)
+}
@@ -0,0 +1,47 @@
+package goarpa_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DownloadCustomerStatement(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/statement", r.URL.Path)
+		assert.Equal(t, "7", r.URL.Query().Get("BusinessID"))
+		assert.Equal(t, "pdf", r.URL.Query().Get("Format"))
+		assert.NotEmpty(t, r.URL.Query().Get("FromDate"))
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.7 statement"))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	ctx := context.Background()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 3, 0)
+	params := goarpa.CustomerStatementParams{BusinessID: 7, FromDate: &from, ToDate: &to}
+	var buf bytes.Buffer
+	_, err := client.DownloadCustomerStatement(ctx, "token", nil, params, &buf)
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+
+	client.Config.GetCustomerStatementEndpoint = "statement"
+	n, err := client.DownloadCustomerStatement(ctx, "token", nil, params, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Equal(t, "%PDF-1.7 statement", buf.String())
+
+	params.FromDate, params.ToDate = &to, &from
+	_, err = client.DownloadCustomerStatement(ctx, "token", nil, params, &buf)
+	assert.Error(t, err)
+}
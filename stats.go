@@ -0,0 +1,205 @@
+package goarpa
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ClientStats is a snapshot of the counters of a client, e.g. to expose on a debug endpoint as JSON.
+// The counters start when the client is created.
+type ClientStats struct {
+	// Since is the time the counters started
+	Since time.Time `json:"since"`
+	// Requests is the number of calls made, a retried call counts once
+	Requests int64 `json:"requests"`
+	// Retries is the number of retries of the calls
+	Retries int64 `json:"retries"`
+	// Errors are the failed calls by type: "http 4xx", "http 5xx", "canceled", "transport"
+	// and the types of the errors reported by Arpa, e.g. "arpa" or "session expired"
+	Errors map[string]int64 `json:"errors"`
+	// AverageLatency is the average duration of the calls, including their retries
+	AverageLatency time.Duration `json:"averageLatency"`
+	// Endpoints are the counters by endpoint, relative to the base path
+	Endpoints map[string]EndpointStats `json:"endpoints"`
+	// ActiveSessions is the number of sessions of the TokenManagers of the client which did not expire
+	ActiveSessions int `json:"activeSessions"`
+	// Throttle is the state of the throttle of the client, nil without WithAdaptiveThrottling
+	Throttle *ThrottleStats `json:"throttle,omitempty"`
+}
+
+// EndpointStats are the counters of an endpoint
+type EndpointStats struct {
+	Requests       int64         `json:"requests"`
+	Errors         int64         `json:"errors"`
+	AverageLatency time.Duration `json:"averageLatency"`
+}
+
+// clientStats collects the counters of a client
+type clientStats struct {
+	mu        sync.Mutex
+	since     time.Time
+	requests  int64
+	retries   int64
+	latency   time.Duration
+	errors    map[string]int64
+	endpoints map[string]*endpointStats
+	// sessions are the expiry of the sessions by TokenManager
+	sessions map[*TokenManager]time.Time
+}
+
+type endpointStats struct {
+	requests int64
+	errors   int64
+	latency  time.Duration
+}
+
+// Stats returns a snapshot of the counters of the client
+func (g *GoArpa) Stats() ClientStats {
+	s := &g.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := ClientStats{
+		Since:     s.since,
+		Requests:  s.requests,
+		Retries:   s.retries,
+		Errors:    make(map[string]int64, len(s.errors)),
+		Endpoints: make(map[string]EndpointStats, len(s.endpoints)),
+	}
+	if s.requests > 0 {
+		stats.AverageLatency = s.latency / time.Duration(s.requests)
+	}
+	for errType, count := range s.errors {
+		stats.Errors[errType] = count
+	}
+	for endpoint, counters := range s.endpoints {
+		endpointStats := EndpointStats{Requests: counters.requests, Errors: counters.errors}
+		if counters.requests > 0 {
+			endpointStats.AverageLatency = counters.latency / time.Duration(counters.requests)
+		}
+		stats.Endpoints[endpoint] = endpointStats
+	}
+	now := g.Clock().Now()
+	for _, expiresAt := range s.sessions {
+		if now.Before(expiresAt) {
+			stats.ActiveSessions++
+		}
+	}
+	if g.throttle != nil {
+		throttle := g.throttle.snapshot()
+		stats.Throttle = &throttle
+	}
+	return stats
+}
+
+// init starts the counters at the time
+func (s *clientStats) init(since time.Time) {
+	s.since = since
+	s.errors = make(map[string]int64)
+	s.endpoints = make(map[string]*endpointStats)
+	s.sessions = make(map[*TokenManager]time.Time)
+}
+
+// endpoint returns the counters of the endpoint, the lock must be held
+func (s *clientStats) endpoint(endpoint string) *endpointStats {
+	counters, ok := s.endpoints[endpoint]
+	if !ok {
+		counters = &endpointStats{}
+		s.endpoints[endpoint] = counters
+	}
+	return counters
+}
+
+// recordCall counts a completed call, errType is empty when it succeeded
+func (s *clientStats) recordCall(req *resty.Request, endpoint string, errType string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if req != nil && req.Attempt > 1 {
+		s.retries += int64(req.Attempt - 1)
+	}
+	s.latency += latency
+	counters := s.endpoint(endpoint)
+	counters.requests++
+	counters.latency += latency
+	if errType != "" {
+		s.errors[errType]++
+		counters.errors++
+	}
+}
+
+// recordError counts an error reported by Arpa in the envelope of a successful response
+func (s *clientStats) recordError(endpoint string, errType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[errType]++
+	s.endpoint(endpoint).errors++
+}
+
+// trackSession records the expiry of the session of the token manager, a zero expiry ends it
+func (s *clientStats) trackSession(manager *TokenManager, expiresAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expiresAt.IsZero() {
+		delete(s.sessions, manager)
+		return
+	}
+	s.sessions[manager] = expiresAt
+}
+
+// statsEndpoint returns the endpoint of the request relative to the base path, without its query
+func (g *GoArpa) statsEndpoint(req *resty.Request) string {
+	if req == nil {
+		return ""
+	}
+	endpoint := strings.TrimPrefix(req.URL, g.basePath)
+	if u, err := url.Parse(endpoint); err == nil {
+		endpoint = u.Path
+	}
+	return strings.TrimPrefix(endpoint, urlSeparator)
+}
+
+// onCallSuccess counts a call which got a response
+func (g *GoArpa) onCallSuccess(_ *resty.Client, resp *resty.Response) {
+	var errType string
+	switch {
+	case isLoginRedirect(resp):
+		errType = string(APIErrTypeSessionExpired)
+	case resp.IsError():
+		errType = fmt.Sprintf("http %dxx", resp.StatusCode()/100)
+	}
+	g.stats.recordCall(resp.Request, g.statsEndpoint(resp.Request), errType, g.callLatency(resp.Request))
+}
+
+// onCallError counts a call which failed
+func (g *GoArpa) onCallError(req *resty.Request, err error) {
+	errType := "transport"
+	var respErr *resty.ResponseError
+	switch {
+	case isContextError(err):
+		errType = "canceled"
+	case errors.As(err, &respErr) && respErr.Response.IsError():
+		errType = fmt.Sprintf("http %dxx", respErr.Response.StatusCode()/100)
+	}
+	g.stats.recordCall(req, g.statsEndpoint(req), errType, g.callLatency(req))
+}
+
+// callLatency returns the duration of the call since its first attempt
+func (g *GoArpa) callLatency(req *resty.Request) time.Duration {
+	if req == nil {
+		return 0
+	}
+	state := retryStateFromRequest(req)
+	if state == nil {
+		return time.Since(req.Time)
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return g.Clock().Now().Sub(state.start)
+}
@@ -0,0 +1,65 @@
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Stats(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/serv/token/GetServiceToken":
+			_, _ = w.Write([]byte(`"token"`))
+		case r.URL.Path == "/items":
+			w.WriteHeader(http.StatusBadGateway)
+		case r.URL.Query().Get("BusinessCode") != "":
+			_, _ = w.Write([]byte(`{"data":[],"error":"business not found"}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"7"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	client.Config.GetItemsEndpoint = "items"
+	client.RestyClient().
+		SetRetryCount(1).
+		SetRetryWaitTime(time.Millisecond).
+		AddRetryCondition(func(r *resty.Response, err error) bool {
+			return r != nil && r.StatusCode() == http.StatusBadGateway
+		})
+	ctx := context.Background()
+
+	session := goarpa.NewTokenManager(client, "admin", "secret", goarpa.TokenManagerOptions{})
+	token, err := session.Token(ctx)
+	require.NoError(t, err)
+	_, err = client.GetCustomerByMobile(ctx, token, nil, "09120000000")
+	require.NoError(t, err)
+	_, err = client.GetCustomerByBusinessCode(ctx, token, nil, "1001")
+	require.Error(t, err)
+	_, err = client.GetItems(ctx, token, nil, goarpa.GetItemsParams{})
+	require.Error(t, err)
+
+	stats := client.Stats()
+	assert.Equal(t, int64(4), stats.Requests)
+	assert.Equal(t, int64(1), stats.Retries)
+	assert.Equal(t, map[string]int64{"arpa": 1, "http 5xx": 1}, stats.Errors)
+	assert.Equal(t, int64(2), stats.Endpoints["serv/api/GetBusiness"].Requests)
+	assert.Equal(t, int64(1), stats.Endpoints["serv/api/GetBusiness"].Errors)
+	assert.Equal(t, int64(1), stats.Endpoints["items"].Errors)
+	assert.Greater(t, stats.AverageLatency, time.Duration(0))
+	assert.Equal(t, 1, stats.ActiveSessions)
+
+	session.Invalidate()
+	assert.Equal(t, 0, client.Stats().ActiveSessions)
+}
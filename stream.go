@@ -0,0 +1,168 @@
+package goarpa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+var streamingContextKey = &contextKey{"streaming"}
+
+// GetCustomersStream decodes the businesses one by one while the response is read,
+// instead of buffering the whole body, and calls fn for each of them.
+// Returning an error from fn stops the decoding and returns that error.
+func (g *GoArpa) GetCustomersStream(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCustomersParams, fn func(Customer) error) error {
+	const errMessage = "could not get customers"
+
+	filter := params.Inactive.or(g.inactiveFilter)
+	return g.getStream(ctx, accessToken, cookie, g.config().GetCustomersEndpoint, params, errMessage, func(data json.RawMessage) error {
+		var datum Datum2
+		if err := json.Unmarshal(data, &datum); err != nil {
+			return err
+		}
+		if !filter.keep(!datum.IsActive()) {
+			return nil
+		}
+		return fn(datum.ToCustomer())
+	})
+}
+
+// GetTransactionsStream decodes the transactions one by one, see GetCustomersStream
+func (g *GoArpa) GetTransactionsStream(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetTransactionsParams, fn func(Transaction) error) error {
+	const errMessage = "could not get transactions"
+
+	return g.getStream(ctx, accessToken, cookie, g.config().GetTransactionsEndpoint, params, errMessage, func(data json.RawMessage) error {
+		var transaction Transaction
+		if err := json.Unmarshal(data, &transaction); err != nil {
+			return err
+		}
+		return fn(transaction)
+	})
+}
+
+// getStream requests a list endpoint without parsing the response and streams the items of its data
+func (g *GoArpa) getStream(ctx context.Context, accessToken string, cookie []*http.Cookie, endpoint string, params interface{}, errMessage string, fn func(json.RawMessage) error) error {
+	url, err := g.endpointURL(endpoint)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+
+	queryParams, err := GetQueryParams(params)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+
+	resp, err := g.GetRequestWithBearerAuthWithCookie(context.WithValue(ctx, streamingContextKey, true), accessToken, cookie).
+		SetQueryParams(queryParams).
+		SetDoNotParseResponse(true).
+		Get(url)
+	if resp != nil && resp.RawBody() != nil {
+		defer resp.RawBody().Close()
+	}
+
+	if err := checkForError(resp, err, errMessage); err != nil {
+		return err
+	}
+
+	arpaErr, err := streamEnvelope(resp.RawBody(), fn)
+	if err != nil {
+		return errors.Wrap(err, errMessage)
+	}
+	return g.checkForArpaError(resp, arpaErr, errMessage)
+}
+
+// streamEnvelope reads an Arpa envelope calling fn for each item of its data,
+// which can be an array or a single object
+func streamEnvelope(body io.Reader, fn func(json.RawMessage) error) (*ArpaError, error) {
+	decoder := json.NewDecoder(body)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	var arpaErr *ArpaError
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		switch {
+		case key == "error":
+			if err := decoder.Decode(&arpaErr); err != nil {
+				return nil, err
+			}
+		case key == "data":
+			if err := streamData(decoder, fn); err != nil {
+				return arpaErr, err
+			}
+		default:
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return arpaErr, expectDelim(decoder, '}')
+}
+
+func streamData(decoder *json.Decoder, fn func(json.RawMessage) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case nil:
+		return nil
+	case json.Delim('{'):
+		// a single object, its fields are collected since the opening brace is already consumed
+		fields := make(map[string]json.RawMessage)
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return err
+			}
+			fields[fmt.Sprint(key)] = value
+		}
+		if err := expectDelim(decoder, '}'); err != nil {
+			return err
+		}
+		item, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		return fn(item)
+	case json.Delim('['):
+		for decoder.More() {
+			var item json.RawMessage
+			if err := decoder.Decode(&item); err != nil {
+				return err
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return expectDelim(decoder, ']')
+	}
+	return fmt.Errorf("unexpected token %v in data", token)
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("unexpected token %v, expected %v", token, delim)
+	}
+	return nil
+}
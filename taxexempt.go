@@ -0,0 +1,47 @@
+package goarpa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erfandiakoo/goarpa/v2/shared/constant"
+	"github.com/pkg/errors"
+)
+
+// SetCustomerTaxExempt exempts the business from the tax and toll, or charges them again, see Customer.TaxExempt.
+// It returns ErrNotSupported when the installation has no update endpoint, see UpdateCustomerPartial.
+func (g *GoArpa) SetCustomerTaxExempt(ctx context.Context, accessToken string, cookie []*http.Cookie, businessID BusinessID, exempt bool) error {
+	return setCustomerTaxExempt(ctx, g, accessToken, cookie, businessID, exempt)
+}
+
+func setCustomerTaxExempt(ctx context.Context, client GoArpaIface, accessToken string, cookie []*http.Cookie, businessID BusinessID, exempt bool) error {
+	changes := CustomerChanges{string(constant.TaxExemptField): json.RawMessage(strconv.FormatBool(exempt))}
+	if _, err := client.UpdateCustomerPartial(ctx, accessToken, cookie, businessID, changes); err != nil {
+		return errors.Wrap(err, "could not set customer tax exemption")
+	}
+	return nil
+}
+
+// NewCustomerTransactionRequest returns a transaction of the customer with the defaults of the installation profile,
+// see NewTransactionRequest. The transaction calculates the tax and toll, which are disabled for the transaction and
+// all its lines when the customer is tax exempt, unless keepTaxAndToll overrides the exemption, e.g. for goods taxed
+// whatever the customer.
+func (g *GoArpa) NewCustomerTransactionRequest(customer Customer, factorType FactorType, keepTaxAndToll bool, items ...TransactionItem) CreateTransactionRequest {
+	transaction := g.NewTransactionRequest(customer.ID, factorType, items...)
+	if customer.TaxExempt && !keepTaxAndToll {
+		transaction.ExemptTaxAndToll()
+	} else {
+		transaction.Data.CalcTaxAndToll = 1
+	}
+	return transaction
+}
+
+// ExemptTaxAndToll disables the tax and toll of the transaction and of all its lines
+func (r *CreateTransactionRequest) ExemptTaxAndToll() {
+	r.Data.CalcTaxAndToll = 0
+	for i := range r.Items {
+		r.Items[i].TaxExempt = true
+	}
+}
@@ -0,0 +1,61 @@
+package goarpa_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SetCustomerTaxExempt(t *testing.T) {
+	t.Parallel()
+	simulated := goarpa.NewSimulatedClient()
+	ctx := context.Background()
+
+	created, err := simulated.CreateCustomer(ctx, goarpa.SimulatedToken, nil, goarpa.CreateCustomerRequest{BusName: "Embassy"})
+	require.NoError(t, err)
+	businessID := created.Data.BusinessID
+
+	exempt := func() bool {
+		customer, err := simulated.GetCustomerByBusinessCode(ctx, goarpa.SimulatedToken, nil, string(created.Data.BusinessCode))
+		require.NoError(t, err)
+		return customer.Data[0].ToCustomer().TaxExempt
+	}
+	assert.False(t, exempt())
+	require.NoError(t, simulated.SetCustomerTaxExempt(ctx, goarpa.SimulatedToken, nil, businessID, true))
+	assert.True(t, exempt())
+	require.NoError(t, simulated.SetCustomerTaxExempt(ctx, goarpa.SimulatedToken, nil, businessID, false))
+	assert.False(t, exempt())
+
+	request, err := goarpa.Customer{Name: "Embassy", TaxExempt: true}.ToCreateCustomerRequest()
+	require.NoError(t, err)
+	require.NotNil(t, request.TaxExempt)
+	assert.True(t, *request.TaxExempt)
+}
+
+func Test_NewCustomerTransactionRequest(t *testing.T) {
+	t.Parallel()
+	client := goarpa.NewClient("http://localhost")
+	tax := goarpa.TaxConfig{TaxPercent: 9, TollPercent: 1}
+	item := goarpa.TransactionItem{ItemID: 1, Qty: 2, Price: goarpa.NewMoney(1000)}
+	customer := goarpa.Customer{ID: 42}
+
+	transaction := client.NewCustomerTransactionRequest(customer, goarpa.FactorTypeSale, false, item)
+	assert.Equal(t, int64(1), transaction.Data.CalcTaxAndToll)
+	assert.False(t, transaction.Items[0].TaxExempt)
+	assert.Equal(t, "2200", transaction.CalculateTotals(tax).Total.String())
+
+	customer.TaxExempt = true
+	transaction = client.NewCustomerTransactionRequest(customer, goarpa.FactorTypeSale, false, item)
+	assert.Equal(t, int64(0), transaction.Data.CalcTaxAndToll)
+	assert.True(t, transaction.Items[0].TaxExempt)
+	assert.Equal(t, "2000", transaction.CalculateTotals(tax).Total.String())
+
+	// the override keeps the tax and toll of an exempt customer
+	transaction = client.NewCustomerTransactionRequest(customer, goarpa.FactorTypeSale, true, item)
+	assert.Equal(t, int64(1), transaction.Data.CalcTaxAndToll)
+	assert.False(t, transaction.Items[0].TaxExempt)
+	assert.Equal(t, "2200", transaction.CalculateTotals(tax).Total.String())
+}
@@ -0,0 +1,140 @@
+package goarpa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrTenantNotFound is returned by the TenantManager for an unknown tenant
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// TenantConfig is the Arpa server and the credentials of a tenant
+type TenantConfig struct {
+	BasePath string
+	Username string
+	Password string
+	// Options are applied to the client of the tenant after the options of the manager
+	Options []func(*GoArpa)
+}
+
+// TenantLookup returns the config of a tenant, or an error matching ErrTenantNotFound
+type TenantLookup func(ctx context.Context, tenantID string) (TenantConfig, error)
+
+// StaticTenants returns a lookup serving the tenants of the map
+func StaticTenants(tenants map[string]TenantConfig) TenantLookup {
+	return func(_ context.Context, tenantID string) (TenantConfig, error) {
+		config, ok := tenants[tenantID]
+		if !ok {
+			return TenantConfig{}, fmt.Errorf("%w: %s", ErrTenantNotFound, tenantID)
+		}
+		return config, nil
+	}
+}
+
+// TenantManagerOptions configure a TenantManager
+type TenantManagerOptions struct {
+	// ClientOptions are applied to the clients of all the tenants
+	ClientOptions []func(*GoArpa)
+	// Token configures the token managers of the tenants
+	Token TokenManagerOptions
+}
+
+// TenantManager holds the clients and the sessions of many Arpa companies.
+// They are constructed on the first use of a tenant and cached until the tenant is removed.
+// It is safe for concurrent use.
+type TenantManager struct {
+	lookup  TenantLookup
+	options TenantManagerOptions
+
+	mu      sync.Mutex
+	tenants map[string]*tenant
+}
+
+type tenant struct {
+	client *GoArpa
+	tokens *TokenManager
+}
+
+// NewTenantManager returns a tenant manager reading the configs of the tenants with the lookup
+func NewTenantManager(lookup TenantLookup, options TenantManagerOptions) *TenantManager {
+	return &TenantManager{
+		lookup:  lookup,
+		options: options,
+		tenants: make(map[string]*tenant),
+	}
+}
+
+// ForTenant returns the client of the tenant
+func (m *TenantManager) ForTenant(ctx context.Context, tenantID string) (GoArpaIface, error) {
+	t, err := m.tenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return t.client, nil
+}
+
+// Session returns a valid access token of the tenant with the cookies of its session
+func (m *TenantManager) Session(ctx context.Context, tenantID string) (string, []*http.Cookie, error) {
+	t, err := m.tenant(ctx, tenantID)
+	if err != nil {
+		return "", nil, err
+	}
+	return t.tokens.Session(ctx)
+}
+
+// TokenSource returns the token source of the tenant
+func (m *TenantManager) TokenSource(tenantID string) TokenSource {
+	return func(ctx context.Context) (string, error) {
+		token, _, err := m.Session(ctx, tenantID)
+		return token, err
+	}
+}
+
+// Invalidate drops the cached session of the tenant, e.g. after Arpa rejected its token
+func (m *TenantManager) Invalidate(tenantID string) {
+	m.mu.Lock()
+	t := m.tenants[tenantID]
+	m.mu.Unlock()
+	if t != nil {
+		t.tokens.Invalidate()
+	}
+}
+
+// Remove drops the client and the session of the tenant, so that its config is read again on the next use
+func (m *TenantManager) Remove(tenantID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tenants, tenantID)
+}
+
+func (m *TenantManager) tenant(ctx context.Context, tenantID string) (*tenant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.tenants[tenantID]; ok {
+		return t, nil
+	}
+
+	config, err := m.lookup(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if config.BasePath == "" {
+		return nil, fmt.Errorf("tenant %s has no base path", tenantID)
+	}
+
+	options := append(append([]func(*GoArpa){}, m.options.ClientOptions...), config.Options...)
+	client, err := NewValidatedClient(config.BasePath, options...)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+	}
+	t := &tenant{
+		client: client,
+		tokens: NewTokenManager(client, config.Username, config.Password, m.options.Token),
+	}
+	m.tenants[tenantID] = t
+	return t, nil
+}
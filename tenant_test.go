@@ -0,0 +1,69 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TenantManager(t *testing.T) {
+	t.Parallel()
+	newServer := func(token string, logins *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logins.Add(1)
+			_, _ = w.Write([]byte(token))
+		}))
+	}
+	var loginsA, loginsB atomic.Int32
+	serverA := newServer("token-a", &loginsA)
+	defer serverA.Close()
+	serverB := newServer("token-b", &loginsB)
+	defer serverB.Close()
+
+	manager := goarpa.NewTenantManager(goarpa.StaticTenants(map[string]goarpa.TenantConfig{
+		"a": {BasePath: serverA.URL, Username: "user-a", Password: "pass"},
+		"b": {BasePath: serverB.URL, Username: "user-b", Password: "pass"},
+	}), goarpa.TenantManagerOptions{})
+	ctx := context.Background()
+
+	clientA, err := manager.ForTenant(ctx, "a")
+	require.NoError(t, err)
+	again, err := manager.ForTenant(ctx, "a")
+	require.NoError(t, err)
+	assert.Same(t, clientA, again)
+
+	clientB, err := manager.ForTenant(ctx, "b")
+	require.NoError(t, err)
+	assert.NotSame(t, clientA, clientB)
+
+	for i := 0; i < 2; i++ {
+		token, err := manager.TokenSource("a")(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-a", token)
+	}
+	token, _, err := manager.Session(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "token-b", token)
+	assert.Equal(t, int32(1), loginsA.Load())
+	assert.Equal(t, int32(1), loginsB.Load())
+
+	manager.Invalidate("a")
+	_, err = manager.TokenSource("a")(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), loginsA.Load())
+
+	manager.Remove("a")
+	fresh, err := manager.ForTenant(ctx, "a")
+	require.NoError(t, err)
+	assert.NotSame(t, clientA, fresh)
+
+	_, err = manager.ForTenant(ctx, "unknown")
+	assert.True(t, errors.Is(err, goarpa.ErrTenantNotFound))
+}
@@ -0,0 +1,192 @@
+package goarpa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// throttledMessages are the parts of the errors Arpa answers the calls over its rate limit with,
+// they are compared case-insensitively
+var throttledMessages = [][]byte{
+	[]byte("too many requests"),
+	[]byte("rate limit"),
+	[]byte("تعداد درخواست"),
+}
+
+// maxThrottledBodySize is the size of the largest body searched for the throttledMessages
+const maxThrottledBodySize = 4096
+
+// AdaptiveThrottle paces the requests of a client at a rate adapted to the throttling of Arpa:
+// the rate grows additively while Arpa answers, and is multiplied by Decrease when Arpa throttles a request,
+// with a 429 or a "too many requests" body. The rate is decreased once per second at most,
+// so that the requests in flight when Arpa starts throttling divide it once.
+type AdaptiveThrottle struct {
+	// InitialRate is the number of requests per second the client starts with
+	InitialRate float64
+	// MinRate and MaxRate bound the rate
+	MinRate float64
+	MaxRate float64
+	// Increase is the rate added per second of requests answered at the current rate
+	Increase float64
+	// Decrease multiplies the rate when Arpa throttles a request, between 0 and 1
+	Decrease float64
+}
+
+// DefaultAdaptiveThrottle is the throttle of WithAdaptiveThrottling for its zero fields
+var DefaultAdaptiveThrottle = AdaptiveThrottle{InitialRate: 20, MinRate: 1, MaxRate: 100, Increase: 1, Decrease: 0.5}
+
+// ThrottleStats is the state of the throttle of a client, see WithAdaptiveThrottling
+type ThrottleStats struct {
+	// Rate is the number of requests per second currently allowed
+	Rate float64 `json:"rate"`
+	// Throttled is the number of responses throttling the client
+	Throttled int64 `json:"throttled"`
+	// LastThrottledAt is the time of the last of them, zero if none
+	LastThrottledAt time.Time `json:"lastThrottledAt"`
+}
+
+// WithAdaptiveThrottling paces the requests of the client, including their retries, at a rate adapted
+// to the throttling of Arpa, see AdaptiveThrottle. The zero fields of the throttle are those of
+// DefaultAdaptiveThrottle. The state of the throttle is in the Throttle of the Stats of the client.
+func WithAdaptiveThrottling(throttle AdaptiveThrottle) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.throttle = newAdaptiveThrottle(throttle)
+	}
+}
+
+// adaptiveThrottle is the state of an AdaptiveThrottle
+type adaptiveThrottle struct {
+	mu          sync.Mutex
+	config      AdaptiveThrottle
+	rate        float64
+	next        time.Time
+	decreasedAt time.Time
+	stats       ThrottleStats
+}
+
+func newAdaptiveThrottle(config AdaptiveThrottle) *adaptiveThrottle {
+	if config.InitialRate <= 0 {
+		config.InitialRate = DefaultAdaptiveThrottle.InitialRate
+	}
+	if config.MinRate <= 0 {
+		config.MinRate = DefaultAdaptiveThrottle.MinRate
+	}
+	if config.MaxRate <= 0 {
+		config.MaxRate = DefaultAdaptiveThrottle.MaxRate
+	}
+	if config.Increase <= 0 {
+		config.Increase = DefaultAdaptiveThrottle.Increase
+	}
+	if config.Decrease <= 0 || config.Decrease >= 1 {
+		config.Decrease = DefaultAdaptiveThrottle.Decrease
+	}
+	return &adaptiveThrottle{
+		config: config,
+		rate:   min(max(config.InitialRate, config.MinRate), config.MaxRate),
+	}
+}
+
+// reserve returns the wait before the next request may start
+func (t *adaptiveThrottle) reserve(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start := t.next
+	if start.Before(now) {
+		start = now
+	}
+	t.next = start.Add(time.Duration(float64(time.Second) / t.rate))
+	return start.Sub(now)
+}
+
+// observe adapts the rate to a response
+func (t *adaptiveThrottle) observe(now time.Time, throttled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !throttled {
+		// rate requests a second add Increase a second
+		t.rate = min(t.rate+t.config.Increase/t.rate, t.config.MaxRate)
+		return
+	}
+	t.stats.Throttled++
+	t.stats.LastThrottledAt = now
+	if now.Sub(t.decreasedAt) >= time.Second {
+		t.rate = max(t.rate*t.config.Decrease, t.config.MinRate)
+		t.decreasedAt = now
+	}
+}
+
+// snapshot returns the state of the throttle
+func (t *adaptiveThrottle) snapshot() ThrottleStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.stats
+	stats.Rate = t.rate
+	return stats
+}
+
+// isThrottledResponse reports whether Arpa refused the request for its rate. The body of a failed response
+// is searched for the throttledMessages, only the error field of the envelope of a successful one:
+// its data may hold the same words, e.g. in the name of an item.
+func isThrottledResponse(resp *resty.Response) bool {
+	if resp.StatusCode() == http.StatusTooManyRequests {
+		return true
+	}
+	// the throttling messages are short, the catalogs are not searched
+	body := resp.Body()
+	if len(body) > maxThrottledBodySize {
+		return false
+	}
+	if !resp.IsError() {
+		var envelope struct {
+			Error json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return false
+		}
+		body = envelope.Error
+	}
+	body = bytes.ToLower(body)
+	for _, message := range throttledMessages {
+		if bytes.Contains(body, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitThrottle delays the request to the rate of the throttle
+func (g *GoArpa) waitThrottle(_ *resty.Client, req *resty.Request) error {
+	if g.throttle == nil {
+		return nil
+	}
+	wait := g.throttle.reserve(g.Clock().Now())
+	if wait <= 0 {
+		return nil
+	}
+	ctx := req.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timer := g.Clock().NewTimer(wait)
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	}
+}
+
+// observeThrottle adapts the rate of the throttle to the response
+func (g *GoArpa) observeThrottle(_ *resty.Client, resp *resty.Response) error {
+	if g.throttle != nil {
+		g.throttle.observe(g.Clock().Now(), isThrottledResponse(resp))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package goarpa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AdaptiveThrottling(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("MobileNo") {
+		case "09120000001":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "09120000002":
+			_, _ = w.Write([]byte(`{"data":[],"error":"Too Many Requests, retry later"}`))
+		case "09120000003":
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"8","BusName":"Rate Limit Consulting","Address":"تعداد درخواست"}],"error":null}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":[{"BusinessID":"7"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithAdaptiveThrottling(goarpa.AdaptiveThrottle{InitialRate: 50, MaxRate: 60}))
+	ctx := context.Background()
+	assert.Nil(t, goarpa.NewClient(server.URL).Stats().Throttle)
+
+	// the requests are paced at the rate
+	start := time.Now()
+	for range 3 {
+		_, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	throttle := client.Stats().Throttle
+	require.NotNil(t, throttle)
+	assert.Greater(t, throttle.Rate, 50.0)
+	assert.Zero(t, throttle.Throttled)
+
+	// the rate is halved once by the responses throttling the client at the same time
+	_, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000001")
+	require.Error(t, err)
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000002")
+	require.Error(t, err)
+	throttle = client.Stats().Throttle
+	assert.Equal(t, int64(2), throttle.Throttled)
+	assert.InDelta(t, 25.0, throttle.Rate, 1)
+	assert.False(t, throttle.LastThrottledAt.IsZero())
+
+	// the data of a successful response is not an error, whatever its words
+	_, err = client.GetCustomerByMobile(ctx, "token", nil, "09120000003")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), client.Stats().Throttle.Throttled)
+}
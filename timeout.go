@@ -0,0 +1,97 @@
+package goarpa
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Timeouts limit each attempt of the calls by category, including the read of the response body.
+// The zero durations do not limit the calls, the deadline of the context of the call still applies.
+type Timeouts struct {
+	// Lookup limits the reads, e.g. GetCustomerByMobile
+	Lookup time.Duration
+	// Mutation limits the calls which change data, e.g. CreateTransaction
+	Mutation time.Duration
+	// Report limits the report calls: SubmitReportJob, GetReportRows and OpenReport
+	Report time.Duration
+}
+
+// WithTimeouts applies the timeouts of the categories to the calls, e.g.
+// WithTimeouts(Timeouts{Lookup: 5 * time.Second, Mutation: 30 * time.Second, Report: 2 * time.Minute}).
+// The streams other than OpenReport are not limited, their body is read by the caller.
+func WithTimeouts(timeouts Timeouts) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.timeouts = timeouts
+	}
+}
+
+var reportTimeoutContextKey = &contextKey{"reportTimeout"}
+
+// withReportTimeout marks the calls made with the context as report calls, see Timeouts.Report
+func withReportTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reportTimeoutContextKey, true)
+}
+
+// timeoutTransport limits each request by the timeout of its category
+type timeoutTransport struct {
+	next     http.RoundTripper
+	timeouts Timeouts
+}
+
+// wrapTimeoutTransport installs the timeouts on the resty client, inside the charset conversion
+func wrapTimeoutTransport(restyClient *resty.Client, timeouts Timeouts) {
+	charset, ok := restyClient.GetClient().Transport.(*charsetTransport)
+	if !ok || timeouts == (Timeouts{}) {
+		return
+	}
+	if _, ok := charset.next.(*timeoutTransport); ok {
+		return
+	}
+	charset.next = &timeoutTransport{next: charset.next, timeouts: timeouts}
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := t.timeout(req)
+	if timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil || resp.Body == nil {
+		cancel()
+		return resp, err
+	}
+	// the timeout covers the read of the body, it is released once the body is closed
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (t *timeoutTransport) timeout(req *http.Request) time.Duration {
+	ctx := req.Context()
+	switch {
+	case ctx.Value(reportTimeoutContextKey) != nil:
+		return t.timeouts.Report
+	case ctx.Value(streamingContextKey) != nil:
+		return 0
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		return t.timeouts.Lookup
+	default:
+		return t.timeouts.Mutation
+	}
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,46 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Timeouts(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"TransactionID":"7","Number":"1","JobID":"1"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithTimeouts(goarpa.Timeouts{
+		Lookup:   20 * time.Millisecond,
+		Mutation: 5 * time.Second,
+		Report:   20 * time.Millisecond,
+	}))
+	client.Config.SubmitReportJobEndpoint = "report"
+	ctx := context.Background()
+
+	_, err := client.GetCustomerByMobile(ctx, "token", nil, "09120000000")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+	_, err = client.CreateTransaction(ctx, "token", benchmarkTransaction)
+	require.NoError(t, err)
+
+	// the report job is posted but limited by the report timeout
+	_, err = client.SubmitReportJob(ctx, "token", nil, goarpa.ReportJobRequest{ReportName: "sales"})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
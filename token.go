@@ -1,9 +1,193 @@
 package goarpa
 
-import "time"
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
 
 type JWT struct {
 	AccessToken  string    `json:"accessToken"`
 	RefreshToken string    `json:"refreshToken"`
 	ExpiresAt    time.Time `json:"expiresAt"`
 }
+
+// DefaultTokenTTL is the lifetime assumed for the tokens which do not carry their expiry
+const DefaultTokenTTL = 20 * time.Minute
+
+// WithStaticToken authenticates every call with a long-lived service key instead of a login session.
+// The access token passed to the methods may be empty, the cookies are ignored and GetAdminToken
+// returns the static token without calling GetServiceToken, so a TokenManager works unchanged.
+func WithStaticToken(token string) func(*GoArpa) {
+	return func(g *GoArpa) {
+		g.staticToken = token
+	}
+}
+
+// authToken returns the token of a call, the static token of the client unless the caller passed one
+func (g *GoArpa) authToken(token string) string {
+	if token == "" {
+		return g.staticToken
+	}
+	return token
+}
+
+// TokenManagerOptions configure a TokenManager
+type TokenManagerOptions struct {
+	// TTL is the lifetime of the tokens whose expiry is unknown, DefaultTokenTTL by default
+	TTL time.Duration
+	// RefreshBefore renews the token this long before it expires, a minute by default
+	RefreshBefore time.Duration
+	// TokenHeader is the response header Arpa rotates the access token of the session with, if any.
+	// The cookies set by the responses always update the session.
+	TokenHeader string
+}
+
+// TokenManager logs in with GetAdminToken and caches the token and the cookies of the session
+// until they expire. It is safe for concurrent use, concurrent callers share a single login.
+//
+// The session is rolling: the cookies Arpa sets on the responses of the calls made with its token update it,
+// and the calls made with a token of the session send its current token and cookies, so that the callers
+// holding the ones returned by Session keep working when Arpa rotates them.
+type TokenManager struct {
+	client   *GoArpa
+	username string
+	password string
+	options  TokenManagerOptions
+
+	mu        sync.Mutex
+	token     string
+	cookies   []*http.Cookie
+	expiresAt time.Time
+	// tokens are the tokens of the session registered with the client, the rotated ones included
+	tokens []string
+}
+
+// NewTokenManager returns a token manager logging in to the client with the credentials
+func NewTokenManager(client *GoArpa, username string, password string, options TokenManagerOptions) *TokenManager {
+	if options.TTL <= 0 {
+		options.TTL = DefaultTokenTTL
+	}
+	if options.RefreshBefore <= 0 {
+		options.RefreshBefore = time.Minute
+	}
+	return &TokenManager{client: client, username: username, password: password, options: options}
+}
+
+// Token returns a valid access token, logging in when there is none. It can be used as a TokenSource.
+func (m *TokenManager) Token(ctx context.Context) (string, error) {
+	token, _, err := m.Session(ctx)
+	return token, err
+}
+
+// Session returns a valid access token with the cookies of its session
+func (m *TokenManager) Session(ctx context.Context) (string, []*http.Cookie, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token != "" && m.client.Clock().Now().Before(m.expiresAt.Add(-m.options.RefreshBefore)) {
+		return m.token, m.cookies, nil
+	}
+	if m.token == "" && m.adoptImportedSession() {
+		return m.token, m.cookies, nil
+	}
+
+	token, cookies, err := m.client.GetAdminToken(ctx, m.username, m.password)
+	if err != nil {
+		return "", nil, err
+	}
+	m.unregister()
+	m.token = token
+	m.cookies = cookies
+	m.register(token)
+	m.expiresAt = m.client.Clock().Now().Add(m.options.TTL)
+	if expiresAt, ok := tokenExpiry(token); ok {
+		m.expiresAt = expiresAt
+	}
+	m.client.stats.trackSession(m, m.expiresAt)
+	return m.token, m.cookies, nil
+}
+
+// current returns the token and the cookies of the session
+func (m *TokenManager) current() (string, []*http.Cookie) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.token, m.cookies
+}
+
+// roll updates the session the token of a call belongs to with the token and the cookies of its response
+func (m *TokenManager) roll(callToken string, token string, cookies []*http.Cookie) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.token == "" || !slices.Contains(m.tokens, callToken) {
+		// the session was renewed or invalidated during the call
+		return
+	}
+	m.cookies = mergeCookies(m.cookies, cookies)
+	if token != "" && token != m.token {
+		m.token = token
+		m.register(token)
+	}
+}
+
+// register maps the token to the manager in the client, the lock must be held
+func (m *TokenManager) register(token string) {
+	if m.client.staticToken != "" {
+		return
+	}
+	m.tokens = append(m.tokens, token)
+	m.client.managedSessions.Store(token, m)
+}
+
+// unregister removes the tokens of the session from the client, the lock must be held
+func (m *TokenManager) unregister() {
+	for _, token := range m.tokens {
+		m.client.managedSessions.CompareAndDelete(token, m)
+	}
+	m.tokens = nil
+}
+
+// Invalidate drops the cached token, e.g. after Arpa rejected it, so that the next call logs in again
+func (m *TokenManager) Invalidate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unregister()
+	m.token = ""
+	m.cookies = nil
+	m.expiresAt = time.Time{}
+	m.client.stats.trackSession(m, m.expiresAt)
+}
+
+// jwtClaims are the claims of an access token used by the client
+type jwtClaims struct {
+	Exp int64  `json:"exp"`
+	Sub string `json:"sub"`
+}
+
+// parseJWTClaims decodes the claims of the token when it is a JWT, without verifying it
+func parseJWTClaims(token string) (jwtClaims, bool) {
+	var claims jwtClaims
+	parts := strings.Split(strings.Trim(token, `"`), ".")
+	if len(parts) != 3 {
+		return claims, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, false
+	}
+	return claims, json.Unmarshal(payload, &claims) == nil
+}
+
+// tokenExpiry returns the "exp" claim of the token when it is a JWT
+func tokenExpiry(token string) (time.Time, bool) {
+	claims, ok := parseJWTClaims(token)
+	if !ok || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
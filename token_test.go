@@ -0,0 +1,174 @@
+package goarpa_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TokenManager(t *testing.T) {
+	t.Parallel()
+	var logins atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := logins.Add(1)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: fmt.Sprint(n)})
+		_, _ = fmt.Fprintf(w, "token-%d", n)
+	}))
+	defer server.Close()
+
+	manager := goarpa.NewTokenManager(goarpa.NewClient(server.URL), "user", "pass", goarpa.TokenManagerOptions{})
+	ctx := context.Background()
+
+	token, cookies, err := manager.Session(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "1", cookies[0].Value)
+
+	token, err = manager.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, int32(1), logins.Load())
+
+	manager.Invalidate()
+	token, err = manager.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+}
+
+func Test_TokenManagerJWTExpiry(t *testing.T) {
+	t.Parallel()
+	var logins atomic.Int32
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(30*time.Second).Unix())))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logins.Add(1)
+		_, _ = w.Write([]byte("header." + payload + ".signature"))
+	}))
+	defer server.Close()
+
+	manager := goarpa.NewTokenManager(goarpa.NewClient(server.URL), "user", "pass", goarpa.TokenManagerOptions{})
+
+	// the token expires within the refresh margin, so it is renewed on every call
+	for i := 0; i < 2; i++ {
+		_, err := manager.Token(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), logins.Load())
+}
+
+func Test_StaticToken(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Equal(t, "Bearer service-key", r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("Cookie"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL, goarpa.WithStaticToken("service-key"))
+	ctx := context.Background()
+
+	token, cookies, err := client.GetAdminToken(ctx, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "service-key", token)
+	assert.Nil(t, cookies)
+	assert.Equal(t, int32(0), calls.Load())
+
+	_, err = client.GetCustomerByMobile(ctx, "", []*http.Cookie{{Name: "session", Value: "1"}}, "09120000000")
+	require.NoError(t, err)
+
+	manager := goarpa.NewTokenManager(client, "", "", goarpa.TokenManagerOptions{})
+	token, err = manager.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "service-key", token)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func Test_TokenManagerClock(t *testing.T) {
+	t.Parallel()
+	var logins atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "token-%d", logins.Add(1))
+	}))
+	defer server.Close()
+
+	clock := goarpa.NewManualClock(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+	client := goarpa.NewClient(server.URL, goarpa.WithClock(clock))
+	manager := goarpa.NewTokenManager(client, "user", "pass", goarpa.TokenManagerOptions{TTL: 10 * time.Minute})
+	ctx := context.Background()
+
+	token, err := manager.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// the token is renewed a minute before it expires
+	clock.Advance(8 * time.Minute)
+	token, err = manager.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	clock.Advance(time.Minute)
+	token, err = manager.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, 1, client.Stats().ActiveSessions)
+}
+
+func Test_TokenManagerRollingSession(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/serv/token/GetServiceToken" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "0"})
+			_, _ = w.Write([]byte("token-0"))
+			return
+		}
+		// every call rotates the session and rejects the previous one
+		n := calls.Add(1)
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != fmt.Sprint(n-1) || r.Header.Get("Authorization") != fmt.Sprintf("Bearer token-%d", n-1) {
+			_, _ = w.Write([]byte(`{"data":[],"error":"session not found"}`))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: fmt.Sprint(n)})
+		w.Header().Set("X-Session-Token", fmt.Sprintf("token-%d", n))
+		_, _ = w.Write([]byte(`{"data":[{"BusinessID":"7"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	// the cookies are updated by the client, not by its jar
+	client.RestyClient().SetCookieJar(nil)
+	manager := goarpa.NewTokenManager(client, "user", "pass", goarpa.TokenManagerOptions{TokenHeader: "X-Session-Token"})
+	ctx := context.Background()
+
+	token, cookies, err := manager.Session(ctx)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := client.GetCustomerByMobile(ctx, token, cookies, "09120000000")
+		require.NoError(t, err)
+	}
+
+	token, cookies, err = manager.Session(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-3", token)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "3", cookies[0].Value)
+
+	// a session which is not managed is sent as is
+	_, err = client.GetCustomerByMobile(ctx, "other", nil, "09120000000")
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package goarpa
+
+// TaxConfig is the value added tax applied by CalculateTotals
+type TaxConfig struct {
+	// TaxPercent is the tax percent, e.g. 9
+	TaxPercent float64
+	// TollPercent is the toll percent, e.g. 1
+	TollPercent float64
+	// Places is the number of decimal places the amounts are rounded to, 0 for whole rials
+	Places int32
+}
+
+// DefaultTaxConfig is the value added tax of 10%, 9% tax and 1% toll, rounded to whole rials
+var DefaultTaxConfig = TaxConfig{TaxPercent: 9, TollPercent: 1}
+
+// TransactionDiscount is the discount of a whole transaction, see Data.TransDiscountAmount and Data.TransDiscountPercent
+type TransactionDiscount struct {
+	Amount  Money
+	Percent float64
+}
+
+// LineTotals are the amounts of a line of a transaction
+type LineTotals struct {
+	// Gross is the price times the quantity, the free quantity is not charged
+	Gross Money
+	// Discount is the discount of the line
+	Discount Money
+	// TransactionDiscount is the share of the transaction discount allocated to the line
+	TransactionDiscount Money
+	// Taxable is the amount the tax and toll are calculated on, zero for the tax exempt lines
+	Taxable Money
+	Tax     Money
+	Toll    Money
+	// Total is the amount of the line after the discounts, with its tax and toll
+	Total Money
+}
+
+// Totals are the amounts of a transaction, see CalculateTotals
+type Totals struct {
+	Lines               []LineTotals
+	Gross               Money
+	LineDiscount        Money
+	TransactionDiscount Money
+	Tax                 Money
+	Toll                Money
+	AddSub              Money
+	// Total is the final amount of the transaction
+	Total Money
+}
+
+// CalculateTotals previews the amounts of a transaction, so that the final amount can be shown before
+// it is posted. The rounding below is assumed, it has not been checked against invoices issued by Arpa,
+// and the preview may differ from the posted amounts by a few rials, see testdata/totals to record the totals
+// of a server. Every amount of a line is rounded half away from zero:
+//
+//   - the gross amount is the price times the quantity,
+//   - the discount of the line is its discount amount plus its discount percent of the gross amount,
+//   - the transaction discount, its amount plus its percent of the lines after their discounts,
+//     is allocated to the lines in proportion to their amounts, the last line taking the rounding difference,
+//   - the tax and the toll are calculated on each line after the discounts, except on the tax exempt lines,
+//   - the additions and subtractions are added to the total without tax.
+func CalculateTotals(items []TransactionItem, addSubs []AddSub, discount TransactionDiscount, tax TaxConfig) Totals {
+	round := func(m Money) Money { return m.Round(tax.Places) }
+
+	totals := Totals{Lines: make([]LineTotals, len(items))}
+	var net Money
+	for i, item := range items {
+		line := &totals.Lines[i]
+		line.Gross = round(item.Price.Times(item.Qty))
+		line.Discount = round(item.DiscountAmount.Add(line.Gross.Percent(item.DiscountPercent)))
+		net = net.Add(line.Gross.Sub(line.Discount))
+	}
+
+	totals.TransactionDiscount = round(discount.Amount.Add(net.Percent(discount.Percent)))
+	remaining := totals.TransactionDiscount
+	for i, item := range items {
+		line := &totals.Lines[i]
+		lineNet := line.Gross.Sub(line.Discount)
+		switch {
+		case i == len(items)-1:
+			line.TransactionDiscount = remaining
+		case !net.IsZero():
+			line.TransactionDiscount = round(Money{totals.TransactionDiscount.Mul(lineNet.Decimal).Div(net.Decimal)})
+		}
+		remaining = remaining.Sub(line.TransactionDiscount)
+
+		amount := lineNet.Sub(line.TransactionDiscount)
+		if !item.TaxExempt {
+			line.Taxable = amount
+			line.Tax = round(amount.Percent(tax.TaxPercent))
+			line.Toll = round(amount.Percent(tax.TollPercent))
+		}
+		line.Total = amount.Add(line.Tax).Add(line.Toll)
+
+		totals.Gross = totals.Gross.Add(line.Gross)
+		totals.LineDiscount = totals.LineDiscount.Add(line.Discount)
+		totals.Tax = totals.Tax.Add(line.Tax)
+		totals.Toll = totals.Toll.Add(line.Toll)
+		totals.Total = totals.Total.Add(line.Total)
+	}
+
+	for _, addSub := range addSubs {
+		totals.AddSub = totals.AddSub.Add(addSub.TASAmount)
+	}
+	totals.Total = totals.Total.Add(totals.AddSub)
+	return totals
+}
+
+// CalculateTotals previews the amounts of the transaction, see CalculateTotals.
+// Like Arpa, no tax and toll are calculated when Data.CalcTaxAndToll is 0, whatever the lines.
+func (r CreateTransactionRequest) CalculateTotals(tax TaxConfig) Totals {
+	discount := TransactionDiscount{Amount: r.Data.TransDiscountAmount, Percent: r.Data.TransDiscountPercent}
+	items := r.Items
+	if r.Data.CalcTaxAndToll == 0 {
+		items = make([]TransactionItem, len(r.Items))
+		for i, item := range r.Items {
+			item.TaxExempt = true
+			items[i] = item
+		}
+	}
+	return CalculateTotals(items, r.AddSub, discount, tax)
+}
@@ -0,0 +1,172 @@
+package goarpa
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/erfandiakoo/goarpa/v2/shared/constant"
+)
+
+// TransactionItem is a line of a transaction
+type TransactionItem struct {
+	ItemID          ItemID
+	Qty             float64
+	Price           Money
+	DiscountAmount  Money
+	DiscountPercent float64
+	// TaxExempt disables tax and toll calculation for the line
+	TaxExempt bool
+	// FreeQty is the quantity given for free, on top of Qty
+	FreeQty float64
+	// WarehouseID is the warehouse the line is taken from, the warehouse of the transaction when zero
+	WarehouseID int64
+	// Extra holds additional keys which are sent as is
+	Extra map[string]*int64
+}
+
+// MarshalJSON marshals the line into the keys the NewTransaction endpoint expects
+func (i TransactionItem) MarshalJSON() ([]byte, error) {
+	if len(i.Extra) == 0 {
+		return i.appendJSON(make([]byte, 0, 128))
+	}
+
+	line := make(map[string]interface{}, len(i.Extra)+8)
+	for key, value := range i.Extra {
+		line[key] = value
+	}
+
+	line[string(constant.ItemIDKey)] = i.ItemID
+	line[string(constant.QtyKey)] = i.Qty
+	if !i.Price.IsZero() {
+		line[string(constant.PriceKey)] = i.Price
+	}
+	if !i.DiscountAmount.IsZero() {
+		line[string(constant.DiscountAmountKey)] = i.DiscountAmount
+	}
+	if i.DiscountPercent != 0 {
+		line[string(constant.DiscountPercentKey)] = i.DiscountPercent
+	}
+	if i.TaxExempt {
+		line[string(constant.CalcTaxAndTollKey)] = 0
+	}
+	if i.FreeQty != 0 {
+		line[string(constant.FreeQtyKey)] = i.FreeQty
+	}
+	if i.WarehouseID != 0 {
+		line[string(constant.StockIDKey)] = i.WarehouseID
+	}
+
+	return json.Marshal(line)
+}
+
+// appendJSON appends the line without the extra keys. The keys are sorted, like encoding/json sorts
+// the keys of the map used when there are extra keys, so both produce the same output.
+func (i TransactionItem) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	if i.TaxExempt {
+		b = append(b, `"`+string(constant.CalcTaxAndTollKey)+`":0,`...)
+	}
+	if !i.DiscountAmount.IsZero() {
+		b = append(b, `"`+string(constant.DiscountAmountKey)+`":`...)
+		b = append(append(b, i.DiscountAmount.Decimal.String()...), ',')
+	}
+	if i.DiscountPercent != 0 {
+		b = append(b, `"`+string(constant.DiscountPercentKey)+`":`...)
+		if b, err = appendJSONFloat(b, i.DiscountPercent); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if i.FreeQty != 0 {
+		b = append(b, `"`+string(constant.FreeQtyKey)+`":`...)
+		if b, err = appendJSONFloat(b, i.FreeQty); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	b = append(b, `"`+string(constant.ItemIDKey)+`":`...)
+	b = append(strconv.AppendInt(b, int64(i.ItemID), 10), ',')
+	if !i.Price.IsZero() {
+		b = append(b, `"`+string(constant.PriceKey)+`":`...)
+		b = append(append(b, i.Price.Decimal.String()...), ',')
+	}
+	b = append(b, `"`+string(constant.QtyKey)+`":`...)
+	if b, err = appendJSONFloat(b, i.Qty); err != nil {
+		return nil, err
+	}
+	if i.WarehouseID != 0 {
+		b = append(b, `,"`+string(constant.StockIDKey)+`":`...)
+		b = strconv.AppendInt(b, i.WarehouseID, 10)
+	}
+	return append(b, '}'), nil
+}
+
+// UnmarshalJSON unmarshals a line, the unknown keys are kept in Extra
+func (i *TransactionItem) UnmarshalJSON(data []byte) error {
+	var line map[string]json.RawMessage
+	if err := json.Unmarshal(data, &line); err != nil {
+		return err
+	}
+
+	*i = TransactionItem{}
+	var (
+		itemID          ItemID
+		qty             EnforcedFloat
+		discountPercent EnforcedFloat
+		calcTaxAndToll  EnforcedInt
+		freeQty         EnforcedFloat
+		warehouseID     EnforcedInt
+	)
+	fields := map[string]interface{}{
+		string(constant.ItemIDKey):          &itemID,
+		string(constant.QtyKey):             &qty,
+		string(constant.PriceKey):           &i.Price,
+		string(constant.DiscountAmountKey):  &i.DiscountAmount,
+		string(constant.DiscountPercentKey): &discountPercent,
+		string(constant.CalcTaxAndTollKey):  &calcTaxAndToll,
+		string(constant.FreeQtyKey):         &freeQty,
+		string(constant.StockIDKey):         &warehouseID,
+	}
+	for key, value := range line {
+		if field, ok := fields[key]; ok {
+			if err := json.Unmarshal(value, field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var extra *int64
+		if err := json.Unmarshal(value, &extra); err != nil {
+			return err
+		}
+		if i.Extra == nil {
+			i.Extra = make(map[string]*int64)
+		}
+		i.Extra[key] = extra
+	}
+
+	i.ItemID = itemID
+	i.Qty = float64(qty)
+	i.DiscountPercent = float64(discountPercent)
+	_, hasCalcTaxAndToll := line[string(constant.CalcTaxAndTollKey)]
+	i.TaxExempt = hasCalcTaxAndToll && calcTaxAndToll == 0
+	i.FreeQty = float64(freeQty)
+	i.WarehouseID = int64(warehouseID)
+	return nil
+}
+
+// Warehouse returns the warehouse the line is taken from in the transaction, zero for the default warehouse of Arpa
+func (i TransactionItem) Warehouse(data Data) int64 {
+	if i.WarehouseID != 0 {
+		return i.WarehouseID
+	}
+	return data.WarehouseID
+}
+
+// SetCurrency posts the amounts of the transaction in the currency, converted by Arpa with its rate,
+// e.g. a purchase invoice in USD with the rate of GetCurrencyRates
+func (d *Data) SetCurrency(rate CurrencyRate) {
+	d.CurrencyID = rate.ID.Int64()
+	d.CurrencyRate = &rate.Rate
+}
@@ -0,0 +1,189 @@
+package goarpa_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TransactionItemJSON(t *testing.T) {
+	t.Parallel()
+	item := goarpa.TransactionItem{
+		ItemID:          12,
+		Qty:             2,
+		Price:           goarpa.NewMoney(150000),
+		DiscountAmount:  goarpa.NewMoney(5000),
+		DiscountPercent: 2.5,
+		TaxExempt:       true,
+		FreeQty:         1,
+		WarehouseID:     2,
+		Extra:           map[string]*int64{"StockAreaID": goarpa.Int64P(3)},
+	}
+	b, err := json.Marshal(item)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ItemID":12,"Qty":2,"Price":150000,"DiscountAmount":5000,"DiscountPercent":2.5,"CalcTaxAndToll":0,"FreeQty":1,"StockID":2,"StockAreaID":3}`, string(b))
+
+	var decoded goarpa.TransactionItem
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, item, decoded)
+}
+
+func Test_TransactionItemJSONWithoutExtra(t *testing.T) {
+	t.Parallel()
+	// the lines without extra keys are encoded without a map, the output must not change
+	testCases := map[string]goarpa.TransactionItem{
+		`{"ItemID":1,"Qty":0}`: {ItemID: 1},
+		`{"CalcTaxAndToll":0,"DiscountAmount":5000,"DiscountPercent":2.5,"FreeQty":1,"ItemID":12,"Price":150000.5,"Qty":2}`: {
+			ItemID: 12, Qty: 2, Price: goarpa.NewMoneyFromFloat(150000.5), DiscountAmount: goarpa.NewMoney(5000),
+			DiscountPercent: 2.5, TaxExempt: true, FreeQty: 1,
+		},
+		`{"ItemID":3,"Qty":1,"StockID":2}`: {ItemID: 3, Qty: 1, WarehouseID: 2},
+		`{"ItemID":3,"Qty":1e-7}`:          {ItemID: 3, Qty: 0.0000001},
+		`{"ItemID":3,"Qty":1e+21}`:         {ItemID: 3, Qty: 1e21},
+	}
+	for expected, item := range testCases {
+		b, err := json.Marshal(item)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(b))
+	}
+}
+
+// The expected amounts are worked by hand from the rounding assumed by CalculateTotals, not taken from invoices issued by Arpa
+func Test_CalculateTotals(t *testing.T) {
+	t.Parallel()
+	transaction := goarpa.CreateTransactionRequest{
+		Data: goarpa.Data{CalcTaxAndToll: 1, TransDiscountPercent: 5},
+		Items: []goarpa.TransactionItem{
+			{ItemID: 1, Qty: 2, Price: goarpa.NewMoney(125000), DiscountPercent: 10},
+			{ItemID: 2, Qty: 1.5, Price: goarpa.NewMoney(9900), DiscountAmount: goarpa.NewMoney(850)},
+			{ItemID: 3, Qty: 1, FreeQty: 1, Price: goarpa.NewMoney(33333), TaxExempt: true},
+		},
+		AddSub: []goarpa.AddSub{
+			{AddSubID: 1, TASAmount: goarpa.NewMoney(50000)},
+			{AddSubID: 2, TASAmount: goarpa.NewMoney(-2000)},
+		},
+	}
+
+	totals := transaction.CalculateTotals(goarpa.DefaultTaxConfig)
+	amounts := func(line goarpa.LineTotals) []string {
+		return []string{line.Gross.String(), line.Discount.String(), line.TransactionDiscount.String(), line.Tax.String(), line.Toll.String(), line.Total.String()}
+	}
+	// the tax of 213750 is 19237.5, rounded half away from zero
+	assert.Equal(t, []string{"250000", "25000", "11250", "19238", "2138", "235126"}, amounts(totals.Lines[0]))
+	assert.Equal(t, []string{"14850", "850", "700", "1197", "133", "14630"}, amounts(totals.Lines[1]))
+	// the last line takes the rounding difference of the transaction discount, the free quantity is not charged
+	assert.Equal(t, []string{"33333", "0", "1667", "0", "0", "31666"}, amounts(totals.Lines[2]))
+	assert.Equal(t, "13617", totals.TransactionDiscount.String())
+	assert.Equal(t, "20435", totals.Tax.String())
+	assert.Equal(t, "2271", totals.Toll.String())
+	assert.Equal(t, "48000", totals.AddSub.String())
+	assert.Equal(t, "329422", totals.Total.String())
+
+	// a transaction which does not calculate the tax and toll has none on any line
+	transaction.Data.CalcTaxAndToll = 0
+	totals = transaction.CalculateTotals(goarpa.DefaultTaxConfig)
+	assert.Equal(t, "0", totals.Tax.String())
+	assert.Equal(t, "0", totals.Toll.String())
+	assert.Equal(t, "306716", totals.Total.String())
+	assert.False(t, transaction.Items[0].TaxExempt)
+
+	assert.Equal(t, "0", goarpa.CalculateTotals(nil, nil, goarpa.TransactionDiscount{}, goarpa.DefaultTaxConfig).Total.String())
+}
+
+// totalsFixture is a transaction of testdata/totals with the TotalAmount listed by Arpa once posted
+type totalsFixture struct {
+	Transaction goarpa.CreateTransactionRequest `json:"transaction"`
+	TotalAmount goarpa.Money                    `json:"totalAmount"`
+}
+
+// readTotalsFixtures returns the fixtures of testdata/totals by name
+func readTotalsFixtures(t *testing.T) map[string]totalsFixture {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "totals", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	fixtures := make(map[string]totalsFixture, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var fixture totalsFixture
+		require.NoError(t, json.Unmarshal(data, &fixture), path)
+		fixtures[strings.TrimSuffix(filepath.Base(path), ".json")] = fixture
+	}
+	return fixtures
+}
+
+// Test_CalculateTotalsFixtures checks the preview of the transactions against the totals listed by Arpa,
+// see testdata/totals/README.md
+func Test_CalculateTotalsFixtures(t *testing.T) {
+	t.Parallel()
+	for name, fixture := range readTotalsFixtures(t) {
+		totals := fixture.Transaction.CalculateTotals(goarpa.DefaultTaxConfig)
+		assert.True(t, fixture.TotalAmount.Equal(totals.Total.Decimal), "%s: expected %s, previewed %s", name, fixture.TotalAmount, totals.Total)
+	}
+}
+
+func Test_TransactionShiftID(t *testing.T) {
+	t.Parallel()
+	data, err := json.Marshal(goarpa.Data{BusinessID: 42, TransStateID: goarpa.TransStateFinal, FactorTypeID: goarpa.FactorTypeSale})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "ShiftID")
+
+	data, err = json.Marshal(goarpa.Data{BusinessID: 42, TransStateID: goarpa.TransStateFinal, FactorTypeID: goarpa.FactorTypeSale, ShiftID: 7})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"ShiftID":7`)
+}
+
+func Test_TransactionWithoutState(t *testing.T) {
+	t.Parallel()
+	data, err := json.Marshal(goarpa.CreateTransactionRequest{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "TransStateId")
+	assert.NotContains(t, string(data), "FactorTypeId")
+
+	data, err = json.Marshal(goarpa.CreateTransactionRequest{Data: goarpa.Data{BusinessID: 42, FactorTypeID: goarpa.FactorTypeSale}})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"FactorTypeId":1`)
+	assert.NotContains(t, string(data), "TransStateId")
+
+	_, err = json.Marshal(goarpa.Data{TransStateID: goarpa.TransState(9)})
+	require.Error(t, err)
+}
+
+func Test_TransactionListEnums(t *testing.T) {
+	t.Parallel()
+	var transactions []goarpa.Transaction
+	require.NoError(t, json.Unmarshal([]byte(`[{"TransStateID":"2","FactorTypeID":3},{"TransStateID":null,"FactorTypeID":null}]`), &transactions))
+	assert.Equal(t, goarpa.TransStateFinal, transactions[0].TransStateID)
+	assert.Equal(t, goarpa.FactorTypePurchase, transactions[0].FactorTypeID)
+	assert.Zero(t, transactions[1].TransStateID)
+	assert.Zero(t, transactions[1].FactorTypeID)
+
+	// the transactions without a state are marshalled too
+	_, err := json.Marshal(transactions)
+	require.NoError(t, err)
+
+	var transaction goarpa.Transaction
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"FactorTypeID":9}`), &transaction), "invalid factor type: 9")
+}
+
+func Test_TransactionCurrency(t *testing.T) {
+	t.Parallel()
+	transaction := goarpa.Data{BusinessID: 42, TransStateID: goarpa.TransStateFinal, FactorTypeID: goarpa.FactorTypePurchase}
+	data, err := json.Marshal(transaction)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Currency")
+
+	var rates []goarpa.CurrencyRate
+	require.NoError(t, json.Unmarshal([]byte(`[{"CurrencyID":"2","CurrencyCode":"USD","CurrencyRate":"615000.5"}]`), &rates))
+	transaction.SetCurrency(rates[0])
+	data, err = json.Marshal(transaction)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"CurrencyID":2,"CurrencyRate":615000.5`)
+}
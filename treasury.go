@@ -0,0 +1,87 @@
+package goarpa
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// GetCashMovementsParams are the params of GetReceipts and GetPayments
+type GetCashMovementsParams struct {
+	ListParams
+	BusinessID *BusinessID `json:"BusinessID,omitempty"`
+	FromDate   *time.Time  `json:"FromDate,omitempty"`
+	ToDate     *time.Time  `json:"ToDate,omitempty"`
+}
+
+// CashMovement is a receipt from a business or a payment to a business recorded by the treasury
+type CashMovement struct {
+	DocID      StringInt64 `json:"DocID"`
+	DocNumber  EnforcedInt `json:"DocNumber"`
+	BusinessID BusinessID  `json:"BusinessID"`
+	DocDate    *CustomTime `json:"DocDate"`
+	Amount     Money       `json:"Amount"`
+	// SettlementID is the settlement type of the movement, e.g. cash, card or cheque, see GetSettlements
+	SettlementID StringInt64    `json:"SettlementID"`
+	Description  EnforcedString `json:"Description"`
+	// TransactionID is the invoice the movement settles, zero when it is not attributed to one
+	TransactionID    TransactionID `json:"TransactionID"`
+	ModificationDate *CustomTime   `json:"Modification_Date"`
+}
+
+// GetCashMovementsResponse is the response of GetReceipts and GetPayments
+type GetCashMovementsResponse = APIResponse[CashMovement]
+
+// GetReceipts returns a page of the receipts of the treasury, the money received from the businesses.
+// It returns ErrNotSupported when the installation has no receipt list endpoint.
+func (g *GoArpa) GetReceipts(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) (*GetCashMovementsResponse, error) {
+	const errMessage = "could not get receipts"
+
+	var result GetCashMovementsResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetReceiptsEndpoint, params, &result, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetPayments returns a page of the payments of the treasury, the money paid to the businesses.
+// It returns ErrNotSupported when the installation has no payment list endpoint.
+func (g *GoArpa) GetPayments(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) (*GetCashMovementsResponse, error) {
+	const errMessage = "could not get payments"
+
+	var result GetCashMovementsResponse
+	if err := g.getList(ctx, accessToken, cookie, g.config().GetPaymentsEndpoint, params, &result, errMessage); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// IterateReceipts iterates over all the receipts, fetching the pages transparently
+func (g *GoArpa) IterateReceipts(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) iter.Seq2[CashMovement, error] {
+	return iterateCashMovements(ctx, params, func(ctx context.Context, params GetCashMovementsParams) (*GetCashMovementsResponse, error) {
+		return g.GetReceipts(ctx, accessToken, cookie, params)
+	})
+}
+
+// IteratePayments iterates over all the payments, fetching the pages transparently
+func (g *GoArpa) IteratePayments(ctx context.Context, accessToken string, cookie []*http.Cookie, params GetCashMovementsParams) iter.Seq2[CashMovement, error] {
+	return iterateCashMovements(ctx, params, func(ctx context.Context, params GetCashMovementsParams) (*GetCashMovementsResponse, error) {
+		return g.GetPayments(ctx, accessToken, cookie, params)
+	})
+}
+
+// iterateCashMovements iterates over the pages of receipts or payments returned by get
+func iterateCashMovements(ctx context.Context, params GetCashMovementsParams, get func(context.Context, GetCashMovementsParams) (*GetCashMovementsResponse, error)) iter.Seq2[CashMovement, error] {
+	return iteratePages(ctx, params.ListParams, func(ctx context.Context, paging ListParams) ([]CashMovement, error) {
+		params := params
+		params.ListParams = paging
+		result, err := get(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	})
+}
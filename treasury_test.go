@@ -0,0 +1,65 @@
+package goarpa_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetReceiptsAndPayments(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		query := r.URL.Query()
+		assert.Equal(t, "7", query.Get("BusinessID"))
+		assert.NotEmpty(t, query.Get("FromDate"))
+		switch r.URL.Path {
+		case "/receipts":
+			// two pages of two receipts then a page of one
+			page := query.Get("PageNumber")
+			if page == "3" {
+				_, _ = w.Write([]byte(`{"data":[{"DocID":"5","BusinessID":"7","Amount":"500","SettlementID":"1"}],"error":null}`))
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"data":[{"DocID":"%[1]s1","Amount":100},{"DocID":"%[1]s2","Amount":"200"}],"error":null}`, page)
+		case "/payments":
+			_, _ = w.Write([]byte(`{"data":[{"DocID":"9","DocNumber":"12","BusinessID":"7","Amount":"50","DocDate":"2024-02-01 10:00:00"}],"error":null}`))
+		}
+	}))
+	defer server.Close()
+
+	client := goarpa.NewClient(server.URL)
+	ctx := context.Background()
+	businessID := goarpa.BusinessID(7)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := goarpa.GetCashMovementsParams{BusinessID: &businessID, FromDate: &from}
+	_, err := client.GetReceipts(ctx, "token", nil, params)
+	assert.True(t, errors.Is(err, goarpa.ErrNotSupported))
+
+	client.Config.GetReceiptsEndpoint = "receipts"
+	client.Config.GetPaymentsEndpoint = "payments"
+	params.PageSize = 2
+	var total goarpa.Money
+	var count int
+	for receipt, err := range client.IterateReceipts(ctx, "token", nil, params) {
+		require.NoError(t, err)
+		total = total.Add(receipt.Amount)
+		count++
+	}
+	assert.Equal(t, 5, count)
+	assert.Equal(t, "1100", total.String())
+
+	payments, err := client.GetPayments(ctx, "token", nil, params)
+	require.NoError(t, err)
+	require.Len(t, payments.Data, 1)
+	assert.Equal(t, goarpa.EnforcedInt(12), payments.Data[0].DocNumber)
+	assert.Equal(t, 2024, payments.Data[0].DocDate.Year())
+}
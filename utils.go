@@ -12,9 +12,26 @@ import (
 	ptime "github.com/yaa110/go-persian-calendar"
 )
 
-type contextKey string
+// contextKey is a pointer when used, so that passing it to context.Value does not allocate
+type contextKey struct {
+	name string
+}
+
+var tracerContextKey = &contextKey{"tracer"}
+
+// ToPtr returns a pointer of a variable of any type, e.g. ToPtr(RealPerson)
+func ToPtr[T any](value T) *T {
+	return &value
+}
 
-var tracerContextKey = contextKey("tracer")
+// FromPtr returns the value of a pointer or the zero value of the type if the pointer is nil
+func FromPtr[T any](value *T) T {
+	if value == nil {
+		var zero T
+		return zero
+	}
+	return *value
+}
 
 // StringP returns a pointer of a string variable
 func StringP(value string) *string {
@@ -129,10 +146,21 @@ func NilOrEmptySlice(value *[]string) bool {
 }
 
 // WithTracer generates a context that has a tracer attached
+// The tracer of the context takes precedence over opentracing.GlobalTracer()
 func WithTracer(ctx context.Context, tracer opentracing.Tracer) context.Context {
 	return context.WithValue(ctx, tracerContextKey, tracer)
 }
 
+// TracerFromContext returns the tracer attached to the context
+// or opentracing.GlobalTracer() if there is none
+func TracerFromContext(ctx context.Context) opentracing.Tracer {
+	tracer, ok := ctx.Value(tracerContextKey).(opentracing.Tracer)
+	if !ok || tracer == nil {
+		return opentracing.GlobalTracer()
+	}
+	return tracer
+}
+
 func GregorianToShamsi(gDate string) string {
 	parts := strings.Split(gDate, "-")
 
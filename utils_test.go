@@ -0,0 +1,42 @@
+package goarpa_test
+
+import (
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PointerHelpers(t *testing.T) {
+	t.Parallel()
+	realOrFinancial := goarpa.ToPtr(goarpa.RealPerson)
+	assert.Equal(t, goarpa.RealPerson, *realOrFinancial)
+	assert.Equal(t, goarpa.RealPerson, goarpa.FromPtr(realOrFinancial))
+	assert.Equal(t, goarpa.RealOrFinancial(0), goarpa.FromPtr[goarpa.RealOrFinancial](nil))
+	assert.Equal(t, "", goarpa.PString(nil))
+	assert.Equal(t, int64(5), goarpa.PInt64(goarpa.Int64P(5)))
+}
+
+func Test_NormalizeMobile(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{"09121234567", "+989121234567", "00989121234567", "9121234567", "۰۹۱۲ ۱۲۳ ۴۵۶۷", "٠٩١٢-١٢٣-٤٥٦٧"} {
+		mobile, err := goarpa.NormalizeMobile(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, "09121234567", mobile, input)
+	}
+	_, err := goarpa.NormalizeMobile("0912123456")
+	assert.Error(t, err)
+
+	phone, err := goarpa.NormalizePhone("+98 (21) 8888-1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "02188881234", phone)
+}
+
+func Test_NormalizeText(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "علی کریمی", goarpa.NormalizeText("  علي   كريمي "))
+	assert.Equal(t, "موسی", goarpa.NormalizeText("موسى"))
+	assert.Equal(t, "محمد", goarpa.NormalizeText("مُحَمّـد"))
+	assert.Equal(t, "کد 1402", goarpa.NormalizeText("كد ۱۴۰۲"))
+	assert.Equal(t, "0123", goarpa.NormalizeDigits("٠١٢٣"))
+}
@@ -0,0 +1,105 @@
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// sensitiveKeys are the lower cased query params and JSON fields which are scrubbed
+var sensitiveKeys = map[string]bool{
+	"username":      true,
+	"password":      true,
+	"token":         true,
+	"access_token":  true,
+	"accesstoken":   true,
+	"refresh_token": true,
+	"refreshtoken":  true,
+	"nationalcode":  true,
+	"nationalid":    true,
+	"fincode":       true,
+	"idno":          true,
+}
+
+// SanitizeDefaults scrubs the credentials, the tokens, the cookies and the national codes of the interaction.
+// The response of a token request, whose body is the token itself, is replaced entirely.
+func SanitizeDefaults(interaction *Interaction) {
+	request := &interaction.Request
+	for _, header := range []string{"Authorization", "Cookie"} {
+		if request.Header.Get(header) != "" {
+			request.Header.Set(header, Redacted)
+		}
+	}
+	// the headers which vary between runs would break the matching
+	request.Header.Del("User-Agent")
+	request.Header.Del("Traceparent")
+
+	isTokenRequest := false
+	if u, err := url.Parse(request.URL); err == nil {
+		query := u.Query()
+		for key := range query {
+			if sensitiveKeys[strings.ToLower(key)] {
+				query.Set(key, Redacted)
+				isTokenRequest = isTokenRequest || strings.EqualFold(key, "password")
+			}
+		}
+		u.RawQuery = query.Encode()
+		request.URL = u.String()
+	}
+	request.Body = sanitizeJSON(request.Body)
+
+	response := &interaction.Response
+	if response.Header.Get("Set-Cookie") != "" {
+		cookies := response.Header.Values("Set-Cookie")
+		response.Header.Del("Set-Cookie")
+		for _, cookie := range cookies {
+			name, _, _ := strings.Cut(cookie, "=")
+			response.Header.Add("Set-Cookie", name+"="+Redacted)
+		}
+	}
+	if isTokenRequest && response.StatusCode < 400 {
+		response.Body = Redacted
+		return
+	}
+	response.Body = sanitizeJSON(response.Body)
+}
+
+// sanitizeJSON scrubs the sensitive fields of a JSON body, other bodies are returned as is
+func sanitizeJSON(body string) string {
+	if body == "" {
+		return body
+	}
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(scrub(value)); err != nil {
+		return body
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func scrub(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveKeys[strings.ToLower(key)] && field != nil {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = scrub(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = scrub(item)
+		}
+	}
+	return value
+}
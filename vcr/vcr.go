@@ -0,0 +1,221 @@
+// Package vcr records the HTTP interactions with Arpa into cassette files and replays them,
+// so that tests written against a real server can run offline.
+//
+// The interactions are sanitized before being written: the credentials, tokens, cookies and
+// national codes are scrubbed, and only the path and the query of the URLs are kept.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the sensitive values
+const Redacted = "[REDACTED]"
+
+// Mode is the mode of a Recorder
+type Mode int
+
+const (
+	// ModeReplay serves the interactions of the cassette and fails the requests which are not in it
+	ModeReplay Mode = iota
+	// ModeRecord sends the requests to the server and records the interactions
+	ModeRecord
+)
+
+// ErrInteractionNotFound is returned in replay mode for a request which is not in the cassette
+var ErrInteractionNotFound = errors.New("interaction not found in cassette")
+
+// Request is a recorded request
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction is a request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the content of a cassette file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is a http.RoundTripper recording or replaying a cassette
+type Recorder struct {
+	path       string
+	mode       Mode
+	next       http.RoundTripper
+	sanitizers []func(*Interaction)
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// WithTransport sets the transport of the requests sent in record mode, http.DefaultTransport by default
+func WithTransport(next http.RoundTripper) func(*Recorder) {
+	return func(r *Recorder) {
+		r.next = next
+	}
+}
+
+// WithSanitizer adds a sanitizer which is applied to the interactions after the default ones.
+// In replay mode, it is applied to the live requests before they are matched.
+func WithSanitizer(sanitizer func(*Interaction)) func(*Recorder) {
+	return func(r *Recorder) {
+		r.sanitizers = append(r.sanitizers, sanitizer)
+	}
+}
+
+// New returns a recorder of the cassette at path. In replay mode, the cassette must exist.
+func New(path string, mode Mode, options ...func(*Recorder)) (*Recorder, error) {
+	r := &Recorder{
+		path:       path,
+		mode:       mode,
+		next:       http.DefaultTransport,
+		sanitizers: []func(*Interaction){SanitizeDefaults},
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Exists returns true if the cassette file exists
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// RoundTrip records or replays the request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	interaction := Interaction{Request: Request{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Header: req.Header.Clone(),
+		Body:   string(body),
+	}}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, interaction)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction.Response = Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       string(respBody),
+	}
+	r.sanitize(&interaction)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Stop writes the cassette in record mode
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0o644)
+}
+
+// replay returns the response of the first unused interaction matching the request
+func (r *Recorder) replay(req *http.Request, interaction Interaction) (*http.Response, error) {
+	r.sanitize(&interaction)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, recorded := range r.cassette.Interactions {
+		if r.used[i] || !matches(recorded.Request, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.Response.StatusCode, http.StatusText(recorded.Response.StatusCode)),
+			StatusCode:    recorded.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(recorded.Response.Body)),
+			ContentLength: int64(len(recorded.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, interaction.Request.Method, interaction.Request.URL)
+}
+
+func (r *Recorder) sanitize(interaction *Interaction) {
+	for _, sanitizer := range r.sanitizers {
+		sanitizer(interaction)
+	}
+}
+
+func matches(recorded Request, request Request) bool {
+	return recorded.Method == request.Method && recorded.URL == request.URL && recorded.Body == request.Body
+}
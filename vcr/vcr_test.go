@@ -0,0 +1,69 @@
+package vcr_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erfandiakoo/goarpa/v2/vcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RecordAndReplay(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_, _ = w.Write([]byte("secret-token"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"BusinessID":"7","NationalCode":"0012345679"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, err := vcr.New(path, vcr.ModeRecord)
+	require.NoError(t, err)
+	client := &http.Client{Transport: recorder}
+
+	get := func(client *http.Client, url string, token string) string {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	assert.Equal(t, "secret-token", get(client, server.URL+"/token?username=admin&password=p4ss", ""))
+	assert.Contains(t, get(client, server.URL+"/customers", "secret-token"), "0012345679")
+	require.NoError(t, recorder.Stop())
+
+	cassette, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, secret := range []string{"p4ss", "secret-token", "0012345679", strings.TrimPrefix(server.URL, "http://")} {
+		assert.NotContains(t, string(cassette), secret)
+	}
+
+	// the replay does not need the server, nor the real credentials
+	server.Close()
+	recorder, err = vcr.New(path, vcr.ModeReplay)
+	require.NoError(t, err)
+	client = &http.Client{Transport: recorder}
+	token := get(client, "http://arpa.invalid/token?username=u&password=p", "")
+	assert.Equal(t, vcr.Redacted, token)
+	assert.Contains(t, get(client, "http://arpa.invalid/customers", token), `"BusinessID":"7"`)
+
+	_, err = client.Get("http://arpa.invalid/items")
+	assert.ErrorIs(t, err, vcr.ErrInteractionNotFound)
+}
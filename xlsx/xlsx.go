@@ -0,0 +1,275 @@
+// Package xlsx writes query results to Excel workbooks formatted for Persian readers:
+// right-to-left sheets, Jalali date columns and grouped amounts.
+//
+// The workbooks are written without any third party dependency and streamed row by row,
+// so large exports do not have to be held in memory.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	ptime "github.com/yaa110/go-persian-calendar"
+)
+
+// Column is a column of the sheet. Value returns a string, a bool, an integer, a float,
+// a goarpa.Money, a time.Time (written as a Jalali date) or nil for an empty cell.
+type Column[T any] struct {
+	Header string
+	Width  float64
+	Value  func(record T) any
+}
+
+// Options configure the workbook
+type Options struct {
+	// SheetName is the name of the sheet, "Sheet1" by default
+	SheetName string
+	// LeftToRight disables the right-to-left layout of the sheet
+	LeftToRight bool
+	// WithTime writes the time of the date columns after the Jalali date
+	WithTime bool
+}
+
+// Write writes the records as a single sheet workbook
+func Write[T any](w io.Writer, columns []Column[T], records iter.Seq2[T, error], options Options) error {
+	if options.SheetName == "" {
+		options.SheetName = "Sheet1"
+	}
+
+	archive := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+		{"xl/styles.xml", styles},
+		{"xl/workbook.xml", fmt.Sprintf(workbook, escape(options.SheetName))},
+	} {
+		if err := writePart(archive, part.name, part.content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeSheet(sheet, columns, records, options); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// Customers returns the default columns of a customer sheet
+func Customers() []Column[goarpa.Customer] {
+	return []Column[goarpa.Customer]{
+		{Header: "شناسه", Width: 10, Value: func(c goarpa.Customer) any { return c.ID.Int64() }},
+		{Header: "کد", Width: 12, Value: func(c goarpa.Customer) any { return c.Code }},
+		{Header: "نام", Width: 30, Value: func(c goarpa.Customer) any { return c.Name }},
+		{Header: "کد ملی", Width: 14, Value: func(c goarpa.Customer) any { return c.NationalCode }},
+		{Header: "موبایل", Width: 14, Value: func(c goarpa.Customer) any { return c.Mobile }},
+		{Header: "تلفن", Width: 14, Value: func(c goarpa.Customer) any { return c.Phone }},
+		{Header: "آدرس", Width: 40, Value: func(c goarpa.Customer) any { return c.Address }},
+		{Header: "سقف اعتبار", Width: 16, Value: func(c goarpa.Customer) any { return c.UnCashCredit }},
+		{Header: "غیرفعال", Width: 8, Value: func(c goarpa.Customer) any { return c.Inactive }},
+		{Header: "تاریخ ایجاد", Width: 12, Value: func(c goarpa.Customer) any { return c.CreatedAt }},
+		{Header: "تاریخ ویرایش", Width: 12, Value: func(c goarpa.Customer) any { return c.ModifiedAt }},
+	}
+}
+
+// Transactions returns the default columns of a transaction sheet
+func Transactions() []Column[goarpa.Transaction] {
+	return []Column[goarpa.Transaction]{
+		{Header: "شناسه", Width: 10, Value: func(t goarpa.Transaction) any { return t.TransactionID.Int64() }},
+		{Header: "شماره", Width: 10, Value: func(t goarpa.Transaction) any { return int64(t.TransNumber) }},
+		{Header: "شناسه طرف حساب", Width: 14, Value: func(t goarpa.Transaction) any { return t.BusinessID.Int64() }},
+		{Header: "تاریخ", Width: 12, Value: func(t goarpa.Transaction) any { return customTime(t.TransDate) }},
+		{Header: "مبلغ", Width: 16, Value: func(t goarpa.Transaction) any { return t.TotalAmount }},
+		{Header: "شرح", Width: 40, Value: func(t goarpa.Transaction) any { return string(t.Description) }},
+	}
+}
+
+// WriteCustomers writes the customers with the default columns
+func WriteCustomers(w io.Writer, customers iter.Seq2[goarpa.Customer, error], options Options) error {
+	return Write(w, Customers(), customers, options)
+}
+
+// WriteTransactions writes the transactions with the default columns
+func WriteTransactions(w io.Writer, transactions iter.Seq2[goarpa.Transaction, error], options Options) error {
+	return Write(w, Transactions(), transactions, options)
+}
+
+func customTime(t *goarpa.CustomTime) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+// the style indexes of styles.xml
+const (
+	styleDefault = 0
+	styleHeader  = 1
+	styleAmount  = 2
+	styleDate    = 3
+)
+
+func writeSheet[T any](w io.Writer, columns []Column[T], records iter.Seq2[T, error], options Options) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	fmt.Fprintf(&b, `<sheetViews><sheetView workbookViewId="0" rightToLeft="%d"><pane ySplit="1" topLeftCell="A2" state="frozen"/></sheetView></sheetViews>`, boolInt(!options.LeftToRight))
+
+	b.WriteString(`<cols>`)
+	for i, column := range columns {
+		width := column.Width
+		if width <= 0 {
+			width = 15
+		}
+		fmt.Fprintf(&b, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', -1, 64))
+	}
+	b.WriteString(`</cols><sheetData>`)
+
+	b.WriteString(`<row r="1">`)
+	for i, column := range columns {
+		writeCell(&b, cellRef(i, 1), column.Header, styleHeader, options)
+	}
+	b.WriteString(`</row>`)
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+
+	row := 1
+	for record, err := range records {
+		if err != nil {
+			return err
+		}
+		row++
+
+		b.Reset()
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		for i, column := range columns {
+			writeCell(&b, cellRef(i, row), column.Value(record), styleDefault, options)
+		}
+		b.WriteString(`</row>`)
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, `</sheetData></worksheet>`)
+	return err
+}
+
+func writeCell(b *strings.Builder, ref string, value any, style int, options Options) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case string:
+		if v == "" {
+			return
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t>%s</t></is></c>`, ref, style, escape(v))
+	case bool:
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="b"><v>%d</v></c>`, ref, style, boolInt(v))
+	case int:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+	case int64:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+	case float64:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleAmount, strconv.FormatFloat(v, 'f', -1, 64))
+	case goarpa.Money:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleAmount, v.String())
+	case time.Time:
+		if v.IsZero() {
+			return
+		}
+		writeCell(b, ref, jalali(v, options.WithTime), styleDate, options)
+	default:
+		writeCell(b, ref, fmt.Sprint(v), style, options)
+	}
+}
+
+// jalali formats the time as a Jalali date in the Iran time zone
+func jalali(t time.Time, withTime bool) string {
+	pt := ptime.New(t.In(ptime.Iran()))
+	year, month, day := pt.Date()
+	date := fmt.Sprintf("%d/%02d/%02d", year, month, day)
+	if withTime {
+		date += fmt.Sprintf(" %02d:%02d", pt.Hour(), pt.Minute())
+	}
+	return date
+}
+
+// cellRef returns the A1 reference of the cell
+func cellRef(column int, row int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func escape(value string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+func writePart(archive *zip.Writer, name string, content string) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
+
+const contentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const workbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+const workbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+// styles defines the default, header (bold), amount (#,##0.##) and date (centered) cell styles
+const styles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="#,##0.##"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Tahoma"/></font><font><b/><sz val="11"/><name val="Tahoma"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0" applyAlignment="1"><alignment horizontal="center"/></xf>
+</cellXfs>
+</styleSheet>`
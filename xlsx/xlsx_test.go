@@ -0,0 +1,59 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/erfandiakoo/goarpa/v2"
+	"github.com/erfandiakoo/goarpa/v2/xlsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptime "github.com/yaa110/go-persian-calendar"
+)
+
+func Test_WriteTransactions(t *testing.T) {
+	t.Parallel()
+	transactions := []goarpa.Transaction{{
+		TransactionID: 42,
+		TransNumber:   7,
+		TransDate:     &goarpa.CustomTime{Time: time.Date(2024, 3, 1, 10, 0, 0, 0, ptime.Iran())},
+		TotalAmount:   goarpa.NewMoney(1250000),
+		Description:   "فروش <نقدی>",
+	}}
+	records := func(yield func(goarpa.Transaction, error) bool) {
+		for _, transaction := range transactions {
+			if !yield(transaction, nil) {
+				return
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, xlsx.WriteTransactions(&out, records, xlsx.Options{SheetName: "فاکتورها"}))
+
+	archive, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+	var sheet, workbook string
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		switch file.Name {
+		case "xl/worksheets/sheet1.xml":
+			sheet = string(content)
+		case "xl/workbook.xml":
+			workbook = string(content)
+		}
+	}
+
+	assert.Contains(t, workbook, `name="فاکتورها"`)
+	assert.Contains(t, sheet, `rightToLeft="1"`)
+	assert.Contains(t, sheet, `<c r="A2" s="0"><v>42</v></c>`)
+	assert.Contains(t, sheet, `<t>1402/12/11</t>`)
+	assert.Contains(t, sheet, `<c r="E2" s="2"><v>1250000</v></c>`)
+	assert.Contains(t, sheet, `<t>فروش &lt;نقدی&gt;</t>`)
+}